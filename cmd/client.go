@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/codegen"
+)
+
+var (
+	clientSchemaFile    string
+	clientOperationsDir string
+	clientPackageName   string
+	clientOutFile       string
+)
+
+// clientCmd represents the client command
+var clientCmd = &cobra.Command{
+	Use:   "client",
+	Short: "Generates a typed go client for GraphQL operations",
+	Long: `client generates a struct for the variables and the response of every named query and mutation in the operations directory
+and a function executing the operation, either against an HTTP endpoint or an execution engine running in the same process.
+All .graphql files of the operations directory are loaded into a single document, so fragments can be shared between them.`,
+	Example: `graphql-go-tools gen client -s ./schema.graphql -d ./operations -p client -o ./client/client.go`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var out io.Writer
+		if clientOutFile == "" {
+			out = cmd.OutOrStdout()
+		} else {
+			o, err := os.Create(clientOutFile)
+			if err != nil {
+				return err
+			}
+			defer o.Close()
+			out = o
+		}
+
+		gen := codegen.New(codegen.Config{
+			PackageName: clientPackageName,
+		})
+		return gen.GenerateFromFiles(clientSchemaFile, clientOperationsDir, out)
+	},
+}
+
+func init() {
+	genCmd.AddCommand(clientCmd)
+
+	clientCmd.Flags().StringVarP(&clientSchemaFile, "schema", "s", "", "schema is the file of the GraphQL schema (required)")
+	_ = clientCmd.MarkFlagRequired("schema")
+
+	clientCmd.Flags().StringVarP(&clientOperationsDir, "operations", "d", "", "operations is the directory containing the .graphql files of the operations (required)")
+	_ = clientCmd.MarkFlagRequired("operations")
+
+	clientCmd.Flags().StringVarP(&clientPackageName, "packageName", "p", "client", "packageName is the package for the generated code (optional)")
+
+	clientCmd.Flags().StringVarP(&clientOutFile, "outFile", "o", "", "outFile is a flag to redirect the output directly into a file (optional)")
+}
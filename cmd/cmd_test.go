@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/graphqlerrors"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/operationreport"
+)
+
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	out := &bytes.Buffer{}
+	rootCmd.SetOutput(out)
+	rootCmd.SetArgs(args)
+	err := rootCmd.Execute()
+	return out.String(), err
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("valid operations", func(t *testing.T) {
+		out, err := run(t, "validate", "-s", "testdata/schema.graphql", "testdata/hero.graphql", "testdata/droids.graphql")
+		require.NoError(t, err)
+		assert.Empty(t, out)
+	})
+
+	t.Run("invalid operation", func(t *testing.T) {
+		out, err := run(t, "validate", "-s", "testdata/schema.graphql", "testdata/hero.graphql", "testdata/invalid.graphql")
+		assert.Equal(t, errValidationFailed, err)
+		assert.Contains(t, out, "testdata/invalid.graphql: field: unknown not defined on type: Character\n")
+		assert.NotContains(t, out, "hero.graphql")
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		out, err := run(t, "validate", "-s", "testdata/schema.graphql", "testdata/syntax_error.graphql", "testdata/invalid.graphql")
+		assert.Equal(t, errValidationFailed, err)
+		assert.Equal(t, "testdata/syntax_error.graphql:5:13: unexpected token - got: RPAREN want one of: []\n"+
+			"testdata/invalid.graphql: field: unknown not defined on type: Character\n", out)
+	})
+}
+
+func TestFormatReport(t *testing.T) {
+	report := operationreport.Report{}
+	report.AddInternalError(errors.New("failed"))
+	report.AddExternalError(operationreport.ExternalError{
+		Message:   "fragment spread is not used",
+		Locations: []graphqlerrors.Location{{Line: 1, Column: 5}, {Line: 7, Column: 3}},
+	})
+	report.AddExternalError(operationreport.ExternalError{Message: "operation is not defined"})
+
+	assert.Equal(t, "query.graphql: internal error: failed\n"+
+		"query.graphql:1:5: fragment spread is not used\n"+
+		"query.graphql:7:3: fragment spread is not used\n"+
+		"query.graphql: operation is not defined", formatReport("query.graphql", report))
+	assert.Equal(t, "internal error: failed\n"+
+		"1:5: fragment spread is not used\n"+
+		"7:3: fragment spread is not used\n"+
+		"operation is not defined", formatReport("", report))
+}
+
+func TestNormalize(t *testing.T) {
+	t.Run("operation", func(t *testing.T) {
+		out, err := run(t, "normalize", "-s", "testdata/schema.graphql", "-o", "Hero", "testdata/hero.graphql")
+		require.NoError(t, err)
+		assert.Equal(t, "query Hero {\n    hero {\n        name\n    }\n}\n", out)
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		out, err := run(t, "normalize", "-s", "testdata/accounts.graphql", "-o", "")
+		require.NoError(t, err)
+		assert.Contains(t, out, "type Query {\n    me: User\n}")
+	})
+}
+
+func TestMerge(t *testing.T) {
+	out, err := run(t, "merge", "testdata/accounts.graphql", "testdata/reviews.graphql")
+	require.NoError(t, err)
+	assert.Contains(t, out, "type User {")
+	assert.Contains(t, out, "reviews: [Review]")
+	assert.NotContains(t, out, "@key")
+}
+
+func TestIntrospect(t *testing.T) {
+	out, err := run(t, "introspect", "testdata/schema.graphql")
+	require.NoError(t, err)
+
+	var data struct {
+		Schema struct {
+			QueryType struct {
+				Name string `json:"name"`
+			} `json:"queryType"`
+		} `json:"__schema"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &data))
+	assert.Equal(t, "Query", data.Schema.QueryType.Name)
+
+	introspectionFile := filepath.Join(t.TempDir(), "introspection.json")
+	require.NoError(t, os.WriteFile(introspectionFile, []byte(`{"data":`+out+`}`), 0644))
+
+	out, err = run(t, "introspect", introspectionFile)
+	require.NoError(t, err)
+	assert.Contains(t, out, "interface Character {\n    name: String!\n}")
+	assert.Contains(t, out, "type Droid implements Character {")
+}
+
+func TestGenClient(t *testing.T) {
+	out, err := run(t, "gen", "client", "-s", "testdata/schema.graphql", "-d", "testdata/operations", "-p", "starwars", "-o", "")
+	require.NoError(t, err)
+	assert.Contains(t, out, "package starwars\n")
+	assert.Contains(t, out, "func Hero(ctx context.Context, executor client.Executor) (*HeroResponse, error) {")
+	assert.Contains(t, out, "func Droids(ctx context.Context, executor client.Executor) (*DroidsResponse, error) {")
+
+	outFile := filepath.Join(t.TempDir(), "client.go")
+	_, err = run(t, "gen", "client", "-s", "testdata/schema.graphql", "-d", "testdata/operations", "-p", "starwars", "-o", outFile)
+	require.NoError(t, err)
+	generated, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	assert.Equal(t, out, string(generated))
+}
+
+func TestComplexity(t *testing.T) {
+	out, err := run(t, "complexity", "-s", "testdata/schema.graphql", "-o", "", "testdata/droids.graphql")
+	require.NoError(t, err)
+	assert.Equal(t, `FIELD         NODE COUNT  COMPLEXITY  DEPTH
+(operation)   1           1           2
+Query.droids  1           1           1
+`, out)
+}
+
+func TestPlan(t *testing.T) {
+	t.Run("single upstream", func(t *testing.T) {
+		out, err := run(t, "plan", "-c", "testdata/proxy.yaml", "-o", "", "testdata/hero.graphql")
+		require.NoError(t, err)
+		assert.Equal(t, `synchronous response
+  data: Object!
+    fetch 0 (graphql_datasource.Source): {"method":"POST","url":"http://localhost:4000/graphql","body":{"query":"{hero {name}}"}}
+    hero: Object
+      name: String!
+`, out)
+	})
+
+	t.Run("federation", func(t *testing.T) {
+		out, err := run(t, "plan", "-c", "testdata/federation.yaml", "-o", "", "testdata/me.graphql")
+		require.NoError(t, err)
+		assert.Contains(t, out, `fetch 0 (graphql_datasource.Source): {"method":"POST","url":"http://accounts.service","body":{"query":"{me {username id}}"}}`)
+		assert.Contains(t, out, `batch fetch 1 (graphql_datasource.Source): {"method":"POST","url":"http://reviews.service"`)
+		assert.Contains(t, out, `"variables":{"representations":[{"id":$$object:id$$,"__typename":"User"}]}`)
+		assert.Contains(t, out, "reviews: Array")
+	})
+}
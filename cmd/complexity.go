@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astnormalization"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/middleware/operation_complexity"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/operationreport"
+)
+
+var (
+	complexitySchemaFile    string
+	complexityOperationName string
+)
+
+// complexityCmd represents the complexity command
+var complexityCmd = &cobra.Command{
+	Use:   "complexity [operation file]",
+	Short: "Calculates node count, complexity and depth of an operation",
+	Long: `complexity estimates the cost of an operation the same way the operation_complexity middleware does.
+Lists are multiplied by the "first", "last" or "limit" arguments or by the @nodeCountMultiply directive.
+The stats are printed for the whole operation and for every root field.`,
+	Example: `graphql-go-tools complexity -s schema.graphql query.graphql`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		definition, err := parseSchemaFile(complexitySchemaFile)
+		if err != nil {
+			return err
+		}
+		operation, err := parseFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		report := operationreport.Report{}
+		if complexityOperationName != "" {
+			astnormalization.NormalizeNamedOperation(operation, definition, []byte(complexityOperationName), &report)
+		} else {
+			astnormalization.NormalizeOperation(operation, definition, &report)
+		}
+		if report.HasErrors() {
+			return errors.New(formatReport(args[0], report))
+		}
+
+		stats, rootFieldStats := operation_complexity.CalculateOperationComplexity(operation, definition, &report)
+		if report.HasErrors() {
+			return errors.New(formatReport(args[0], report))
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FIELD\tNODE COUNT\tCOMPLEXITY\tDEPTH")
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", "(operation)", stats.NodeCount, stats.Complexity, stats.Depth)
+		for _, field := range rootFieldStats {
+			name := field.TypeName + "." + field.FieldName
+			if field.Alias != "" {
+				name = fmt.Sprintf("%s (%s)", name, field.Alias)
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", name, field.Stats.NodeCount, field.Stats.Complexity, field.Stats.Depth)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(complexityCmd)
+
+	complexityCmd.Flags().StringVarP(&complexitySchemaFile, "schema", "s", "", "schema is the path to the schema file (required)")
+	_ = complexityCmd.MarkFlagRequired("schema")
+	complexityCmd.Flags().StringVarP(&complexityOperationName, "operationName", "o", "", "operationName selects the operation if the file contains multiple operations (optional)")
+}
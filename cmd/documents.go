@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astparser"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/asttransform"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/operationreport"
+)
+
+// parseFile parses a GraphQL document from a file, "-" reads from stdin
+func parseFile(path string) (*ast.Document, error) {
+	content, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, report := astparser.ParseGraphqlDocumentBytes(content)
+	if report.HasErrors() {
+		return nil, errors.New(formatReport(path, report))
+	}
+	return &doc, nil
+}
+
+// parseSchemaFile parses a schema and merges it with the base schema containing the builtin scalars and directives
+func parseSchemaFile(path string) (*ast.Document, error) {
+	definition, err := parseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := asttransform.MergeDefinitionWithBaseSchema(definition); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return definition, nil
+}
+
+func readFile(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// formatReport renders all errors of a report, one line per error location in the form "path:line:col: message".
+// Errors without a location are rendered as "path: message", path is empty for input without a file.
+func formatReport(path string, report operationreport.Report) string {
+	lines := make([]string, 0, len(report.InternalErrors)+len(report.ExternalErrors))
+	for _, err := range report.InternalErrors {
+		lines = append(lines, reportLine(path, "", fmt.Sprintf("internal error: %s", err)))
+	}
+	for _, err := range report.ExternalErrors {
+		if len(err.Locations) == 0 {
+			lines = append(lines, reportLine(path, "", err.Message))
+			continue
+		}
+		for _, location := range err.Locations {
+			lines = append(lines, reportLine(path, fmt.Sprintf("%d:%d", location.Line, location.Column), err.Message))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// reportLine prefixes the message with the non-empty path and location
+func reportLine(path, location, message string) string {
+	prefix := make([]string, 0, 2)
+	for _, part := range []string{path, location} {
+		if part != "" {
+			prefix = append(prefix, part)
+		}
+	}
+	if len(prefix) == 0 {
+		return message
+	}
+	return strings.Join(prefix, ":") + ": " + message
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astparser"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astprinter"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/asttransform"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/introspection"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/operationreport"
+)
+
+// introspectCmd represents the introspect command
+var introspectCmd = &cobra.Command{
+	Use:   "introspect [schema or introspection file]",
+	Short: "Converts between SDL and introspection JSON",
+	Long: `introspect converts a schema SDL into the JSON result of an introspection query and vice versa.
+Files starting with "{" are treated as introspection JSON and printed as SDL, all other files are parsed as SDL.
+The introspection JSON may either be the plain "__schema" object or a complete response with a "data" field.`,
+	Example: `graphql-go-tools introspect schema.graphql > introspection.json
+graphql-go-tools introspect introspection.json > schema.graphql`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		content, err := readFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		if bytes.HasPrefix(bytes.TrimSpace(content), []byte("{")) {
+			return introspectionJSONToSDL(cmd, content)
+		}
+		return sdlToIntrospectionJSON(cmd, content)
+	},
+}
+
+func introspectionJSONToSDL(cmd *cobra.Command, content []byte) error {
+	var response struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(content, &response); err == nil && len(response.Data) != 0 {
+		content = response.Data
+	}
+
+	converter := introspection.JsonConverter{}
+	doc, err := converter.GraphQLDocument(bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+
+	return printIndent(cmd, astprinter.PrintIndent(doc, nil, []byte("  "), cmd.OutOrStdout()))
+}
+
+func sdlToIntrospectionJSON(cmd *cobra.Command, content []byte) error {
+	definition, report := astparser.ParseGraphqlDocumentBytes(content)
+	if report.HasErrors() {
+		return errors.New(formatReport("", report))
+	}
+	if err := asttransform.MergeDefinitionWithBaseSchema(&definition); err != nil {
+		return err
+	}
+
+	var data introspection.Data
+	report = operationreport.Report{}
+	introspection.NewGenerator().Generate(&definition, &report, &data)
+	if report.HasErrors() {
+		return errors.New(formatReport("", report))
+	}
+
+	out, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(out))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(introspectCmd)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/federation/sdlmerge"
+)
+
+// mergeCmd represents the merge command
+var mergeCmd = &cobra.Command{
+	Use:   "merge [subgraph sdl files...]",
+	Short: "Merges federated subgraph SDLs into a supergraph schema",
+	Long: `merge composes the SDLs of federated subgraphs into a single schema,
+entity extensions are merged into their entities and federation directives are removed.`,
+	Example: `graphql-go-tools merge accounts.graphql products.graphql reviews.graphql`,
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sdls := make([]string, 0, len(args))
+		for _, file := range args {
+			sdl, err := readFile(file)
+			if err != nil {
+				return err
+			}
+			sdls = append(sdls, string(sdl))
+		}
+
+		merged, err := sdlmerge.MergeSDLs(sdls...)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), merged)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+}
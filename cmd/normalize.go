@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astnormalization"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astprinter"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/operationreport"
+)
+
+var (
+	normalizeSchemaFile    string
+	normalizeOperationName string
+)
+
+// normalizeCmd represents the normalize command
+var normalizeCmd = &cobra.Command{
+	Use:   "normalize [operation file]",
+	Short: "Prints the normalized form of an operation or a schema",
+	Long: `normalize prints an operation the way the engine sees it before planning:
+fragments are inlined, selections merged and inline values extracted into variables.
+Without an operation file the schema itself is normalized, e.g. type extensions are merged into their types.`,
+	Example: `graphql-go-tools normalize -s schema.graphql -o MyQuery query.graphql`,
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			definition, err := parseFile(normalizeSchemaFile)
+			if err != nil {
+				return err
+			}
+			report := operationreport.Report{}
+			astnormalization.NormalizeDefinition(definition, &report)
+			if report.HasErrors() {
+				return errors.New(formatReport(normalizeSchemaFile, report))
+			}
+			return printIndent(cmd, astprinter.PrintIndent(definition, nil, []byte("  "), cmd.OutOrStdout()))
+		}
+
+		definition, err := parseSchemaFile(normalizeSchemaFile)
+		if err != nil {
+			return err
+		}
+		operation, err := parseFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		report := operationreport.Report{}
+		if normalizeOperationName != "" {
+			astnormalization.NormalizeNamedOperation(operation, definition, []byte(normalizeOperationName), &report)
+		} else {
+			astnormalization.NormalizeOperation(operation, definition, &report)
+		}
+		if report.HasErrors() {
+			return errors.New(formatReport(args[0], report))
+		}
+
+		if err := printIndent(cmd, astprinter.PrintIndent(operation, definition, []byte("  "), cmd.OutOrStdout())); err != nil {
+			return err
+		}
+		if len(operation.Input.Variables) != 0 && string(operation.Input.Variables) != "{}" {
+			fmt.Fprintf(cmd.OutOrStdout(), "variables: %s\n", operation.Input.Variables)
+		}
+		return nil
+	},
+}
+
+// printIndent terminates the printed document with a new line
+func printIndent(cmd *cobra.Command, err error) error {
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout())
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(normalizeCmd)
+
+	normalizeCmd.Flags().StringVarP(&normalizeSchemaFile, "schema", "s", "", "schema is the path to the schema file (required)")
+	_ = normalizeCmd.MarkFlagRequired("schema")
+	normalizeCmd.Flags().StringVarP(&normalizeOperationName, "operationName", "o", "", "operationName selects the operation to normalize if the file contains multiple operations (optional)")
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jensneuse/abstractlogger"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	graphqlDataSource "github.com/TykTechnologies/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/graphql"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/operationreport"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/postprocess"
+)
+
+var (
+	planConfigFile    string
+	planOperationName string
+)
+
+// planConfig configures the engine used to plan operations.
+// Either Schema and URL configure a single upstream, or Subgraphs configure a federated graph.
+// Relative file paths are resolved relative to the config file.
+type planConfig struct {
+	Schema    string           `yaml:"schema"`
+	URL       string           `yaml:"url"`
+	Method    string           `yaml:"method"`
+	Subgraphs []subgraphConfig `yaml:"subgraphs"`
+}
+
+type subgraphConfig struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+	SDL  string `yaml:"sdl"`
+}
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan [operation file]",
+	Short: "Prints the query plan of an operation",
+	Long: `plan normalizes, validates and plans an operation like the execution engine does and prints the resulting plan:
+the fetches to the upstreams including their rendered input, and the shape of the response.
+
+The config file is YAML (or JSON) and configures either a single upstream:
+
+  schema: schema.graphql
+  url: http://localhost:4000/graphql
+
+or a federated graph:
+
+  subgraphs:
+    - name: accounts
+      url: http://localhost:4001/graphql
+      sdl: accounts.graphql`,
+	Example: `graphql-go-tools plan -c gateway.yaml query.graphql`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engineConfig, err := loadPlanConfig(planConfigFile)
+		if err != nil {
+			return err
+		}
+
+		query, err := readFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		engine, err := graphql.NewExecutionEngineV2(context.Background(), abstractlogger.NoopLogger, engineConfig)
+		if err != nil {
+			return err
+		}
+
+		request := &graphql.Request{
+			OperationName: planOperationName,
+			Query:         string(query),
+		}
+		if err := engine.Normalize(request); err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		if err := engine.ValidateForSchema(request); err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+
+		report := operationreport.Report{}
+		queryPlan, err := engine.Plan(postprocess.DefaultProcessor(), request, &report)
+		if err != nil {
+			if errors.As(err, &report) {
+				return errors.New(formatReport(args[0], report))
+			}
+			return err
+		}
+
+		return newPlanPrinter(cmd.OutOrStdout()).print(queryPlan)
+	},
+}
+
+func loadPlanConfig(configFile string) (graphql.EngineV2Configuration, error) {
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return graphql.EngineV2Configuration{}, err
+	}
+
+	var config planConfig
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return graphql.EngineV2Configuration{}, fmt.Errorf("%s: %w", configFile, err)
+	}
+
+	dir := filepath.Dir(configFile)
+	readRelative := func(path string) ([]byte, error) {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		return os.ReadFile(path)
+	}
+
+	if len(config.Subgraphs) != 0 {
+		dataSourceConfigs := make([]graphqlDataSource.Configuration, 0, len(config.Subgraphs))
+		for _, subgraph := range config.Subgraphs {
+			sdl, err := readRelative(subgraph.SDL)
+			if err != nil {
+				return graphql.EngineV2Configuration{}, fmt.Errorf("subgraph %s: %w", subgraph.Name, err)
+			}
+			dataSourceConfigs = append(dataSourceConfigs, graphqlDataSource.Configuration{
+				Fetch: graphqlDataSource.FetchConfiguration{
+					URL: subgraph.URL,
+				},
+				Federation: graphqlDataSource.FederationConfiguration{
+					Enabled:    true,
+					ServiceSDL: string(sdl),
+				},
+			})
+		}
+		return graphql.NewFederationEngineConfigFactory(dataSourceConfigs, graphqlDataSource.NewBatchFactory()).EngineV2Configuration()
+	}
+
+	if config.Schema == "" || config.URL == "" {
+		return graphql.EngineV2Configuration{}, fmt.Errorf("%s: either schema and url or subgraphs must be configured", configFile)
+	}
+	sdl, err := readRelative(config.Schema)
+	if err != nil {
+		return graphql.EngineV2Configuration{}, err
+	}
+	schema, err := graphql.NewSchemaFromString(string(sdl))
+	if err != nil {
+		return graphql.EngineV2Configuration{}, err
+	}
+	method := config.Method
+	if method == "" {
+		method = "POST"
+	}
+	return graphql.NewProxyEngineConfigFactory(schema, graphql.ProxyUpstreamConfig{
+		URL:    config.URL,
+		Method: method,
+	}, graphqlDataSource.NewBatchFactory()).EngineV2Configuration()
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+
+	planCmd.Flags().StringVarP(&planConfigFile, "config", "c", "", "config is the path to the engine config file (required)")
+	_ = planCmd.MarkFlagRequired("config")
+	planCmd.Flags().StringVarP(&planOperationName, "operationName", "o", "", "operationName selects the operation if the file contains multiple operations (optional)")
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/engine/plan"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/engine/resolve"
+)
+
+// planPrinter renders a plan as an indented tree of fetches and response fields
+type planPrinter struct {
+	out   io.Writer
+	depth int
+}
+
+func newPlanPrinter(out io.Writer) *planPrinter {
+	return &planPrinter{out: out}
+}
+
+func (p *planPrinter) print(queryPlan plan.Plan) error {
+	switch t := queryPlan.(type) {
+	case *plan.SynchronousResponsePlan:
+		p.line("synchronous response")
+		p.printResponse(t.Response)
+	case *plan.StreamingResponsePlan:
+		p.line("streaming response")
+		p.printResponse(t.Response.InitialResponse)
+	case *plan.SubscriptionResponsePlan:
+		p.line("subscription")
+		p.depth++
+		p.line("trigger input: %s", t.Response.Trigger.Input)
+		p.depth--
+		p.printResponse(t.Response.Response)
+	default:
+		return fmt.Errorf("unsupported plan %T", queryPlan)
+	}
+	return nil
+}
+
+func (p *planPrinter) printResponse(response *resolve.GraphQLResponse) {
+	if response == nil {
+		return
+	}
+	p.depth++
+	p.printNode("data", response.Data)
+	p.depth--
+}
+
+func (p *planPrinter) printNode(name string, node resolve.Node) {
+	switch n := node.(type) {
+	case *resolve.Object:
+		p.line("%s: Object%s", name, nullable(n.Nullable))
+		p.depth++
+		p.printFetch(n.Fetch)
+		for _, field := range n.Fields {
+			fieldName := string(field.Name)
+			if len(field.OnTypeNames) != 0 {
+				typeNames := make([]string, 0, len(field.OnTypeNames))
+				for _, typeName := range field.OnTypeNames {
+					typeNames = append(typeNames, string(typeName))
+				}
+				fieldName += " on " + strings.Join(typeNames, "|")
+			}
+			p.printNode(fieldName, field.Value)
+		}
+		p.depth--
+	case *resolve.Array:
+		p.line("%s: Array%s", name, nullable(n.Nullable))
+		p.depth++
+		p.printNode("item", n.Item)
+		p.depth--
+	case *resolve.String:
+		p.line("%s: String%s", name, nullable(n.Nullable))
+	case *resolve.Boolean:
+		p.line("%s: Boolean%s", name, nullable(n.Nullable))
+	case *resolve.Integer:
+		p.line("%s: Integer%s", name, nullable(n.Nullable))
+	case *resolve.Float:
+		p.line("%s: Float%s", name, nullable(n.Nullable))
+	case *resolve.BigInt:
+		p.line("%s: BigInt%s", name, nullable(n.Nullable))
+	case *resolve.Scalar:
+		p.line("%s: Scalar%s", name, nullable(n.Nullable))
+	case *resolve.CustomNode:
+		p.line("%s: Custom%s", name, nullable(n.Nullable))
+	case *resolve.EmptyObject:
+		p.line("%s: EmptyObject", name)
+	case *resolve.EmptyArray:
+		p.line("%s: EmptyArray", name)
+	case *resolve.Null:
+		p.line("%s: Null", name)
+	default:
+		p.line("%s: %T", name, node)
+	}
+}
+
+func (p *planPrinter) printFetch(fetch resolve.Fetch) {
+	switch f := fetch.(type) {
+	case nil:
+	case *resolve.SingleFetch:
+		p.line("fetch %d (%s): %s", f.BufferId, f.DataSourceIdentifier, fetchInput(f))
+	case *resolve.BatchFetch:
+		p.line("batch fetch %d (%s): %s", f.Fetch.BufferId, f.Fetch.DataSourceIdentifier, fetchInput(f.Fetch))
+	case *resolve.ParallelFetch:
+		p.line("parallel fetch")
+		p.depth++
+		for _, child := range f.Fetches {
+			p.printFetch(child)
+		}
+		p.depth--
+	default:
+		p.line("fetch %T", fetch)
+	}
+}
+
+func (p *planPrinter) line(format string, args ...interface{}) {
+	fmt.Fprintf(p.out, "%s%s\n", strings.Repeat("  ", p.depth), fmt.Sprintf(format, args...))
+}
+
+// fetchInput returns the input of a fetch, post-processed fetches carry it as a template
+// in which case variables are rendered as $$kind:path$$ placeholders
+func fetchInput(fetch *resolve.SingleFetch) string {
+	if fetch.Input != "" || len(fetch.InputTemplate.Segments) == 0 {
+		return fetch.Input
+	}
+	b := strings.Builder{}
+	for _, segment := range fetch.InputTemplate.Segments {
+		switch segment.SegmentType {
+		case resolve.StaticSegmentType:
+			b.Write(segment.Data)
+		case resolve.VariableSegmentType:
+			b.WriteString("$$")
+			b.WriteString(variableKind(segment.VariableKind))
+			b.WriteString(":")
+			b.WriteString(strings.Join(segment.VariableSourcePath, "."))
+			b.WriteString("$$")
+		}
+	}
+	return b.String()
+}
+
+func variableKind(kind resolve.VariableKind) string {
+	switch kind {
+	case resolve.ContextVariableKind:
+		return "context"
+	case resolve.ObjectVariableKind:
+		return "object"
+	case resolve.HeaderVariableKind:
+		return "header"
+	default:
+		return "unknown"
+	}
+}
+
+func nullable(isNullable bool) string {
+	if isNullable {
+		return ""
+	}
+	return "!"
+}
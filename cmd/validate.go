@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astnormalization"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/astvalidation"
+	"github.com/TykTechnologies/graphql-go-tools/pkg/operationreport"
+)
+
+var errValidationFailed = errors.New("validation failed")
+
+var validateSchemaFile string
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:   "validate [operation files...]",
+	Short: "Validates a schema and operations against it",
+	Long: `validate checks the schema against the rules of the GraphQL specification
+and validates every given operation file against the schema.
+All errors are printed, the command fails if any document is invalid.`,
+	Example: `graphql-go-tools validate -s schema.graphql queries/*.graphql`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		definition, err := parseSchemaFile(validateSchemaFile)
+		if err != nil {
+			return err
+		}
+
+		valid := true
+		report := operationreport.Report{}
+		astvalidation.DefaultDefinitionValidator().Validate(definition, &report)
+		if report.HasErrors() {
+			valid = false
+			fmt.Fprintln(cmd.OutOrStdout(), formatReport(validateSchemaFile, report))
+		}
+
+		for _, operationFile := range args {
+			operation, err := parseFile(operationFile)
+			if err != nil {
+				valid = false
+				fmt.Fprintln(cmd.OutOrStdout(), err)
+				continue
+			}
+
+			report.Reset()
+			astnormalization.NormalizeOperation(operation, definition, &report)
+			if !report.HasErrors() {
+				astvalidation.DefaultOperationValidator().Validate(operation, definition, &report)
+			}
+			if report.HasErrors() {
+				valid = false
+				fmt.Fprintln(cmd.OutOrStdout(), formatReport(operationFile, report))
+			}
+		}
+
+		if !valid {
+			return errValidationFailed
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().StringVarP(&validateSchemaFile, "schema", "s", "", "schema is the path to the schema file (required)")
+	_ = validateCmd.MarkFlagRequired("schema")
+}
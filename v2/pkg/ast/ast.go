@@ -49,6 +49,7 @@ type Document struct {
 	OperationDefinitions         []OperationDefinition
 	VariableDefinitions          []VariableDefinition
 	FragmentDefinitions          []FragmentDefinition
+	Comments                     []Comment
 	BooleanValues                [2]BooleanValue
 	Refs                         [][8]int
 	RefIndex                     int
@@ -158,6 +159,7 @@ func (d *Document) Reset() {
 	d.OperationDefinitions = d.OperationDefinitions[:0]
 	d.VariableDefinitions = d.VariableDefinitions[:0]
 	d.FragmentDefinitions = d.FragmentDefinitions[:0]
+	d.Comments = d.Comments[:0]
 
 	d.RefIndex = -1
 	d.Index.Reset()
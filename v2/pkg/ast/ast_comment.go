@@ -0,0 +1,55 @@
+package ast
+
+import (
+	"bytes"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/internal/pkg/unsafebytes"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer/position"
+)
+
+// CommentPlacement describes where a comment is placed relative to the node it's attached to
+type CommentPlacement int
+
+const (
+	// CommentPlacementLeading comments are on the lines before the node, above its description
+	CommentPlacementLeading CommentPlacement = iota
+	// CommentPlacementTrailing comments are at the end of the first line of the node
+	CommentPlacementTrailing
+	// CommentPlacementClosing comments are after the last child of the node, before its closing brace.
+	// Comments at the end of the document are attached to InvalidNode.
+	CommentPlacementClosing
+)
+
+// Comment is a single comment line retained by the parser, see astparser.Parser.ParseWithComments
+// example:
+// # a comment
+type Comment struct {
+	Text      ByteSliceReference // the comment including the leading #
+	Position  position.Position
+	Node      Node // the node the comment is attached to
+	Placement CommentPlacement
+}
+
+func (d *Document) CommentBytes(ref int) ByteSlice {
+	return bytes.TrimRight(d.Input.ByteSlice(d.Comments[ref].Text), " \t\r")
+}
+
+func (d *Document) CommentString(ref int) string {
+	return unsafebytes.BytesToString(d.CommentBytes(ref))
+}
+
+// AddComment attaches a comment to a node
+func (d *Document) AddComment(comment Comment) (ref int) {
+	d.Comments = append(d.Comments, comment)
+	return len(d.Comments) - 1
+}
+
+// NodeComments returns the refs of the comments attached to a node with the given placement in source order
+func (d *Document) NodeComments(node Node, placement CommentPlacement) (refs []int) {
+	for i := range d.Comments {
+		if d.Comments[i].Node == node && d.Comments[i].Placement == placement {
+			refs = append(refs, i)
+		}
+	}
+	return refs
+}
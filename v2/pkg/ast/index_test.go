@@ -223,3 +223,39 @@ func TestIndex_ReplaceNode(t *testing.T) {
 		assert.Equal(t, expectedIndexAfter, idx)
 	})
 }
+
+func TestIndex_RemoveNode(t *testing.T) {
+	unrelatedNode := Node{
+		Kind: NodeKindObjectTypeDefinition,
+		Ref:  5,
+	}
+	node := Node{
+		Kind: NodeKindObjectTypeExtension,
+		Ref:  1,
+	}
+
+	t.Run("should only remove the given node", func(t *testing.T) {
+		idx := emptyIndex()
+		idx.AddNodeStr("User", unrelatedNode)
+		idx.AddNodeStr("User", node)
+
+		idx.RemoveNode([]byte("User"), node)
+		expectedIndex := Index{
+			nodes: map[uint64][]Node{
+				xxhash.Sum64String("User"): {unrelatedNode},
+			},
+		}
+
+		assert.Equal(t, expectedIndex, idx)
+	})
+
+	t.Run("should remove the name when no node is left", func(t *testing.T) {
+		idx := emptyIndex()
+		idx.AddNodeStr("User", node)
+
+		idx.RemoveNode([]byte("User"), node)
+		_, exists := idx.FirstNodeByNameStr("User")
+
+		assert.False(t, exists)
+	})
+}
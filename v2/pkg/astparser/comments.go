@@ -0,0 +1,354 @@
+package astparser
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer/keyword"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer/position"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer/token"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/operationreport"
+)
+
+// ParseGraphqlDocumentStringWithComments takes a raw GraphQL document in string format and parses it into an AST
+// retaining its comments, see Parser.ParseWithComments.
+func ParseGraphqlDocumentStringWithComments(input string) (ast.Document, operationreport.Report) {
+	return ParseGraphqlDocumentBytesWithComments([]byte(input))
+}
+
+// ParseGraphqlDocumentBytesWithComments takes a raw GraphQL document in byte slice format and parses it into an AST
+// retaining its comments, see Parser.ParseWithComments.
+func ParseGraphqlDocumentBytesWithComments(input []byte) (ast.Document, operationreport.Report) {
+	parser := NewParser()
+	doc := *ast.NewSmallDocument()
+	doc.Input.ResetInputBytes(input)
+	report := operationreport.Report{}
+	parser.ParseWithComments(&doc, &report)
+	return doc, report
+}
+
+// ParseWithComments parses all input in a Document.Input into the Document like Parse
+// and attaches the comments of the input to the nodes of the Document, see ast.Comment.
+// A comment on its own line is attached to the next node as leading comment.
+// A comment at the end of a line is attached to the last node starting on that line,
+// or to the operation whose selection set starts on that line, as trailing comment,
+// arguments on the line of their field are skipped, variable definitions only if the comment follows their closing parenthesis.
+// A comment after the last node of a block is attached to the node owning the block as closing comment,
+// comments after the last definition are attached to ast.InvalidNode.
+// Nodes without a node kind, e.g. root operation type definitions, don't get comments attached,
+// their comments are attached to the enclosing node.
+func (p *Parser) ParseWithComments(document *ast.Document, report *operationreport.Report) {
+	p.Parse(document, report)
+	if report.HasErrors() {
+		return
+	}
+
+	attacher := commentAttacher{
+		document:   document,
+		lineStarts: lineStarts(document.Input.RawBytes),
+	}
+	attacher.collectAnchors()
+	attacher.attach(p.tokenizer.tokens[:p.tokenizer.maxTokens])
+}
+
+// commentAnchor is a node comments can be attached to
+type commentAnchor struct {
+	node   ast.Node
+	parent int    // index of the anchor of the enclosing node, -1 for root nodes
+	line   uint32 // first line of the node including its description
+	header uint32 // first line of the node after its description
+	end    uint32 // line of the closing brace of the node, 0 for nodes without a block
+	// inline is true for arguments, variable definitions and union members, which are usually on the line of their parent
+	inline bool
+	// listEnd is the line of the closing parenthesis of the variable definitions of an inline variable definition,
+	// comments at the end of earlier lines belong to the variable definitions rather than to their parent
+	listEnd uint32
+	// brace is the line of the opening brace of an operation, which follows its variable definitions
+	brace uint32
+}
+
+type commentAttacher struct {
+	document   *ast.Document
+	lineStarts []int
+	anchors    []commentAnchor
+}
+
+func lineStarts(input []byte) []int {
+	starts := []int{0}
+	for i := range input {
+		if input[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// line returns the 1-based line of a byte offset
+func (a *commentAttacher) line(offset uint32) uint32 {
+	return uint32(sort.Search(len(a.lineStarts), func(i int) bool {
+		return a.lineStarts[i] > int(offset)
+	}))
+}
+
+func (a *commentAttacher) add(node ast.Node, parent int, line, end uint32) int {
+	return a.addDescribed(node, parent, ast.Description{}, line, end)
+}
+
+// addDescribed adds a node which might have a description before its header line
+func (a *commentAttacher) addDescribed(node ast.Node, parent int, description ast.Description, header, end uint32) int {
+	line := header
+	if description.IsDefined {
+		line = description.Position.LineStart
+	}
+	a.anchors = append(a.anchors, commentAnchor{
+		node:   node,
+		parent: parent,
+		line:   line,
+		header: header,
+		end:    end,
+	})
+	return len(a.anchors) - 1
+}
+
+func (a *commentAttacher) addInline(node ast.Node, parent int, line uint32) {
+	a.add(node, parent, line, 0)
+	a.anchors[len(a.anchors)-1].inline = true
+}
+
+// collectAnchors collects all nodes comments can be attached to in source order
+func (a *commentAttacher) collectAnchors() {
+	for _, node := range a.document.RootNodes {
+		a.collectNode(node, -1)
+	}
+}
+
+func (a *commentAttacher) collectNode(node ast.Node, parent int) {
+	d := a.document
+	switch node.Kind {
+	case ast.NodeKindSchemaDefinition:
+		schema := d.SchemaDefinitions[node.Ref]
+		a.addDescribed(node, parent, schema.Description, schema.SchemaLiteral.LineStart, schema.RootOperationTypeDefinitions.RBrace.LineStart)
+	case ast.NodeKindSchemaExtension:
+		schema := d.SchemaExtensions[node.Ref]
+		a.add(node, parent, schema.ExtendLiteral.LineStart, schema.RootOperationTypeDefinitions.RBrace.LineStart)
+	case ast.NodeKindObjectTypeDefinition:
+		object := d.ObjectTypeDefinitions[node.Ref]
+		a.collectFieldDefinitions(a.addDescribed(node, parent, object.Description, object.TypeLiteral.LineStart, object.FieldsDefinition.RBRACE.LineStart), object.FieldsDefinition.Refs)
+	case ast.NodeKindObjectTypeExtension:
+		object := d.ObjectTypeExtensions[node.Ref]
+		a.collectFieldDefinitions(a.add(node, parent, object.ExtendLiteral.LineStart, object.FieldsDefinition.RBRACE.LineStart), object.FieldsDefinition.Refs)
+	case ast.NodeKindInterfaceTypeDefinition:
+		iface := d.InterfaceTypeDefinitions[node.Ref]
+		a.collectFieldDefinitions(a.addDescribed(node, parent, iface.Description, iface.InterfaceLiteral.LineStart, iface.FieldsDefinition.RBRACE.LineStart), iface.FieldsDefinition.Refs)
+	case ast.NodeKindInterfaceTypeExtension:
+		iface := d.InterfaceTypeExtensions[node.Ref]
+		a.collectFieldDefinitions(a.add(node, parent, iface.ExtendLiteral.LineStart, iface.FieldsDefinition.RBRACE.LineStart), iface.FieldsDefinition.Refs)
+	case ast.NodeKindInputObjectTypeDefinition:
+		input := d.InputObjectTypeDefinitions[node.Ref]
+		a.collectInputValueDefinitions(a.addDescribed(node, parent, input.Description, input.InputLiteral.LineStart, input.InputFieldsDefinition.RPAREN.LineStart), input.InputFieldsDefinition.Refs, false)
+	case ast.NodeKindInputObjectTypeExtension:
+		input := d.InputObjectTypeExtensions[node.Ref]
+		a.collectInputValueDefinitions(a.add(node, parent, input.ExtendLiteral.LineStart, input.InputFieldsDefinition.RPAREN.LineStart), input.InputFieldsDefinition.Refs, false)
+	case ast.NodeKindEnumTypeDefinition:
+		enum := d.EnumTypeDefinitions[node.Ref]
+		a.collectEnumValueDefinitions(a.addDescribed(node, parent, enum.Description, enum.EnumLiteral.LineStart, enum.EnumValuesDefinition.RBRACE.LineStart), enum.EnumValuesDefinition.Refs)
+	case ast.NodeKindEnumTypeExtension:
+		enum := d.EnumTypeExtensions[node.Ref]
+		a.collectEnumValueDefinitions(a.add(node, parent, enum.ExtendLiteral.LineStart, enum.EnumValuesDefinition.RBRACE.LineStart), enum.EnumValuesDefinition.Refs)
+	case ast.NodeKindUnionTypeDefinition:
+		union := d.UnionTypeDefinitions[node.Ref]
+		a.collectUnionMembers(a.addDescribed(node, parent, union.Description, union.UnionLiteral.LineStart, 0), union.UnionMemberTypes.Refs)
+	case ast.NodeKindUnionTypeExtension:
+		union := d.UnionTypeExtensions[node.Ref]
+		a.collectUnionMembers(a.add(node, parent, union.ExtendLiteral.LineStart, 0), union.UnionMemberTypes.Refs)
+	case ast.NodeKindScalarTypeDefinition:
+		scalar := d.ScalarTypeDefinitions[node.Ref]
+		a.addDescribed(node, parent, scalar.Description, scalar.ScalarLiteral.LineStart, 0)
+	case ast.NodeKindScalarTypeExtension:
+		a.add(node, parent, d.ScalarTypeExtensions[node.Ref].ExtendLiteral.LineStart, 0)
+	case ast.NodeKindDirectiveDefinition:
+		directive := d.DirectiveDefinitions[node.Ref]
+		a.collectInputValueDefinitions(a.addDescribed(node, parent, directive.Description, directive.DirectiveLiteral.LineStart, 0), directive.ArgumentsDefinition.Refs, true)
+	case ast.NodeKindOperationDefinition:
+		operation := d.OperationDefinitions[node.Ref]
+		selectionSet := d.SelectionSets[operation.SelectionSet]
+		line := operation.OperationTypeLiteral.LineStart
+		if line == 0 {
+			line = selectionSet.LBrace.LineStart
+		}
+		anchor := a.add(node, parent, line, selectionSet.RBrace.LineStart)
+		a.anchors[anchor].brace = selectionSet.LBrace.LineStart
+		for _, ref := range operation.VariableDefinitions.Refs {
+			a.addInline(ast.Node{Kind: ast.NodeKindVariableDefinition, Ref: ref}, anchor, d.VariableDefinitions[ref].VariableValue.Position.LineStart)
+			a.anchors[len(a.anchors)-1].listEnd = operation.VariableDefinitions.RPAREN.LineStart
+		}
+		a.collectSelections(anchor, operation.SelectionSet)
+	case ast.NodeKindFragmentDefinition:
+		fragment := d.FragmentDefinitions[node.Ref]
+		anchor := a.add(node, parent, fragment.FragmentLiteral.LineStart, d.SelectionSets[fragment.SelectionSet].RBrace.LineStart)
+		a.collectSelections(anchor, fragment.SelectionSet)
+	case ast.NodeKindField:
+		field := d.Fields[node.Ref]
+		var end uint32
+		if field.HasSelections {
+			end = d.SelectionSets[field.SelectionSet].RBrace.LineStart
+		}
+		anchor := a.add(node, parent, field.Position.LineStart, end)
+		for _, ref := range field.Arguments.Refs {
+			a.addInline(ast.Node{Kind: ast.NodeKindArgument, Ref: ref}, anchor, d.Arguments[ref].Position.LineStart)
+		}
+		if field.HasSelections {
+			a.collectSelections(anchor, field.SelectionSet)
+		}
+	case ast.NodeKindInlineFragment:
+		fragment := d.InlineFragments[node.Ref]
+		var end uint32
+		if fragment.HasSelections {
+			end = d.SelectionSets[fragment.SelectionSet].RBrace.LineStart
+		}
+		anchor := a.add(node, parent, fragment.Spread.LineStart, end)
+		if fragment.HasSelections {
+			a.collectSelections(anchor, fragment.SelectionSet)
+		}
+	case ast.NodeKindFragmentSpread:
+		a.add(node, parent, d.FragmentSpreads[node.Ref].Spread.LineStart, 0)
+	}
+}
+
+func (a *commentAttacher) collectFieldDefinitions(parent int, refs []int) {
+	for _, ref := range refs {
+		field := a.document.FieldDefinitions[ref]
+		anchor := a.addDescribed(ast.Node{Kind: ast.NodeKindFieldDefinition, Ref: ref}, parent, field.Description, a.line(field.Name.Start), 0)
+		a.collectInputValueDefinitions(anchor, field.ArgumentsDefinition.Refs, true)
+	}
+}
+
+// collectInputValueDefinitions collects input fields or, if inline is true, argument definitions
+func (a *commentAttacher) collectInputValueDefinitions(parent int, refs []int, inline bool) {
+	for _, ref := range refs {
+		value := a.document.InputValueDefinitions[ref]
+		a.addDescribed(ast.Node{Kind: ast.NodeKindInputValueDefinition, Ref: ref}, parent, value.Description, a.line(value.Name.Start), 0)
+		a.anchors[len(a.anchors)-1].inline = inline
+	}
+}
+
+func (a *commentAttacher) collectEnumValueDefinitions(parent int, refs []int) {
+	for _, ref := range refs {
+		value := a.document.EnumValueDefinitions[ref]
+		a.addDescribed(ast.Node{Kind: ast.NodeKindEnumValueDefinition, Ref: ref}, parent, value.Description, a.line(value.EnumValue.Start), 0)
+	}
+}
+
+func (a *commentAttacher) collectUnionMembers(parent int, refs []int) {
+	for _, ref := range refs {
+		a.addInline(ast.Node{Kind: ast.NodeKindUnionMemberType, Ref: ref}, parent, a.document.Types[ref].Position.LineStart)
+	}
+}
+
+func (a *commentAttacher) collectSelections(parent int, selectionSet int) {
+	for _, ref := range a.document.SelectionSets[selectionSet].SelectionRefs {
+		selection := a.document.Selections[ref]
+		switch selection.Kind {
+		case ast.SelectionKindField:
+			a.collectNode(ast.Node{Kind: ast.NodeKindField, Ref: selection.Ref}, parent)
+		case ast.SelectionKindInlineFragment:
+			a.collectNode(ast.Node{Kind: ast.NodeKindInlineFragment, Ref: selection.Ref}, parent)
+		case ast.SelectionKindFragmentSpread:
+			a.collectNode(ast.Node{Kind: ast.NodeKindFragmentSpread, Ref: selection.Ref}, parent)
+		}
+	}
+}
+
+// attach splits the comment tokens into lines and attaches each line to an anchor
+func (a *commentAttacher) attach(tokens []token.Token) {
+	var previousLine uint32
+	for _, tok := range tokens {
+		if tok.Keyword != keyword.COMMENT {
+			previousLine = tok.TextPosition.LineEnd
+			continue
+		}
+
+		text := a.document.Input.ByteSlice(tok.Literal)
+		start := tok.Literal.Start
+		line := tok.TextPosition.LineStart
+		for len(text) != 0 {
+			end := bytes.IndexByte(text, '\n')
+			if end == -1 {
+				end = len(text)
+			}
+			trimmed := bytes.TrimLeft(text[:end], " \t\r")
+			offset := start + uint32(end-len(trimmed))
+			if len(trimmed) != 0 {
+				a.attachLine(ast.ByteSliceReference{Start: offset, End: start + uint32(end)}, line, line == previousLine)
+			}
+			if end == len(text) {
+				break
+			}
+			text = text[end+1:]
+			start += uint32(end + 1)
+			line++
+		}
+	}
+}
+
+func (a *commentAttacher) attachLine(text ast.ByteSliceReference, line uint32, endOfLine bool) {
+	column := text.Start - uint32(a.lineStarts[line-1]) + 1
+	comment := ast.Comment{
+		Text: text,
+		Position: position.Position{
+			LineStart: line,
+			LineEnd:   line,
+			CharStart: column,
+			CharEnd:   column + text.Length(),
+		},
+	}
+
+	if endOfLine {
+		for i := len(a.anchors) - 1; i >= 0; i-- {
+			if a.anchors[i].inline && a.anchors[a.anchors[i].parent].header == line && line >= a.anchors[i].listEnd {
+				continue
+			}
+			if a.anchors[i].header == line || a.anchors[i].brace == line {
+				comment.Node = a.anchors[i].node
+				comment.Placement = ast.CommentPlacementTrailing
+				a.document.AddComment(comment)
+				return
+			}
+		}
+	}
+
+	enclosing := -1
+	next := -1
+	for i := range a.anchors {
+		if a.anchors[i].end != 0 && a.anchors[i].line < line && line < a.anchors[i].end {
+			enclosing = i
+		}
+		if a.anchors[i].line > line {
+			next = i
+			break
+		}
+	}
+
+	switch {
+	case enclosing != -1 && (next == -1 || !a.isDescendant(next, enclosing)):
+		comment.Node = a.anchors[enclosing].node
+		comment.Placement = ast.CommentPlacementClosing
+	case next != -1:
+		comment.Node = a.anchors[next].node
+		comment.Placement = ast.CommentPlacementLeading
+	default:
+		comment.Node = ast.InvalidNode
+		comment.Placement = ast.CommentPlacementClosing
+	}
+	a.document.AddComment(comment)
+}
+
+func (a *commentAttacher) isDescendant(anchor, ancestor int) bool {
+	for parent := a.anchors[anchor].parent; parent != -1; parent = a.anchors[parent].parent {
+		if parent == ancestor {
+			return true
+		}
+	}
+	return false
+}
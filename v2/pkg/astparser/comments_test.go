@@ -0,0 +1,124 @@
+package astparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+)
+
+func TestParser_ParseWithComments(t *testing.T) {
+	type attached struct {
+		text      string
+		node      string
+		placement ast.CommentPlacement
+	}
+
+	run := func(t *testing.T, input string, expected []attached) {
+		t.Helper()
+		doc, report := ParseGraphqlDocumentStringWithComments(input)
+		require.False(t, report.HasErrors(), report.Error())
+
+		actual := make([]attached, 0, len(doc.Comments))
+		for i, comment := range doc.Comments {
+			node := "document"
+			if comment.Node != ast.InvalidNode {
+				node = comment.Node.Kind.String() + ":" + nodeName(&doc, comment.Node)
+			}
+			actual = append(actual, attached{text: doc.CommentString(i), node: node, placement: comment.Placement})
+		}
+		assert.Equal(t, expected, actual)
+	}
+
+	t.Run("schema", func(t *testing.T) {
+		run(t, `# header
+"The query"
+type Query { # trailing
+	# leading
+	hero(
+		# first argument
+		id: ID # trailing id
+	): Character # after arguments
+	droid(id: ID): Droid # trailing droid
+	# closing
+}
+# end`, []attached{
+			{text: "# header", node: "NodeKindObjectTypeDefinition:Query", placement: ast.CommentPlacementLeading},
+			{text: "# trailing", node: "NodeKindObjectTypeDefinition:Query", placement: ast.CommentPlacementTrailing},
+			{text: "# leading", node: "NodeKindFieldDefinition:hero", placement: ast.CommentPlacementLeading},
+			{text: "# first argument", node: "NodeKindInputValueDefinition:id", placement: ast.CommentPlacementLeading},
+			{text: "# trailing id", node: "NodeKindInputValueDefinition:id", placement: ast.CommentPlacementTrailing},
+			{text: "# after arguments", node: "NodeKindFieldDefinition:droid", placement: ast.CommentPlacementLeading},
+			{text: "# trailing droid", node: "NodeKindFieldDefinition:droid", placement: ast.CommentPlacementTrailing},
+			{text: "# closing", node: "NodeKindObjectTypeDefinition:Query", placement: ast.CommentPlacementClosing},
+			{text: "# end", node: "document", placement: ast.CommentPlacementClosing},
+		})
+	})
+
+	t.Run("merged comment lines are split", func(t *testing.T) {
+		run(t, `enum Episode {
+	NEWHOPE # trailing
+	# leading
+	EMPIRE
+}`, []attached{
+			{text: "# trailing", node: "NodeKindEnumValueDefinition:NEWHOPE", placement: ast.CommentPlacementTrailing},
+			{text: "# leading", node: "NodeKindEnumValueDefinition:EMPIRE", placement: ast.CommentPlacementLeading},
+		})
+	})
+
+	t.Run("operation", func(t *testing.T) {
+		run(t, `query Hero($id: ID) { # operation
+	hero(id: $id) { # hero
+		# name
+		name
+		... on Droid { primaryFunction }
+		# closing hero
+	}
+}`, []attached{
+			{text: "# operation", node: "NodeKindOperationDefinition:Hero", placement: ast.CommentPlacementTrailing},
+			{text: "# hero", node: "NodeKindField:hero", placement: ast.CommentPlacementTrailing},
+			{text: "# name", node: "NodeKindField:name", placement: ast.CommentPlacementLeading},
+			{text: "# closing hero", node: "NodeKindField:hero", placement: ast.CommentPlacementClosing},
+		})
+	})
+
+	t.Run("variable definitions", func(t *testing.T) {
+		run(t, `query Q($a: Int = 1 # a
+	$b: Int # b
+) { # operation
+	# field
+	a
+}`, []attached{
+			{text: "# a", node: "NodeKindVariableDefinition:a", placement: ast.CommentPlacementTrailing},
+			{text: "# b", node: "NodeKindVariableDefinition:b", placement: ast.CommentPlacementTrailing},
+			{text: "# operation", node: "NodeKindOperationDefinition:Q", placement: ast.CommentPlacementTrailing},
+			{text: "# field", node: "NodeKindField:a", placement: ast.CommentPlacementLeading},
+		})
+	})
+
+	t.Run("comments are reset with the document", func(t *testing.T) {
+		doc, report := ParseGraphqlDocumentStringWithComments("# comment\nscalar Date")
+		require.False(t, report.HasErrors())
+		require.Len(t, doc.Comments, 1)
+		doc.Reset()
+		assert.Empty(t, doc.Comments)
+	})
+}
+
+func nodeName(doc *ast.Document, node ast.Node) string {
+	switch node.Kind {
+	case ast.NodeKindOperationDefinition:
+		return doc.OperationDefinitionNameString(node.Ref)
+	case ast.NodeKindFieldDefinition:
+		return doc.FieldDefinitionNameString(node.Ref)
+	case ast.NodeKindInputValueDefinition:
+		return doc.InputValueDefinitionNameString(node.Ref)
+	case ast.NodeKindEnumValueDefinition:
+		return doc.EnumValueDefinitionNameString(node.Ref)
+	case ast.NodeKindVariableDefinition:
+		return doc.VariableValueNameString(doc.VariableDefinitions[node.Ref].VariableValue.Ref)
+	}
+	return doc.NodeNameString(node)
+}
@@ -45,6 +45,12 @@ type Parser struct {
 	tokenizer            *Tokenizer
 	shouldIndex          bool
 	reportInternalErrors bool
+	// tolerant mode state, see ParseTolerant
+	tolerant       bool
+	tolerantReport *operationreport.Report
+	scopeReport    operationreport.Report
+	diagnostics    []Diagnostic
+	eofReported    bool
 }
 
 // NewParser returns a new parser with all values properly initialized
@@ -77,6 +83,8 @@ func (p *Parser) tokenize() {
 
 func (p *Parser) parse() {
 	for {
+		startIndex := p.tokenizer.currentToken + 1
+		rootNodes := len(p.document.RootNodes)
 		key, literalReference := p.peekLiteral()
 
 		switch key {
@@ -120,7 +128,10 @@ func (p *Parser) parse() {
 		}
 
 		if p.report.HasErrors() {
-			return
+			if !p.tolerant {
+				return
+			}
+			p.recoverDefinition(startIndex, rootNodes)
 		}
 	}
 }
@@ -139,8 +150,10 @@ func (p *Parser) errUnexpectedIdentKey(unexpected token.Token, unexpectedKey ide
 		return
 	}
 
+	message := fmt.Sprintf("unexpected literal - got: %s want one of: %v", unexpectedKey, expectedKeywords)
+	p.addDiagnostic(message, unexpected)
 	p.report.AddExternalError(operationreport.ExternalError{
-		Message: fmt.Sprintf("unexpected literal - got: %s want one of: %v", unexpectedKey, expectedKeywords),
+		Message: message,
 		Locations: []graphqlerrors.Location{
 			{
 				Line:   unexpected.TextPosition.LineStart,
@@ -186,8 +199,10 @@ func (p *Parser) errUnexpectedToken(unexpected token.Token, expectedKeywords ...
 		return
 	}
 
+	message := fmt.Sprintf("unexpected token - got: %s want one of: %v", unexpected.Keyword, expectedKeywords)
+	p.addDiagnostic(message, unexpected)
 	p.report.AddExternalError(operationreport.ExternalError{
-		Message: fmt.Sprintf("unexpected token - got: %s want one of: %v", unexpected.Keyword, expectedKeywords),
+		Message: message,
 		Locations: []graphqlerrors.Location{
 			{
 				Line:   unexpected.TextPosition.LineStart,
@@ -1281,36 +1296,63 @@ func (p *Parser) parseSelectionSet() (int, bool) {
 	set.SelectionRefs = p.document.Refs[p.document.NextRefIndex()][:0]
 	lbraceToken := p.mustRead(keyword.LBRACE)
 	set.LBrace = lbraceToken.TextPosition
+	lbraceIndex := p.tokenizer.currentToken
+
+	if p.tolerant && p.report.HasErrors() {
+		return ast.InvalidRef, false
+	}
 
 	for {
 		switch p.peek() {
 		case keyword.RBRACE:
 			rbraceToken := p.read()
 			set.RBrace = rbraceToken.TextPosition
-
-			if len(set.SelectionRefs) == 0 {
-				return 0, false
-			}
-
-			p.document.SelectionSets = append(p.document.SelectionSets, set)
-			return len(p.document.SelectionSets) - 1, true
+			return p.addSelectionSet(set)
 
 		case keyword.IDENT, keyword.SPREAD:
 			if cap(set.SelectionRefs) == 0 {
 				set.SelectionRefs = p.document.Refs[p.document.NextRefIndex()][:0]
 			}
 			ref := p.parseSelection()
-			set.SelectionRefs = append(set.SelectionRefs, ref)
+			if !p.report.HasErrors() {
+				set.SelectionRefs = append(set.SelectionRefs, ref)
+			}
+		case keyword.EOF:
+			if p.tolerant {
+				// close all unclosed selection sets but report the missing brace only once
+				if !p.eofReported {
+					p.errUnexpectedToken(p.read(), keyword.RBRACE, keyword.IDENT, keyword.SPREAD)
+					p.eofReported = true
+					p.flushErrors()
+				}
+				return p.addSelectionSet(set)
+			}
+			p.errUnexpectedToken(p.read(), keyword.RBRACE, keyword.IDENT, keyword.SPREAD)
 		default:
 			p.errUnexpectedToken(p.read(), keyword.RBRACE, keyword.IDENT, keyword.SPREAD)
 		}
 
 		if p.report.HasErrors() {
-			return ast.InvalidRef, false
+			if !p.tolerant {
+				return ast.InvalidRef, false
+			}
+			if closed, rbrace := p.recoverSelectionSet(lbraceIndex); closed {
+				set.RBrace = rbrace
+				return p.addSelectionSet(set)
+			}
 		}
 	}
 }
 
+func (p *Parser) addSelectionSet(set ast.SelectionSet) (int, bool) {
+	if len(set.SelectionRefs) == 0 {
+		return 0, false
+	}
+
+	p.document.SelectionSets = append(p.document.SelectionSets, set)
+	return len(p.document.SelectionSets) - 1, true
+}
+
 func (p *Parser) parseSelection() int {
 	next := p.peek()
 	switch next {
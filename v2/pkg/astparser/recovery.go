@@ -0,0 +1,208 @@
+package astparser
+
+import (
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer/identkeyword"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer/keyword"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer/literal"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer/position"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer/token"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/operationreport"
+)
+
+// Diagnostic is a syntax error collected while parsing in tolerant mode
+type Diagnostic struct {
+	Message string
+	// Position is the range of the unexpected token
+	Position position.Position
+}
+
+// ParseGraphqlDocumentStringTolerant takes a raw GraphQL document in string format and parses it into an AST
+// without stopping at the first syntax error, see Parser.ParseTolerant.
+func ParseGraphqlDocumentStringTolerant(input string) (ast.Document, []Diagnostic, operationreport.Report) {
+	return ParseGraphqlDocumentBytesTolerant([]byte(input))
+}
+
+// ParseGraphqlDocumentBytesTolerant takes a raw GraphQL document in byte slice format and parses it into an AST
+// without stopping at the first syntax error, see Parser.ParseTolerant.
+func ParseGraphqlDocumentBytesTolerant(input []byte) (ast.Document, []Diagnostic, operationreport.Report) {
+	parser := NewParser()
+	doc := *ast.NewSmallDocument()
+	doc.Input.ResetInputBytes(input)
+	report := operationreport.Report{}
+	diagnostics := parser.ParseTolerant(&doc, &report)
+	return doc, diagnostics, report
+}
+
+// ParseTolerant parses all input in a Document.Input into the Document.
+// Other than Parse it doesn't stop at the first syntax error but recovers at selection set and definition boundaries.
+// A selection containing a syntax error is dropped from its selection set,
+// a definition containing a syntax error which can't be recovered within a selection set is dropped from the Document.
+// This way the Document only contains complete nodes and can be validated.
+// All syntax errors are added to the report and returned as diagnostics including the range of the unexpected token.
+func (p *Parser) ParseTolerant(document *ast.Document, report *operationreport.Report) []Diagnostic {
+	p.document = document
+	p.report = &p.scopeReport
+	p.report.Reset()
+	p.tolerantReport = report
+	p.tolerant = true
+	p.diagnostics = nil
+	p.eofReported = false
+
+	p.tokenize()
+	p.parse()
+	p.flushErrors()
+
+	p.tolerant = false
+	p.tolerantReport = nil
+	p.report = report
+	return p.diagnostics
+}
+
+// addDiagnostic records a syntax error in tolerant mode
+func (p *Parser) addDiagnostic(message string, unexpected token.Token) {
+	if !p.tolerant {
+		return
+	}
+	pos := unexpected.TextPosition
+	if unexpected.Keyword == keyword.EOF {
+		pos = p.endOfInput()
+	}
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Message:  message,
+		Position: pos,
+	})
+}
+
+// endOfInput returns the position right after the last token
+func (p *Parser) endOfInput() position.Position {
+	if p.tokenizer.maxTokens == 0 {
+		return position.Position{LineStart: 1, LineEnd: 1, CharStart: 1, CharEnd: 1}
+	}
+	last := p.tokenizer.tokens[p.tokenizer.maxTokens-1].TextPosition
+	return position.Position{
+		LineStart: last.LineEnd,
+		LineEnd:   last.LineEnd,
+		CharStart: last.CharEnd,
+		CharEnd:   last.CharEnd,
+	}
+}
+
+// flushErrors moves the errors of the current recovery scope into the report passed to ParseTolerant
+func (p *Parser) flushErrors() {
+	p.tolerantReport.InternalErrors = append(p.tolerantReport.InternalErrors, p.scopeReport.InternalErrors...)
+	p.tolerantReport.ExternalErrors = append(p.tolerantReport.ExternalErrors, p.scopeReport.ExternalErrors...)
+	p.scopeReport.Reset()
+}
+
+// recoverSelectionSet skips the tokens of a broken selection up to the next selection or the end of the selection set
+// opened by the token at lbraceIndex.
+// It returns true if the closing brace of the selection set was already consumed by the broken selection.
+func (p *Parser) recoverSelectionSet(lbraceIndex int) (closed bool, rbrace position.Position) {
+	p.flushErrors()
+
+	current := p.tokenizer.currentToken
+	braces, parens := 0, 0
+	for i := lbraceIndex + 1; i < p.tokenizer.maxTokens; i++ {
+		tok := p.tokenizer.tokens[i]
+		if i > current && braces == 0 && parens == 0 {
+			switch tok.Keyword {
+			case keyword.IDENT, keyword.SPREAD, keyword.RBRACE:
+				p.tokenizer.seek(i)
+				return false, rbrace
+			}
+		}
+		switch tok.Keyword {
+		case keyword.LBRACE:
+			braces++
+		case keyword.RBRACE:
+			if braces == 0 {
+				if i > current {
+					p.tokenizer.seek(i)
+					return false, rbrace
+				}
+				p.tokenizer.seek(i + 1)
+				return true, tok.TextPosition
+			}
+			braces--
+			parens = 0
+		case keyword.LPAREN, keyword.LBRACK:
+			parens++
+		case keyword.RPAREN, keyword.RBRACK:
+			if parens > 0 {
+				parens--
+			}
+		}
+	}
+
+	p.tokenizer.seek(p.tokenizer.maxTokens)
+	return false, rbrace
+}
+
+// recoverDefinition drops the root nodes added by the broken definition starting at the token at startIndex
+// and skips its tokens up to the start of the next definition
+func (p *Parser) recoverDefinition(startIndex, rootNodes int) {
+	p.flushErrors()
+
+	for _, node := range p.document.RootNodes[rootNodes:] {
+		name := p.document.NodeNameBytes(node)
+		if node.Kind == ast.NodeKindSchemaDefinition {
+			name = literal.SCHEMA
+		}
+		p.document.Index.RemoveNode(name, node)
+	}
+	p.document.RootNodes = p.document.RootNodes[:rootNodes]
+
+	current := p.tokenizer.currentToken
+	braces, parens := 0, 0
+	previous := keyword.UNDEFINED
+	for i := startIndex; i < p.tokenizer.maxTokens; i++ {
+		tok := p.tokenizer.tokens[i]
+		if tok.Keyword == keyword.COMMENT {
+			continue
+		}
+		if i > startIndex && p.isDefinitionStart(tok, previous, braces == 0 && parens == 0, i > current) {
+			p.tokenizer.seek(i)
+			return
+		}
+		switch tok.Keyword {
+		case keyword.LBRACE:
+			braces++
+		case keyword.RBRACE:
+			if braces > 0 {
+				braces--
+			}
+			parens = 0
+		case keyword.LPAREN, keyword.LBRACK:
+			parens++
+		case keyword.RPAREN, keyword.RBRACK:
+			if parens > 0 {
+				parens--
+			}
+		}
+		previous = tok.Keyword
+	}
+
+	p.tokenizer.seek(p.tokenizer.maxTokens)
+}
+
+// isDefinitionStart reports whether a definition could start at tok.
+// Definition keywords in the first column are accepted at any nesting level, even if they were consumed by the broken definition,
+// to recover from unclosed braces and parentheses.
+func (p *Parser) isDefinitionStart(tok token.Token, previous keyword.Keyword, topLevel, afterError bool) bool {
+	switch tok.Keyword {
+	case keyword.STRING, keyword.BLOCKSTRING:
+		return topLevel && afterError
+	case keyword.LBRACE:
+		// an anonymous operation directly follows another definition
+		return topLevel && afterError && previous == keyword.RBRACE
+	case keyword.IDENT:
+		switch p.identKeywordToken(tok) {
+		case identkeyword.ENUM, identkeyword.TYPE, identkeyword.UNION, identkeyword.QUERY, identkeyword.MUTATION,
+			identkeyword.SUBSCRIPTION, identkeyword.INPUT, identkeyword.EXTEND, identkeyword.SCHEMA, identkeyword.SCALAR,
+			identkeyword.FRAGMENT, identkeyword.INTERFACE, identkeyword.DIRECTIVE:
+			return (topLevel && afterError) || tok.TextPosition.CharStart == 1
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package astparser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astprinter"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astvalidation"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer/position"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/operationreport"
+)
+
+func TestParser_ParseTolerant(t *testing.T) {
+	run := func(t *testing.T, input string, expectedDocument string, expectedDiagnostics ...Diagnostic) ast.Document {
+		t.Helper()
+
+		doc, diagnostics, report := ParseGraphqlDocumentStringTolerant(input)
+		assert.Equal(t, expectedDiagnostics, diagnostics)
+		require.Len(t, report.ExternalErrors, len(expectedDiagnostics))
+		for i := range diagnostics {
+			assert.Equal(t, diagnostics[i].Message, report.ExternalErrors[i].Message)
+		}
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, astprinter.Print(&doc, nil, buf))
+		assert.Equal(t, expectedDocument, buf.String())
+		return doc
+	}
+
+	t.Run("valid document", func(t *testing.T) {
+		run(t, `query Q { a }`, `query Q {a}`)
+	})
+
+	t.Run("broken selections are dropped", func(t *testing.T) {
+		run(t, `query Q {
+  a(x: )
+  b { c(: 1) d }
+  e
+}`, `query Q {b {d} e}`,
+			Diagnostic{
+				Message:  "unexpected token - got: RPAREN want one of: []",
+				Position: position.Position{LineStart: 2, LineEnd: 2, CharStart: 8, CharEnd: 9},
+			},
+			Diagnostic{
+				Message:  "unexpected token - got: COLON want one of: [RPAREN]",
+				Position: position.Position{LineStart: 3, LineEnd: 3, CharStart: 9, CharEnd: 10},
+			},
+		)
+	})
+
+	t.Run("closing brace consumed by broken selection", func(t *testing.T) {
+		run(t, `{ me { ... on Person { id personID: } name } }`, `{me {... on Person {id} name}}`,
+			Diagnostic{
+				Message:  "unexpected token - got: RBRACE want one of: [IDENT]",
+				Position: position.Position{LineStart: 1, LineEnd: 1, CharStart: 37, CharEnd: 38},
+			},
+		)
+	})
+
+	t.Run("unclosed selection sets at the end of the input", func(t *testing.T) {
+		run(t, `query Q { a }
+query R { hero { name`, `query Q {a} query R {hero {name}}`,
+			Diagnostic{
+				Message:  "unexpected token - got: EOF want one of: [RBRACE IDENT SPREAD]",
+				Position: position.Position{LineStart: 2, LineEnd: 2, CharStart: 22, CharEnd: 22},
+			},
+		)
+	})
+
+	t.Run("broken definitions are dropped", func(t *testing.T) {
+		run(t, `type A { a: String b }
+type B { b: [String }
+"description"
+type C { c: String }
+query Q($a: ) { a }
+{ anonymous }`, `"description"
+type C {c: String} {anonymous}`,
+			Diagnostic{
+				Message:  "unexpected token - got: RBRACE want one of: [COLON]",
+				Position: position.Position{LineStart: 1, LineEnd: 1, CharStart: 22, CharEnd: 23},
+			},
+			Diagnostic{
+				Message:  "unexpected token - got: RBRACE want one of: [RBRACK]",
+				Position: position.Position{LineStart: 2, LineEnd: 2, CharStart: 21, CharEnd: 22},
+			},
+			Diagnostic{
+				Message:  "unexpected token - got: RPAREN want one of: [IDENT LBRACK]",
+				Position: position.Position{LineStart: 5, LineEnd: 5, CharStart: 13, CharEnd: 14},
+			},
+		)
+	})
+
+	t.Run("unclosed definition followed by definition in the first column", func(t *testing.T) {
+		doc := run(t, `type A {
+  a: String
+  b(
+type B { b: String }`, `type B {b: String}`,
+			Diagnostic{
+				Message:  "unexpected token - got: IDENT want one of: [COLON]",
+				Position: position.Position{LineStart: 4, LineEnd: 4, CharStart: 6, CharEnd: 7},
+			},
+		)
+
+		_, exists := doc.Index.FirstNodeByNameStr("A")
+		assert.False(t, exists)
+		_, exists = doc.Index.FirstNodeByNameStr("B")
+		assert.True(t, exists)
+	})
+
+	t.Run("partial document can be validated", func(t *testing.T) {
+		definition, report := ParseGraphqlDocumentString(`
+			schema { query: Query }
+			scalar String
+			type Query { hero: Hero }
+			type Hero { name: String }`)
+		require.False(t, report.HasErrors())
+
+		operation, diagnostics, _ := ParseGraphqlDocumentStringTolerant(`
+			query Q { hero { name unknown(x: ) unknown } }`)
+		require.Len(t, diagnostics, 1)
+
+		validationReport := operationreport.Report{}
+		astvalidation.DefaultOperationValidator().Validate(&operation, &definition, &validationReport)
+		require.Len(t, validationReport.ExternalErrors, 1)
+		assert.Equal(t, "field: unknown not defined on type: Hero", validationReport.ExternalErrors[0].Message)
+	})
+}
@@ -54,6 +54,11 @@ func (t *Tokenizer) next() int {
 	return t.currentToken
 }
 
+// seek - moves the tokenizer so that the next Read returns the token at index
+func (t *Tokenizer) seek(index int) {
+	t.currentToken = index - 1
+}
+
 // Read - increments currentToken index and return token if hasNextToken
 // otherwise returns keyword.EOF
 func (t *Tokenizer) Read() token.Token {
@@ -0,0 +1,868 @@
+package astprinter
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+)
+
+// ArgumentWrapping controls when argument lists are printed with one argument per line
+type ArgumentWrapping int
+
+const (
+	// WrapArgumentsAuto wraps argument lists of lines exceeding the line width
+	WrapArgumentsAuto ArgumentWrapping = iota
+	// WrapArgumentsAlways wraps all argument lists
+	WrapArgumentsAlways
+	// WrapArgumentsNever prints all argument lists on a single line
+	WrapArgumentsNever
+)
+
+// FormatOptions configures Format
+type FormatOptions struct {
+	// Indent is the indentation of a single level, defaults to two spaces
+	Indent string
+	// LineWidth is the width at which argument lists and union members are wrapped, defaults to 80
+	LineWidth int
+	// BlockStringDescriptions prints all descriptions as block strings
+	BlockStringDescriptions bool
+	// ArgumentWrapping controls the wrapping of field arguments, argument definitions and variable definitions.
+	// Directive arguments are always printed on a single line.
+	ArgumentWrapping ArgumentWrapping
+	// SortDefinitions orders the definitions of the document by kind and name
+	SortDefinitions bool
+	// SortFields orders field definitions, input field definitions and enum values by name.
+	// Selections and arguments keep their order.
+	SortFields bool
+}
+
+// Format prints a document as formatted GraphQL source.
+// Other than Print it keeps the comments retained by astparser.Parser.ParseWithComments
+// as well as the content of block string descriptions and it wraps long lines.
+// The output ends with a line terminator.
+func Format(document *ast.Document, options FormatOptions, out io.Writer) error {
+	if options.Indent == "" {
+		options.Indent = "  "
+	}
+	if options.LineWidth <= 0 {
+		options.LineWidth = 80
+	}
+
+	f := formatter{
+		document: document,
+		options:  options,
+		comments: map[commentKey][]int{},
+	}
+	for i := range document.Comments {
+		key := commentKey{node: document.Comments[i].Node, placement: document.Comments[i].Placement}
+		f.comments[key] = append(f.comments[key], i)
+	}
+	f.format()
+
+	_, err := out.Write(f.out.Bytes())
+	return err
+}
+
+// FormatString is the same as Format but returns a string instead of writing to an io.Writer
+func FormatString(document *ast.Document, options FormatOptions) (string, error) {
+	buff := &bytes.Buffer{}
+	err := Format(document, options, buff)
+	return buff.String(), err
+}
+
+type commentKey struct {
+	node      ast.Node
+	placement ast.CommentPlacement
+}
+
+type formatter struct {
+	document  *ast.Document
+	options   FormatOptions
+	comments  map[commentKey][]int
+	out       bytes.Buffer
+	lineStart int
+	depth     int
+	// trailing are the comments printed at the end of the current line
+	trailing []int
+}
+
+func (f *formatter) write(s string) {
+	f.out.WriteString(s)
+}
+
+func (f *formatter) indent() {
+	for i := 0; i < f.depth; i++ {
+		f.out.WriteString(f.options.Indent)
+	}
+}
+
+// newline ends the current line with its trailing comments
+func (f *formatter) newline() {
+	for _, ref := range f.trailing {
+		f.out.WriteByte(' ')
+		f.out.Write(f.document.CommentBytes(ref))
+	}
+	f.trailing = f.trailing[:0]
+	f.out.WriteByte('\n')
+	f.lineStart = f.out.Len()
+}
+
+func (f *formatter) line(s string) {
+	f.indent()
+	f.write(s)
+	f.newline()
+}
+
+// fits returns true if s can be added to the current line without exceeding the line width
+func (f *formatter) fits(s string) bool {
+	column := utf8.RuneCount(f.out.Bytes()[f.lineStart:])
+	if f.out.Len() == f.lineStart {
+		column = f.depth * utf8.RuneCountInString(f.options.Indent)
+	}
+	return column+utf8.RuneCountInString(s) <= f.options.LineWidth
+}
+
+func (f *formatter) hasComments(node ast.Node, placement ast.CommentPlacement) bool {
+	return len(f.comments[commentKey{node: node, placement: placement}]) != 0
+}
+
+func (f *formatter) leadingComments(node ast.Node) {
+	for _, ref := range f.comments[commentKey{node: node, placement: ast.CommentPlacementLeading}] {
+		f.line(f.document.CommentString(ref))
+	}
+}
+
+func (f *formatter) trailingComments(node ast.Node) {
+	f.trailing = append(f.trailing, f.comments[commentKey{node: node, placement: ast.CommentPlacementTrailing}]...)
+}
+
+// closingComments prints the comments after the last child of a node one level deeper than the node
+func (f *formatter) closingComments(node ast.Node) {
+	f.depth++
+	for _, ref := range f.comments[commentKey{node: node, placement: ast.CommentPlacementClosing}] {
+		f.line(f.document.CommentString(ref))
+	}
+	f.depth--
+}
+
+func (f *formatter) format() {
+	nodes := make([]ast.Node, len(f.document.RootNodes))
+	copy(nodes, f.document.RootNodes)
+	if f.options.SortDefinitions {
+		sort.SliceStable(nodes, func(i, j int) bool {
+			left, right := definitionRank(nodes[i].Kind), definitionRank(nodes[j].Kind)
+			if left != right {
+				return left < right
+			}
+			leftName, rightName := f.definitionName(nodes[i]), f.definitionName(nodes[j])
+			if leftName != rightName {
+				return leftName < rightName
+			}
+			return !isExtension(nodes[i].Kind) && isExtension(nodes[j].Kind)
+		})
+	}
+
+	printed := false
+	for _, node := range nodes {
+		if node.Kind == ast.NodeKindUnknown {
+			continue
+		}
+		if printed {
+			f.newline()
+		}
+		f.definition(node)
+		printed = true
+	}
+
+	for i, ref := range f.comments[commentKey{node: ast.InvalidNode, placement: ast.CommentPlacementClosing}] {
+		if i == 0 && printed {
+			f.newline()
+		}
+		f.line(f.document.CommentString(ref))
+	}
+}
+
+func definitionRank(kind ast.NodeKind) int {
+	switch kind {
+	case ast.NodeKindSchemaDefinition, ast.NodeKindSchemaExtension:
+		return 0
+	case ast.NodeKindDirectiveDefinition:
+		return 1
+	case ast.NodeKindOperationDefinition:
+		return 3
+	case ast.NodeKindFragmentDefinition:
+		return 4
+	default:
+		return 2
+	}
+}
+
+func isExtension(kind ast.NodeKind) bool {
+	switch kind {
+	case ast.NodeKindSchemaExtension, ast.NodeKindObjectTypeExtension, ast.NodeKindInterfaceTypeExtension,
+		ast.NodeKindUnionTypeExtension, ast.NodeKindEnumTypeExtension, ast.NodeKindInputObjectTypeExtension,
+		ast.NodeKindScalarTypeExtension:
+		return true
+	}
+	return false
+}
+
+func (f *formatter) definitionName(node ast.Node) string {
+	switch node.Kind {
+	case ast.NodeKindOperationDefinition:
+		return f.document.OperationDefinitionNameString(node.Ref)
+	case ast.NodeKindFragmentDefinition:
+		return f.document.FragmentDefinitionNameString(node.Ref)
+	case ast.NodeKindScalarTypeExtension:
+		return f.document.ScalarTypeExtensionNameString(node.Ref)
+	case ast.NodeKindInputObjectTypeExtension:
+		return f.document.InputObjectTypeExtensionNameString(node.Ref)
+	}
+	return f.document.NodeNameString(node)
+}
+
+func (f *formatter) definition(node ast.Node) {
+	d := f.document
+	switch node.Kind {
+	case ast.NodeKindSchemaDefinition:
+		schema := d.SchemaDefinitions[node.Ref]
+		f.header(node, schema.Description, "schema", schema.Directives.Refs)
+		f.rootOperationTypes(node, schema.RootOperationTypeDefinitions.Refs)
+	case ast.NodeKindSchemaExtension:
+		schema := d.SchemaExtensions[node.Ref]
+		f.header(node, ast.Description{}, "extend schema", schema.Directives.Refs)
+		f.rootOperationTypes(node, schema.RootOperationTypeDefinitions.Refs)
+	case ast.NodeKindObjectTypeDefinition:
+		object := d.ObjectTypeDefinitions[node.Ref]
+		f.header(node, object.Description, "type "+d.ObjectTypeDefinitionNameString(node.Ref)+f.implements(object.ImplementsInterfaces.Refs), object.Directives.Refs)
+		f.fieldDefinitions(node, object.FieldsDefinition.Refs)
+	case ast.NodeKindObjectTypeExtension:
+		object := d.ObjectTypeExtensions[node.Ref]
+		f.header(node, ast.Description{}, "extend type "+d.ObjectTypeExtensionNameString(node.Ref)+f.implements(object.ImplementsInterfaces.Refs), object.Directives.Refs)
+		f.fieldDefinitions(node, object.FieldsDefinition.Refs)
+	case ast.NodeKindInterfaceTypeDefinition:
+		iface := d.InterfaceTypeDefinitions[node.Ref]
+		f.header(node, iface.Description, "interface "+d.InterfaceTypeDefinitionNameString(node.Ref)+f.implements(iface.ImplementsInterfaces.Refs), iface.Directives.Refs)
+		f.fieldDefinitions(node, iface.FieldsDefinition.Refs)
+	case ast.NodeKindInterfaceTypeExtension:
+		iface := d.InterfaceTypeExtensions[node.Ref]
+		f.header(node, ast.Description{}, "extend interface "+d.InterfaceTypeExtensionNameString(node.Ref)+f.implements(iface.ImplementsInterfaces.Refs), iface.Directives.Refs)
+		f.fieldDefinitions(node, iface.FieldsDefinition.Refs)
+	case ast.NodeKindInputObjectTypeDefinition:
+		input := d.InputObjectTypeDefinitions[node.Ref]
+		f.header(node, input.Description, "input "+d.InputObjectTypeDefinitionNameString(node.Ref), input.Directives.Refs)
+		f.inputFieldDefinitions(node, input.InputFieldsDefinition.Refs)
+	case ast.NodeKindInputObjectTypeExtension:
+		input := d.InputObjectTypeExtensions[node.Ref]
+		f.header(node, ast.Description{}, "extend input "+d.InputObjectTypeExtensionNameString(node.Ref), input.Directives.Refs)
+		f.inputFieldDefinitions(node, input.InputFieldsDefinition.Refs)
+	case ast.NodeKindEnumTypeDefinition:
+		enum := d.EnumTypeDefinitions[node.Ref]
+		f.header(node, enum.Description, "enum "+d.EnumTypeDefinitionNameString(node.Ref), enum.Directives.Refs)
+		f.enumValueDefinitions(node, enum.EnumValuesDefinition.Refs)
+	case ast.NodeKindEnumTypeExtension:
+		enum := d.EnumTypeExtensions[node.Ref]
+		f.header(node, ast.Description{}, "extend enum "+d.EnumTypeExtensionNameString(node.Ref), enum.Directives.Refs)
+		f.enumValueDefinitions(node, enum.EnumValuesDefinition.Refs)
+	case ast.NodeKindUnionTypeDefinition:
+		union := d.UnionTypeDefinitions[node.Ref]
+		f.header(node, union.Description, "union "+d.UnionTypeDefinitionNameString(node.Ref), union.Directives.Refs)
+		f.unionMembers(union.UnionMemberTypes.Refs)
+	case ast.NodeKindUnionTypeExtension:
+		union := d.UnionTypeExtensions[node.Ref]
+		f.header(node, ast.Description{}, "extend union "+d.UnionTypeExtensionNameString(node.Ref), union.Directives.Refs)
+		f.unionMembers(union.UnionMemberTypes.Refs)
+	case ast.NodeKindScalarTypeDefinition:
+		scalar := d.ScalarTypeDefinitions[node.Ref]
+		f.header(node, scalar.Description, "scalar "+d.ScalarTypeDefinitionNameString(node.Ref), scalar.Directives.Refs)
+		f.newline()
+	case ast.NodeKindScalarTypeExtension:
+		scalar := d.ScalarTypeExtensions[node.Ref]
+		f.header(node, ast.Description{}, "extend scalar "+d.ScalarTypeExtensionNameString(node.Ref), scalar.Directives.Refs)
+		f.newline()
+	case ast.NodeKindDirectiveDefinition:
+		f.directiveDefinition(node)
+	case ast.NodeKindOperationDefinition:
+		f.operationDefinition(node)
+	case ast.NodeKindFragmentDefinition:
+		fragment := d.FragmentDefinitions[node.Ref]
+		f.leadingComments(node)
+		f.indent()
+		f.write("fragment " + d.FragmentDefinitionNameString(node.Ref) + " on " + d.TypeNameString(fragment.TypeCondition.Type) + f.directives(fragment.Directives.Refs))
+		f.trailingComments(node)
+		f.selectionSet(node, fragment.SelectionSet)
+	}
+}
+
+// header prints the comments, the description and the first line of a definition up to its directives
+func (f *formatter) header(node ast.Node, description ast.Description, text string, directiveRefs []int) {
+	f.leadingComments(node)
+	f.description(description)
+	f.indent()
+	f.write(text + f.directives(directiveRefs))
+	f.trailingComments(node)
+}
+
+func (f *formatter) implements(refs []int) string {
+	if len(refs) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		names = append(names, f.document.TypeNameString(ref))
+	}
+	return " implements " + strings.Join(names, " & ")
+}
+
+// block prints the children of a node enclosed in braces
+func (f *formatter) block(node ast.Node, children func()) {
+	f.write(" {")
+	f.newline()
+	f.depth++
+	children()
+	f.depth--
+	f.closingComments(node)
+	f.line("}")
+}
+
+func (f *formatter) rootOperationTypes(node ast.Node, refs []int) {
+	if len(refs) == 0 && !f.hasComments(node, ast.CommentPlacementClosing) {
+		f.newline()
+		return
+	}
+	f.block(node, func() {
+		for _, ref := range refs {
+			definition := f.document.RootOperationTypeDefinitions[ref]
+			f.line(definition.OperationType.Name() + ": " + string(f.document.Input.ByteSlice(definition.NamedType.Name)))
+		}
+	})
+}
+
+func (f *formatter) fieldDefinitions(node ast.Node, refs []int) {
+	if len(refs) == 0 {
+		f.newline()
+		return
+	}
+	refs = f.sorted(refs, f.document.FieldDefinitionNameString)
+	f.block(node, func() {
+		for _, ref := range refs {
+			definition := f.document.FieldDefinitions[ref]
+			fieldNode := ast.Node{Kind: ast.NodeKindFieldDefinition, Ref: ref}
+			f.leadingComments(fieldNode)
+			f.description(definition.Description)
+			f.indent()
+			f.write(f.document.FieldDefinitionNameString(ref))
+			f.trailingComments(fieldNode)
+			suffix := ": " + f.typeString(definition.Type) + f.directives(definition.Directives.Refs)
+			f.argumentsDefinition(definition.ArgumentsDefinition.Refs, suffix)
+			f.write(suffix)
+			f.newline()
+		}
+	})
+}
+
+func (f *formatter) inputFieldDefinitions(node ast.Node, refs []int) {
+	if len(refs) == 0 {
+		f.newline()
+		return
+	}
+	refs = f.sorted(refs, f.document.InputValueDefinitionNameString)
+	f.block(node, func() {
+		for _, ref := range refs {
+			f.inputValueDefinition(ref)
+		}
+	})
+}
+
+func (f *formatter) inputValueDefinition(ref int) {
+	node := ast.Node{Kind: ast.NodeKindInputValueDefinition, Ref: ref}
+	f.leadingComments(node)
+	f.description(f.document.InputValueDefinitions[ref].Description)
+	f.indent()
+	f.write(f.inputValueDefinitionString(ref))
+	f.trailingComments(node)
+	f.newline()
+}
+
+func (f *formatter) inputValueDefinitionString(ref int) string {
+	definition := f.document.InputValueDefinitions[ref]
+	out := f.document.InputValueDefinitionNameString(ref) + ": " + f.typeString(definition.Type)
+	if definition.DefaultValue.IsDefined {
+		out += " = " + f.valueString(definition.DefaultValue.Value)
+	}
+	return out + f.directives(definition.Directives.Refs)
+}
+
+func (f *formatter) enumValueDefinitions(node ast.Node, refs []int) {
+	if len(refs) == 0 {
+		f.newline()
+		return
+	}
+	refs = f.sorted(refs, f.document.EnumValueDefinitionNameString)
+	f.block(node, func() {
+		for _, ref := range refs {
+			valueNode := ast.Node{Kind: ast.NodeKindEnumValueDefinition, Ref: ref}
+			f.leadingComments(valueNode)
+			f.description(f.document.EnumValueDefinitions[ref].Description)
+			f.indent()
+			f.write(f.document.EnumValueDefinitionNameString(ref) + f.directives(f.document.EnumValueDefinitions[ref].Directives.Refs))
+			f.trailingComments(valueNode)
+			f.newline()
+		}
+	})
+}
+
+// unionMembers prints the members of a union on the current line or one per line if they don't fit
+func (f *formatter) unionMembers(refs []int) {
+	if len(refs) == 0 {
+		f.newline()
+		return
+	}
+
+	names := make([]string, 0, len(refs))
+	wrap := false
+	for _, ref := range refs {
+		names = append(names, f.document.TypeNameString(ref))
+		wrap = wrap || f.hasComments(ast.Node{Kind: ast.NodeKindUnionMemberType, Ref: ref}, ast.CommentPlacementLeading)
+	}
+	inline := " = " + strings.Join(names, " | ")
+	if !wrap && f.fits(inline) {
+		f.write(inline)
+		for _, ref := range refs {
+			f.trailingComments(ast.Node{Kind: ast.NodeKindUnionMemberType, Ref: ref})
+		}
+		f.newline()
+		return
+	}
+
+	f.write(" =")
+	f.newline()
+	f.depth++
+	for i, ref := range refs {
+		member := ast.Node{Kind: ast.NodeKindUnionMemberType, Ref: ref}
+		f.leadingComments(member)
+		f.indent()
+		f.write("| " + names[i])
+		f.trailingComments(member)
+		f.newline()
+	}
+	f.depth--
+}
+
+func (f *formatter) directiveDefinition(node ast.Node) {
+	d := f.document
+	definition := d.DirectiveDefinitions[node.Ref]
+
+	locations := make([]string, 0, 4)
+	iter := definition.DirectiveLocations.Iterable()
+	for iter.Next() {
+		locations = append(locations, iter.Value().LiteralString())
+	}
+	suffix := ""
+	if definition.Repeatable.IsRepeatable {
+		suffix = " repeatable"
+	}
+	suffix += " on " + strings.Join(locations, " | ")
+
+	f.leadingComments(node)
+	f.description(definition.Description)
+	f.indent()
+	f.write("directive @" + d.DirectiveDefinitionNameString(node.Ref))
+	f.trailingComments(node)
+	f.argumentsDefinition(definition.ArgumentsDefinition.Refs, suffix)
+	f.write(suffix)
+	f.newline()
+}
+
+// argumentsDefinition prints argument definitions on the current line if the line including suffix fits,
+// otherwise each argument is printed on its own line
+func (f *formatter) argumentsDefinition(refs []int, suffix string) {
+	if len(refs) == 0 {
+		return
+	}
+
+	inline := make([]string, 0, len(refs))
+	wrap := false
+	for _, ref := range refs {
+		inline = append(inline, f.inputValueDefinitionString(ref))
+		wrap = wrap || f.document.InputValueDefinitions[ref].Description.IsDefined ||
+			f.hasComments(ast.Node{Kind: ast.NodeKindInputValueDefinition, Ref: ref}, ast.CommentPlacementLeading)
+	}
+	if !wrap && !f.wrapArguments("("+strings.Join(inline, ", ")+")"+suffix) {
+		f.write("(" + strings.Join(inline, ", ") + ")")
+		for _, ref := range refs {
+			f.trailingComments(ast.Node{Kind: ast.NodeKindInputValueDefinition, Ref: ref})
+		}
+		return
+	}
+
+	f.write("(")
+	f.newline()
+	f.depth++
+	for _, ref := range refs {
+		f.inputValueDefinition(ref)
+	}
+	f.depth--
+	f.indent()
+	f.write(")")
+}
+
+func (f *formatter) wrapArguments(line string) bool {
+	switch f.options.ArgumentWrapping {
+	case WrapArgumentsAlways:
+		return true
+	case WrapArgumentsNever:
+		return false
+	default:
+		return !f.fits(line)
+	}
+}
+
+func (f *formatter) operationDefinition(node ast.Node) {
+	d := f.document
+	operation := d.OperationDefinitions[node.Ref]
+
+	f.leadingComments(node)
+	f.indent()
+
+	name := d.OperationDefinitionNameString(node.Ref)
+	shorthand := operation.OperationType == ast.OperationTypeQuery && operation.OperationTypeLiteral.LineStart == 0 &&
+		name == "" && len(operation.VariableDefinitions.Refs) == 0 && len(operation.Directives.Refs) == 0
+	if shorthand {
+		f.write("{")
+		f.trailingComments(node)
+		f.newline()
+		f.depth++
+		f.selections(operation.SelectionSet)
+		f.depth--
+		f.closingComments(node)
+		f.line("}")
+		return
+	}
+
+	header := operation.OperationType.Name()
+	if name != "" {
+		header += " " + name
+	}
+	f.write(header)
+
+	suffix := f.directives(operation.Directives.Refs)
+	f.variableDefinitions(operation.VariableDefinitions.Refs, suffix+" {")
+	f.write(suffix)
+	// the comments are printed after the opening brace rather than after wrapped variable definitions
+	f.trailingComments(node)
+	f.selectionSet(node, operation.SelectionSet)
+}
+
+func (f *formatter) variableDefinitions(refs []int, suffix string) {
+	if len(refs) == 0 {
+		return
+	}
+
+	inline := make([]string, 0, len(refs))
+	wrap := false
+	for _, ref := range refs {
+		definition := f.document.VariableDefinitions[ref]
+		out := "$" + f.document.VariableValueNameString(definition.VariableValue.Ref) + ": " + f.typeString(definition.Type)
+		if definition.DefaultValue.IsDefined {
+			out += " = " + f.valueString(definition.DefaultValue.Value)
+		}
+		inline = append(inline, out+f.directives(definition.Directives.Refs))
+		// trailing comments of variable definitions printed on a single line would end up after the opening brace
+		node := ast.Node{Kind: ast.NodeKindVariableDefinition, Ref: ref}
+		wrap = wrap || f.hasComments(node, ast.CommentPlacementLeading) || f.hasComments(node, ast.CommentPlacementTrailing)
+	}
+	f.arguments(ast.NodeKindVariableDefinition, refs, inline, wrap, suffix)
+}
+
+// arguments prints arguments or variable definitions on the current line or one per line
+func (f *formatter) arguments(kind ast.NodeKind, refs []int, inline []string, wrap bool, suffix string) {
+	if !wrap && !f.wrapArguments("("+strings.Join(inline, ", ")+")"+suffix) {
+		f.write("(" + strings.Join(inline, ", ") + ")")
+		for _, ref := range refs {
+			f.trailingComments(ast.Node{Kind: kind, Ref: ref})
+		}
+		return
+	}
+
+	f.write("(")
+	f.newline()
+	f.depth++
+	for i, ref := range refs {
+		node := ast.Node{Kind: kind, Ref: ref}
+		f.leadingComments(node)
+		f.indent()
+		f.write(inline[i])
+		f.trailingComments(node)
+		f.newline()
+	}
+	f.depth--
+	f.indent()
+	f.write(")")
+}
+
+// selectionSet prints the selections of a node and ends the current line
+func (f *formatter) selectionSet(node ast.Node, ref int) {
+	f.block(node, func() {
+		f.selections(ref)
+	})
+}
+
+func (f *formatter) selections(selectionSet int) {
+	d := f.document
+	for _, ref := range d.SelectionSets[selectionSet].SelectionRefs {
+		selection := d.Selections[ref]
+		switch selection.Kind {
+		case ast.SelectionKindField:
+			f.field(selection.Ref)
+		case ast.SelectionKindFragmentSpread:
+			node := ast.Node{Kind: ast.NodeKindFragmentSpread, Ref: selection.Ref}
+			f.leadingComments(node)
+			f.indent()
+			f.write("..." + d.FragmentSpreadNameString(selection.Ref) + f.directives(d.FragmentSpreads[selection.Ref].Directives.Refs))
+			f.trailingComments(node)
+			f.newline()
+		case ast.SelectionKindInlineFragment:
+			fragment := d.InlineFragments[selection.Ref]
+			node := ast.Node{Kind: ast.NodeKindInlineFragment, Ref: selection.Ref}
+			f.leadingComments(node)
+			f.indent()
+			f.write("...")
+			if fragment.TypeCondition.Type != ast.InvalidRef {
+				f.write(" on " + d.TypeNameString(fragment.TypeCondition.Type))
+			}
+			f.write(f.directives(fragment.Directives.Refs))
+			f.trailingComments(node)
+			if fragment.HasSelections {
+				f.selectionSet(node, fragment.SelectionSet)
+			} else {
+				f.newline()
+			}
+		}
+	}
+}
+
+func (f *formatter) field(ref int) {
+	d := f.document
+	field := d.Fields[ref]
+	node := ast.Node{Kind: ast.NodeKindField, Ref: ref}
+
+	f.leadingComments(node)
+	f.indent()
+	if field.Alias.IsDefined {
+		f.write(d.FieldAliasString(ref) + ": ")
+	}
+	f.write(d.FieldNameString(ref))
+	f.trailingComments(node)
+
+	suffix := f.directives(field.Directives.Refs)
+	if field.HasSelections {
+		suffix += " {"
+	}
+	if len(field.Arguments.Refs) != 0 {
+		inline := make([]string, 0, len(field.Arguments.Refs))
+		wrap := false
+		for _, argument := range field.Arguments.Refs {
+			inline = append(inline, d.ArgumentNameString(argument)+": "+f.valueString(d.Arguments[argument].Value))
+			wrap = wrap || f.hasComments(ast.Node{Kind: ast.NodeKindArgument, Ref: argument}, ast.CommentPlacementLeading)
+		}
+		f.arguments(ast.NodeKindArgument, field.Arguments.Refs, inline, wrap, suffix)
+	}
+	f.write(f.directives(field.Directives.Refs))
+
+	if field.HasSelections {
+		f.selectionSet(node, field.SelectionSet)
+		return
+	}
+	f.newline()
+}
+
+func (f *formatter) directives(refs []int) string {
+	out := ""
+	for _, ref := range refs {
+		directive := f.document.Directives[ref]
+		out += " @" + f.document.DirectiveNameString(ref)
+		if len(directive.Arguments.Refs) == 0 {
+			continue
+		}
+		arguments := make([]string, 0, len(directive.Arguments.Refs))
+		for _, argument := range directive.Arguments.Refs {
+			arguments = append(arguments, f.document.ArgumentNameString(argument)+": "+f.valueString(f.document.Arguments[argument].Value))
+		}
+		out += "(" + strings.Join(arguments, ", ") + ")"
+	}
+	return out
+}
+
+func (f *formatter) typeString(ref int) string {
+	out, _ := f.document.PrintTypeBytes(ref, nil)
+	return string(out)
+}
+
+func (f *formatter) valueString(value ast.Value) string {
+	d := f.document
+	switch value.Kind {
+	case ast.ValueKindList:
+		values := make([]string, 0, len(d.ListValues[value.Ref].Refs))
+		for _, ref := range d.ListValues[value.Ref].Refs {
+			values = append(values, f.valueString(d.Value(ref)))
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	case ast.ValueKindObject:
+		fields := make([]string, 0, len(d.ObjectValues[value.Ref].Refs))
+		for _, ref := range d.ObjectValues[value.Ref].Refs {
+			fields = append(fields, d.ObjectFieldNameString(ref)+": "+f.valueString(d.ObjectFieldValue(ref)))
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	out, _ := d.PrintValueBytes(value, nil)
+	return string(out)
+}
+
+// sorted returns a sorted copy of refs if SortFields is enabled
+func (f *formatter) sorted(refs []int, name func(ref int) string) []int {
+	if !f.options.SortFields {
+		return refs
+	}
+	out := make([]int, len(refs))
+	copy(out, refs)
+	sort.SliceStable(out, func(i, j int) bool {
+		return name(out[i]) < name(out[j])
+	})
+	return out
+}
+
+// description prints a description on its own lines.
+// Block strings are printed with their content re-indented, other strings are kept as they are
+// unless BlockStringDescriptions is enabled.
+func (f *formatter) description(description ast.Description) {
+	if !description.IsDefined {
+		return
+	}
+
+	content := string(f.document.Input.ByteSlice(description.Content))
+	var lines []string
+	if description.IsBlockString {
+		lines = blockStringLines(f.blockStringIndent(description.Content) + content)
+	} else {
+		value, ok := unescapeString(content)
+		if !f.options.BlockStringDescriptions || !ok || value == "" || strings.TrimSpace(value) != value {
+			f.line(`"` + content + `"`)
+			return
+		}
+		lines = strings.Split(strings.ReplaceAll(value, `"""`, `\"""`), "\n")
+	}
+
+	f.line(`"""`)
+	for _, line := range lines {
+		if line == "" {
+			f.newline()
+			continue
+		}
+		f.line(line)
+	}
+	f.line(`"""`)
+}
+
+// blockStringIndent restores the indentation of the first line of a block string which the lexer removes from its content.
+// If the content starts on a new line, the line terminator and the indentation are returned.
+func (f *formatter) blockStringIndent(content ast.ByteSliceReference) string {
+	raw := f.document.Input.RawBytes
+	start := int(content.Start)
+	for start > 0 && (raw[start-1] == ' ' || raw[start-1] == '\t') {
+		start--
+	}
+	if start == 0 || raw[start-1] != '\n' {
+		return ""
+	}
+	return "\n" + string(raw[start:content.Start])
+}
+
+// blockStringLines returns the lines of a raw block string with the common indentation
+// as well as leading and trailing blank lines removed
+func blockStringLines(raw string) []string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+
+	commonIndent := -1
+	for i, line := range lines {
+		if i == 0 {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent == len(line) {
+			continue
+		}
+		if commonIndent == -1 || indent < commonIndent {
+			commonIndent = indent
+		}
+	}
+
+	for i := range lines {
+		if i != 0 && commonIndent > 0 && len(lines[i]) >= commonIndent {
+			lines[i] = lines[i][commonIndent:]
+		}
+		lines[i] = strings.TrimRight(lines[i], " \t")
+	}
+	if len(lines) != 0 {
+		lines[0] = strings.TrimLeft(lines[0], " \t")
+	}
+
+	for len(lines) != 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) != 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// unescapeString returns the value of the raw content of a string
+func unescapeString(raw string) (string, bool) {
+	if !strings.Contains(raw, `\`) {
+		return raw, true
+	}
+
+	out := strings.Builder{}
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '\\' {
+			out.WriteByte(raw[i])
+			continue
+		}
+		if i+1 == len(raw) {
+			return "", false
+		}
+		i++
+		switch raw[i] {
+		case '"', '\\', '/':
+			out.WriteByte(raw[i])
+		case 'b':
+			out.WriteByte('\b')
+		case 'f':
+			out.WriteByte('\f')
+		case 'n':
+			out.WriteByte('\n')
+		case 'r':
+			out.WriteByte('\r')
+		case 't':
+			out.WriteByte('\t')
+		case 'u':
+			if i+5 > len(raw) {
+				return "", false
+			}
+			code, err := strconv.ParseUint(raw[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", false
+			}
+			out.WriteRune(rune(code))
+			i += 4
+		default:
+			return "", false
+		}
+	}
+	return out.String(), true
+}
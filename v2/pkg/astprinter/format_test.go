@@ -0,0 +1,350 @@
+package astprinter
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astparser"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/testing/goldie"
+)
+
+func TestFormat(t *testing.T) {
+	run := func(t *testing.T, options FormatOptions, input, expected string) {
+		t.Helper()
+		doc, report := astparser.ParseGraphqlDocumentStringWithComments(input)
+		require.False(t, report.HasErrors(), report.Error())
+
+		out, err := FormatString(&doc, options)
+		require.NoError(t, err)
+		assert.Equal(t, expected, out)
+
+		formatted, report := astparser.ParseGraphqlDocumentStringWithComments(out)
+		require.False(t, report.HasErrors(), report.Error())
+		again, err := FormatString(&formatted, options)
+		require.NoError(t, err)
+		assert.Equal(t, out, again, "formatting must be idempotent")
+	}
+
+	t.Run("comments", func(t *testing.T) {
+		run(t, FormatOptions{}, `
+# header
+type Query { # query root
+  # the hero
+  hero: Character # might be null
+  droid(id: ID!): Droid
+  # to do: search
+}
+
+enum Episode { NEWHOPE
+  # the empire
+  EMPIRE JEDI }
+# trailer`, `# header
+type Query { # query root
+  # the hero
+  hero: Character # might be null
+  droid(id: ID!): Droid
+  # to do: search
+}
+
+enum Episode {
+  NEWHOPE
+  # the empire
+  EMPIRE
+  JEDI
+}
+
+# trailer
+`)
+	})
+
+	t.Run("operation comments", func(t *testing.T) {
+		run(t, FormatOptions{}, `query Hero($episode: Episode) { # hero query
+  hero(episode: $episode) { # the hero
+    # always ask for the name
+    name
+    ... on Droid { primaryFunction } # droids only
+    # more fields later
+  }
+}`, `query Hero($episode: Episode) { # hero query
+  hero(episode: $episode) { # the hero
+    # always ask for the name
+    name
+    ... on Droid {
+      primaryFunction # droids only
+    }
+    # more fields later
+  }
+}
+`)
+	})
+
+	t.Run("variable definition comments", func(t *testing.T) {
+		run(t, FormatOptions{}, `query Q($a: Int = 1 # c
+) { # operation
+  # next
+  a
+}`, `query Q(
+  $a: Int = 1 # c
+) { # operation
+  # next
+  a
+}
+`)
+		run(t, FormatOptions{}, `query Q # operation
+($a: Int, $b: Int # b
+) { a }`, `query Q(
+  $a: Int
+  $b: Int # b
+) { # operation
+  a
+}
+`)
+	})
+
+	t.Run("descriptions", func(t *testing.T) {
+		run(t, FormatOptions{}, `
+"""
+  A character.
+
+    Indented example
+"""
+type Character {
+  "the name"
+  name: String
+  """Friends of the character"""
+  friends: [Character]
+}`, `"""
+A character.
+
+  Indented example
+"""
+type Character {
+  "the name"
+  name: String
+  """
+  Friends of the character
+  """
+  friends: [Character]
+}
+`)
+	})
+
+	t.Run("block string descriptions", func(t *testing.T) {
+		run(t, FormatOptions{BlockStringDescriptions: true}, `
+"A \"quoted\" character\nwith two lines"
+type Character {
+  "caf\u00e9"
+  name: String
+}`, `"""
+A "quoted" character
+with two lines
+"""
+type Character {
+  """
+  café
+  """
+  name: String
+}
+`)
+	})
+
+	t.Run("argument wrapping", func(t *testing.T) {
+		input := `type Query {
+  search(text: String, first: Int = 10, after: String, filter: Filter): [Result!]!
+  hero(id: ID): Character
+}`
+		run(t, FormatOptions{LineWidth: 40}, input, `type Query {
+  search(
+    text: String
+    first: Int = 10
+    after: String
+    filter: Filter
+  ): [Result!]!
+  hero(id: ID): Character
+}
+`)
+		run(t, FormatOptions{ArgumentWrapping: WrapArgumentsAlways}, input, `type Query {
+  search(
+    text: String
+    first: Int = 10
+    after: String
+    filter: Filter
+  ): [Result!]!
+  hero(
+    id: ID
+  ): Character
+}
+`)
+		run(t, FormatOptions{LineWidth: 40, ArgumentWrapping: WrapArgumentsNever}, input, `type Query {
+  search(text: String, first: Int = 10, after: String, filter: Filter): [Result!]!
+  hero(id: ID): Character
+}
+`)
+	})
+
+	t.Run("arguments with descriptions are wrapped", func(t *testing.T) {
+		run(t, FormatOptions{}, `type Query { hero("the id" id: ID, # the name
+  name: String): Character }`, `type Query {
+  hero( # the name
+    "the id"
+    id: ID
+    name: String
+  ): Character
+}
+`)
+	})
+
+	t.Run("field arguments and variables", func(t *testing.T) {
+		run(t, FormatOptions{LineWidth: 50, Indent: "    "}, `query Search($text: String!, $first: Int = 10, $after: String) {
+  search(text: $text, first: $first, after: $after, filter: {kinds: [HUMAN, DROID]}) @include(if: true) { name }
+}`, `query Search(
+    $text: String!
+    $first: Int = 10
+    $after: String
+) {
+    search(
+        text: $text
+        first: $first
+        after: $after
+        filter: {kinds: [HUMAN, DROID]}
+    ) @include(if: true) {
+        name
+    }
+}
+`)
+	})
+
+	t.Run("union members", func(t *testing.T) {
+		run(t, FormatOptions{LineWidth: 30}, `union SearchResult = Human | Droid | Starship`, `union SearchResult =
+  | Human
+  | Droid
+  | Starship
+`)
+	})
+
+	t.Run("sorting", func(t *testing.T) {
+		run(t, FormatOptions{SortDefinitions: true, SortFields: true}, `
+query B { b }
+type Query { b: String a(z: Int, y: Int): String }
+fragment F on Query { a }
+extend type Query { c: String }
+directive @d on FIELD
+enum E { B A }
+schema { query: Query }
+query A { a }`, `schema {
+  query: Query
+}
+
+directive @d on FIELD
+
+enum E {
+  A
+  B
+}
+
+type Query {
+  a(z: Int, y: Int): String
+  b: String
+}
+
+extend type Query {
+  c: String
+}
+
+query A {
+  a
+}
+
+query B {
+  b
+}
+
+fragment F on Query {
+  a
+}
+`)
+	})
+
+	t.Run("definitions", func(t *testing.T) {
+		run(t, FormatOptions{}, `
+schema @a { query: Query mutation: Mutation }
+extend schema @b
+scalar Date @specifiedBy(url: "https://example.com")
+extend scalar Date @c
+interface Node implements Entity & Named { id: ID! }
+extend interface Node @d
+input Filter { kinds: [Kind!] = [HUMAN] limit: Int = 10 @deprecated }
+extend input Filter { name: String }
+extend enum Kind { ALIEN }
+extend union Result = Alien
+directive @cached(ttl: Int = 60) repeatable on FIELD_DEFINITION | OBJECT
+{ node { id } }
+subscription { updated }`, `schema @a {
+  query: Query
+  mutation: Mutation
+}
+
+extend schema @b
+
+scalar Date @specifiedBy(url: "https://example.com")
+
+extend scalar Date @c
+
+interface Node implements Entity & Named {
+  id: ID!
+}
+
+extend interface Node @d
+
+input Filter {
+  kinds: [Kind!] = [HUMAN]
+  limit: Int = 10 @deprecated
+}
+
+extend input Filter {
+  name: String
+}
+
+extend enum Kind {
+  ALIEN
+}
+
+extend union Result = Alien
+
+directive @cached(ttl: Int = 60) repeatable on OBJECT | FIELD_DEFINITION
+
+{
+  node {
+    id
+  }
+}
+
+subscription {
+  updated
+}
+`)
+	})
+
+	t.Run("document without comments", func(t *testing.T) {
+		doc, report := astparser.ParseGraphqlDocumentString("# dropped\ntype Query { a: String }")
+		require.False(t, report.HasErrors())
+		out, err := FormatString(&doc, FormatOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "type Query {\n  a: String\n}\n", out)
+	})
+}
+
+func TestFormatSchemaDefinition(t *testing.T) {
+	schema, err := os.ReadFile("./testdata/starwars.schema.graphql")
+	require.NoError(t, err)
+
+	doc, report := astparser.ParseGraphqlDocumentBytesWithComments(schema)
+	require.False(t, report.HasErrors(), report.Error())
+
+	out, err := FormatString(&doc, FormatOptions{})
+	require.NoError(t, err)
+	goldie.Assert(t, "starwars_schema_definition_formatted", []byte(out))
+}
@@ -0,0 +1,82 @@
+// Package client contains the runtime used by code generated with the codegen package.
+// Generated operations are executed through an Executor, which is either an HTTP endpoint or an ExecutionEngineV2 in the same process.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var ErrEmptyResponse = errors.New("graphql response contains neither data nor errors")
+
+// Request is the operation sent by a generated client function
+type Request struct {
+	OperationName string      `json:"operationName,omitempty"`
+	Query         string      `json:"query"`
+	Variables     interface{} `json:"variables,omitempty"`
+}
+
+// Executor executes a Request and unmarshals the "data" of the response into response
+type Executor interface {
+	Execute(ctx context.Context, request *Request, response interface{}) error
+}
+
+type Location struct {
+	Line   uint32 `json:"line"`
+	Column uint32 `json:"column"`
+}
+
+// Error is a single entry of the "errors" array of a GraphQL response
+type Error struct {
+	Message    string                     `json:"message"`
+	Locations  []Location                 `json:"locations,omitempty"`
+	Path       []interface{}              `json:"path,omitempty"`
+	Extensions map[string]json.RawMessage `json:"extensions,omitempty"`
+}
+
+func (e Error) Error() string {
+	return e.Message
+}
+
+// Errors is returned when the response contains GraphQL errors.
+// The data of the response might still be partially set.
+type Errors []Error
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for i := range e {
+		messages = append(messages, e[i].Message)
+	}
+	return strings.Join(messages, ", ")
+}
+
+type rawResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors Errors          `json:"errors"`
+}
+
+// DecodeResponse unmarshals the data of a GraphQL response into out.
+// When the response contains errors, they are returned as Errors after unmarshalling the data.
+func DecodeResponse(body []byte, out interface{}) error {
+	var response rawResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return err
+	}
+
+	hasData := len(response.Data) != 0 && string(response.Data) != "null"
+	if hasData && out != nil {
+		if err := json.Unmarshal(response.Data, out); err != nil {
+			return err
+		}
+	}
+
+	if len(response.Errors) != 0 {
+		return response.Errors
+	}
+	if !hasData {
+		return ErrEmptyResponse
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
+)
+
+type heroResponse struct {
+	Hero struct {
+		Name string `json:"name"`
+	} `json:"hero"`
+}
+
+func TestDecodeResponse(t *testing.T) {
+	t.Run("data", func(t *testing.T) {
+		var response heroResponse
+		err := DecodeResponse([]byte(`{"data":{"hero":{"name":"R2-D2"}}}`), &response)
+		require.NoError(t, err)
+		assert.Equal(t, "R2-D2", response.Hero.Name)
+	})
+
+	t.Run("partial data with errors", func(t *testing.T) {
+		var response heroResponse
+		err := DecodeResponse([]byte(`{"data":{"hero":{"name":"R2-D2"}},"errors":[{"message":"friends unavailable","path":["hero","friends"]}]}`), &response)
+		require.Error(t, err)
+		assert.Equal(t, "R2-D2", response.Hero.Name)
+
+		errs, ok := err.(Errors)
+		require.True(t, ok)
+		assert.Equal(t, "friends unavailable", errs[0].Message)
+		assert.Equal(t, []interface{}{"hero", "friends"}, errs[0].Path)
+	})
+
+	t.Run("empty response", func(t *testing.T) {
+		err := DecodeResponse([]byte(`{"data":null}`), &heroResponse{})
+		assert.Equal(t, ErrEmptyResponse, err)
+	})
+}
+
+func TestHTTPExecutor_Execute(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"operationName":"Hero","query":"query Hero { hero { name } }","variables":{"episode":"JEDI"}}`, string(body))
+
+		_, _ = w.Write([]byte(`{"data":{"hero":{"name":"Luke"}}}`))
+	}))
+	defer server.Close()
+
+	executor := NewHTTPExecutor(server.URL, server.Client())
+	executor.Header.Set("Authorization", "Bearer token")
+
+	var response heroResponse
+	err := executor.Execute(context.Background(), &Request{
+		OperationName: "Hero",
+		Query:         "query Hero { hero { name } }",
+		Variables:     map[string]string{"episode": "JEDI"},
+	}, &response)
+	require.NoError(t, err)
+	assert.Equal(t, "Luke", response.Hero.Name)
+}
+
+type fakeEngine struct {
+	response  string
+	err       error
+	operation *graphql.Request
+}
+
+func (f *fakeEngine) Execute(_ context.Context, operation *graphql.Request, writer resolve.SubscriptionResponseWriter, _ ...graphql.ExecutionOptionsV2) error {
+	f.operation = operation
+	if f.err != nil {
+		return f.err
+	}
+	_, err := writer.Write([]byte(f.response))
+	return err
+}
+
+func TestEngineExecutor_Execute(t *testing.T) {
+	t.Run("executes operation with variables", func(t *testing.T) {
+		engine := &fakeEngine{response: `{"data":{"hero":{"name":"Leia"}}}`}
+		executor := NewEngineExecutor(engine)
+
+		var response heroResponse
+		err := executor.Execute(context.Background(), &Request{
+			OperationName: "Hero",
+			Query:         "query Hero($episode: Episode) { hero(episode: $episode) { name } }",
+			Variables:     map[string]string{"episode": "JEDI"},
+		}, &response)
+		require.NoError(t, err)
+		assert.Equal(t, "Leia", response.Hero.Name)
+		assert.Equal(t, "Hero", engine.operation.OperationName)
+		assert.Equal(t, json.RawMessage(`{"episode":"JEDI"}`), engine.operation.Variables)
+	})
+
+	t.Run("engine errors are returned as Errors", func(t *testing.T) {
+		engine := &fakeEngine{err: graphql.RequestErrors{{Message: "field unknown"}}}
+		err := NewEngineExecutor(engine).Execute(context.Background(), &Request{Query: "{ unknown }"}, &heroResponse{})
+		assert.Equal(t, Errors{{Message: "field unknown"}}, err)
+	})
+}
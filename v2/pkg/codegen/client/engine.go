@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
+)
+
+// EngineExecutor executes operations directly against an ExecutionEngineV2 without going through the network
+type EngineExecutor struct {
+	engine  graphql.ExecutionEngineV2Executor
+	options []graphql.ExecutionOptionsV2
+}
+
+func NewEngineExecutor(engine graphql.ExecutionEngineV2Executor, options ...graphql.ExecutionOptionsV2) *EngineExecutor {
+	return &EngineExecutor{
+		engine:  engine,
+		options: options,
+	}
+}
+
+func (e *EngineExecutor) Execute(ctx context.Context, request *Request, response interface{}) error {
+	operation := graphql.Request{
+		OperationName: request.OperationName,
+		Query:         request.Query,
+	}
+	if request.Variables != nil {
+		variables, err := json.Marshal(request.Variables)
+		if err != nil {
+			return err
+		}
+		operation.Variables = variables
+	}
+
+	resultWriter := graphql.NewEngineResultWriter()
+	if err := e.engine.Execute(ctx, &operation, &resultWriter, e.options...); err != nil {
+		return requestErrors(err)
+	}
+
+	return DecodeResponse(resultWriter.Bytes(), response)
+}
+
+// requestErrors converts validation and planning errors of the engine into Errors
+func requestErrors(err error) error {
+	requestErrors := graphql.RequestErrorsFromError(err)
+	errs := make(Errors, 0, len(requestErrors))
+	for i := range requestErrors {
+		clientError := Error{
+			Message: requestErrors[i].Message,
+		}
+		for _, location := range requestErrors[i].Locations {
+			clientError.Locations = append(clientError.Locations, Location{
+				Line:   location.Line,
+				Column: location.Column,
+			})
+		}
+		errs = append(errs, clientError)
+	}
+	return errs
+}
+
+// Interface guard
+var _ Executor = (*EngineExecutor)(nil)
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// HTTPExecutor sends operations as JSON POST requests to a GraphQL endpoint
+type HTTPExecutor struct {
+	URL    string
+	Client *http.Client
+	// Header is added to every request, e.g. to forward authorization
+	Header http.Header
+}
+
+func NewHTTPExecutor(url string, client *http.Client) *HTTPExecutor {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPExecutor{
+		URL:    url,
+		Client: client,
+		Header: http.Header{},
+	}
+}
+
+func (h *HTTPExecutor) Execute(ctx context.Context, request *Request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range h.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/graphql-response+json, application/json")
+
+	res, err := h.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	err = DecodeResponse(data, response)
+	if err != nil && res.StatusCode >= http.StatusBadRequest {
+		if _, ok := err.(Errors); !ok {
+			return fmt.Errorf("unexpected status code %d: %w", res.StatusCode, err)
+		}
+	}
+	return err
+}
+
+// Interface guard
+var _ Executor = (*HTTPExecutor)(nil)
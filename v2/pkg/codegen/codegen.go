@@ -0,0 +1,552 @@
+// Package codegen generates typed go clients for GraphQL operations.
+//
+// For every named query and mutation the generator emits a struct for the variables,
+// structs for the response (including fragments, unions and interfaces which are decoded based on __typename)
+// and a function executing the operation through a client.Executor,
+// which can either be an HTTP endpoint or an ExecutionEngineV2 running in the same process.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astnormalization"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astparser"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astprinter"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/asttransform"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astvalidation"
+)
+
+const (
+	clientImportPath = "github.com/TykTechnologies/graphql-go-tools/v2/pkg/codegen/client"
+	jsonImportPath   = "encoding/json"
+)
+
+// ScalarMapping maps a custom GraphQL scalar to a go type, e.g. DateTime to time.Time
+type ScalarMapping struct {
+	// ImportPath of the package containing the type, empty for builtin types
+	ImportPath string
+	// TypeName is the qualified name of the type, e.g. "time.Time"
+	TypeName string
+}
+
+type Config struct {
+	PackageName string
+	// Scalars maps custom scalars to go types, scalars without mapping are generated as json.RawMessage
+	Scalars map[string]ScalarMapping
+}
+
+type Generator struct {
+	config     Config
+	definition *ast.Document
+	operation  *ast.Document
+
+	out           *bytes.Buffer
+	imports       map[string]struct{}
+	usedFragments map[int]struct{}
+	enums         map[string]struct{}
+	inputs        map[string]struct{}
+}
+
+func New(config Config) *Generator {
+	if config.PackageName == "" {
+		config.PackageName = "client"
+	}
+	return &Generator{
+		config: config,
+	}
+}
+
+// GenerateFromFiles parses the schema file and all .graphql files in operationsDir and generates the client code into w
+func (g *Generator) GenerateFromFiles(schemaFile, operationsDir string, w io.Writer) error {
+	schema, err := os.ReadFile(schemaFile)
+	if err != nil {
+		return err
+	}
+	definition, report := astparser.ParseGraphqlDocumentBytes(schema)
+	if report.HasErrors() {
+		return fmt.Errorf("parse schema %s: %w", schemaFile, report)
+	}
+	if err := asttransform.MergeDefinitionWithBaseSchema(&definition); err != nil {
+		return err
+	}
+
+	operation, err := LoadOperations(operationsDir)
+	if err != nil {
+		return err
+	}
+
+	return g.Generate(&definition, operation, w)
+}
+
+// LoadOperations parses all .graphql files of a directory into a single document.
+// Fragments can therefore be shared between the files of the directory.
+func LoadOperations(dir string) (*ast.Document, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.graphql"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .graphql files found in %s", dir)
+	}
+	sort.Strings(files)
+
+	var operations bytes.Buffer
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		operations.Write(content)
+		operations.WriteString("\n")
+	}
+
+	operation, report := astparser.ParseGraphqlDocumentBytes(operations.Bytes())
+	if report.HasErrors() {
+		return nil, fmt.Errorf("parse operations in %s: %w", dir, report)
+	}
+	return &operation, nil
+}
+
+// Generate renders the client code for all named operations of the operation document into w.
+// The definition has to be a complete schema, e.g. merged with the base schema using asttransform.MergeDefinitionWithBaseSchema.
+func (g *Generator) Generate(definition, operation *ast.Document, w io.Writer) error {
+	g.definition = definition
+	g.operation = operation
+	g.out = &bytes.Buffer{}
+	g.imports = map[string]struct{}{}
+	g.enums = map[string]struct{}{}
+	g.inputs = map[string]struct{}{}
+
+	for _, node := range operation.RootNodes {
+		if node.Kind != ast.NodeKindOperationDefinition {
+			continue
+		}
+		if err := g.renderOperation(node.Ref); err != nil {
+			return err
+		}
+	}
+
+	if err := g.renderInputs(); err != nil {
+		return err
+	}
+	g.renderEnums()
+
+	var file bytes.Buffer
+	file.WriteString("// Code generated by graphql-go-tools codegen, DO NOT EDIT.\n\n")
+	fmt.Fprintf(&file, "package %s\n\n", g.config.PackageName)
+	g.renderImports(&file)
+	file.Write(g.out.Bytes())
+
+	formatted, err := format.Source(file.Bytes())
+	if err != nil {
+		return fmt.Errorf("format generated code: %w", err)
+	}
+	_, err = w.Write(formatted)
+	return err
+}
+
+func (g *Generator) renderImports(out *bytes.Buffer) {
+	g.imports["context"] = struct{}{}
+	g.imports[clientImportPath] = struct{}{}
+
+	var stdlib, external []string
+	for importPath := range g.imports {
+		if strings.Contains(strings.Split(importPath, "/")[0], ".") {
+			external = append(external, importPath)
+		} else {
+			stdlib = append(stdlib, importPath)
+		}
+	}
+	sort.Strings(stdlib)
+	sort.Strings(external)
+
+	out.WriteString("import (\n")
+	for _, importPath := range stdlib {
+		fmt.Fprintf(out, "%q\n", importPath)
+	}
+	if len(stdlib) != 0 && len(external) != 0 {
+		out.WriteString("\n")
+	}
+	for _, importPath := range external {
+		fmt.Fprintf(out, "%q\n", importPath)
+	}
+	out.WriteString(")\n\n")
+}
+
+func (g *Generator) renderOperation(ref int) error {
+	operationName := g.operation.OperationDefinitionNameString(ref)
+	operationType := g.operation.OperationDefinitions[ref].OperationType
+	if operationName == "" {
+		return fmt.Errorf("%s operations must be named to generate code", operationType.Name())
+	}
+	if operationType == ast.OperationTypeSubscription {
+		// subscriptions can't be executed as a single request/response, they are skipped
+		return nil
+	}
+
+	rootTypeName := g.rootTypeName(operationType)
+	if rootTypeName == "" {
+		return fmt.Errorf("schema has no %s type", operationType.Name())
+	}
+
+	g.usedFragments = map[int]struct{}{}
+	goName := exportedName(operationName)
+	response := newSelectionSet(rootTypeName, g.operation.OperationDefinitions[ref].SelectionSet)
+	if err := g.collectSelections(response, g.operation.OperationDefinitions[ref].SelectionSet); err != nil {
+		return fmt.Errorf("operation %s: %w", operationName, err)
+	}
+	g.ensureTypenames(response)
+
+	query, err := g.printOperation(ref)
+	if err != nil {
+		return err
+	}
+	if err := g.validateOperation(query, operationName); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(g.out, "// %sOperation is the %s %s sent by %s\n", goName, operationType.Name(), operationName, goName)
+	fmt.Fprintf(g.out, "const %sOperation = %s\n\n", goName, goLiteral(query))
+
+	hasVariables := g.operation.OperationDefinitions[ref].HasVariableDefinitions && len(g.operation.OperationDefinitions[ref].VariableDefinitions.Refs) != 0
+	if hasVariables {
+		if err := g.renderVariables(goName+"Variables", ref); err != nil {
+			return err
+		}
+	}
+
+	if err := g.renderSelectionSet(goName+"Response", response); err != nil {
+		return fmt.Errorf("operation %s: %w", operationName, err)
+	}
+
+	fmt.Fprintf(g.out, "// %s executes the %s %s\n", goName, operationType.Name(), operationName)
+	if hasVariables {
+		fmt.Fprintf(g.out, "func %s(ctx context.Context, executor client.Executor, variables %sVariables) (*%sResponse, error) {\n", goName, goName, goName)
+	} else {
+		fmt.Fprintf(g.out, "func %s(ctx context.Context, executor client.Executor) (*%sResponse, error) {\n", goName, goName)
+	}
+	fmt.Fprintf(g.out, "request := &client.Request{\nOperationName: %q,\nQuery: %sOperation,\n", operationName, goName)
+	if hasVariables {
+		g.out.WriteString("Variables: variables,\n")
+	}
+	g.out.WriteString("}\n")
+	fmt.Fprintf(g.out, "var response %sResponse\n", goName)
+	g.out.WriteString("err := executor.Execute(ctx, request, &response)\nreturn &response, err\n}\n\n")
+	return nil
+}
+
+func (g *Generator) rootTypeName(operationType ast.OperationType) string {
+	var name ast.ByteSlice
+	switch operationType {
+	case ast.OperationTypeQuery:
+		name = g.definition.Index.QueryTypeName
+	case ast.OperationTypeMutation:
+		name = g.definition.Index.MutationTypeName
+	case ast.OperationTypeSubscription:
+		name = g.definition.Index.SubscriptionTypeName
+	}
+	return string(name)
+}
+
+// printOperation prints the operation together with all fragments it uses
+func (g *Generator) printOperation(ref int) (string, error) {
+	rootNodes := g.operation.RootNodes
+	defer func() {
+		g.operation.RootNodes = rootNodes
+	}()
+
+	nodes := []ast.Node{{Kind: ast.NodeKindOperationDefinition, Ref: ref}}
+	for _, node := range rootNodes {
+		if node.Kind != ast.NodeKindFragmentDefinition {
+			continue
+		}
+		if _, ok := g.usedFragments[node.Ref]; ok {
+			nodes = append(nodes, node)
+		}
+	}
+	g.operation.RootNodes = nodes
+
+	return astprinter.PrintStringIndent(g.operation, g.definition, "  ")
+}
+
+// validateOperation validates the printed operation the same way the engine does, after normalizing it
+func (g *Generator) validateOperation(query, operationName string) error {
+	operation, report := astparser.ParseGraphqlDocumentString(query)
+	if report.HasErrors() {
+		return fmt.Errorf("operation %s: %w", operationName, report)
+	}
+	astnormalization.NormalizeNamedOperation(&operation, g.definition, []byte(operationName), &report)
+	if report.HasErrors() {
+		return fmt.Errorf("operation %s: %w", operationName, report)
+	}
+	astvalidation.DefaultOperationValidator().Validate(&operation, g.definition, &report)
+	if report.HasErrors() {
+		return fmt.Errorf("operation %s: %w", operationName, report)
+	}
+	return nil
+}
+
+func (g *Generator) renderVariables(structName string, operationRef int) error {
+	fmt.Fprintf(g.out, "type %s struct {\n", structName)
+	for _, variableRef := range g.operation.OperationDefinitions[operationRef].VariableDefinitions.Refs {
+		name := g.operation.VariableDefinitionNameString(variableRef)
+		typeRef := g.operation.VariableDefinitions[variableRef].Type
+		goType, err := g.inputType(g.operation, typeRef, true)
+		if err != nil {
+			return fmt.Errorf("variable $%s: %w", name, err)
+		}
+		fmt.Fprintf(g.out, "%s %s `json:\"%s%s\"`\n", exportedName(name), goType, name, omitEmpty(g.operation, typeRef))
+	}
+	g.out.WriteString("}\n\n")
+	return nil
+}
+
+func (g *Generator) renderSelectionSet(structName string, set *selectionSet) error {
+	fmt.Fprintf(g.out, "type %s struct {\n", structName)
+	for _, field := range set.fields {
+		fieldName := exportedName(field.responseKey)
+		if field.typeRef == ast.InvalidRef {
+			fmt.Fprintf(g.out, "%s string `json:\"%s\"`\n", fieldName, field.responseKey)
+			continue
+		}
+		goType, err := g.outputType(field.typeRef, structName+fieldName, field.selections != nil, true)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.responseKey, err)
+		}
+		fmt.Fprintf(g.out, "%s %s `json:\"%s\"`\n", fieldName, goType, field.responseKey)
+	}
+	for _, fragment := range set.fragments {
+		fmt.Fprintf(g.out, "// On%s is set when the object is a %s\n", exportedName(fragment.typeName), fragment.typeName)
+		fmt.Fprintf(g.out, "On%s *%sOn%s `json:\"-\"`\n", exportedName(fragment.typeName), structName, exportedName(fragment.typeName))
+	}
+	g.out.WriteString("}\n\n")
+
+	if len(set.fragments) != 0 {
+		g.renderUnmarshalFragments(structName, set)
+	}
+
+	for _, field := range set.fields {
+		if field.selections == nil {
+			continue
+		}
+		if err := g.renderSelectionSet(structName+exportedName(field.responseKey), field.selections); err != nil {
+			return err
+		}
+	}
+	for _, fragment := range set.fragments {
+		if err := g.renderSelectionSet(structName+"On"+exportedName(fragment.typeName), fragment.selections); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderUnmarshalFragments renders an UnmarshalJSON method decoding the fragments matching the __typename of the object
+func (g *Generator) renderUnmarshalFragments(structName string, set *selectionSet) {
+	g.imports[jsonImportPath] = struct{}{}
+
+	receiver := strings.ToLower(structName[:1])
+	fmt.Fprintf(g.out, "func (%s *%s) UnmarshalJSON(data []byte) error {\n", receiver, structName)
+	fmt.Fprintf(g.out, "type alias %s\n", structName)
+	fmt.Fprintf(g.out, "if err := json.Unmarshal(data, (*alias)(%s)); err != nil {\nreturn err\n}\n", receiver)
+	for _, fragment := range set.fragments {
+		fragmentName := "On" + exportedName(fragment.typeName)
+		quoted := make([]string, 0, len(fragment.possibleTypes))
+		for _, possibleType := range fragment.possibleTypes {
+			quoted = append(quoted, strconv.Quote(possibleType))
+		}
+		fmt.Fprintf(g.out, "switch %s.%s {\ncase %s:\n", receiver, exportedName(set.typenameKey), strings.Join(quoted, ", "))
+		fmt.Fprintf(g.out, "%s.%s = &%s%s{}\n", receiver, fragmentName, structName, fragmentName)
+		fmt.Fprintf(g.out, "if err := json.Unmarshal(data, %s.%s); err != nil {\nreturn err\n}\n}\n", receiver, fragmentName)
+	}
+	g.out.WriteString("return nil\n}\n\n")
+}
+
+// outputType returns the go type of a field in the response
+func (g *Generator) outputType(typeRef int, structName string, hasSelections, nullable bool) (string, error) {
+	switch g.definition.Types[typeRef].TypeKind {
+	case ast.TypeKindNonNull:
+		return g.outputType(g.definition.Types[typeRef].OfType, structName, hasSelections, false)
+	case ast.TypeKindList:
+		itemType, err := g.outputType(g.definition.Types[typeRef].OfType, structName, hasSelections, true)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + itemType, nil
+	}
+
+	var goType string
+	if hasSelections {
+		goType = structName
+	} else {
+		var err error
+		goType, err = g.namedType(g.definition.TypeNameString(typeRef))
+		if err != nil {
+			return "", err
+		}
+	}
+	return pointerIfNullable(goType, nullable), nil
+}
+
+// inputType returns the go type of a variable or input field, typeRef references a type of doc
+func (g *Generator) inputType(doc *ast.Document, typeRef int, nullable bool) (string, error) {
+	switch doc.Types[typeRef].TypeKind {
+	case ast.TypeKindNonNull:
+		return g.inputType(doc, doc.Types[typeRef].OfType, false)
+	case ast.TypeKindList:
+		itemType, err := g.inputType(doc, doc.Types[typeRef].OfType, true)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + itemType, nil
+	}
+
+	goType, err := g.namedType(doc.TypeNameString(typeRef))
+	if err != nil {
+		return "", err
+	}
+	return pointerIfNullable(goType, nullable), nil
+}
+
+// namedType returns the go type of a scalar, enum or input object and registers enums and inputs to be rendered
+func (g *Generator) namedType(typeName string) (string, error) {
+	switch typeName {
+	case "String", "ID":
+		return "string", nil
+	case "Int":
+		return "int", nil
+	case "Float":
+		return "float64", nil
+	case "Boolean":
+		return "bool", nil
+	}
+
+	if mapping, ok := g.config.Scalars[typeName]; ok {
+		if mapping.ImportPath != "" {
+			g.imports[mapping.ImportPath] = struct{}{}
+		}
+		return mapping.TypeName, nil
+	}
+
+	node, exists := g.definition.Index.FirstNodeByNameStr(typeName)
+	if !exists {
+		return "", fmt.Errorf("type %s is not defined in the schema", typeName)
+	}
+	switch node.Kind {
+	case ast.NodeKindScalarTypeDefinition:
+		g.imports[jsonImportPath] = struct{}{}
+		return "json.RawMessage", nil
+	case ast.NodeKindEnumTypeDefinition:
+		g.enums[typeName] = struct{}{}
+		return exportedName(typeName), nil
+	case ast.NodeKindInputObjectTypeDefinition:
+		g.inputs[typeName] = struct{}{}
+		return exportedName(typeName), nil
+	}
+	return "", fmt.Errorf("type %s must be selected with a selection set", typeName)
+}
+
+func (g *Generator) renderEnums() {
+	for _, typeName := range sortedKeys(g.enums) {
+		node, _ := g.definition.Index.FirstNodeByNameStr(typeName)
+		goName := exportedName(typeName)
+
+		renderDescription(g.out, goName, g.definition.EnumTypeDefinitionDescriptionString(node.Ref))
+		fmt.Fprintf(g.out, "type %s string\n\nconst (\n", goName)
+		for _, valueRef := range g.definition.EnumTypeDefinitions[node.Ref].EnumValuesDefinition.Refs {
+			value := g.definition.EnumValueDefinitionNameString(valueRef)
+			fmt.Fprintf(g.out, "%s%s %s = %q\n", goName, exportedName(value), goName, value)
+		}
+		g.out.WriteString(")\n\n")
+	}
+}
+
+// renderInputs renders all input objects used by variables, including input objects nested in other input objects
+func (g *Generator) renderInputs() error {
+	rendered := map[string]struct{}{}
+	for len(rendered) != len(g.inputs) {
+		for _, typeName := range sortedKeys(g.inputs) {
+			if _, ok := rendered[typeName]; ok {
+				continue
+			}
+			rendered[typeName] = struct{}{}
+			if err := g.renderInput(typeName); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Generator) renderInput(typeName string) error {
+	node, _ := g.definition.Index.FirstNodeByNameStr(typeName)
+	goName := exportedName(typeName)
+
+	renderDescription(g.out, goName, g.definition.InputObjectTypeDefinitionDescriptionString(node.Ref))
+	fmt.Fprintf(g.out, "type %s struct {\n", goName)
+	for _, inputValueRef := range g.definition.InputObjectTypeDefinitions[node.Ref].InputFieldsDefinition.Refs {
+		name := g.definition.InputValueDefinitionNameString(inputValueRef)
+		typeRef := g.definition.InputValueDefinitionType(inputValueRef)
+		goType, err := g.inputType(g.definition, typeRef, true)
+		if err != nil {
+			return fmt.Errorf("input %s field %s: %w", typeName, name, err)
+		}
+		fmt.Fprintf(g.out, "%s %s `json:\"%s%s\"`\n", exportedName(name), goType, name, omitEmpty(g.definition, typeRef))
+	}
+	g.out.WriteString("}\n\n")
+	return nil
+}
+
+func renderDescription(out *bytes.Buffer, goName, description string) {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return
+	}
+	for i, line := range strings.Split(description, "\n") {
+		if i == 0 && !strings.HasPrefix(line, goName+" ") {
+			fmt.Fprintf(out, "// %s %s\n", goName, strings.TrimSpace(line))
+			continue
+		}
+		fmt.Fprintf(out, "// %s\n", strings.TrimSpace(line))
+	}
+}
+
+func pointerIfNullable(goType string, nullable bool) string {
+	if !nullable || goType == "json.RawMessage" {
+		return goType
+	}
+	return "*" + goType
+}
+
+func omitEmpty(doc *ast.Document, typeRef int) string {
+	if doc.TypeIsNonNull(typeRef) {
+		return ""
+	}
+	return ",omitempty"
+}
+
+// goLiteral renders s as a raw string literal if possible
+func goLiteral(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
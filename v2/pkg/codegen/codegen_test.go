@@ -0,0 +1,96 @@
+package codegen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/internal/pkg/unsafeparser"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/asttransform"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/testing/goldie"
+)
+
+func TestGenerator_GenerateFromFiles(t *testing.T) {
+	generator := New(Config{
+		PackageName: "starwars",
+		Scalars: map[string]ScalarMapping{
+			"DateTime": {ImportPath: "time", TypeName: "time.Time"},
+		},
+	})
+
+	out := &bytes.Buffer{}
+	err := generator.GenerateFromFiles("./testdata/schema.graphql", "./testdata/operations", out)
+	require.NoError(t, err)
+
+	goldie.Assert(t, "starwars_client", out.Bytes())
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	generate := func(t *testing.T, schema, operation string) (string, error) {
+		t.Helper()
+		definition := unsafeparser.ParseGraphqlDocumentString(schema)
+		require.NoError(t, asttransform.MergeDefinitionWithBaseSchema(&definition))
+		op := unsafeparser.ParseGraphqlDocumentString(operation)
+
+		out := &bytes.Buffer{}
+		err := New(Config{PackageName: "client"}).Generate(&definition, &op, out)
+		// collapse the alignment of gofmt to keep the assertions readable
+		return strings.Join(strings.Fields(out.String()), " "), err
+	}
+
+	schema := `
+		scalar JSON
+		type Query {
+			user(id: ID!): User
+			users(filter: [UserFilter!]): [User!]!
+		}
+		input UserFilter {
+			role: Role
+			metadata: JSON
+		}
+		enum Role { ADMIN USER_MANAGER }
+		type User {
+			id: ID!
+			name: String
+			metadata: JSON
+		}`
+
+	t.Run("nullable fields are pointers and non null lists are slices", func(t *testing.T) {
+		out, err := generate(t, schema, `query Users($filter: [UserFilter!]) { users(filter: $filter) { id userName: name metadata } }`)
+		require.NoError(t, err)
+		assert.Contains(t, out, "Users []UsersResponseUsers `json:\"users\"`")
+		assert.Contains(t, out, "UserName *string `json:\"userName\"`")
+		assert.Contains(t, out, "Metadata json.RawMessage `json:\"metadata\"`")
+		assert.Contains(t, out, "Filter []UserFilter `json:\"filter,omitempty\"`")
+		assert.Contains(t, out, "Role *Role `json:\"role,omitempty\"`")
+		assert.Contains(t, out, "RoleUserManager Role = \"USER_MANAGER\"")
+	})
+
+	t.Run("operation without variables", func(t *testing.T) {
+		out, err := generate(t, schema, `query Admin { user(id: "1") { id } }`)
+		require.NoError(t, err)
+		assert.Contains(t, out, "func Admin(ctx context.Context, executor client.Executor) (*AdminResponse, error) {")
+		assert.NotContains(t, out, "AdminVariables")
+	})
+
+	t.Run("anonymous operations are rejected", func(t *testing.T) {
+		_, err := generate(t, schema, `{ user(id: "1") { id } }`)
+		assert.EqualError(t, err, "query operations must be named to generate code")
+	})
+
+	t.Run("invalid operations are rejected", func(t *testing.T) {
+		_, err := generate(t, schema, `query User { user(id: "1") { unknown } }`)
+		assert.Error(t, err)
+	})
+}
+
+func TestExportedName(t *testing.T) {
+	assert.Equal(t, "NewHope", exportedName("NEW_HOPE"))
+	assert.Equal(t, "PrimaryFunction", exportedName("primaryFunction"))
+	assert.Equal(t, "DroidID", exportedName("droidId"))
+	assert.Equal(t, "Typename", exportedName("__typename"))
+	assert.Equal(t, "ID", exportedName("id"))
+}
@@ -0,0 +1,74 @@
+package codegen
+
+import (
+	"strings"
+	"unicode"
+)
+
+// commonInitialisms are rendered in upper case to follow the go naming conventions, e.g. "userId" becomes "UserID"
+var commonInitialisms = map[string]struct{}{
+	"Api":  {},
+	"Html": {},
+	"Http": {},
+	"Id":   {},
+	"Json": {},
+	"Sql":  {},
+	"Uri":  {},
+	"Url":  {},
+	"Uuid": {},
+}
+
+// exportedName converts a GraphQL name into an exported go identifier.
+// Underscores separate words and words written in all caps (as usual for enum values) are title cased,
+// e.g. "NEW_HOPE" becomes "NewHope", "primaryFunction" becomes "PrimaryFunction" and "droidId" becomes "DroidID".
+func exportedName(name string) string {
+	var out strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		if isUpper(part) {
+			part = strings.ToLower(part)
+		}
+		for _, word := range splitWords(part) {
+			word = strings.ToUpper(word[:1]) + word[1:]
+			if _, ok := commonInitialisms[word]; ok {
+				word = strings.ToUpper(word)
+			}
+			out.WriteString(word)
+		}
+	}
+	if out.Len() == 0 {
+		return "X"
+	}
+	result := out.String()
+	if !unicode.IsLetter(rune(result[0])) {
+		result = "X" + result
+	}
+	return result
+}
+
+// splitWords splits a camel case name at every upper case letter
+func splitWords(name string) (words []string) {
+	start := 0
+	for i := 1; i < len(name); i++ {
+		if unicode.IsUpper(rune(name[i])) && !unicode.IsUpper(rune(name[i-1])) {
+			words = append(words, name[start:i])
+			start = i
+		}
+	}
+	return append(words, name[start:])
+}
+
+func isUpper(s string) bool {
+	hasLetter := false
+	for _, r := range s {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			hasLetter = true
+		}
+	}
+	return hasLetter
+}
@@ -0,0 +1,226 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+)
+
+const typenameFieldName = "__typename"
+
+// selectionSet is the merged view of all selections on one response object.
+// Fields of fragments which always apply to typeName are merged into fields,
+// fragments on other types are kept separately and are decoded depending on the __typename of the response object.
+type selectionSet struct {
+	typeName  string
+	fields    []*selectionField
+	fragments []*typedFragment
+	// typenameKey is the response key of the __typename field, it is empty when __typename is not selected
+	typenameKey string
+	// astSelectionSet is the first selection set in the operation document contributing to this selection set
+	astSelectionSet int
+}
+
+type selectionField struct {
+	responseKey string
+	// typeRef references the field type in the definition, it is ast.InvalidRef for __typename
+	typeRef    int
+	selections *selectionSet
+}
+
+type typedFragment struct {
+	typeName      string
+	possibleTypes []string
+	selections    *selectionSet
+}
+
+func (s *selectionSet) field(responseKey string) *selectionField {
+	for i := range s.fields {
+		if s.fields[i].responseKey == responseKey {
+			return s.fields[i]
+		}
+	}
+	return nil
+}
+
+func (s *selectionSet) fragment(typeName string) *typedFragment {
+	for i := range s.fragments {
+		if s.fragments[i].typeName == typeName {
+			return s.fragments[i]
+		}
+	}
+	return nil
+}
+
+func newSelectionSet(typeName string, astSelectionSet int) *selectionSet {
+	return &selectionSet{
+		typeName:        typeName,
+		astSelectionSet: astSelectionSet,
+	}
+}
+
+// collectSelections merges all selections of the operation selection set with the given ref into set
+func (g *Generator) collectSelections(set *selectionSet, selectionSetRef int) error {
+	for _, selectionRef := range g.operation.SelectionSets[selectionSetRef].SelectionRefs {
+		selection := g.operation.Selections[selectionRef]
+		switch selection.Kind {
+		case ast.SelectionKindField:
+			if err := g.collectField(set, selection.Ref); err != nil {
+				return err
+			}
+		case ast.SelectionKindInlineFragment:
+			if !g.operation.InlineFragments[selection.Ref].HasSelections {
+				continue
+			}
+			typeCondition := g.operation.InlineFragmentTypeConditionNameString(selection.Ref)
+			if err := g.collectFragment(set, typeCondition, g.operation.InlineFragments[selection.Ref].SelectionSet); err != nil {
+				return err
+			}
+		case ast.SelectionKindFragmentSpread:
+			fragmentName := g.operation.FragmentSpreadNameBytes(selection.Ref)
+			fragmentRef, exists := g.operation.FragmentDefinitionRef(fragmentName)
+			if !exists {
+				return fmt.Errorf("fragment %s is not defined", fragmentName)
+			}
+			g.usedFragments[fragmentRef] = struct{}{}
+			if !g.operation.FragmentDefinitions[fragmentRef].HasSelections {
+				continue
+			}
+			typeCondition := g.operation.FragmentDefinitionTypeNameString(fragmentRef)
+			if err := g.collectFragment(set, typeCondition, g.operation.FragmentDefinitions[fragmentRef].SelectionSet); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Generator) collectField(set *selectionSet, fieldRef int) error {
+	responseKey := g.operation.FieldAliasOrNameString(fieldRef)
+	fieldName := g.operation.FieldNameBytes(fieldRef)
+
+	if string(fieldName) == typenameFieldName {
+		if set.typenameKey == "" {
+			set.typenameKey = responseKey
+		}
+		if set.field(responseKey) == nil {
+			set.fields = append(set.fields, &selectionField{
+				responseKey: responseKey,
+				typeRef:     ast.InvalidRef,
+			})
+		}
+		return nil
+	}
+
+	typeNode, exists := g.definition.Index.FirstNodeByNameStr(set.typeName)
+	if !exists {
+		return fmt.Errorf("type %s is not defined in the schema", set.typeName)
+	}
+	fieldDefinitionRef, exists := g.definition.NodeFieldDefinitionByName(typeNode, fieldName)
+	if !exists {
+		return fmt.Errorf("field %s is not defined on type %s", fieldName, set.typeName)
+	}
+	typeRef := g.definition.FieldDefinitionType(fieldDefinitionRef)
+
+	field := set.field(responseKey)
+	if field == nil {
+		field = &selectionField{
+			responseKey: responseKey,
+			typeRef:     typeRef,
+		}
+		set.fields = append(set.fields, field)
+	}
+
+	if !g.operation.Fields[fieldRef].HasSelections {
+		return nil
+	}
+	if field.selections == nil {
+		field.selections = newSelectionSet(g.definition.ResolveTypeNameString(typeRef), g.operation.Fields[fieldRef].SelectionSet)
+	}
+	return g.collectSelections(field.selections, g.operation.Fields[fieldRef].SelectionSet)
+}
+
+func (g *Generator) collectFragment(set *selectionSet, typeCondition string, selectionSetRef int) error {
+	if typeCondition == "" || typeCondition == set.typeName || g.isImplementedBy(typeCondition, set.typeName) {
+		return g.collectSelections(set, selectionSetRef)
+	}
+
+	fragment := set.fragment(typeCondition)
+	if fragment == nil {
+		possibleTypes := g.possibleTypes(typeCondition)
+		if len(possibleTypes) == 0 {
+			return fmt.Errorf("type condition %s has no possible types", typeCondition)
+		}
+		fragment = &typedFragment{
+			typeName:      typeCondition,
+			possibleTypes: possibleTypes,
+			selections:    newSelectionSet(typeCondition, selectionSetRef),
+		}
+		set.fragments = append(set.fragments, fragment)
+	}
+	return g.collectSelections(fragment.selections, selectionSetRef)
+}
+
+// isImplementedBy returns true when typeName is an object type which is a possible type of the abstract type abstractTypeName
+func (g *Generator) isImplementedBy(abstractTypeName, typeName string) bool {
+	node, exists := g.definition.Index.FirstNodeByNameStr(typeName)
+	if !exists || node.Kind != ast.NodeKindObjectTypeDefinition {
+		return false
+	}
+	for _, possibleType := range g.possibleTypes(abstractTypeName) {
+		if possibleType == typeName {
+			return true
+		}
+	}
+	return false
+}
+
+// possibleTypes returns the names of all object types a value of the given type could be resolved to
+func (g *Generator) possibleTypes(typeName string) []string {
+	node, exists := g.definition.Index.FirstNodeByNameStr(typeName)
+	if !exists {
+		return nil
+	}
+	switch node.Kind {
+	case ast.NodeKindObjectTypeDefinition:
+		return []string{typeName}
+	case ast.NodeKindUnionTypeDefinition:
+		memberTypeNames, _ := g.definition.UnionTypeDefinitionMemberTypeNames(node.Ref)
+		return memberTypeNames
+	case ast.NodeKindInterfaceTypeDefinition:
+		var typeNames []string
+		for i := range g.definition.ObjectTypeDefinitions {
+			objectNode := ast.Node{Kind: ast.NodeKindObjectTypeDefinition, Ref: i}
+			if g.definition.NodeImplementsInterface(objectNode, []byte(typeName)) {
+				typeNames = append(typeNames, g.definition.ObjectTypeDefinitionNameString(i))
+			}
+		}
+		return typeNames
+	}
+	return nil
+}
+
+// ensureTypenames adds a __typename selection to every selection set with fragments on other types,
+// the generated code relies on __typename to decide which fragments to decode
+func (g *Generator) ensureTypenames(set *selectionSet) {
+	if set == nil {
+		return
+	}
+	if len(set.fragments) != 0 && set.typenameKey == "" {
+		field := g.operation.AddField(ast.Field{
+			Name: g.operation.Input.AppendInputString(typenameFieldName),
+		})
+		g.operation.AddSelection(set.astSelectionSet, ast.Selection{
+			Kind: ast.SelectionKindField,
+			Ref:  field.Ref,
+		})
+		set.typenameKey = typenameFieldName
+		set.fields = append([]*selectionField{{responseKey: typenameFieldName, typeRef: ast.InvalidRef}}, set.fields...)
+	}
+	for i := range set.fields {
+		g.ensureTypenames(set.fields[i].selections)
+	}
+	for i := range set.fragments {
+		g.ensureTypenames(set.fragments[i].selections)
+	}
+}
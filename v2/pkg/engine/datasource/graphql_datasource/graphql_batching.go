@@ -0,0 +1,226 @@
+package graphql_datasource
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/httpclient"
+)
+
+const (
+	// DefaultBatchWindow is the time fetches are collected before they are sent as a batch
+	DefaultBatchWindow = 2 * time.Millisecond
+	// DefaultBatchTimeout limits the time a batch request takes if not all of its fetches have a deadline
+	DefaultBatchTimeout = 10 * time.Second
+)
+
+// RequestBatcher sends fetches issued concurrently to the same subgraph as one batch request:
+// a JSON array of GraphQL requests which the subgraph answers with a JSON array of responses in the same order.
+// Fetches are batched if they are sent to the same URL with the same method and headers within the batch window,
+// e.g. the fetches of a resolve.ParallelFetch. Fetches without a sibling in the window are sent as single requests.
+//
+// A batch request is sent on its own context, which ends with the latest deadline of its fetches,
+// so it doesn't carry the values of a single fetch, e.g. its span. Response headers are reported to the observers of all fetches.
+//
+// A RequestBatcher is set on the Factory and used by the data sources with FetchConfiguration.Batch enabled.
+type RequestBatcher struct {
+	httpClient   *http.Client
+	window       time.Duration
+	maxBatchSize int
+	timeout      time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*requestBatch
+}
+
+type requestBatch struct {
+	input    []byte
+	requests []*batchedRequest
+	sent     bool
+}
+
+type batchedRequest struct {
+	ctx  context.Context
+	body []byte
+	done chan batchedResponse
+}
+
+type batchedResponse struct {
+	data []byte
+	err  error
+}
+
+// NewRequestBatcher creates a batcher sending the batches with httpClient.
+// A batch is sent when the window elapsed after its first fetch or when it contains maxBatchSize fetches, 0 disables the limit.
+func NewRequestBatcher(httpClient *http.Client, window time.Duration, maxBatchSize int) *RequestBatcher {
+	if window <= 0 {
+		window = DefaultBatchWindow
+	}
+	return &RequestBatcher{
+		httpClient:   httpClient,
+		window:       window,
+		maxBatchSize: maxBatchSize,
+		timeout:      DefaultBatchTimeout,
+		pending:      map[string]*requestBatch{},
+	}
+}
+
+// Load adds the request to the batch of its subgraph and writes its response once the batch has been answered
+func (b *RequestBatcher) Load(ctx context.Context, input []byte, writer io.Writer) error {
+	key := batchKey(input)
+	body, _, _, err := jsonparser.Get(input, httpclient.BODY)
+	if err != nil {
+		return err
+	}
+	// the buffers of the fetch might be reused if its context is canceled before the batch is sent
+	request := &batchedRequest{
+		ctx:  ctx,
+		body: append([]byte(nil), body...),
+		done: make(chan batchedResponse, 1),
+	}
+	b.add(key, append([]byte(nil), input...), request)
+
+	select {
+	case response := <-request.done:
+		if response.err != nil {
+			return response.err
+		}
+		_, err = writer.Write(response.data)
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *RequestBatcher) add(key string, input []byte, request *batchedRequest) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	batch, ok := b.pending[key]
+	if !ok {
+		batch = &requestBatch{input: input}
+		b.pending[key] = batch
+		time.AfterFunc(b.window, func() {
+			b.flush(key, batch)
+		})
+	}
+	batch.requests = append(batch.requests, request)
+	if b.maxBatchSize > 0 && len(batch.requests) >= b.maxBatchSize {
+		b.detach(key, batch)
+		go b.send(batch)
+	}
+}
+
+func (b *RequestBatcher) flush(key string, batch *requestBatch) {
+	b.mu.Lock()
+	if batch.sent {
+		b.mu.Unlock()
+		return
+	}
+	b.detach(key, batch)
+	b.mu.Unlock()
+	b.send(batch)
+}
+
+// detach removes the batch from the pending batches, later fetches open a new batch. It must be called with b.mu held.
+func (b *RequestBatcher) detach(key string, batch *requestBatch) {
+	batch.sent = true
+	if b.pending[key] == batch {
+		delete(b.pending, key)
+	}
+}
+
+func (b *RequestBatcher) send(batch *requestBatch) {
+	if len(batch.requests) == 1 {
+		request := batch.requests[0]
+		buf := &bytes.Buffer{}
+		err := httpclient.Do(b.httpClient, request.ctx, batch.input, buf)
+		request.done <- batchedResponse{data: buf.Bytes(), err: err}
+		return
+	}
+
+	responses, err := b.sendBatch(batch)
+	for i, request := range batch.requests {
+		if err != nil {
+			request.done <- batchedResponse{err: err}
+			continue
+		}
+		request.done <- batchedResponse{data: responses[i]}
+	}
+}
+
+func (b *RequestBatcher) sendBatch(batch *requestBatch) ([][]byte, error) {
+	body := &bytes.Buffer{}
+	body.WriteByte('[')
+	for i, request := range batch.requests {
+		if i > 0 {
+			body.WriteByte(',')
+		}
+		body.Write(request.body)
+	}
+	body.WriteByte(']')
+
+	input, err := jsonparser.Set(append([]byte(nil), batch.input...), body.Bytes(), httpclient.BODY)
+	if err != nil {
+		return nil, err
+	}
+	// traces are added to the response of a single request
+	input = jsonparser.Delete(input, httpclient.TRACE)
+
+	ctx, cancel := b.batchContext(batch)
+	defer cancel()
+	buf := &bytes.Buffer{}
+	if err = httpclient.Do(b.httpClient, ctx, input, buf); err != nil {
+		return nil, err
+	}
+
+	responses := make([][]byte, 0, len(batch.requests))
+	_, err = jsonparser.ArrayEach(buf.Bytes(), func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		responses = append(responses, value)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid batch response: %w", err)
+	}
+	if len(responses) != len(batch.requests) {
+		return nil, fmt.Errorf("invalid batch response: %d responses for %d requests", len(responses), len(batch.requests))
+	}
+	return responses, nil
+}
+
+// batchContext returns the context of the batch request, it outlives the fetches which are canceled before the batch is answered
+func (b *RequestBatcher) batchContext(batch *requestBatch) (context.Context, context.CancelFunc) {
+	var deadline time.Time
+	for _, request := range batch.requests {
+		requestDeadline, ok := request.ctx.Deadline()
+		if !ok {
+			requestDeadline = time.Now().Add(b.timeout)
+		}
+		if requestDeadline.After(deadline) {
+			deadline = requestDeadline
+		}
+	}
+	ctx := httpclient.WithResponseHeadersObserver(context.Background(), func(header http.Header) {
+		for _, request := range batch.requests {
+			httpclient.ObserveResponseHeaders(request.ctx, header)
+		}
+	})
+	return context.WithDeadline(ctx, deadline)
+}
+
+// batchKey identifies the requests which can be sent in one batch
+func batchKey(input []byte) string {
+	key := &bytes.Buffer{}
+	for _, path := range []string{httpclient.URL, httpclient.METHOD, httpclient.HEADER, httpclient.QUERYPARAMS} {
+		value, _, _, _ := jsonparser.Get(input, path)
+		key.Write(value)
+		key.WriteByte(0)
+	}
+	return key.String()
+}
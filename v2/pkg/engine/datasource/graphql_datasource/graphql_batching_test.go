@@ -0,0 +1,244 @@
+package graphql_datasource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/httpclient"
+)
+
+// batchingSubgraph answers every request with the id variable of the request, batches are answered with an array
+type batchingSubgraph struct {
+	mu      sync.Mutex
+	batches []int
+	// truncate drops the last response of batches
+	truncate bool
+}
+
+func (b *batchingSubgraph) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	response := func(request GraphQLBody) string {
+		var variables struct {
+			ID string `json:"id"`
+		}
+		_ = json.Unmarshal(request.Variables, &variables)
+		return fmt.Sprintf(`{"data":{"user":{"id":"%s"}}}`, variables.ID)
+	}
+
+	if !bytes.HasPrefix(body, []byte("[")) {
+		var request GraphQLBody
+		_ = json.Unmarshal(body, &request)
+		b.record(1)
+		_, _ = fmt.Fprint(w, response(request))
+		return
+	}
+
+	var requests []GraphQLBody
+	_ = json.Unmarshal(body, &requests)
+	b.record(len(requests))
+	if b.truncate {
+		requests = requests[:len(requests)-1]
+	}
+	responses := make([]json.RawMessage, 0, len(requests))
+	for _, request := range requests {
+		responses = append(responses, json.RawMessage(response(request)))
+	}
+	_ = json.NewEncoder(w).Encode(responses)
+}
+
+func (b *batchingSubgraph) record(size int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.batches = append(b.batches, size)
+}
+
+func (b *batchingSubgraph) requests() []int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.batches
+}
+
+func TestRequestBatcher(t *testing.T) {
+	input := func(url, id string) []byte {
+		var input []byte
+		input = httpclient.SetInputBodyWithPath(input, []byte(`{"id":"`+id+`"}`), "variables")
+		input = httpclient.SetInputBodyWithPath(input, []byte(`query($id: ID!){user(id: $id){id}}`), "query")
+		input = httpclient.SetInputURL(input, []byte(url))
+		input = httpclient.SetInputMethod(input, []byte(http.MethodPost))
+		return httpclient.SetInputFlag(input, httpclient.BATCH_REQUEST)
+	}
+
+	loadConcurrently := func(src *Source, url string, count int) ([]string, []error) {
+		results := make([]string, count)
+		errs := make([]error, count)
+		wg := sync.WaitGroup{}
+		for i := 0; i < count; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				buf := &bytes.Buffer{}
+				errs[i] = src.Load(context.Background(), input(url, fmt.Sprint(i)), buf)
+				results[i] = buf.String()
+			}(i)
+		}
+		wg.Wait()
+		return results, errs
+	}
+
+	t.Run("concurrent fetches are sent as one batch", func(t *testing.T) {
+		subgraph := &batchingSubgraph{}
+		server := httptest.NewServer(subgraph)
+		defer server.Close()
+
+		src := &Source{httpClient: server.Client(), batcher: NewRequestBatcher(server.Client(), 50*time.Millisecond, 0)}
+		results, errs := loadConcurrently(src, server.URL, 3)
+		for i := range results {
+			require.NoError(t, errs[i])
+			assert.Equal(t, fmt.Sprintf(`{"data":{"user":{"id":"%d"}}}`, i), results[i])
+		}
+		assert.Equal(t, []int{3}, subgraph.requests())
+	})
+
+	t.Run("single fetches are sent without batch", func(t *testing.T) {
+		subgraph := &batchingSubgraph{}
+		server := httptest.NewServer(subgraph)
+		defer server.Close()
+
+		src := &Source{httpClient: server.Client(), batcher: NewRequestBatcher(server.Client(), time.Millisecond, 0)}
+		buf := &bytes.Buffer{}
+		require.NoError(t, src.Load(context.Background(), input(server.URL, "1"), buf))
+		assert.Equal(t, `{"data":{"user":{"id":"1"}}}`, buf.String())
+		assert.Equal(t, []int{1}, subgraph.requests())
+	})
+
+	t.Run("batches are limited to the max batch size", func(t *testing.T) {
+		subgraph := &batchingSubgraph{}
+		server := httptest.NewServer(subgraph)
+		defer server.Close()
+
+		src := &Source{httpClient: server.Client(), batcher: NewRequestBatcher(server.Client(), 50*time.Millisecond, 2)}
+		_, errs := loadConcurrently(src, server.URL, 4)
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+		assert.Equal(t, []int{2, 2}, subgraph.requests())
+	})
+
+	t.Run("fetches to different subgraphs aren't batched", func(t *testing.T) {
+		subgraph := &batchingSubgraph{}
+		server := httptest.NewServer(subgraph)
+		defer server.Close()
+
+		src := &Source{httpClient: server.Client(), batcher: NewRequestBatcher(server.Client(), 50*time.Millisecond, 0)}
+		var errs, otherErrs []error
+		wg := sync.WaitGroup{}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, errs = loadConcurrently(src, server.URL, 2)
+		}()
+		go func() {
+			defer wg.Done()
+			_, otherErrs = loadConcurrently(src, server.URL+"/other", 1)
+		}()
+		wg.Wait()
+		for _, err := range append(errs, otherErrs...) {
+			require.NoError(t, err)
+		}
+		assert.ElementsMatch(t, []int{2, 1}, subgraph.requests())
+	})
+
+	t.Run("invalid batch response", func(t *testing.T) {
+		subgraph := &batchingSubgraph{truncate: true}
+		server := httptest.NewServer(subgraph)
+		defer server.Close()
+
+		src := &Source{httpClient: server.Client(), batcher: NewRequestBatcher(server.Client(), 50*time.Millisecond, 0)}
+		_, errs := loadConcurrently(src, server.URL, 2)
+		for _, err := range errs {
+			assert.EqualError(t, err, "invalid batch response: 1 responses for 2 requests")
+		}
+	})
+
+	t.Run("fetches aren't batched without batcher", func(t *testing.T) {
+		subgraph := &batchingSubgraph{}
+		server := httptest.NewServer(subgraph)
+		defer server.Close()
+
+		src := &Source{httpClient: server.Client()}
+		_, errs := loadConcurrently(src, server.URL, 2)
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+		assert.Equal(t, []int{1, 1}, subgraph.requests())
+	})
+
+	t.Run("response headers of a batch are reported to all fetches", func(t *testing.T) {
+		subgraph := &batchingSubgraph{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", "max-age=60")
+			subgraph.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+
+		src := &Source{httpClient: server.Client(), batcher: NewRequestBatcher(server.Client(), 50*time.Millisecond, 0)}
+		headers := make([]string, 2)
+		wg := sync.WaitGroup{}
+		for i := range headers {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				ctx := httpclient.WithResponseHeadersObserver(context.Background(), func(header http.Header) {
+					headers[i] = header.Get("Cache-Control")
+				})
+				assert.NoError(t, src.Load(ctx, input(server.URL, fmt.Sprint(i)), &bytes.Buffer{}))
+			}(i)
+		}
+		wg.Wait()
+		assert.Equal(t, []int{2}, subgraph.requests())
+		assert.Equal(t, []string{"max-age=60", "max-age=60"}, headers)
+	})
+
+	t.Run("batch is canceled after the latest deadline of its fetches", func(t *testing.T) {
+		canceled := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the server notices the canceled request once it read the body
+			_, _ = io.ReadAll(r.Body)
+			select {
+			case <-r.Context().Done():
+				close(canceled)
+			case <-time.After(5 * time.Second):
+			}
+		}))
+		defer server.Close()
+
+		src := &Source{httpClient: server.Client(), batcher: NewRequestBatcher(server.Client(), 10*time.Millisecond, 0)}
+		wg := sync.WaitGroup{}
+		for _, timeout := range []time.Duration{50 * time.Millisecond, 100 * time.Millisecond} {
+			wg.Add(1)
+			go func(timeout time.Duration) {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				defer cancel()
+				assert.ErrorIs(t, src.Load(ctx, input(server.URL, "1"), &bytes.Buffer{}), context.DeadlineExceeded)
+			}(timeout)
+		}
+		wg.Wait()
+		select {
+		case <-canceled:
+		case <-time.After(time.Second):
+			t.Fatal("batch request wasn't canceled")
+		}
+	})
+}
@@ -3,10 +3,13 @@ package graphql_datasource
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"regexp"
 	"slices"
 
@@ -32,6 +35,9 @@ import (
 
 const removeNullVariablesDirectiveName = "removeNullVariables"
 
+// FederatedTracingHeader requests a federated trace (ftv1) from a subgraph, the trace is returned in the "ftv1" response extension
+const FederatedTracingHeader = "apollo-federation-include-trace"
+
 var (
 	DefaultPostProcessingConfiguration = resolve.PostProcessingConfiguration{
 		SelectResponseDataPath:   []string{"data"},
@@ -62,6 +68,7 @@ type Planner struct {
 	variables                          resolve.Variables
 	lastFieldEnclosingTypeName         string
 	fetchClient                        *http.Client
+	batcher                            *RequestBatcher
 	subscriptionClient                 GraphQLSubscriptionClient
 	rootTypeName                       string // rootTypeName - holds name of top level type
 	rootFieldName                      string // rootFieldName - holds name of root type field
@@ -343,6 +350,15 @@ type FetchConfiguration struct {
 	URL    string
 	Method string
 	Header http.Header
+	// PersistedQueries sends the sha256 hash of the query in extensions.persistedQuery instead of the query text (Apollo automatic persisted queries).
+	// If the subgraph doesn't know the hash, the request is retried with the query text, which registers it for the hash.
+	PersistedQueries bool
+	// UseGETForHashedQueries sends the hashed requests of queries as GET requests, so that they can be cached by CDNs in front of the subgraph.
+	// Mutations and retries with the query text are sent with Method.
+	UseGETForHashedQueries bool
+	// Batch sends the fetches with the RequestBatcher of the Factory, fetches aren't batched if it's not set.
+	// Persisted queries aren't batched.
+	Batch bool
 }
 
 func (c *Configuration) ApplyDefaults() {
@@ -376,13 +392,22 @@ func (p *Planner) Register(visitor *plan.Visitor, configuration plan.DataSourceC
 
 func (p *Planner) ConfigureFetch() resolve.FetchConfiguration {
 	var input []byte
+	query := p.printOperation()
 	input = httpclient.SetInputBodyWithPath(input, p.upstreamVariables, "variables")
-	input = httpclient.SetInputBodyWithPath(input, p.printOperation(), "query")
+	input = httpclient.SetInputBodyWithPath(input, query, "query")
 
 	if p.unnulVariables {
 		input = httpclient.SetInputFlag(input, httpclient.UNNULL_VARIABLES)
 	}
 
+	if p.config.Fetch.PersistedQueries && len(query) != 0 {
+		input = p.setPersistedQuery(input, query)
+	}
+
+	if p.config.Fetch.Batch {
+		input = httpclient.SetInputFlag(input, httpclient.BATCH_REQUEST)
+	}
+
 	header, err := json.Marshal(p.config.Fetch.Header)
 	if err == nil && len(header) != 0 && !bytes.Equal(header, literal.NULL) {
 		input = httpclient.SetInputHeader(input, header)
@@ -404,6 +429,7 @@ func (p *Planner) ConfigureFetch() resolve.FetchConfiguration {
 		Input: string(input),
 		DataSource: &Source{
 			httpClient: p.fetchClient,
+			batcher:    p.batcher,
 		},
 		Variables:                             p.variables,
 		RequiresEntityFetch:                   p.requiresEntityFetch(),
@@ -413,6 +439,18 @@ func (p *Planner) ConfigureFetch() resolve.FetchConfiguration {
 	}
 }
 
+// setPersistedQuery adds the hash of the query to the extensions of the request, the query is removed from the request by Source.Load
+func (p *Planner) setPersistedQuery(input, query []byte) []byte {
+	hash := sha256.Sum256(query)
+	extensions := fmt.Sprintf(`{"persistedQuery":{"version":1,"sha256Hash":"%s"}}`, hex.EncodeToString(hash[:]))
+	input = httpclient.SetInputBodyWithPath(input, []byte(extensions), "extensions")
+	input = httpclient.SetInputFlag(input, httpclient.PERSISTED_QUERY)
+	if p.config.Fetch.UseGETForHashedQueries && p.upstreamOperation.OperationDefinitions[0].OperationType == ast.OperationTypeQuery {
+		input = httpclient.SetInputFlag(input, httpclient.USE_GET_FOR_HASHED_QUERY)
+	}
+	return input
+}
+
 func (p *Planner) shouldSelectSingleEntity() bool {
 	return p.dataSourcePlannerConfig.HasRequiredFields() &&
 		p.dataSourcePlannerConfig.PathType == plan.PlannerPathObject
@@ -1654,6 +1692,8 @@ type Factory struct {
 	OnWsConnectionInitCallback *OnWsConnectionInitCallback
 	SubscriptionClient         *SubscriptionClient
 	Logger                     abstractlogger.Logger
+	// RequestBatcher batches the fetches of data sources with FetchConfiguration.Batch enabled
+	RequestBatcher *RequestBatcher
 }
 
 func (f *Factory) Planner(ctx context.Context) plan.DataSourcePlanner {
@@ -1672,12 +1712,14 @@ func (f *Factory) Planner(ctx context.Context) plan.DataSourcePlanner {
 	}
 	return &Planner{
 		fetchClient:        f.HTTPClient,
+		batcher:            f.RequestBatcher,
 		subscriptionClient: f.SubscriptionClient,
 	}
 }
 
 type Source struct {
 	httpClient *http.Client
+	batcher    *RequestBatcher
 }
 
 func (s *Source) compactAndUnNullVariables(input []byte) []byte {
@@ -1764,9 +1806,90 @@ func (s *Source) replaceEmptyObject(variables []byte) ([]byte, bool) {
 
 func (s *Source) Load(ctx context.Context, input []byte, writer io.Writer) (err error) {
 	input = s.compactAndUnNullVariables(input)
+	if resolve.FederatedTraceRequested(ctx) {
+		input, err = jsonparser.Set(input, []byte(`["ftv1"]`), httpclient.HEADER, FederatedTracingHeader)
+		if err != nil {
+			return err
+		}
+	}
+	if httpclient.IsInputFlagSet(input, httpclient.PERSISTED_QUERY) {
+		return s.loadPersistedQuery(ctx, input, writer)
+	}
+	if s.batcher != nil && httpclient.IsInputFlagSet(input, httpclient.BATCH_REQUEST) {
+		return s.batcher.Load(ctx, input, writer)
+	}
+	return httpclient.Do(s.httpClient, ctx, input, writer)
+}
+
+// loadPersistedQuery sends the request without the query text, it's retried with the query if the subgraph doesn't know the hash of the query
+func (s *Source) loadPersistedQuery(ctx context.Context, input []byte, writer io.Writer) error {
+	hashedInput := jsonparser.Delete(append([]byte(nil), input...), httpclient.BODY, "query")
+	if httpclient.IsInputFlagSet(input, httpclient.USE_GET_FOR_HASHED_QUERY) {
+		var err error
+		if hashedInput, err = persistedQueryGETInput(hashedInput); err != nil {
+			return err
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := httpclient.Do(s.httpClient, ctx, hashedInput, buf); err != nil {
+		return err
+	}
+	if !isPersistedQueryNotFound(buf.Bytes()) {
+		_, err := writer.Write(buf.Bytes())
+		return err
+	}
 	return httpclient.Do(s.httpClient, ctx, input, writer)
 }
 
+// persistedQueryGETInput moves the body of the request into the URL parameters of a GET request
+func persistedQueryGETInput(input []byte) ([]byte, error) {
+	rawURL, err := jsonparser.GetString(input, httpclient.URL)
+	if err != nil {
+		return nil, err
+	}
+	requestURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	query := requestURL.Query()
+	err = jsonparser.ObjectEach(input, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
+		if dataType == jsonparser.Null {
+			return nil
+		}
+		if dataType == jsonparser.String {
+			if value, err = jsonparser.Unescape(value, nil); err != nil {
+				return err
+			}
+		}
+		query.Set(string(key), string(value))
+		return nil
+	}, httpclient.BODY)
+	if err != nil {
+		return nil, err
+	}
+	requestURL.RawQuery = query.Encode()
+
+	input = jsonparser.Delete(input, httpclient.BODY)
+	input = httpclient.SetInputURL(input, []byte(requestURL.String()))
+	return httpclient.SetInputMethod(input, []byte(http.MethodGet)), nil
+}
+
+// isPersistedQueryNotFound returns true if the subgraph doesn't know the hash or doesn't support persisted queries
+func isPersistedQueryNotFound(response []byte) bool {
+	notFound := false
+	_, _ = jsonparser.ArrayEach(response, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		message, _ := jsonparser.GetString(value, "message")
+		code, _ := jsonparser.GetString(value, "extensions", "code")
+		switch {
+		case message == "PersistedQueryNotFound", code == "PERSISTED_QUERY_NOT_FOUND",
+			message == "PersistedQueryNotSupported", code == "PERSISTED_QUERY_NOT_SUPPORTED":
+			notFound = true
+		}
+	}, "errors")
+	return notFound
+}
+
 type GraphQLSubscriptionClient interface {
 	Subscribe(ctx *resolve.Context, options GraphQLSubscriptionOptions, updater resolve.SubscriptionUpdater) error
 	UniqueRequestID(ctx *resolve.Context, options GraphQLSubscriptionOptions, hash *xxhash.Digest) (err error)
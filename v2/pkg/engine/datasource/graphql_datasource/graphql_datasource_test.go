@@ -13,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/buger/jsonparser"
 	"github.com/cespare/xxhash/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -103,6 +104,81 @@ func TestGraphQLDataSourceTypenames(t *testing.T) {
 	})
 }
 
+func TestGraphQLDataSourcePersistedQueries(t *testing.T) {
+	def := `
+		schema {
+			query: Query
+			mutation: Mutation
+		}
+
+		type Query {
+			hello: String
+		}
+
+		type Mutation {
+			greet: String
+		}`
+
+	planConfiguration := plan.Configuration{
+		DataSources: []plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{
+					{
+						TypeName:   "Query",
+						FieldNames: []string{"hello"},
+					},
+					{
+						TypeName:   "Mutation",
+						FieldNames: []string{"greet"},
+					},
+				},
+				Factory: &Factory{},
+				Custom: ConfigJson(Configuration{
+					Fetch: FetchConfiguration{
+						URL:                    "https://example.com/graphql",
+						PersistedQueries:       true,
+						UseGETForHashedQueries: true,
+					},
+					UpstreamSchema: def,
+				}),
+			},
+		},
+		DisableResolveFieldPositions: true,
+	}
+
+	response := func(input, fieldName string) *resolve.GraphQLResponse {
+		return &resolve.GraphQLResponse{
+			Data: &resolve.Object{
+				Fetch: &resolve.SingleFetch{
+					FetchConfiguration: resolve.FetchConfiguration{
+						DataSource:     &Source{},
+						Input:          input,
+						PostProcessing: DefaultPostProcessingConfiguration,
+					},
+					DataSourceIdentifier: []byte("graphql_datasource.Source"),
+				},
+				Fields: []*resolve.Field{
+					{
+						Name: []byte(fieldName),
+						Value: &resolve.String{
+							Path:     []string{fieldName},
+							Nullable: true,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("query", RunTest(def, `query Hello { hello }`, "Hello", &plan.SynchronousResponsePlan{
+		Response: response(`{"method":"POST","url":"https://example.com/graphql","use_get_for_hashed_query":true,"persisted_query":true,"body":{"extensions":{"persistedQuery":{"version":1,"sha256Hash":"9dd7ff987fac8d0d1979084ebde5ce8bd855cd066d1a34e98432275cc6bc264c"}},"query":"{hello}"}}`, "hello"),
+	}, planConfiguration))
+
+	t.Run("mutations aren't sent with GET", RunTest(def, `mutation Greet { greet }`, "Greet", &plan.SynchronousResponsePlan{
+		Response: response(`{"method":"POST","url":"https://example.com/graphql","persisted_query":true,"body":{"extensions":{"persistedQuery":{"version":1,"sha256Hash":"0badf041137a6f1e1268f6bdb57d380b5680ff47f7277433942e7b9d212844c4"}},"query":"mutation{greet}"}}`, "greet"),
+	}, planConfiguration))
+}
+
 func TestGraphQLDataSource(t *testing.T) {
 	t.Skip("FIXME")
 
@@ -8533,6 +8609,93 @@ func TestSource_Load(t *testing.T) {
 	})
 }
 
+func TestSource_Load_PersistedQueries(t *testing.T) {
+	const (
+		query     = `query($id: ID!){user(id: $id){name}}`
+		queryHash = "sha256-of-query"
+	)
+
+	// the subgraph stores the queries sent with their hash, like Apollo Server
+	var (
+		mux      sync.Mutex
+		requests []string
+		stored   = map[string]string{}
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.Lock()
+		defer mux.Unlock()
+
+		var body GraphQLBody
+		if r.Method == http.MethodGet {
+			body.Query = r.URL.Query().Get("query")
+			body.Variables = json.RawMessage(r.URL.Query().Get("variables"))
+			body.Extensions = json.RawMessage(r.URL.Query().Get("extensions"))
+		} else {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		}
+		requests = append(requests, fmt.Sprintf("%s query=%t variables=%s", r.Method, body.Query != "", body.Variables))
+
+		hash, err := jsonparser.GetString(body.Extensions, "persistedQuery", "sha256Hash")
+		require.NoError(t, err)
+		if body.Query == "" {
+			if _, ok := stored[hash]; !ok {
+				_, _ = fmt.Fprint(w, `{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`)
+				return
+			}
+		} else {
+			stored[hash] = body.Query
+		}
+		_, _ = fmt.Fprint(w, `{"data":{"user":{"name":"Jens"}}}`)
+	}))
+	defer ts.Close()
+
+	input := func(flags ...string) []byte {
+		var input []byte
+		input = httpclient.SetInputBodyWithPath(input, []byte(`{"id":"1"}`), "variables")
+		input = httpclient.SetInputBodyWithPath(input, []byte(query), "query")
+		input = httpclient.SetInputBodyWithPath(input, []byte(`{"persistedQuery":{"version":1,"sha256Hash":"`+queryHash+`"}}`), "extensions")
+		input = httpclient.SetInputURL(input, []byte(ts.URL))
+		input = httpclient.SetInputMethod(input, []byte(http.MethodPost))
+		input = httpclient.SetInputFlag(input, httpclient.PERSISTED_QUERY)
+		for _, flag := range flags {
+			input = httpclient.SetInputFlag(input, flag)
+		}
+		return input
+	}
+	load := func(t *testing.T, input []byte) []string {
+		mux.Lock()
+		requests, stored = nil, map[string]string{}
+		mux.Unlock()
+
+		src := &Source{httpClient: &http.Client{}}
+		for i := 0; i < 2; i++ {
+			buf := &bytes.Buffer{}
+			require.NoError(t, src.Load(context.Background(), input, buf))
+			assert.Equal(t, `{"data":{"user":{"name":"Jens"}}}`, buf.String())
+		}
+
+		mux.Lock()
+		defer mux.Unlock()
+		return requests
+	}
+
+	t.Run("unknown hashes are retried with the query", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`POST query=false variables={"id":"1"}`,
+			`POST query=true variables={"id":"1"}`,
+			`POST query=false variables={"id":"1"}`,
+		}, load(t, input()))
+	})
+
+	t.Run("hashed queries are sent with GET", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`GET query=false variables={"id":"1"}`,
+			`POST query=true variables={"id":"1"}`,
+			`GET query=false variables={"id":"1"}`,
+		}, load(t, input(httpclient.USE_GET_FOR_HASHED_QUERY)))
+	})
+}
+
 func TestUnNullVariables(t *testing.T) {
 	t.Run("should not unnull variables if not enabled", func(t *testing.T) {
 		t.Run("two variables, one null", func(t *testing.T) {
@@ -8,17 +8,22 @@ import (
 	"io"
 	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/buger/jsonparser"
 	log "github.com/jensneuse/abstractlogger"
 	"github.com/r3labs/sse/v2"
 
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/httpclient"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
 )
 
 var (
 	headerData  = []byte("data:")
 	headerEvent = []byte("event:")
+	headerID    = []byte("id:")
+	headerRetry = []byte("retry:")
 
 	eventTypeComplete = []byte("complete")
 	eventTypeNext     = []byte("next")
@@ -29,6 +34,12 @@ type gqlSSEConnectionHandler struct {
 	ctx     context.Context
 	log     log.Logger
 	options GraphQLSubscriptionOptions
+	// reconnectPolicy configures the reconnection after the stream dropped
+	reconnectPolicy ReconnectPolicy
+	// lastEventID is the id of the last received event, it is sent as Last-Event-ID header when reconnecting
+	lastEventID string
+	// retry is the reconnection time sent by the origin, it takes precedence over the backoff of the reconnectPolicy
+	retry time.Duration
 }
 
 func newSSEConnectionHandler(ctx *resolve.Context, conn *http.Client, opts GraphQLSubscriptionOptions, l log.Logger) *gqlSSEConnectionHandler {
@@ -67,40 +78,66 @@ func (h *gqlSSEConnectionHandler) StartBlocking(sub Subscription) {
 }
 
 func (h *gqlSSEConnectionHandler) subscribe(ctx context.Context, sub Subscription, dataCh, errCh chan []byte) {
-	resp, err := h.performSubscriptionRequest(ctx)
-	if err != nil {
-		h.log.Error("failed to perform subscription request", log.Error(err))
+	for attempt := 0; ; attempt++ {
+		resp, err := h.performSubscriptionRequest(ctx)
+		requestFailed := err != nil
+		if requestFailed {
+			h.log.Error("failed to perform subscription request", log.Error(err))
+		} else {
+			attempt = 0
+			err = h.readEvents(ctx, resp.Body, dataCh, errCh)
+			_ = resp.Body.Close()
+			if err == nil {
+				return
+			}
+		}
 
 		if ctx.Err() != nil {
 			// request context was canceled do not send an error as channel will be closed
 			return
 		}
 
-		sub.updater.Update([]byte(internalError))
+		backoff, ok := h.reconnectPolicy.backoff(attempt)
+		if !ok {
+			switch {
+			case requestFailed:
+				sub.updater.Update([]byte(internalError))
+			case err != io.EOF:
+				h.log.Error("failed to read event", log.Error(err))
+				errCh <- []byte(internalError)
+			}
+			return
+		}
+		if h.retry > 0 {
+			backoff = h.retry
+		}
 
-		return
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
+}
 
-	reader := sse.NewEventStreamReader(resp.Body, math.MaxInt)
+// readEvents reads the events of the stream until it is complete, failed with an error or the context is done.
+// It returns an error only if the stream dropped, which is io.EOF if the origin closed it.
+func (h *gqlSSEConnectionHandler) readEvents(ctx context.Context, body io.Reader, dataCh, errCh chan []byte) error {
+	reader := sse.NewEventStreamReader(body, math.MaxInt)
 
 	for {
 		if ctx.Err() != nil {
-			return
+			return nil
 		}
 
 		msg, err := reader.ReadEvent()
 		if err != nil {
-			if err == io.EOF {
-				return
+			if err != io.EOF {
+				h.log.Error("failed to read event", log.Error(err))
 			}
-
-			h.log.Error("failed to read event", log.Error(err))
-
-			errCh <- []byte(internalError)
-			return
+			return err
 		}
 
 		if len(msg) == 0 {
@@ -121,7 +158,7 @@ func (h *gqlSSEConnectionHandler) subscribe(ctx context.Context, sub Subscriptio
 
 				if ctx.Err() != nil {
 					// request context was canceled do not send an error as channel will be closed
-					return
+					return nil
 				}
 
 				dataCh <- data
@@ -130,10 +167,16 @@ func (h *gqlSSEConnectionHandler) subscribe(ctx context.Context, sub Subscriptio
 
 				switch {
 				case bytes.Equal(event, eventTypeComplete):
-					return
+					return nil
 				case bytes.Equal(event, eventTypeNext):
 					continue
 				}
+			case bytes.HasPrefix(line, headerID):
+				h.lastEventID = string(trim(line[len(headerID):]))
+			case bytes.HasPrefix(line, headerRetry):
+				if retry, err := strconv.Atoi(string(trim(line[len(headerRetry):]))); err == nil && retry >= 0 {
+					h.retry = time.Duration(retry) * time.Millisecond
+				}
 			case bytes.HasPrefix(msg, []byte(":")):
 				// according to the spec, we ignore messages starting with a colon
 				continue
@@ -159,11 +202,11 @@ func (h *gqlSSEConnectionHandler) subscribe(ctx context.Context, sub Subscriptio
 							h.log.Error("failed to set errors", log.Error(err))
 
 							errCh <- []byte(internalError)
-							return
+							return nil
 						}
 
 						errCh <- response
-						return
+						return nil
 					} else if valueType == jsonparser.Object {
 						response := []byte(`{"errors":[]}`)
 						response, err = jsonparser.Set(response, val, "errors", "[0]")
@@ -171,17 +214,17 @@ func (h *gqlSSEConnectionHandler) subscribe(ctx context.Context, sub Subscriptio
 							h.log.Error("failed to set errors", log.Error(err))
 
 							errCh <- []byte(internalError)
-							return
+							return nil
 						}
 
 						errCh <- response
-						return
+						return nil
 					}
 
 				default:
 					h.log.Error("failed to parse errors", log.Error(err))
 					errCh <- []byte(internalError)
-					return
+					return nil
 				}
 			}
 		}
@@ -234,7 +277,7 @@ func (h *gqlSSEConnectionHandler) buildGETRequest(ctx context.Context) (*http.Re
 	}
 
 	if h.options.Header != nil {
-		req.Header = h.options.Header
+		req.Header = h.options.Header.Clone()
 	}
 
 	query := req.URL.Query()
@@ -274,7 +317,7 @@ func (h *gqlSSEConnectionHandler) buildPOSTRequest(ctx context.Context) (*http.R
 	}
 
 	if h.options.Header != nil {
-		req.Header = h.options.Header
+		req.Header = h.options.Header.Clone()
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -282,9 +325,13 @@ func (h *gqlSSEConnectionHandler) buildPOSTRequest(ctx context.Context) (*http.R
 	return req, nil
 }
 
-// setSSEHeaders sets the headers required for SSE for both GET and POST requests
+// setSSEHeaders sets the headers required for SSE for both GET and POST requests and the trace context
 func (h *gqlSSEConnectionHandler) setSSEHeaders(req *http.Request) {
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Connection", "keep-alive")
 	req.Header.Set("Cache-Control", "no-cache")
+	if h.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", h.lastEventID)
+	}
+	httpclient.InjectTraceContext(req.Context(), req.Header)
 }
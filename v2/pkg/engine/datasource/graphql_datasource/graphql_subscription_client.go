@@ -14,6 +14,7 @@ import (
 	"github.com/coder/websocket"
 	"github.com/jensneuse/abstractlogger"
 
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/httpclient"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
 )
 
@@ -32,6 +33,7 @@ type SubscriptionClient struct {
 	handlersMu                 sync.Mutex
 	wsSubProtocol              string
 	onWsConnectionInitCallback *OnWsConnectionInitCallback
+	reconnectPolicy            ReconnectPolicy
 
 	readTimeout time.Duration
 }
@@ -62,11 +64,19 @@ func WithOnWsConnectionInitCallback(callback *OnWsConnectionInitCallback) Option
 	}
 }
 
+// WithReconnectPolicy enables the reconnection of dropped upstream connections, see ReconnectPolicy.
+func WithReconnectPolicy(policy ReconnectPolicy) Options {
+	return func(options *opts) {
+		options.reconnectPolicy = policy
+	}
+}
+
 type opts struct {
 	readTimeout                time.Duration
 	log                        abstractlogger.Logger
 	wsSubProtocol              string
 	onWsConnectionInitCallback *OnWsConnectionInitCallback
+	reconnectPolicy            ReconnectPolicy
 }
 
 // GraphQLSubscriptionClientFactory abstracts the way of creating a new GraphQLSubscriptionClient.
@@ -103,6 +113,7 @@ func NewGraphQLSubscriptionClient(httpClient, streamingClient *http.Client, engi
 		},
 		wsSubProtocol:              op.wsSubProtocol,
 		onWsConnectionInitCallback: op.onWsConnectionInitCallback,
+		reconnectPolicy:            op.reconnectPolicy,
 	}
 }
 
@@ -151,6 +162,7 @@ func (c *SubscriptionClient) subscribeSSE(reqCtx *resolve.Context, options Graph
 	}
 
 	handler := newSSEConnectionHandler(reqCtx, c.streamingClient, options, c.log)
+	handler.reconnectPolicy = c.reconnectPolicy
 
 	go func() {
 		handler.StartBlocking(sub)
@@ -269,15 +281,65 @@ func (c *SubscriptionClient) newWSConnectionHandler(reqCtx context.Context, opti
 		subProtocols = []string{c.wsSubProtocol}
 	}
 
-	conn, upgradeResponse, err := websocket.Dial(reqCtx, options.URL, &websocket.DialOptions{
+	conn, err := c.dialWS(reqCtx, options, subProtocols)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.wsSubProtocol == "" {
+		c.wsSubProtocol = conn.Subprotocol()
+	}
+
+	var reconnector *wsReconnector
+	if c.reconnectPolicy.enabled() {
+		// the protocol negotiated by the first connection is used for all reconnections
+		reconnectProtocols := []string{c.wsSubProtocol}
+		reconnector = &wsReconnector{
+			policy: c.reconnectPolicy,
+			dial: func(ctx context.Context) (*websocket.Conn, error) {
+				return c.dialWS(ctx, options, reconnectProtocols)
+			},
+			log: c.log,
+		}
+	}
+
+	switch c.wsSubProtocol {
+	case ProtocolGraphQLWS:
+		handler := newGQLWSConnectionHandler(c.engineCtx, conn, c.readTimeout, c.log)
+		handler.reconnector = reconnector
+		return handler, nil
+	case ProtocolGraphQLTWS:
+		handler := newGQLTWSConnectionHandler(c.engineCtx, conn, c.readTimeout, c.log)
+		handler.reconnector = reconnector
+		return handler, nil
+	default:
+		return nil, fmt.Errorf("unknown protocol %s", conn.Subprotocol())
+	}
+}
+
+// dialWS opens a WebSocket connection to the origin and waits for the acknowledgement of the connection_init message
+func (c *SubscriptionClient) dialWS(ctx context.Context, options GraphQLSubscriptionOptions, subProtocols []string) (conn *websocket.Conn, err error) {
+	// the header of the options is part of the handler id, so the trace context is added to a copy
+	header := options.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	httpclient.InjectTraceContext(ctx, header)
+
+	conn, upgradeResponse, err := websocket.Dial(ctx, options.URL, &websocket.DialOptions{
 		HTTPClient:      c.httpClient,
-		HTTPHeader:      options.Header,
+		HTTPHeader:      header,
 		CompressionMode: websocket.CompressionDisabled,
 		Subprotocols:    subProtocols,
 	})
 	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		if err != nil {
+			_ = conn.CloseNow()
+		}
+	}()
 	// Disable the maximum message size limit. Don't use MaxInt64 since
 	// the github.com/coder/websocket doesn't handle it correctly on 32 bit systems.
 	conn.SetReadLimit(math.MaxInt32)
@@ -285,7 +347,7 @@ func (c *SubscriptionClient) newWSConnectionHandler(reqCtx context.Context, opti
 		return nil, fmt.Errorf("upgrade unsuccessful")
 	}
 
-	connectionInitMessage, err := c.getConnectionInitMessage(reqCtx, options.URL, options.Header)
+	connectionInitMessage, err := c.getConnectionInitMessage(ctx, options.URL, options.Header)
 	if err != nil {
 		return nil, err
 	}
@@ -305,27 +367,15 @@ func (c *SubscriptionClient) newWSConnectionHandler(reqCtx context.Context, opti
 	}
 
 	// init + ack
-	err = conn.Write(reqCtx, websocket.MessageText, connectionInitMessage)
+	err = conn.Write(ctx, websocket.MessageText, connectionInitMessage)
 	if err != nil {
 		return nil, err
 	}
 
-	if c.wsSubProtocol == "" {
-		c.wsSubProtocol = conn.Subprotocol()
-	}
-
-	if err := waitForAck(reqCtx, conn); err != nil {
+	if err = waitForAck(ctx, conn); err != nil {
 		return nil, err
 	}
-
-	switch c.wsSubProtocol {
-	case ProtocolGraphQLWS:
-		return newGQLWSConnectionHandler(c.engineCtx, conn, c.readTimeout, c.log), nil
-	case ProtocolGraphQLTWS:
-		return newGQLTWSConnectionHandler(c.engineCtx, conn, c.readTimeout, c.log), nil
-	default:
-		return nil, fmt.Errorf("unknown protocol %s", conn.Subprotocol())
-	}
+	return conn, nil
 }
 
 func (c *SubscriptionClient) getConnectionInitMessage(ctx context.Context, url string, header http.Header) ([]byte, error) {
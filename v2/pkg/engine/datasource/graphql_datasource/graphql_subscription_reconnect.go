@@ -0,0 +1,99 @@
+package graphql_datasource
+
+import (
+	"context"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/jensneuse/abstractlogger"
+)
+
+const (
+	DefaultReconnectInitialBackoff = 500 * time.Millisecond
+	DefaultReconnectMaxBackoff     = 30 * time.Second
+)
+
+// ReconnectPolicy configures how the SubscriptionClient reconnects to the origin after an upstream connection dropped.
+// WebSocket connections are re-established with a new connection_init, including the payload of the OnWsConnectionInitCallback,
+// and all active subscriptions of the connection are resubscribed.
+// SSE subscriptions are resumed with the Last-Event-ID of the last received event and wait for the retry interval sent by the origin, if any.
+// Subscriptions receive an error only if all attempts failed.
+type ReconnectPolicy struct {
+	// MaxRetries is the number of reconnection attempts after a connection dropped, reconnection is disabled if it is 0
+	MaxRetries int
+	// InitialBackoff is the wait time before the first attempt, it is doubled after every failed attempt.
+	// Defaults to DefaultReconnectInitialBackoff.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait time between attempts, defaults to DefaultReconnectMaxBackoff
+	MaxBackoff time.Duration
+}
+
+func (p ReconnectPolicy) enabled() bool {
+	return p.MaxRetries > 0
+}
+
+// backoff returns the wait time before the attempt, counting from 0, and false if the retry budget is exhausted
+func (p ReconnectPolicy) backoff(attempt int) (time.Duration, bool) {
+	if attempt >= p.MaxRetries {
+		return 0, false
+	}
+	backoff, maxBackoff := p.InitialBackoff, p.MaxBackoff
+	if backoff <= 0 {
+		backoff = DefaultReconnectInitialBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultReconnectMaxBackoff
+	}
+	for i := 0; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff), true
+}
+
+// wsReconnector re-establishes the WebSocket connection of a connection handler
+type wsReconnector struct {
+	policy ReconnectPolicy
+	// dial opens a new connection to the origin including connection_init and ack
+	dial func(ctx context.Context) (*websocket.Conn, error)
+	log  abstractlogger.Logger
+}
+
+// reconnect dials the origin until a connection is established or the retry budget is exhausted, the error of the last attempt is returned.
+// Subscriptions arriving while waiting are passed to queue, so that they can be subscribed on the new connection.
+func (r *wsReconnector) reconnect(ctx context.Context, subscribeCh <-chan Subscription, queue func(sub Subscription)) (*websocket.Conn, error) {
+	var err error
+	for attempt := 0; ; attempt++ {
+		backoff, ok := r.policy.backoff(attempt)
+		if !ok {
+			return nil, err
+		}
+		if err = r.wait(ctx, backoff, subscribeCh, queue); err != nil {
+			return nil, err
+		}
+
+		var conn *websocket.Conn
+		conn, err = r.dial(ctx)
+		if err == nil {
+			return conn, nil
+		}
+		r.log.Error("wsReconnector.reconnect",
+			abstractlogger.Int("attempt", attempt+1),
+			abstractlogger.Error(err),
+		)
+	}
+}
+
+func (r *wsReconnector) wait(ctx context.Context, backoff time.Duration, subscribeCh <-chan Subscription, queue func(sub Subscription)) error {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case sub := <-subscribeCh:
+			queue(sub)
+		case <-timer.C:
+			return nil
+		}
+	}
+}
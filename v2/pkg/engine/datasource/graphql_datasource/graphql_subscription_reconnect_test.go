@@ -0,0 +1,195 @@
+package graphql_datasource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/coder/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+)
+
+func TestReconnectPolicy_Backoff(t *testing.T) {
+	policy := ReconnectPolicy{MaxRetries: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for attempt, want := range expected {
+		backoff, ok := policy.backoff(attempt)
+		assert.True(t, ok)
+		assert.Equal(t, want, backoff)
+	}
+	_, ok := policy.backoff(5)
+	assert.False(t, ok)
+
+	backoff, ok := ReconnectPolicy{MaxRetries: 1}.backoff(0)
+	assert.True(t, ok)
+	assert.Equal(t, DefaultReconnectInitialBackoff, backoff)
+
+	assert.False(t, ReconnectPolicy{}.enabled())
+}
+
+func TestWebsocketSubscriptionClientReconnect(t *testing.T) {
+	protocols := []struct {
+		protocol  string
+		startType string
+		dataType  string
+	}{
+		{protocol: ProtocolGraphQLWS, startType: "start", dataType: "data"},
+		{protocol: ProtocolGraphQLTWS, startType: "subscribe", dataType: "next"},
+	}
+
+	for _, p := range protocols {
+		p := p
+		t.Run(p.protocol, func(t *testing.T) {
+			acceptAndInit := func(w http.ResponseWriter, r *http.Request) *websocket.Conn {
+				conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{p.protocol}})
+				require.NoError(t, err)
+				_, data, err := conn.Read(r.Context())
+				require.NoError(t, err)
+				assert.Equal(t, `{"type":"connection_init","payload":{"authorization":"secret"}}`, string(data))
+				require.NoError(t, conn.Write(r.Context(), websocket.MessageText, []byte(`{"type":"connection_ack"}`)))
+				return conn
+			}
+			assertStart := func(r *http.Request, conn *websocket.Conn) {
+				_, data, err := conn.Read(r.Context())
+				require.NoError(t, err)
+				messageType, _ := jsonparser.GetString(data, "type")
+				id, _ := jsonparser.GetString(data, "id")
+				assert.Equal(t, p.startType, messageType)
+				assert.Equal(t, "1", id)
+			}
+			sendData := func(r *http.Request, conn *websocket.Conn, text string) {
+				message := fmt.Sprintf(`{"type":"%s","id":"1","payload":{"data":{"messageAdded":{"text":"%s"}}}}`, p.dataType, text)
+				require.NoError(t, conn.Write(r.Context(), websocket.MessageText, []byte(message)))
+			}
+
+			var callback OnWsConnectionInitCallback = func(ctx context.Context, url string, header http.Header) (json.RawMessage, error) {
+				return json.RawMessage(`{"authorization":"secret"}`), nil
+			}
+			subscribe := func(t *testing.T, serverURL string, policy ReconnectPolicy) (*testSubscriptionUpdater, context.CancelFunc) {
+				ctx, clientCancel := context.WithCancel(context.Background())
+				client := NewGraphQLSubscriptionClient(http.DefaultClient, http.DefaultClient, ctx,
+					WithReadTimeout(time.Millisecond),
+					WithLogger(logger()),
+					WithWSSubProtocol(p.protocol),
+					WithOnWsConnectionInitCallback(&callback),
+					WithReconnectPolicy(policy),
+				)
+				updater := &testSubscriptionUpdater{}
+				err := client.Subscribe(resolve.NewContext(ctx), GraphQLSubscriptionOptions{
+					URL: serverURL,
+					Body: GraphQLBody{
+						Query: `subscription {messageAdded(roomName: "room"){text}}`,
+					},
+				}, updater)
+				require.NoError(t, err)
+				return updater, clientCancel
+			}
+
+			t.Run("resubscribes after the connection dropped", func(t *testing.T) {
+				connections := atomic.NewInt64(0)
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					conn := acceptAndInit(w, r)
+					assertStart(r, conn)
+					if connections.Inc() == 1 {
+						sendData(r, conn, "first")
+						_ = conn.CloseNow()
+						return
+					}
+					sendData(r, conn, "second")
+					_, _, _ = conn.Read(r.Context())
+				}))
+				defer server.Close()
+
+				updater, clientCancel := subscribe(t, server.URL, ReconnectPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond})
+				defer clientCancel()
+
+				updater.AwaitUpdates(t, time.Second, 2)
+				assert.Equal(t, `{"data":{"messageAdded":{"text":"first"}}}`, updater.updates[0])
+				assert.Equal(t, `{"data":{"messageAdded":{"text":"second"}}}`, updater.updates[1])
+				assert.Equal(t, int64(2), connections.Load())
+			})
+
+			t.Run("sends an error after all attempts failed", func(t *testing.T) {
+				connections := atomic.NewInt64(0)
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if connections.Inc() > 1 {
+						w.WriteHeader(http.StatusServiceUnavailable)
+						return
+					}
+					conn := acceptAndInit(w, r)
+					assertStart(r, conn)
+					sendData(r, conn, "first")
+					_ = conn.CloseNow()
+				}))
+				defer server.Close()
+
+				updater, clientCancel := subscribe(t, server.URL, ReconnectPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond})
+				defer clientCancel()
+
+				updater.AwaitUpdates(t, time.Second, 2)
+				assert.Equal(t, `{"data":{"messageAdded":{"text":"first"}}}`, updater.updates[0])
+				assert.Contains(t, updater.updates[1], `"errors"`)
+				updater.AwaitDone(t, time.Second)
+				assert.Equal(t, int64(3), connections.Load())
+			})
+		})
+	}
+}
+
+func TestGraphQLSubscriptionClientSubscribe_SSE_Reconnect(t *testing.T) {
+	requests := atomic.NewInt64(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		if requests.Inc() == 1 {
+			assert.Empty(t, r.Header.Get("Last-Event-ID"))
+			_, _ = fmt.Fprintf(w, "id: 1\nretry: 10\nevent: next\ndata: %s\n\n", `{"data":{"messageAdded":{"text":"first"}}}`)
+			flusher.Flush()
+			return
+		}
+
+		assert.Equal(t, "1", r.Header.Get("Last-Event-ID"))
+		_, _ = fmt.Fprintf(w, "id: 2\nevent: next\ndata: %s\n\n", `{"data":{"messageAdded":{"text":"second"}}}`)
+		_, _ = fmt.Fprintf(w, "event: complete\n\n")
+		flusher.Flush()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the backoff exceeds the test timeout, so the retry interval of the origin has to be used
+	client := NewGraphQLSubscriptionClient(http.DefaultClient, http.DefaultClient, ctx,
+		WithReadTimeout(time.Millisecond),
+		WithLogger(logger()),
+		WithReconnectPolicy(ReconnectPolicy{MaxRetries: 1, InitialBackoff: time.Hour}),
+	)
+
+	updater := &testSubscriptionUpdater{}
+	err := client.Subscribe(resolve.NewContext(ctx), GraphQLSubscriptionOptions{
+		URL: server.URL,
+		Body: GraphQLBody{
+			Query: `subscription {messageAdded(roomName: "room"){text}}`,
+		},
+		UseSSE: true,
+	}, updater)
+	require.NoError(t, err)
+
+	updater.AwaitUpdates(t, time.Second, 2)
+	assert.Equal(t, `{"data":{"messageAdded":{"text":"first"}}}`, updater.updates[0])
+	assert.Equal(t, `{"data":{"messageAdded":{"text":"second"}}}`, updater.updates[1])
+	assert.Equal(t, int64(2), requests.Load())
+}
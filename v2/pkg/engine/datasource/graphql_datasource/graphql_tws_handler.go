@@ -23,6 +23,8 @@ type gqlTWSConnectionHandler struct {
 	nextSubscriptionID int
 	subscriptions      map[string]Subscription
 	readTimeout        time.Duration
+	// reconnector replaces the connection if it dropped, reconnection is disabled if it is nil
+	reconnector *wsReconnector
 }
 
 func newGQLTWSConnectionHandler(ctx context.Context, conn *websocket.Conn, rt time.Duration, l log.Logger) *gqlTWSConnectionHandler {
@@ -65,8 +67,12 @@ func (h *gqlTWSConnectionHandler) StartBlocking(sub Subscription) {
 			h.subscribe(sub)
 		case err := <-errCh:
 			h.log.Error("gqlWSConnectionHandler.StartBlocking", log.Error(err))
-			h.broadcastErrorMessage(err)
-			return
+			if err = h.reconnect(err); err != nil {
+				h.broadcastErrorMessage(err)
+				return
+			}
+			// the previous readBlocking returned after sending the error
+			go h.readBlocking(readCtx, dataCh, errCh)
 		case data := <-dataCh:
 			messageType, err := jsonparser.GetString(data, "type")
 			if err != nil {
@@ -115,19 +121,11 @@ func (h *gqlTWSConnectionHandler) unsubscribe(subscriptionID string) {
 
 // subscribe adds a new Subscription to the gqlTWSConnectionHandler and sends the subscribeMessage to the origin
 func (h *gqlTWSConnectionHandler) subscribe(sub Subscription) {
-	graphQLBody, err := json.Marshal(sub.options.Body)
-	if err != nil {
-		h.log.Error("failed to marshal GraphQL body", log.Error(err))
-		return
-	}
-
 	h.nextSubscriptionID++
 
 	subscriptionID := strconv.Itoa(h.nextSubscriptionID)
 
-	subscribeRequest := fmt.Sprintf(subscribeMessage, subscriptionID, string(graphQLBody))
-	err = h.conn.Write(h.ctx, websocket.MessageText, []byte(subscribeRequest))
-	if err != nil {
+	if err := h.writeSubscribe(subscriptionID, sub); err != nil {
 		h.log.Error("failed to write subscribe message", log.Error(err))
 		return
 	}
@@ -135,6 +133,46 @@ func (h *gqlTWSConnectionHandler) subscribe(sub Subscription) {
 	h.subscriptions[subscriptionID] = sub
 }
 
+func (h *gqlTWSConnectionHandler) writeSubscribe(subscriptionID string, sub Subscription) error {
+	graphQLBody, err := json.Marshal(sub.options.Body)
+	if err != nil {
+		return err
+	}
+
+	subscribeRequest := fmt.Sprintf(subscribeMessage, subscriptionID, string(graphQLBody))
+	return h.conn.Write(h.ctx, websocket.MessageText, []byte(subscribeRequest))
+}
+
+// reconnect replaces the dropped connection and resubscribes all active subscriptions.
+// It returns the error to send to the subscriptions if reconnection is disabled or all attempts failed.
+func (h *gqlTWSConnectionHandler) reconnect(err error) error {
+	if h.reconnector == nil || h.ctx.Err() != nil {
+		return err
+	}
+	_ = h.conn.CloseNow()
+
+	conn, err := h.reconnector.reconnect(h.ctx, h.subscribeCh, func(sub Subscription) {
+		h.nextSubscriptionID++
+		h.subscriptions[strconv.Itoa(h.nextSubscriptionID)] = sub
+	})
+	if err != nil {
+		return err
+	}
+	h.conn = conn
+
+	for id, sub := range h.subscriptions {
+		if sub.ctx.Err() != nil {
+			sub.updater.Done()
+			delete(h.subscriptions, id)
+			continue
+		}
+		if err = h.writeSubscribe(id, sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (h *gqlTWSConnectionHandler) broadcastErrorMessage(err error) {
 	errMsg := fmt.Sprintf(errorMessageTemplate, err)
 	for _, sub := range h.subscriptions {
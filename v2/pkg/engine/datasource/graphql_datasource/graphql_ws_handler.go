@@ -24,6 +24,8 @@ type gqlWSConnectionHandler struct {
 	nextSubscriptionID int
 	subscriptions      map[string]Subscription
 	readTimeout        time.Duration
+	// reconnector replaces the connection if it dropped, reconnection is disabled if it is nil
+	reconnector *wsReconnector
 }
 
 func newGQLWSConnectionHandler(ctx context.Context, conn *websocket.Conn, readTimeout time.Duration, log abstractlogger.Logger) *gqlWSConnectionHandler {
@@ -76,8 +78,12 @@ func (h *gqlWSConnectionHandler) StartBlocking(sub Subscription) {
 			if !errors.Is(err, context.Canceled) {
 				h.log.Error("gqlWSConnectionHandler.StartBlocking", abstractlogger.Error(err))
 			}
-			h.broadcastErrorMessage(err)
-			return
+			if err = h.reconnect(err); err != nil {
+				h.broadcastErrorMessage(err)
+				return
+			}
+			// the previous readBlocking returned after sending the error
+			go h.readBlocking(readCtx, dataCh, errCh)
 		case data := <-dataCh:
 			messageType, err := jsonparser.GetString(data, "type")
 			if err != nil {
@@ -135,22 +141,55 @@ func (h *gqlWSConnectionHandler) unsubscribeAllAndCloseConn() {
 
 // subscribe adds a new Subscription to the gqlWSConnectionHandler and sends the startMessage to the origin
 func (h *gqlWSConnectionHandler) subscribe(sub Subscription) {
-	graphQLBody, err := json.Marshal(sub.options.Body)
-	if err != nil {
+	h.nextSubscriptionID++
+
+	subscriptionID := strconv.Itoa(h.nextSubscriptionID)
+
+	if err := h.writeStart(subscriptionID, sub); err != nil {
 		return
 	}
 
-	h.nextSubscriptionID++
+	h.subscriptions[subscriptionID] = sub
+}
 
-	subscriptionID := strconv.Itoa(h.nextSubscriptionID)
+func (h *gqlWSConnectionHandler) writeStart(subscriptionID string, sub Subscription) error {
+	graphQLBody, err := json.Marshal(sub.options.Body)
+	if err != nil {
+		return err
+	}
 
 	startRequest := fmt.Sprintf(startMessage, subscriptionID, string(graphQLBody))
-	err = h.conn.Write(h.ctx, websocket.MessageText, []byte(startRequest))
+	return h.conn.Write(h.ctx, websocket.MessageText, []byte(startRequest))
+}
+
+// reconnect replaces the dropped connection and restarts all active subscriptions.
+// It returns the error to send to the subscriptions if reconnection is disabled or all attempts failed.
+func (h *gqlWSConnectionHandler) reconnect(err error) error {
+	if h.reconnector == nil || h.ctx.Err() != nil {
+		return err
+	}
+	_ = h.conn.CloseNow()
+
+	conn, err := h.reconnector.reconnect(h.ctx, h.subscribeCh, func(sub Subscription) {
+		h.nextSubscriptionID++
+		h.subscriptions[strconv.Itoa(h.nextSubscriptionID)] = sub
+	})
 	if err != nil {
-		return
+		return err
 	}
+	h.conn = conn
 
-	h.subscriptions[subscriptionID] = sub
+	for id, sub := range h.subscriptions {
+		if sub.ctx.Err() != nil {
+			sub.updater.Done()
+			delete(h.subscriptions, id)
+			continue
+		}
+		if err = h.writeStart(id, sub); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (h *gqlWSConnectionHandler) handleMessageTypeData(data []byte) {
@@ -0,0 +1,115 @@
+package grpc_datasource
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Configuration maps GraphQL fields to the methods of the gRPC services of a single target.
+// The messages of the methods are described by a FileDescriptorSet, e.g. created with
+// `protoc --include_imports --descriptor_set_out=services.pb services.proto`, no generated code is required.
+type Configuration struct {
+	// Target is the address of the gRPC server, e.g. "orders:50051"
+	Target string `json:"target"`
+	// DescriptorSetFile is the path of the binary FileDescriptorSet describing the services
+	DescriptorSetFile string `json:"descriptorSetFile,omitempty"`
+	// DescriptorSet is the binary FileDescriptorSet describing the services, it takes precedence over DescriptorSetFile
+	DescriptorSet []byte `json:"descriptorSet,omitempty"`
+	// Methods maps the root fields and entity fields to methods
+	Methods []MethodConfiguration `json:"methods"`
+}
+
+// MethodConfiguration maps a GraphQL field to a gRPC method.
+// Fields of the Subscription type are mapped to server-streaming methods, all other fields to unary methods.
+type MethodConfiguration struct {
+	TypeName  string `json:"typeName"`
+	FieldName string `json:"fieldName"`
+	// Service is the fully qualified name of the service, e.g. "shop.v1.OrderService"
+	Service string `json:"service"`
+	// Method is the name of the method, e.g. "GetOrder"
+	Method string `json:"method"`
+	// Request is a JSON template of the request message in the protobuf JSON mapping,
+	// e.g. `{"userId":"{{ .object.id }}"}` for an entity field or `{"id":"{{ .arguments.id }}"}`.
+	// If empty, the arguments of the field are sent as the request message.
+	Request string `json:"request,omitempty"`
+	// Metadata is sent with the call, values can be templates like `{{ .request.headers.Authorization }}`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// ResponsePath selects the value of the field from the response message, e.g. ["orders"] for a
+	// ListOrdersResponse wrapping the orders. If empty, the whole response message is the value of the field.
+	ResponsePath []string `json:"responsePath,omitempty"`
+}
+
+func ConfigJSON(config Configuration) json.RawMessage {
+	out, _ := json.Marshal(config)
+	return out
+}
+
+func (c *Configuration) Validate() error {
+	if c.Target == "" {
+		return errors.New("target cannot be empty")
+	}
+	if len(c.DescriptorSet) == 0 && c.DescriptorSetFile == "" {
+		return errors.New("descriptorSet or descriptorSetFile is required")
+	}
+	for i := range c.Methods {
+		if c.Methods[i].Service == "" || c.Methods[i].Method == "" {
+			return fmt.Errorf("methods[%d]: service and method cannot be empty", i)
+		}
+	}
+	return nil
+}
+
+func (c *Configuration) method(typeName, fieldName string) *MethodConfiguration {
+	for i := range c.Methods {
+		if c.Methods[i].TypeName == typeName && c.Methods[i].FieldName == fieldName {
+			return &c.Methods[i]
+		}
+	}
+	return nil
+}
+
+// files parses the FileDescriptorSet of the configuration
+func (c *Configuration) files() (*protoregistry.Files, error) {
+	descriptorSet := c.DescriptorSet
+	if len(descriptorSet) == 0 {
+		var err error
+		descriptorSet, err = os.ReadFile(c.DescriptorSetFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(descriptorSet, &set); err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	return files, nil
+}
+
+// findMethod looks up the method of a MethodConfiguration
+func findMethod(files *protoregistry.Files, config *MethodConfiguration) (protoreflect.MethodDescriptor, error) {
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(config.Service))
+	if err != nil {
+		return nil, fmt.Errorf("service %s: %w", config.Service, err)
+	}
+	service, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", config.Service)
+	}
+	method := service.Methods().ByName(protoreflect.Name(config.Method))
+	if method == nil {
+		return nil, fmt.Errorf("service %s has no method %s", config.Service, config.Method)
+	}
+	return method, nil
+}
@@ -0,0 +1,55 @@
+package grpc_datasource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfiguration_Validate(t *testing.T) {
+	t.Run("Empty target not allowed", func(t *testing.T) {
+		c := &Configuration{DescriptorSetFile: "orders.pb"}
+		assert.EqualError(t, c.Validate(), "target cannot be empty")
+	})
+
+	t.Run("Descriptor set required", func(t *testing.T) {
+		c := &Configuration{Target: "orders:50051"}
+		assert.EqualError(t, c.Validate(), "descriptorSet or descriptorSetFile is required")
+	})
+
+	t.Run("Method required", func(t *testing.T) {
+		c := &Configuration{
+			Target:            "orders:50051",
+			DescriptorSetFile: "orders.pb",
+			Methods:           []MethodConfiguration{{TypeName: "Query", FieldName: "order", Service: "shop.v1.OrderService"}},
+		}
+		assert.EqualError(t, c.Validate(), "methods[0]: service and method cannot be empty")
+	})
+}
+
+func TestConfiguration_files(t *testing.T) {
+	t.Run("descriptor set file", func(t *testing.T) {
+		descriptorSetFile := filepath.Join(t.TempDir(), "orders.pb")
+		require.NoError(t, os.WriteFile(descriptorSetFile, testDescriptorSet(t), 0o600))
+
+		files, err := (&Configuration{DescriptorSetFile: descriptorSetFile}).files()
+		require.NoError(t, err)
+		method, err := findMethod(files, &MethodConfiguration{Service: "shop.v1.OrderService", Method: "WatchOrder"})
+		require.NoError(t, err)
+		assert.Equal(t, "/shop.v1.OrderService/WatchOrder", methodPath(method))
+		assert.True(t, method.IsStreamingServer())
+	})
+
+	t.Run("invalid descriptor set", func(t *testing.T) {
+		_, err := (&Configuration{DescriptorSet: []byte("orders")}).files()
+		assert.ErrorContains(t, err, "invalid descriptor set")
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		_, err := findMethod(testFiles(t), &MethodConfiguration{Service: "shop.v1.OrderService", Method: "CancelOrder"})
+		assert.EqualError(t, err, "service shop.v1.OrderService has no method CancelOrder")
+	})
+}
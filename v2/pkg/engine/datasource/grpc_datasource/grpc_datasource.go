@@ -0,0 +1,283 @@
+package grpc_datasource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+)
+
+type Planner struct {
+	factory                 *Factory
+	v                       *plan.Visitor
+	config                  Configuration
+	dataSourcePlannerConfig plan.DataSourcePlannerConfiguration
+	conn                    grpc.ClientConnInterface
+	variables               resolve.Variables
+	operationDefinition     int
+	rootField               int
+	rootFieldPath           string
+	methodConfig            *MethodConfiguration
+	method                  protoreflect.MethodDescriptor
+}
+
+func (p *Planner) UpstreamSchema(_ plan.DataSourceConfiguration) *ast.Document {
+	return nil
+}
+
+func (p *Planner) DownstreamResponseFieldAlias(_ int) (alias string, exists bool) {
+	// the gRPC DataSourcePlanner doesn't rewrite upstream fields: skip
+	return
+}
+
+func (p *Planner) DataSourcePlanningBehavior() plan.DataSourcePlanningBehavior {
+	return plan.DataSourcePlanningBehavior{
+		MergeAliasedRootNodes:      false,
+		OverrideFieldPathFromAlias: true,
+	}
+}
+
+func (p *Planner) Register(visitor *plan.Visitor, configuration plan.DataSourceConfiguration, dataSourcePlannerConfiguration plan.DataSourcePlannerConfiguration) error {
+	p.v = visitor
+	p.rootField = ast.InvalidRef
+	p.dataSourcePlannerConfig = dataSourcePlannerConfiguration
+	visitor.Walker.RegisterEnterFieldVisitor(p)
+	if err := json.Unmarshal(configuration.Custom, &p.config); err != nil {
+		return err
+	}
+	if err := p.config.Validate(); err != nil {
+		return err
+	}
+	conn, err := p.factory.conn(p.config.Target)
+	if err != nil {
+		return err
+	}
+	p.conn = conn
+	return nil
+}
+
+func (p *Planner) EnterField(ref int) {
+	if p.rootField != ast.InvalidRef {
+		// nested fields are resolved from the response message
+		return
+	}
+	fieldName := p.v.Operation.FieldNameString(ref)
+	fieldAliasOrName := p.v.Operation.FieldAliasOrNameString(ref)
+	// the field of the parent path belongs to the parent fetch of an entity field
+	currentPath := p.v.Walker.Path.DotDelimitedString() + "." + fieldAliasOrName
+	if p.dataSourcePlannerConfig.ParentPath == currentPath {
+		return
+	}
+	typeName := p.v.Walker.EnclosingTypeDefinition.NameString(p.v.Definition)
+	methodConfig := p.config.method(typeName, fieldName)
+	if methodConfig == nil {
+		return
+	}
+
+	files, err := p.factory.files(&p.config)
+	if err != nil {
+		p.v.Walker.StopWithInternalErr(err)
+		return
+	}
+	method, err := findMethod(files, methodConfig)
+	if err != nil {
+		p.v.Walker.StopWithInternalErr(err)
+		return
+	}
+	if method.IsStreamingClient() {
+		p.v.Walker.StopWithInternalErr(fmt.Errorf("%s.%s: client streaming methods are not supported", typeName, fieldName))
+		return
+	}
+	if method.IsStreamingServer() != (typeName == p.v.Definition.Index.SubscriptionTypeName.String()) {
+		p.v.Walker.StopWithInternalErr(fmt.Errorf("%s.%s: subscriptions must be mapped to server streaming methods and other fields to unary methods", typeName, fieldName))
+		return
+	}
+
+	p.operationDefinition = p.v.Walker.Ancestors[0].Ref
+	p.rootField = ref
+	p.rootFieldPath = fieldAliasOrName
+	p.methodConfig = methodConfig
+	p.method = method
+}
+
+// configureInput renders the input of the data sources:
+// {"method":"/shop.v1.OrderService/GetOrder","metadata":{...},"request":{...}}
+func (p *Planner) configureInput() string {
+	request := p.methodConfig.Request
+	if request == "" {
+		request = p.argumentsRequest()
+	}
+	metadata, _ := json.Marshal(p.methodConfig.Metadata)
+	method, _ := json.Marshal(methodPath(p.method))
+	return fmt.Sprintf(`{"method":%s,"metadata":%s,"request":%s}`, method, metadata, request)
+}
+
+// argumentsRequest renders the arguments of the root field as a JSON object
+func (p *Planner) argumentsRequest() string {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, arg := range p.v.Operation.FieldArguments(p.rootField) {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(p.v.Operation.ArgumentNameString(arg))
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.WriteString(p.argumentValue(arg))
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
+
+func (p *Planner) argumentValue(arg int) string {
+	value := p.v.Operation.ArgumentValue(arg)
+	if value.Kind != ast.ValueKindVariable {
+		out, err := p.v.Operation.ValueToJSON(value)
+		if err != nil {
+			return "null"
+		}
+		return string(out)
+	}
+	variableName := p.v.Operation.VariableValueNameBytes(value.Ref)
+	variableDefinition, ok := p.v.Operation.VariableDefinitionByNameAndOperation(p.operationDefinition, variableName)
+	if !ok {
+		return "null"
+	}
+	renderer, err := resolve.NewJSONVariableRendererWithValidationFromTypeRef(p.v.Operation, p.v.Definition, p.v.Operation.VariableDefinitions[variableDefinition].Type)
+	if err != nil {
+		return "null"
+	}
+	placeholder, _ := p.variables.AddVariable(&resolve.ContextVariable{
+		Path:     []string{string(variableName)},
+		Renderer: renderer,
+	})
+	return placeholder
+}
+
+func (p *Planner) postProcessing() resolve.PostProcessingConfiguration {
+	return resolve.PostProcessingConfiguration{
+		SelectResponseDataPath:   append([]string{"data"}, p.methodConfig.ResponsePath...),
+		SelectResponseErrorsPath: []string{"errors"},
+		MergePath:                []string{p.rootFieldPath},
+	}
+}
+
+func (p *Planner) ConfigureFetch() resolve.FetchConfiguration {
+	if p.methodConfig == nil {
+		p.v.Walker.StopWithInternalErr(errors.New("grpc: no method is configured for the fields of the fetch"))
+		return resolve.FetchConfiguration{}
+	}
+	return resolve.FetchConfiguration{
+		Input:     p.configureInput(),
+		Variables: p.variables,
+		DataSource: &Source{
+			conn:   p.conn,
+			method: p.method,
+		},
+		PostProcessing:                p.postProcessing(),
+		RequiresParallelListItemFetch: p.dataSourcePlannerConfig.PathType == plan.PlannerPathArrayItem,
+	}
+}
+
+func (p *Planner) ConfigureSubscription() plan.SubscriptionConfiguration {
+	if p.methodConfig == nil {
+		p.v.Walker.StopWithInternalErr(errors.New("grpc: no method is configured for the subscription field"))
+		return plan.SubscriptionConfiguration{}
+	}
+	return plan.SubscriptionConfiguration{
+		Input:     p.configureInput(),
+		Variables: p.variables,
+		DataSource: &SubscriptionSource{
+			target: p.config.Target,
+			conn:   p.conn,
+			method: p.method,
+		},
+		PostProcessing: p.postProcessing(),
+	}
+}
+
+// Factory creates the planners of gRPC data sources.
+// It keeps one client connection per target, which is shared by all data sources of the target.
+type Factory struct {
+	// DialOptions are used to create the client connections, defaults to insecure transport credentials
+	DialOptions []grpc.DialOption
+
+	mu         sync.Mutex
+	conns      map[string]*grpc.ClientConn
+	registries map[string]*protoregistry.Files
+}
+
+func (f *Factory) Planner(_ context.Context) plan.DataSourcePlanner {
+	return &Planner{
+		factory: f,
+	}
+}
+
+func (f *Factory) conn(target string) (grpc.ClientConnInterface, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if conn, ok := f.conns[target]; ok {
+		return conn, nil
+	}
+	options := f.DialOptions
+	if options == nil {
+		options = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	conn, err := grpc.NewClient(target, options...)
+	if err != nil {
+		return nil, fmt.Errorf("grpc: target %s: %w", target, err)
+	}
+	if f.conns == nil {
+		f.conns = map[string]*grpc.ClientConn{}
+	}
+	f.conns[target] = conn
+	return conn, nil
+}
+
+// files returns the parsed descriptor set of the configuration, descriptor set files are read once
+func (f *Factory) files(config *Configuration) (*protoregistry.Files, error) {
+	key := config.DescriptorSetFile
+	if len(config.DescriptorSet) != 0 {
+		key = string(config.DescriptorSet)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if files, ok := f.registries[key]; ok {
+		return files, nil
+	}
+	files, err := config.files()
+	if err != nil {
+		return nil, err
+	}
+	if f.registries == nil {
+		f.registries = map[string]*protoregistry.Files{}
+	}
+	f.registries[key] = files
+	return files, nil
+}
+
+// Close closes the client connections of the factory
+func (f *Factory) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var errs []error
+	for target, conn := range f.conns {
+		errs = append(errs, conn.Close())
+		delete(f.conns, target)
+	}
+	return errors.Join(errs...)
+}
+
+var _ plan.PlannerFactory = (*Factory)(nil)
+var _ plan.DataSourcePlanner = (*Planner)(nil)
@@ -0,0 +1,261 @@
+package grpc_datasource
+
+import (
+	"testing"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/staticdatasource"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasourcetesting"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+)
+
+const testSchema = `
+	type Query {
+		order(id: ID!): Order
+		user: User
+	}
+
+	type Subscription {
+		orderUpdates(id: ID!): Order
+	}
+
+	type Order {
+		id: ID!
+		quantity: Int!
+		userId: ID!
+	}
+
+	type User {
+		id: ID!
+		orders: [Order!]!
+	}
+`
+
+func TestGRPCDataSourcePlanning(t *testing.T) {
+	factory := &Factory{}
+	t.Cleanup(func() {
+		_ = factory.Close()
+	})
+
+	config := Configuration{
+		Target:        "orders:50051",
+		DescriptorSet: testDescriptorSet(t),
+		Methods: []MethodConfiguration{
+			{
+				TypeName:  "Query",
+				FieldName: "order",
+				Service:   "shop.v1.OrderService",
+				Method:    "GetOrder",
+				Metadata: map[string]string{
+					"authorization": "{{ .request.headers.Authorization }}",
+				},
+			},
+			{
+				TypeName:  "Subscription",
+				FieldName: "orderUpdates",
+				Service:   "shop.v1.OrderService",
+				Method:    "WatchOrder",
+			},
+			{
+				TypeName:     "User",
+				FieldName:    "orders",
+				Service:      "shop.v1.OrderService",
+				Method:       "ListOrders",
+				Request:      `{"userId":"{{ .object.id }}"}`,
+				ResponsePath: []string{"orders"},
+			},
+		},
+	}
+
+	dataSources := []plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"order"}},
+				{TypeName: "Subscription", FieldNames: []string{"orderUpdates"}},
+				{TypeName: "User", FieldNames: []string{"orders"}},
+			},
+			ChildNodes: []plan.TypeField{
+				{TypeName: "Order", FieldNames: []string{"id", "quantity", "userId"}},
+			},
+			Factory: factory,
+			Custom:  ConfigJSON(config),
+		},
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"user"}},
+			},
+			ChildNodes: []plan.TypeField{
+				{TypeName: "User", FieldNames: []string{"id"}},
+			},
+			Factory: &staticdatasource.Factory{},
+			Custom:  staticdatasource.ConfigJSON(staticdatasource.Configuration{Data: `{"user":{"id":"u-1"}}`}),
+		},
+	}
+
+	planConfiguration := plan.Configuration{
+		DataSources:                  dataSources,
+		DisableResolveFieldPositions: true,
+	}
+
+	orderFields := []*resolve.Field{
+		{
+			Name:  []byte("id"),
+			Value: &resolve.Scalar{Path: []string{"id"}},
+		},
+		{
+			Name:  []byte("quantity"),
+			Value: &resolve.Integer{Path: []string{"quantity"}},
+		},
+	}
+
+	t.Run("unary root field", datasourcetesting.RunTest(testSchema, `
+		query Order($id: ID!) {
+			order(id: $id) {
+				id
+				quantity
+			}
+		}`, "Order",
+		&plan.SynchronousResponsePlan{
+			Response: &resolve.GraphQLResponse{
+				Data: &resolve.Object{
+					Fetch: &resolve.SingleFetch{
+						FetchConfiguration: resolve.FetchConfiguration{
+							Input: `{"method":"/shop.v1.OrderService/GetOrder","metadata":{"authorization":"$$1$$"},"request":{"id":$$0$$}}`,
+							Variables: resolve.NewVariables(
+								&resolve.ContextVariable{
+									Path:     []string{"id"},
+									Renderer: resolve.NewJSONVariableRendererWithValidation(`{"type":["string","integer"]}`),
+								},
+								&resolve.HeaderVariable{Path: []string{"Authorization"}},
+							),
+							DataSource: &Source{},
+							PostProcessing: resolve.PostProcessingConfiguration{
+								SelectResponseDataPath:   []string{"data"},
+								SelectResponseErrorsPath: []string{"errors"},
+								MergePath:                []string{"order"},
+							},
+						},
+						DataSourceIdentifier: []byte("grpc_datasource.Source"),
+					},
+					Fields: []*resolve.Field{
+						{
+							Name: []byte("order"),
+							Value: &resolve.Object{
+								Path:     []string{"order"},
+								Nullable: true,
+								Fields:   orderFields,
+							},
+						},
+					},
+				},
+			},
+		}, planConfiguration))
+
+	t.Run("server streaming subscription", datasourcetesting.RunTest(testSchema, `
+		subscription OrderUpdates {
+			orderUpdates(id: "o-1") {
+				id
+				quantity
+			}
+		}`, "OrderUpdates",
+		&plan.SubscriptionResponsePlan{
+			Response: &resolve.GraphQLSubscription{
+				Trigger: resolve.GraphQLSubscriptionTrigger{
+					Input: []byte(`{"method":"/shop.v1.OrderService/WatchOrder","metadata":null,"request":{"id":$$0$$}}`),
+					Variables: resolve.NewVariables(
+						&resolve.ContextVariable{
+							Path:     []string{"a"},
+							Renderer: resolve.NewJSONVariableRendererWithValidation(`{"type":["string","integer"]}`),
+						},
+					),
+					Source: &SubscriptionSource{},
+					PostProcessing: resolve.PostProcessingConfiguration{
+						SelectResponseDataPath:   []string{"data"},
+						SelectResponseErrorsPath: []string{"errors"},
+						MergePath:                []string{"orderUpdates"},
+					},
+				},
+				Response: &resolve.GraphQLResponse{
+					Data: &resolve.Object{
+						Fields: []*resolve.Field{
+							{
+								Name: []byte("orderUpdates"),
+								Value: &resolve.Object{
+									Path:     []string{"orderUpdates"},
+									Nullable: true,
+									Fields:   orderFields,
+								},
+							},
+						},
+					},
+				},
+			},
+		}, planConfiguration))
+
+	t.Run("entity field", datasourcetesting.RunTest(testSchema, `
+		query UserOrders {
+			user {
+				id
+				orders {
+					id
+					quantity
+				}
+			}
+		}`, "UserOrders",
+		&plan.SynchronousResponsePlan{
+			Response: &resolve.GraphQLResponse{
+				Data: &resolve.Object{
+					Fetch: &resolve.SingleFetch{
+						FetchConfiguration: resolve.FetchConfiguration{
+							Input:      `{"user":{"id":"u-1"}}`,
+							DataSource: staticdatasource.Source{},
+						},
+						DataSourceIdentifier: []byte("staticdatasource.Source"),
+					},
+					Fields: []*resolve.Field{
+						{
+							Name: []byte("user"),
+							Value: &resolve.Object{
+								Path:     []string{"user"},
+								Nullable: true,
+								Fetch: &resolve.SingleFetch{
+									FetchID: 1,
+									FetchConfiguration: resolve.FetchConfiguration{
+										Input: `{"method":"/shop.v1.OrderService/ListOrders","metadata":null,"request":{"userId":"$$0$$"}}`,
+										Variables: resolve.NewVariables(
+											&resolve.ObjectVariable{
+												Path:     []string{"id"},
+												Renderer: resolve.NewPlainVariableRenderer(),
+											},
+										),
+										DataSource: &Source{},
+										PostProcessing: resolve.PostProcessingConfiguration{
+											SelectResponseDataPath:   []string{"data", "orders"},
+											SelectResponseErrorsPath: []string{"errors"},
+											MergePath:                []string{"orders"},
+										},
+									},
+									DataSourceIdentifier: []byte("grpc_datasource.Source"),
+								},
+								Fields: []*resolve.Field{
+									{
+										Name:  []byte("id"),
+										Value: &resolve.Scalar{Path: []string{"id"}},
+									},
+									{
+										Name: []byte("orders"),
+										Value: &resolve.Array{
+											Path: []string{"orders"},
+											Item: &resolve.Object{
+												Fields: orderFields,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}, planConfiguration))
+}
@@ -0,0 +1,193 @@
+package grpc_datasource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/buger/jsonparser"
+	"github.com/cespare/xxhash/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+)
+
+var (
+	dataSourceName = []byte("grpc")
+)
+
+// methodPath is the path of a method used by gRPC, e.g. "/shop.v1.OrderService/GetOrder"
+func methodPath(method protoreflect.MethodDescriptor) string {
+	return fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+}
+
+// Source calls a unary method and writes {"data":<response>} or {"errors":[...]} if the call failed
+type Source struct {
+	conn   grpc.ClientConnInterface
+	method protoreflect.MethodDescriptor
+}
+
+func (s *Source) Load(ctx context.Context, input []byte, w io.Writer) error {
+	call, err := parseInput(ctx, input, s.method)
+	if err != nil {
+		return err
+	}
+	response := dynamicpb.NewMessage(s.method.Output())
+	if err = s.conn.Invoke(call.ctx, call.method, call.request, response); err != nil {
+		return writeError(w, err)
+	}
+	return writeData(w, response)
+}
+
+// SubscriptionSource calls a server streaming method and sends every message of the stream as an update
+type SubscriptionSource struct {
+	target string
+	conn   grpc.ClientConnInterface
+	method protoreflect.MethodDescriptor
+}
+
+func (s *SubscriptionSource) UniqueRequestID(ctx *resolve.Context, input []byte, xxh *xxhash.Digest) (err error) {
+	if _, err = xxh.Write(dataSourceName); err != nil {
+		return err
+	}
+	if _, err = xxh.WriteString(s.target); err != nil {
+		return err
+	}
+	_, err = xxh.Write(input)
+	return err
+}
+
+func (s *SubscriptionSource) Start(ctx *resolve.Context, input []byte, updater resolve.SubscriptionUpdater) error {
+	call, err := parseInput(ctx.Context(), input, s.method)
+	if err != nil {
+		return err
+	}
+	stream, err := s.conn.NewStream(call.ctx, &grpc.StreamDesc{ServerStreams: true}, call.method)
+	if err != nil {
+		return err
+	}
+	if err = stream.SendMsg(call.request); err != nil {
+		return err
+	}
+	if err = stream.CloseSend(); err != nil {
+		return err
+	}
+	go s.receive(ctx.Context(), stream, updater)
+	return nil
+}
+
+func (s *SubscriptionSource) receive(ctx context.Context, stream grpc.ClientStream, updater resolve.SubscriptionUpdater) {
+	for {
+		message := dynamicpb.NewMessage(s.method.Output())
+		err := stream.RecvMsg(message)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if !errors.Is(err, io.EOF) {
+				if data, ok := errorResponse(err); ok {
+					updater.Update(data)
+				}
+			}
+			updater.Done()
+			return
+		}
+		data, err := dataResponse(message)
+		if err != nil {
+			continue
+		}
+		updater.Update(data)
+	}
+}
+
+type call struct {
+	ctx     context.Context
+	method  string
+	request proto.Message
+}
+
+// parseInput creates the request message and the outgoing metadata of a call from the input
+func parseInput(ctx context.Context, input []byte, method protoreflect.MethodDescriptor) (*call, error) {
+	methodName, err := jsonparser.GetString(input, "method")
+	if err != nil {
+		return nil, fmt.Errorf("grpc: method missing in input: %w", err)
+	}
+	request := dynamicpb.NewMessage(method.Input())
+	requestJSON, _, _, err := jsonparser.Get(input, "request")
+	if err == nil {
+		if err = protojson.Unmarshal(requestJSON, request); err != nil {
+			return nil, fmt.Errorf("grpc: invalid request for %s: %w", methodName, err)
+		}
+	}
+	var md map[string]string
+	if value, _, _, err := jsonparser.Get(input, "metadata"); err == nil {
+		if err = json.Unmarshal(value, &md); err != nil {
+			return nil, fmt.Errorf("grpc: invalid metadata: %w", err)
+		}
+	}
+	if len(md) != 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(md))
+	}
+	return &call{ctx: ctx, method: methodName, request: request}, nil
+}
+
+var protoJSON = protojson.MarshalOptions{EmitUnpopulated: true}
+
+func dataResponse(message proto.Message) ([]byte, error) {
+	data, err := protoJSON.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	// protojson randomly adds whitespace to its output
+	out := bytes.NewBufferString(`{"data":`)
+	if err = json.Compact(out, data); err != nil {
+		return nil, err
+	}
+	out.WriteByte('}')
+	return out.Bytes(), nil
+}
+
+// errorResponse renders the status of a failed call as GraphQL error with the status code as extension
+func errorResponse(err error) ([]byte, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil, false
+	}
+	graphQLError := map[string]interface{}{
+		"message": st.Message(),
+		"extensions": map[string]interface{}{
+			"code": st.Code().String(),
+		},
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"errors": []interface{}{graphQLError},
+	})
+	return data, err == nil
+}
+
+func writeData(w io.Writer, message proto.Message) error {
+	data, err := dataResponse(message)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func writeError(w io.Writer, err error) error {
+	data, ok := errorResponse(err)
+	if !ok {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,299 @@
+package grpc_datasource
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+)
+
+func field(name, jsonName string, number int32, typ descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label, typeName string) *descriptorpb.FieldDescriptorProto {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(jsonName),
+		Number:   proto.Int32(number),
+		Type:     typ.Enum(),
+		Label:    label.Enum(),
+	}
+	if typeName != "" {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+// testDescriptorSet describes the order service:
+//
+//	service OrderService {
+//	  rpc GetOrder(GetOrderRequest) returns (Order);
+//	  rpc ListOrders(ListOrdersRequest) returns (ListOrdersResponse);
+//	  rpc WatchOrder(GetOrderRequest) returns (stream Order);
+//	}
+func testDescriptorSet(t *testing.T) []byte {
+	optional, repeated := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("orders.proto"),
+		Package: proto.String("shop.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Order"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("id", "id", 1, str, optional, ""),
+					field("quantity", "quantity", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32, optional, ""),
+					field("user_id", "userId", 3, str, optional, ""),
+				},
+			},
+			{
+				Name:  proto.String("GetOrderRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("id", "id", 1, str, optional, "")},
+			},
+			{
+				Name:  proto.String("ListOrdersRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("user_id", "userId", 1, str, optional, "")},
+			},
+			{
+				Name:  proto.String("ListOrdersResponse"),
+				Field: []*descriptorpb.FieldDescriptorProto{field("orders", "orders", 1, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, repeated, ".shop.v1.Order")},
+			},
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name: proto.String("OrderService"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{Name: proto.String("GetOrder"), InputType: proto.String(".shop.v1.GetOrderRequest"), OutputType: proto.String(".shop.v1.Order")},
+					{Name: proto.String("ListOrders"), InputType: proto.String(".shop.v1.ListOrdersRequest"), OutputType: proto.String(".shop.v1.ListOrdersResponse")},
+					{Name: proto.String("WatchOrder"), InputType: proto.String(".shop.v1.GetOrderRequest"), OutputType: proto.String(".shop.v1.Order"), ServerStreaming: proto.Bool(true)},
+				},
+			},
+		},
+	}
+	descriptorSet, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+	return descriptorSet
+}
+
+func testFiles(t *testing.T) *protoregistry.Files {
+	files, err := (&Configuration{DescriptorSet: testDescriptorSet(t)}).files()
+	require.NoError(t, err)
+	return files
+}
+
+func testMethod(t *testing.T, name string) protoreflect.MethodDescriptor {
+	method, err := findMethod(testFiles(t), &MethodConfiguration{Service: "shop.v1.OrderService", Method: name})
+	require.NoError(t, err)
+	return method
+}
+
+// orderService implements the order service with dynamic messages, without generated code
+type orderService struct {
+	files *protoregistry.Files
+	// metadata are the incoming metadata of the last call
+	metadata metadata.MD
+}
+
+func (o *orderService) order(id string, quantity int32) *dynamicpb.Message {
+	descriptor, _ := o.files.FindDescriptorByName("shop.v1.Order")
+	order := dynamicpb.NewMessage(descriptor.(protoreflect.MessageDescriptor))
+	order.Set(order.Descriptor().Fields().ByName("id"), protoreflect.ValueOfString(id))
+	order.Set(order.Descriptor().Fields().ByName("quantity"), protoreflect.ValueOfInt32(quantity))
+	order.Set(order.Descriptor().Fields().ByName("user_id"), protoreflect.ValueOfString("u-1"))
+	return order
+}
+
+func (o *orderService) request(name protoreflect.FullName) *dynamicpb.Message {
+	descriptor, _ := o.files.FindDescriptorByName(name)
+	return dynamicpb.NewMessage(descriptor.(protoreflect.MessageDescriptor))
+}
+
+func (o *orderService) getOrder(_ interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	o.metadata, _ = metadata.FromIncomingContext(ctx)
+	request := o.request("shop.v1.GetOrderRequest")
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	id := request.Get(request.Descriptor().Fields().ByName("id")).String()
+	if id != "o-1" {
+		return nil, status.Errorf(codes.NotFound, "order %s not found", id)
+	}
+	return o.order(id, 2), nil
+}
+
+func (o *orderService) listOrders(_ interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	request := o.request("shop.v1.ListOrdersRequest")
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	response := o.request("shop.v1.ListOrdersResponse")
+	orders := response.Mutable(response.Descriptor().Fields().ByName("orders")).List()
+	orders.Append(protoreflect.ValueOfMessage(o.order("o-1", 2)))
+	orders.Append(protoreflect.ValueOfMessage(o.order("o-2", 1)))
+	return response, nil
+}
+
+func (o *orderService) watchOrder(_ interface{}, stream grpc.ServerStream) error {
+	request := o.request("shop.v1.GetOrderRequest")
+	if err := stream.RecvMsg(request); err != nil {
+		return err
+	}
+	id := request.Get(request.Descriptor().Fields().ByName("id")).String()
+	if id != "o-1" {
+		return status.Errorf(codes.NotFound, "order %s not found", id)
+	}
+	for quantity := int32(1); quantity <= 3; quantity++ {
+		if err := stream.SendMsg(o.order(id, quantity)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startOrderService serves the order service in-process, the returned dial options connect to it
+func startOrderService(t *testing.T) (*orderService, []grpc.DialOption) {
+	service := &orderService{files: testFiles(t)}
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "shop.v1.OrderService",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{
+			{MethodName: "GetOrder", Handler: service.getOrder},
+			{MethodName: "ListOrders", Handler: service.listOrders},
+		},
+		Streams: []grpc.StreamDesc{
+			{StreamName: "WatchOrder", Handler: service.watchOrder, ServerStreams: true},
+		},
+	}, service)
+
+	listener := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	return service, []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+	}
+}
+
+func testConn(t *testing.T, options []grpc.DialOption) grpc.ClientConnInterface {
+	conn, err := grpc.NewClient("passthrough:///orders", options...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func TestSource_Load(t *testing.T) {
+	service, options := startOrderService(t)
+	conn := testConn(t, options)
+
+	t.Run("unary call", func(t *testing.T) {
+		source := &Source{conn: conn, method: testMethod(t, "GetOrder")}
+		buf := &bytes.Buffer{}
+		err := source.Load(context.Background(), []byte(`{"method":"/shop.v1.OrderService/GetOrder","metadata":{"authorization":"Bearer token"},"request":{"id":"o-1"}}`), buf)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"id":"o-1","quantity":2,"userId":"u-1"}}`, buf.String())
+		assert.Equal(t, []string{"Bearer token"}, service.metadata.Get("authorization"))
+	})
+
+	t.Run("repeated fields", func(t *testing.T) {
+		source := &Source{conn: conn, method: testMethod(t, "ListOrders")}
+		buf := &bytes.Buffer{}
+		err := source.Load(context.Background(), []byte(`{"method":"/shop.v1.OrderService/ListOrders","metadata":null,"request":{"userId":"u-1"}}`), buf)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"orders":[{"id":"o-1","quantity":2,"userId":"u-1"},{"id":"o-2","quantity":1,"userId":"u-1"}]}}`, buf.String())
+	})
+
+	t.Run("status error", func(t *testing.T) {
+		source := &Source{conn: conn, method: testMethod(t, "GetOrder")}
+		buf := &bytes.Buffer{}
+		err := source.Load(context.Background(), []byte(`{"method":"/shop.v1.OrderService/GetOrder","metadata":null,"request":{"id":"o-2"}}`), buf)
+		require.NoError(t, err)
+		assert.Equal(t, `{"errors":[{"extensions":{"code":"NotFound"},"message":"order o-2 not found"}]}`, buf.String())
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		source := &Source{conn: conn, method: testMethod(t, "GetOrder")}
+		err := source.Load(context.Background(), []byte(`{"method":"/shop.v1.OrderService/GetOrder","metadata":null,"request":{"orderId":"o-1"}}`), &bytes.Buffer{})
+		assert.ErrorContains(t, err, "grpc: invalid request for /shop.v1.OrderService/GetOrder")
+	})
+}
+
+type testSubscriptionUpdater struct {
+	updates chan string
+	done    chan struct{}
+}
+
+func (t *testSubscriptionUpdater) Update(data []byte) {
+	t.updates <- string(data)
+}
+
+func (t *testSubscriptionUpdater) Done() {
+	close(t.done)
+}
+
+func (t *testSubscriptionUpdater) awaitUpdates(tt *testing.T, count int) []string {
+	tt.Helper()
+	updates := make([]string, 0, count)
+	for len(updates) < count {
+		select {
+		case update := <-t.updates:
+			updates = append(updates, update)
+		case <-time.After(time.Second):
+			tt.Fatalf("received %d of %d updates", len(updates), count)
+		}
+	}
+	select {
+	case <-t.done:
+	case <-time.After(time.Second):
+		tt.Fatal("subscription not done")
+	}
+	return updates
+}
+
+func TestSubscriptionSource_Start(t *testing.T) {
+	_, options := startOrderService(t)
+	conn := testConn(t, options)
+	source := &SubscriptionSource{target: "orders", conn: conn, method: testMethod(t, "WatchOrder")}
+
+	t.Run("server stream", func(t *testing.T) {
+		updater := &testSubscriptionUpdater{updates: make(chan string, 3), done: make(chan struct{})}
+		err := source.Start(resolve.NewContext(context.Background()), []byte(`{"method":"/shop.v1.OrderService/WatchOrder","metadata":null,"request":{"id":"o-1"}}`), updater)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			`{"data":{"id":"o-1","quantity":1,"userId":"u-1"}}`,
+			`{"data":{"id":"o-1","quantity":2,"userId":"u-1"}}`,
+			`{"data":{"id":"o-1","quantity":3,"userId":"u-1"}}`,
+		}, updater.awaitUpdates(t, 3))
+	})
+
+	t.Run("status error", func(t *testing.T) {
+		updater := &testSubscriptionUpdater{updates: make(chan string, 1), done: make(chan struct{})}
+		err := source.Start(resolve.NewContext(context.Background()), []byte(`{"method":"/shop.v1.OrderService/WatchOrder","metadata":null,"request":{"id":"o-2"}}`), updater)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			`{"errors":[{"extensions":{"code":"NotFound"},"message":"order o-2 not found"}]}`,
+		}, updater.awaitUpdates(t, 1))
+	})
+}
@@ -29,6 +29,9 @@ const (
 	SCHEME                                      = "scheme"
 	HOST                                        = "host"
 	UNNULL_VARIABLES                            = "unnull_variables"
+	PERSISTED_QUERY                             = "persisted_query"
+	USE_GET_FOR_HASHED_QUERY                    = "use_get_for_hashed_query"
+	BATCH_REQUEST                               = "batch_request"
 	UNDEFINED_VARIABLES                         = "undefined"
 	FORWARDED_CLIENT_HEADER_NAMES               = "forwarded_client_header_names"
 	FORWARDED_CLIENT_HEADER_REGULAR_EXPRESSIONS = "forwarded_client_header_regular_expressions"
@@ -13,6 +13,7 @@ import (
 	"github.com/andybalholm/brotli"
 	"github.com/stretchr/testify/assert"
 	"github.com/tidwall/sjson"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/TykTechnologies/graphql-go-tools/v2/internal/pkg/quotes"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer/literal"
@@ -239,4 +240,53 @@ func TestHttpClientDo(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Contains(t, out.String(), `"Authorization":["****"]`)
 	})
+
+	t.Run("response headers observer", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", "max-age=60")
+			_, err := w.Write([]byte("ok"))
+			assert.NoError(t, err)
+		}))
+		defer server.Close()
+		var input []byte
+		input = SetInputMethod(input, []byte("GET"))
+		input = SetInputURL(input, []byte(server.URL))
+
+		var observed http.Header
+		ctx := WithResponseHeadersObserver(background, func(header http.Header) {
+			observed = header
+		})
+		t.Run("net", runTest(ctx, input, `ok`))
+		assert.Equal(t, "max-age=60", observed.Get("Cache-Control"))
+
+		var chained http.Header
+		observed = nil
+		ctx = WithResponseHeadersObserver(ctx, func(header http.Header) {
+			chained = header
+		})
+		t.Run("chained", runTest(ctx, input, `ok`))
+		assert.Equal(t, "max-age=60", observed.Get("Cache-Control"))
+		assert.Equal(t, "max-age=60", chained.Get("Cache-Control"))
+	})
+
+	t.Run("trace context propagation", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(r.Header.Get("traceparent")))
+			assert.NoError(t, err)
+		}))
+		defer server.Close()
+		var input []byte
+		input = SetInputMethod(input, []byte("GET"))
+		input = SetInputURL(input, []byte(server.URL))
+
+		t.Run("without span", runTest(background, input, ``))
+
+		spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+			SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+			TraceFlags: trace.FlagsSampled,
+		})
+		ctx := trace.ContextWithSpanContext(background, spanContext)
+		t.Run("with span", runTest(ctx, input, `00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01`))
+	})
 }
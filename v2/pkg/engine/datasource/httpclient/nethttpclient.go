@@ -15,6 +15,7 @@ import (
 
 	"github.com/andybalholm/brotli"
 	"github.com/buger/jsonparser"
+	"go.opentelemetry.io/otel/propagation"
 
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer/literal"
 )
@@ -46,6 +47,39 @@ var (
 	}
 )
 
+// ResponseHeadersObserver is called with the headers of every upstream response received by Do.
+// It might be called concurrently for the fetches of a single operation.
+type ResponseHeadersObserver func(header http.Header)
+
+type responseHeadersObserverKey struct{}
+
+// WithResponseHeadersObserver returns a context which makes Do report the headers of upstream responses to the observer.
+// Observers already set on ctx keep being called.
+func WithResponseHeadersObserver(ctx context.Context, observer ResponseHeadersObserver) context.Context {
+	if parent, ok := ctx.Value(responseHeadersObserverKey{}).(ResponseHeadersObserver); ok {
+		next := observer
+		observer = func(header http.Header) {
+			parent(header)
+			next(header)
+		}
+	}
+	return context.WithValue(ctx, responseHeadersObserverKey{}, observer)
+}
+
+// ObserveResponseHeaders reports the headers of an upstream response to the observer of ctx, if any.
+// It's used by clients sending the request of ctx on another context, e.g. in a batch.
+func ObserveResponseHeaders(ctx context.Context, header http.Header) {
+	if observer, ok := ctx.Value(responseHeadersObserverKey{}).(ResponseHeadersObserver); ok {
+		observer(header)
+	}
+}
+
+// InjectTraceContext adds the W3C trace context headers of the span in ctx to the header.
+// It's a noop if ctx carries no valid span context.
+func InjectTraceContext(ctx context.Context, header http.Header) {
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
 type TraceHTTP struct {
 	Request  TraceHTTPRequest  `json:"request"`
 	Response TraceHTTPResponse `json:"response"`
@@ -126,6 +160,7 @@ func Do(client *http.Client, ctx context.Context, requestInput []byte, out io.Wr
 	request.Header.Set(AcceptEncodingHeader, EncodingGzip)
 	request.Header.Add(AcceptEncodingHeader, EncodingDeflate)
 	request.Header.Add(AcceptEncodingHeader, EncodingBrotli)
+	InjectTraceContext(ctx, request.Header)
 
 	response, err := client.Do(request)
 	if err != nil {
@@ -133,6 +168,8 @@ func Do(client *http.Client, ctx context.Context, requestInput []byte, out io.Wr
 	}
 	defer response.Body.Close()
 
+	ObserveResponseHeaders(ctx, response.Header)
+
 	respReader, err := respBodyReader(response)
 	if err != nil {
 		return err
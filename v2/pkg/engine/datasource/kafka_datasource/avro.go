@@ -0,0 +1,360 @@
+package kafka_datasource
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// avroSchema is a parsed Avro schema, named types referenced by other types share the same *avroSchema
+type avroSchema struct {
+	typ      string
+	name     string
+	fields   []avroField
+	symbols  []string
+	items    *avroSchema
+	values   *avroSchema
+	branches []*avroSchema
+	size     int
+}
+
+type avroField struct {
+	name   string
+	schema *avroSchema
+}
+
+var avroPrimitiveTypes = map[string]bool{
+	"null": true, "boolean": true, "int": true, "long": true, "float": true, "double": true, "bytes": true, "string": true,
+}
+
+// parseAvroSchema parses the JSON representation of an Avro schema
+func parseAvroSchema(data []byte) (*avroSchema, error) {
+	var schema any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("invalid avro schema: %w", err)
+	}
+	parser := &avroSchemaParser{named: map[string]*avroSchema{}}
+	return parser.parse(schema, "")
+}
+
+type avroSchemaParser struct {
+	named map[string]*avroSchema
+}
+
+func (p *avroSchemaParser) parse(schema any, namespace string) (*avroSchema, error) {
+	switch schema := schema.(type) {
+	case string:
+		return p.reference(schema, namespace)
+	case []any:
+		union := &avroSchema{typ: "union"}
+		for _, branch := range schema {
+			parsed, err := p.parse(branch, namespace)
+			if err != nil {
+				return nil, err
+			}
+			union.branches = append(union.branches, parsed)
+		}
+		return union, nil
+	case map[string]any:
+		return p.parseObject(schema, namespace)
+	default:
+		return nil, fmt.Errorf("invalid avro schema: unexpected %v", schema)
+	}
+}
+
+func (p *avroSchemaParser) reference(name, namespace string) (*avroSchema, error) {
+	if avroPrimitiveTypes[name] {
+		return &avroSchema{typ: name}, nil
+	}
+	if !strings.Contains(name, ".") && namespace != "" {
+		if schema, ok := p.named[namespace+"."+name]; ok {
+			return schema, nil
+		}
+	}
+	if schema, ok := p.named[name]; ok {
+		return schema, nil
+	}
+	return nil, fmt.Errorf("invalid avro schema: unknown type %s", name)
+}
+
+func (p *avroSchemaParser) parseObject(schema map[string]any, namespace string) (*avroSchema, error) {
+	typ, ok := schema["type"].(string)
+	if !ok {
+		// e.g. {"type": {"type": "array", "items": "string"}}
+		return p.parse(schema["type"], namespace)
+	}
+
+	switch typ {
+	case "record", "error", "enum", "fixed":
+		parsed := &avroSchema{typ: typ}
+		if err := p.register(parsed, schema, &namespace); err != nil {
+			return nil, err
+		}
+		return parsed, p.parseNamed(parsed, schema, namespace)
+	case "array":
+		items, err := p.parse(schema["items"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &avroSchema{typ: typ, items: items}, nil
+	case "map":
+		values, err := p.parse(schema["values"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &avroSchema{typ: typ, values: values}, nil
+	default:
+		// primitive types with attributes, e.g. logical types
+		return p.reference(typ, namespace)
+	}
+}
+
+// register adds the named type before its fields are parsed, so that recursive types can reference it
+func (p *avroSchemaParser) register(schema *avroSchema, definition map[string]any, namespace *string) error {
+	name, _ := definition["name"].(string)
+	if name == "" {
+		return fmt.Errorf("invalid avro schema: %s without name", schema.typ)
+	}
+	if ns, ok := definition["namespace"].(string); ok {
+		*namespace = ns
+	}
+	switch {
+	case strings.Contains(name, "."):
+		*namespace = name[:strings.LastIndex(name, ".")]
+	case *namespace != "":
+		name = *namespace + "." + name
+	}
+	schema.name = name
+	p.named[name] = schema
+	return nil
+}
+
+func (p *avroSchemaParser) parseNamed(schema *avroSchema, definition map[string]any, namespace string) error {
+	switch schema.typ {
+	case "record", "error":
+		fields, _ := definition["fields"].([]any)
+		for _, field := range fields {
+			field, ok := field.(map[string]any)
+			if !ok {
+				return fmt.Errorf("invalid avro schema: invalid field of %s", schema.name)
+			}
+			name, _ := field["name"].(string)
+			fieldSchema, err := p.parse(field["type"], namespace)
+			if err != nil {
+				return err
+			}
+			schema.fields = append(schema.fields, avroField{name: name, schema: fieldSchema})
+		}
+	case "enum":
+		symbols, _ := definition["symbols"].([]any)
+		for _, symbol := range symbols {
+			symbol, _ := symbol.(string)
+			schema.symbols = append(schema.symbols, symbol)
+		}
+	case "fixed":
+		size, _ := definition["size"].(float64)
+		schema.size = int(size)
+	}
+	return nil
+}
+
+// decodeAvro decodes a value in the Avro binary encoding into JSON.
+// Unions are decoded into the value of their branch, bytes and fixed values into base64 strings.
+func decodeAvro(schema *avroSchema, data []byte) ([]byte, error) {
+	decoder := &avroDecoder{data: data}
+	if err := decoder.decode(schema); err != nil {
+		return nil, err
+	}
+	return decoder.out.Bytes(), nil
+}
+
+type avroDecoder struct {
+	data []byte
+	pos  int
+	out  bytes.Buffer
+	// items counts the decoded items of all arrays and maps
+	items int64
+}
+
+func (d *avroDecoder) decode(schema *avroSchema) error {
+	switch schema.typ {
+	case "null":
+		d.out.WriteString("null")
+	case "boolean":
+		b, err := d.read(1)
+		if err != nil {
+			return err
+		}
+		d.out.WriteString(strconv.FormatBool(b[0] != 0))
+	case "int", "long":
+		value, err := d.long()
+		if err != nil {
+			return err
+		}
+		d.out.WriteString(strconv.FormatInt(value, 10))
+	case "float":
+		b, err := d.read(4)
+		if err != nil {
+			return err
+		}
+		d.writeFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), 32)
+	case "double":
+		b, err := d.read(8)
+		if err != nil {
+			return err
+		}
+		d.writeFloat(math.Float64frombits(binary.LittleEndian.Uint64(b)), 64)
+	case "bytes", "string":
+		size, err := d.long()
+		if err != nil {
+			return err
+		}
+		b, err := d.read(int(size))
+		if err != nil {
+			return err
+		}
+		if schema.typ == "bytes" {
+			d.writeString(base64.StdEncoding.EncodeToString(b))
+		} else {
+			d.writeString(string(b))
+		}
+	case "fixed":
+		b, err := d.read(schema.size)
+		if err != nil {
+			return err
+		}
+		d.writeString(base64.StdEncoding.EncodeToString(b))
+	case "enum":
+		index, err := d.long()
+		if err != nil {
+			return err
+		}
+		if index < 0 || int(index) >= len(schema.symbols) {
+			return fmt.Errorf("avro: invalid symbol %d of enum %s", index, schema.name)
+		}
+		d.writeString(schema.symbols[index])
+	case "union":
+		index, err := d.long()
+		if err != nil {
+			return err
+		}
+		if index < 0 || int(index) >= len(schema.branches) {
+			return fmt.Errorf("avro: invalid union branch %d", index)
+		}
+		return d.decode(schema.branches[index])
+	case "record", "error":
+		d.out.WriteByte('{')
+		for i, field := range schema.fields {
+			if i > 0 {
+				d.out.WriteByte(',')
+			}
+			d.writeString(field.name)
+			d.out.WriteByte(':')
+			if err := d.decode(field.schema); err != nil {
+				return err
+			}
+		}
+		d.out.WriteByte('}')
+	case "array":
+		d.out.WriteByte('[')
+		err := d.blocks(func(i int) error {
+			if i > 0 {
+				d.out.WriteByte(',')
+			}
+			return d.decode(schema.items)
+		})
+		if err != nil {
+			return err
+		}
+		d.out.WriteByte(']')
+	case "map":
+		d.out.WriteByte('{')
+		err := d.blocks(func(i int) error {
+			if i > 0 {
+				d.out.WriteByte(',')
+			}
+			if err := d.decode(&avroSchema{typ: "string"}); err != nil {
+				return err
+			}
+			d.out.WriteByte(':')
+			return d.decode(schema.values)
+		})
+		if err != nil {
+			return err
+		}
+		d.out.WriteByte('}')
+	default:
+		return fmt.Errorf("avro: unsupported type %s", schema.typ)
+	}
+	return nil
+}
+
+// blocks reads the items of arrays and maps, they are encoded as blocks prefixed by their item count.
+// A negative count is followed by the size of the block in bytes.
+func (d *avroDecoder) blocks(item func(i int) error) error {
+	i := 0
+	for {
+		count, err := d.long()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+		if count < 0 {
+			count = -count
+			if _, err = d.long(); err != nil {
+				return err
+			}
+		}
+		// items of type null take no bytes, so the count of items is limited by the size of the data
+		// to prevent a small message from declaring a huge amount of items
+		if count < 0 || count > int64(len(d.data))-d.items {
+			return fmt.Errorf("avro: invalid block count %d at offset %d", count, d.pos)
+		}
+		d.items += count
+		for ; count > 0; count-- {
+			if err = item(i); err != nil {
+				return err
+			}
+			i++
+		}
+	}
+}
+
+// long reads a zigzag encoded variable-length integer
+func (d *avroDecoder) long() (int64, error) {
+	value, n := binary.Varint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("avro: invalid varint at offset %d", d.pos)
+	}
+	d.pos += n
+	return value, nil
+}
+
+func (d *avroDecoder) read(n int) ([]byte, error) {
+	if n < 0 || n > len(d.data)-d.pos {
+		return nil, fmt.Errorf("avro: unexpected end of data at offset %d", d.pos)
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *avroDecoder) writeString(s string) {
+	encoded, _ := json.Marshal(s)
+	d.out.Write(encoded)
+}
+
+func (d *avroDecoder) writeFloat(f float64, bitSize int) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		d.out.WriteString("null")
+		return
+	}
+	d.out.WriteString(strconv.FormatFloat(f, 'g', -1, bitSize))
+}
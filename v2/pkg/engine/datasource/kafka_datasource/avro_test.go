@@ -0,0 +1,152 @@
+package kafka_datasource
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAvroOrderSchema = `{
+	"type": "record",
+	"name": "Order",
+	"namespace": "shop",
+	"fields": [
+		{"name": "id", "type": "long"},
+		{"name": "paid", "type": "boolean"},
+		{"name": "total", "type": "double"},
+		{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["OPEN", "SHIPPED"]}},
+		{"name": "note", "type": ["null", "string"]},
+		{"name": "items", "type": {"type": "array", "items": {
+			"type": "record", "name": "Item", "fields": [
+				{"name": "sku", "type": "string"},
+				{"name": "quantity", "type": "int"}
+			]
+		}}},
+		{"name": "attributes", "type": {"type": "map", "values": "string"}},
+		{"name": "checksum", "type": {"type": "fixed", "name": "MD5", "size": 2}},
+		{"name": "previous", "type": ["null", "shop.Order"]},
+		{"name": "createdAt", "type": {"type": "long", "logicalType": "timestamp-millis"}}
+	]
+}`
+
+func avroLong(data []byte, value int64) []byte {
+	return binary.AppendVarint(data, value)
+}
+
+func avroString(data []byte, value string) []byte {
+	return append(avroLong(data, int64(len(value))), value...)
+}
+
+func avroDouble(data []byte, value float64) []byte {
+	return binary.LittleEndian.AppendUint64(data, math.Float64bits(value))
+}
+
+// testAvroOrder encodes an order with a previous order in the Avro binary encoding
+func testAvroOrder() []byte {
+	var data []byte
+	data = avroLong(data, 1)      // id
+	data = append(data, 1)        // paid
+	data = avroDouble(data, 12.5) // total
+	data = avroLong(data, 1)      // status: SHIPPED
+	data = avroLong(data, 1)      // note: string branch
+	data = avroString(data, "leave at the door")
+	// items: one block with a negative count followed by its size, then the end of the array
+	item := avroLong(avroString(nil, "A-1"), 2)
+	data = avroLong(data, -1)
+	data = avroLong(data, int64(len(item)))
+	data = append(data, item...)
+	data = avroLong(data, 0)
+	// attributes
+	data = avroLong(data, 1)
+	data = avroString(data, "channel")
+	data = avroString(data, "web")
+	data = avroLong(data, 0)
+	data = append(data, 0xAB, 0xCD) // checksum
+	data = avroLong(data, 1)        // previous: Order branch
+
+	data = avroLong(data, -7)   // id
+	data = append(data, 0)      // paid
+	data = avroDouble(data, 0)  // total
+	data = avroLong(data, 0)    // status: OPEN
+	data = avroLong(data, 0)    // note: null branch
+	data = avroLong(data, 0)    // items
+	data = avroLong(data, 0)    // attributes
+	data = append(data, 0, 0)   // checksum
+	data = avroLong(data, 0)    // previous: null branch
+	data = avroLong(data, 1000) // createdAt
+	return avroLong(data, 2000) // createdAt
+}
+
+func TestDecodeAvro(t *testing.T) {
+	schema, err := parseAvroSchema([]byte(testAvroOrderSchema))
+	require.NoError(t, err)
+
+	t.Run("record", func(t *testing.T) {
+		data, err := decodeAvro(schema, testAvroOrder())
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"id": 1, "paid": true, "total": 12.5, "status": "SHIPPED", "note": "leave at the door",
+			"items": [{"sku": "A-1", "quantity": 2}], "attributes": {"channel": "web"}, "checksum": "q80=",
+			"previous": {
+				"id": -7, "paid": false, "total": 0, "status": "OPEN", "note": null,
+				"items": [], "attributes": {}, "checksum": "AAA=", "previous": null, "createdAt": 1000
+			},
+			"createdAt": 2000
+		}`, string(data))
+	})
+
+	t.Run("truncated data", func(t *testing.T) {
+		data := testAvroOrder()
+		_, err := decodeAvro(schema, data[:len(data)/2])
+		assert.Error(t, err)
+	})
+
+	t.Run("string length overflowing the offset", func(t *testing.T) {
+		str, err := parseAvroSchema([]byte(`"string"`))
+		require.NoError(t, err)
+		_, err = decodeAvro(str, avroLong(nil, math.MaxInt64-1))
+		assert.EqualError(t, err, "avro: unexpected end of data at offset 10")
+	})
+
+	t.Run("block count exceeding the data", func(t *testing.T) {
+		nulls, err := parseAvroSchema([]byte(`{"type": "array", "items": "null"}`))
+		require.NoError(t, err)
+		_, err = decodeAvro(nulls, avroLong(nil, math.MaxInt64))
+		assert.EqualError(t, err, "avro: invalid block count 9223372036854775807 at offset 10")
+
+		nested, err := parseAvroSchema([]byte(`{"type": "array", "items": {"type": "array", "items": "null"}}`))
+		require.NoError(t, err)
+		data := avroLong(nil, 3)
+		for i := 0; i < 3; i++ {
+			data = avroLong(avroLong(data, 3), 0)
+		}
+		data = avroLong(data, 0)
+		_, err = decodeAvro(nested, data)
+		assert.ErrorContains(t, err, "avro: invalid block count")
+	})
+
+	t.Run("invalid enum symbol", func(t *testing.T) {
+		enum, err := parseAvroSchema([]byte(`{"type": "enum", "name": "Status", "symbols": ["OPEN"]}`))
+		require.NoError(t, err)
+		_, err = decodeAvro(enum, avroLong(nil, 3))
+		assert.EqualError(t, err, "avro: invalid symbol 3 of enum Status")
+	})
+}
+
+func TestParseAvroSchema(t *testing.T) {
+	t.Run("unknown type", func(t *testing.T) {
+		_, err := parseAvroSchema([]byte(`{"type": "record", "name": "Order", "fields": [{"name": "item", "type": "Item"}]}`))
+		assert.EqualError(t, err, "invalid avro schema: unknown type Item")
+	})
+
+	t.Run("primitive", func(t *testing.T) {
+		schema, err := parseAvroSchema([]byte(`"string"`))
+		require.NoError(t, err)
+		data, err := decodeAvro(schema, avroString(nil, `"quoted"`))
+		require.NoError(t, err)
+		assert.Equal(t, `"\"quoted\""`, string(data))
+	})
+}
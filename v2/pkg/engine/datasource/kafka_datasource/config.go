@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/IBM/sarama"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/metrics"
 )
 
 const (
@@ -21,6 +23,12 @@ const (
 
 const DefaultBalanceStrategy = BalanceStrategyRange
 
+const (
+	MessageFormatJSON     = "json"
+	MessageFormatAvro     = "avro"
+	MessageFormatProtobuf = "protobuf"
+)
+
 var (
 	DefaultKafkaVersion          = "V1_0_0_0"
 	SaramaSupportedKafkaVersions = map[string]sarama.KafkaVersion{
@@ -56,6 +64,41 @@ type SASL struct {
 	Password string `json:"password"`
 }
 
+// MessageDecoder configures the decoding of Avro or Protobuf encoded messages into JSON.
+// Schemas are loaded from SchemaFile or, for messages in the Confluent wire format, from a schema registry.
+type MessageDecoder struct {
+	// Format is one of MessageFormatJSON, MessageFormatAvro or MessageFormatProtobuf
+	Format string `json:"format"`
+	// SchemaFile is the path of the Avro schema (.avsc) or of the Protobuf file descriptor set (protoc --descriptor_set_out)
+	SchemaFile string `json:"schema_file,omitempty"`
+	// SchemaRegistryURL is the URL of a Confluent compatible schema registry. If set, messages are expected in the
+	// Confluent wire format: Avro schemas are fetched by the schema id of the message.
+	// The registry serves Protobuf schemas as .proto sources, so Protobuf messages are always decoded with SchemaFile.
+	SchemaRegistryURL string `json:"schema_registry_url,omitempty"`
+	// MessageType is the fully qualified name of the Protobuf message, e.g. shop.v1.Order
+	MessageType string `json:"message_type,omitempty"`
+}
+
+func (m *MessageDecoder) Validate() error {
+	switch m.Format {
+	case MessageFormatJSON:
+	case MessageFormatAvro:
+		if m.SchemaFile == "" && m.SchemaRegistryURL == "" {
+			return fmt.Errorf("value_decoder.schema_file or value_decoder.schema_registry_url is required for avro")
+		}
+	case MessageFormatProtobuf:
+		switch {
+		case m.SchemaFile == "":
+			return fmt.Errorf("value_decoder.schema_file is required for protobuf")
+		case m.MessageType == "":
+			return fmt.Errorf("value_decoder.message_type is required for protobuf")
+		}
+	default:
+		return fmt.Errorf("value_decoder.format is invalid: %s", m.Format)
+	}
+	return nil
+}
+
 type GraphQLSubscriptionOptions struct {
 	BrokerAddresses      []string `json:"broker_addresses"`
 	Topics               []string `json:"topics"`
@@ -66,7 +109,17 @@ type GraphQLSubscriptionOptions struct {
 	BalanceStrategy      string   `json:"balance_strategy"`
 	IsolationLevel       string   `json:"isolation_level"`
 	SASL                 SASL     `json:"sasl"`
-	startedCallback      func()
+	// StartTimestamp starts consuming at the first message produced at or after the timestamp (Unix milliseconds),
+	// it overrides the committed offsets of the consumer group.
+	StartTimestamp int64 `json:"start_timestamp,omitempty"`
+	// ValueDecoder decodes the message values, they are forwarded as JSON if it's not set
+	ValueDecoder *MessageDecoder `json:"value_decoder,omitempty"`
+	// EnvelopeField wraps every message into an object with the fields key, value, headers, topic, partition, offset
+	// and timestamp. The object is set to this field of the response data, usually the name of the subscription root field.
+	EnvelopeField string `json:"envelope_field,omitempty"`
+
+	startedCallback func()
+	metrics         metrics.Metrics
 }
 
 func (g *GraphQLSubscriptionOptions) Sanitize() {
@@ -112,6 +165,19 @@ func (g *GraphQLSubscriptionOptions) Validate() error {
 		return fmt.Errorf("isolation_level is invalid: %s", g.IsolationLevel)
 	}
 
+	if g.StartTimestamp < 0 {
+		return fmt.Errorf("start_timestamp cannot be negative")
+	}
+	if g.StartTimestamp != 0 && g.StartConsumingLatest {
+		return fmt.Errorf("start_timestamp and start_consuming_latest cannot be used together")
+	}
+
+	if g.ValueDecoder != nil {
+		if err := g.ValueDecoder.Validate(); err != nil {
+			return err
+		}
+	}
+
 	if g.SASL.Enable {
 		switch {
 		case g.SASL.User == "":
@@ -134,6 +200,14 @@ type SubscriptionConfiguration struct {
 	BalanceStrategy      string   `json:"balance_strategy"`
 	IsolationLevel       string   `json:"isolation_level"`
 	SASL                 SASL     `json:"sasl"`
+	// StartTimestamp starts consuming at the first message produced at or after the timestamp (Unix milliseconds),
+	// it overrides the committed offsets of the consumer group.
+	StartTimestamp int64 `json:"start_timestamp,omitempty"`
+	// ValueDecoder decodes the message values, they are forwarded as JSON if it's not set
+	ValueDecoder *MessageDecoder `json:"value_decoder,omitempty"`
+	// EnvelopeField wraps every message into an object with the fields key, value, headers, topic, partition, offset
+	// and timestamp. The object is set to this field of the response data, usually the name of the subscription root field.
+	EnvelopeField string `json:"envelope_field,omitempty"`
 }
 
 type Configuration struct {
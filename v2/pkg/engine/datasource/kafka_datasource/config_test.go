@@ -146,4 +146,70 @@ func TestConfig_GraphQLSubscriptionOptions(t *testing.T) {
 		err := g.Validate()
 		require.NoError(t, err)
 	})
+
+	t.Run("start_timestamp and start_consuming_latest not allowed together", func(t *testing.T) {
+		g := &GraphQLSubscriptionOptions{
+			BrokerAddresses:      []string{"localhost:9092"},
+			Topics:               []string{"foobar"},
+			GroupID:              "groupid",
+			ClientID:             "clientid",
+			StartConsumingLatest: true,
+			StartTimestamp:       1700000000000,
+		}
+		g.Sanitize()
+		err := g.Validate()
+		require.Equal(t, err.Error(), "start_timestamp and start_consuming_latest cannot be used together")
+	})
+
+	t.Run("Invalid value_decoder configuration - unknown format", func(t *testing.T) {
+		g := &GraphQLSubscriptionOptions{
+			BrokerAddresses: []string{"localhost:9092"},
+			Topics:          []string{"foobar"},
+			GroupID:         "groupid",
+			ClientID:        "clientid",
+			ValueDecoder:    &MessageDecoder{Format: "xml"},
+		}
+		g.Sanitize()
+		err := g.Validate()
+		require.Equal(t, err.Error(), "value_decoder.format is invalid: xml")
+	})
+
+	t.Run("Invalid value_decoder configuration - avro schema missing", func(t *testing.T) {
+		g := &GraphQLSubscriptionOptions{
+			BrokerAddresses: []string{"localhost:9092"},
+			Topics:          []string{"foobar"},
+			GroupID:         "groupid",
+			ClientID:        "clientid",
+			ValueDecoder:    &MessageDecoder{Format: MessageFormatAvro},
+		}
+		g.Sanitize()
+		err := g.Validate()
+		require.Equal(t, err.Error(), "value_decoder.schema_file or value_decoder.schema_registry_url is required for avro")
+	})
+
+	t.Run("Invalid value_decoder configuration - protobuf message_type missing", func(t *testing.T) {
+		g := &GraphQLSubscriptionOptions{
+			BrokerAddresses: []string{"localhost:9092"},
+			Topics:          []string{"foobar"},
+			GroupID:         "groupid",
+			ClientID:        "clientid",
+			ValueDecoder:    &MessageDecoder{Format: MessageFormatProtobuf, SchemaFile: "order.pb"},
+		}
+		g.Sanitize()
+		err := g.Validate()
+		require.Equal(t, err.Error(), "value_decoder.message_type is required for protobuf")
+	})
+
+	t.Run("Valid value_decoder configuration", func(t *testing.T) {
+		g := &GraphQLSubscriptionOptions{
+			BrokerAddresses: []string{"localhost:9092"},
+			Topics:          []string{"foobar"},
+			GroupID:         "groupid",
+			ClientID:        "clientid",
+			ValueDecoder:    &MessageDecoder{Format: MessageFormatAvro, SchemaRegistryURL: "http://localhost:8081"},
+		}
+		g.Sanitize()
+		err := g.Validate()
+		require.NoError(t, err)
+	})
 }
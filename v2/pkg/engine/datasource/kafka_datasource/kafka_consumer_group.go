@@ -2,6 +2,9 @@ package kafka_datasource
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -11,16 +14,21 @@ import (
 	log "github.com/jensneuse/abstractlogger"
 
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/metrics"
 )
 
 const consumerGroupRetryInterval = time.Second
 
 type KafkaConsumerGroupBridge struct {
-	log log.Logger
-	ctx context.Context
+	log     log.Logger
+	ctx     context.Context
+	metrics metrics.Metrics
+	// httpClient fetches the schemas of schema registries
+	httpClient *http.Client
 }
 
 type KafkaConsumerGroup struct {
+	client          sarama.Client
 	consumerGroup   sarama.ConsumerGroup
 	options         *GraphQLSubscriptionOptions
 	log             log.Logger
@@ -36,15 +44,62 @@ type kafkaConsumerGroupHandler struct {
 	options         *GraphQLSubscriptionOptions
 	messages        chan *sarama.ConsumerMessage
 	ctx             context.Context
+	metrics         metrics.Metrics
+	// offsets looks up the offsets of StartTimestamp
+	offsets offsetLookup
+	// seekedPartitions are the partitions which have been moved to the offset of StartTimestamp,
+	// they aren't moved again after a rebalance to not consume the messages twice
+	seekedPartitions map[string]map[int32]bool
+}
+
+// offsetLookup returns the offset of the first message produced at or after time (Unix milliseconds), it's implemented by sarama.Client
+type offsetLookup interface {
+	GetOffset(topic string, partitionID int32, time int64) (int64, error)
 }
 
 // Setup is run at the beginning of a new session, before ConsumeClaim.
-func (k *kafkaConsumerGroupHandler) Setup(_ sarama.ConsumerGroupSession) error {
+func (k *kafkaConsumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
 	k.log.Debug("kafkaConsumerGroupHandler.Setup",
 		log.Strings("topics", k.options.Topics),
 		log.String("groupID", k.options.GroupID),
 		log.String("clientID", k.options.ClientID),
 	)
+	if k.options.StartTimestamp != 0 {
+		return k.seekStartTimestamp(session)
+	}
+	return nil
+}
+
+// seekStartTimestamp moves the claimed partitions to the first message produced at or after StartTimestamp.
+// The claims start at the offsets of the session, so they have to be moved before ConsumeClaim is called.
+func (k *kafkaConsumerGroupHandler) seekStartTimestamp(session sarama.ConsumerGroupSession) error {
+	if k.seekedPartitions == nil {
+		k.seekedPartitions = map[string]map[int32]bool{}
+	}
+	for topic, partitions := range session.Claims() {
+		if k.seekedPartitions[topic] == nil {
+			k.seekedPartitions[topic] = map[int32]bool{}
+		}
+		for _, partition := range partitions {
+			if k.seekedPartitions[topic][partition] {
+				continue
+			}
+			offset, err := k.offsets.GetOffset(topic, partition, k.options.StartTimestamp)
+			if err != nil {
+				return err
+			}
+			if offset < 0 {
+				// no message has been produced after the timestamp yet
+				if offset, err = k.offsets.GetOffset(topic, partition, sarama.OffsetNewest); err != nil {
+					return err
+				}
+			}
+			// ResetOffset only moves the offset backwards and MarkOffset only forwards
+			session.ResetOffset(topic, partition, offset, "")
+			session.MarkOffset(topic, partition, offset, "")
+			k.seekedPartitions[topic][partition] = true
+		}
+	}
 	return nil
 }
 
@@ -78,6 +133,10 @@ func (k *kafkaConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSes
 		select {
 		case k.messages <- msg:
 			cancel()
+			if k.metrics != nil {
+				// The high water mark is the offset of the next message produced to the partition.
+				k.metrics.KafkaConsumerLag(k.options.GroupID, msg.Topic, msg.Partition, claim.HighWaterMarkOffset()-msg.Offset-1)
+			}
 			// If the client wants to most recent messages, don't commit the
 			// offset and reset the offset to sarama.OffsetNewest, then start consuming.
 			if !k.options.StartConsumingLatest {
@@ -98,13 +157,20 @@ func (k *kafkaConsumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSes
 // NewKafkaConsumerGroup creates a new sarama.ConsumerGroup and returns a new
 // *KafkaConsumerGroup instance.
 func NewKafkaConsumerGroup(log log.Logger, saramaConfig *sarama.Config, options *GraphQLSubscriptionOptions) (*KafkaConsumerGroup, error) {
-	cg, err := sarama.NewConsumerGroup(options.BrokerAddresses, options.GroupID, saramaConfig)
+	// the client is shared with the consumer group to look up the offsets of StartTimestamp
+	client, err := sarama.NewClient(options.BrokerAddresses, saramaConfig)
 	if err != nil {
 		return nil, err
 	}
+	cg, err := sarama.NewConsumerGroupFromClient(options.GroupID, client)
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	return &KafkaConsumerGroup{
+		client:          client,
 		consumerGroup:   cg,
 		startedCallback: options.startedCallback,
 		log:             log,
@@ -186,6 +252,8 @@ func (k *KafkaConsumerGroup) StartConsuming(messages chan *sarama.ConsumerMessag
 		options:         k.options,
 		messages:        messages,
 		ctx:             k.ctx,
+		metrics:         k.options.metrics,
+		offsets:         k.client,
 	}
 
 	k.wg.Add(1)
@@ -202,7 +270,12 @@ func (k *KafkaConsumerGroup) Close() error {
 	}
 
 	k.cancel()
-	return k.consumerGroup.Close()
+	err := k.consumerGroup.Close()
+	// the consumer group doesn't close clients it didn't create
+	if clientErr := k.client.Close(); err == nil && clientErr != sarama.ErrClosedClient {
+		err = clientErr
+	}
+	return err
 }
 
 // WaitUntilConsumerStop waits until ConsumerGroup.Consume function stops.
@@ -215,8 +288,9 @@ func NewKafkaConsumerGroupBridge(ctx context.Context, logger log.Logger) *KafkaC
 		logger = log.NoopLogger
 	}
 	return &KafkaConsumerGroupBridge{
-		ctx: ctx,
-		log: logger,
+		ctx:        ctx,
+		log:        logger,
+		httpClient: http.DefaultClient,
 	}
 }
 
@@ -312,6 +386,29 @@ func (c *KafkaConsumerGroupBridge) UniqueRequestID(ctx *resolve.Context, options
 		return err
 	}
 
+	if options.StartTimestamp != 0 {
+		_, err = hash.WriteString("startTimestamp" + strconv.FormatInt(options.StartTimestamp, 10))
+		if err != nil {
+			return err
+		}
+	}
+
+	if options.ValueDecoder != nil {
+		valueDecoder, err := json.Marshal(options.ValueDecoder)
+		if err != nil {
+			return err
+		}
+		_, err = hash.Write(valueDecoder)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = hash.WriteString(options.EnvelopeField)
+	if err != nil {
+		return err
+	}
+
 	if options.SASL.Enable {
 		_, err = hash.WriteString(options.SASL.User)
 		if err != nil {
@@ -334,6 +431,12 @@ func (c *KafkaConsumerGroupBridge) Subscribe(ctx *resolve.Context, options Graph
 	if err := options.Validate(); err != nil {
 		return err
 	}
+	options.metrics = c.metrics
+
+	decoder, err := newMessageDecoder(options.ValueDecoder, c.httpClient)
+	if err != nil {
+		return err
+	}
 
 	saramaConfig, err := c.prepareSaramaConfig(&options)
 	if err != nil {
@@ -374,10 +477,16 @@ func (c *KafkaConsumerGroupBridge) Subscribe(ctx *resolve.Context, options Graph
 				if !ok {
 					return
 				}
-				// The "data" field contains the result of your GraphQL request.
-				result, err := jsonparser.Set([]byte(`{}`), msg.Value, "data")
+				result, err := c.messageResult(ctx.Context(), msg, decoder, options.EnvelopeField)
 				if err != nil {
-					return
+					// a message which can't be decoded shouldn't end the subscription
+					c.log.Error("KafkaConsumerGroupBridge.messageResult",
+						log.String("topic", msg.Topic),
+						log.Int("partition", int(msg.Partition)),
+						log.Int("offset", int(msg.Offset)),
+						log.Error(err),
+					)
+					continue
 				}
 				updater.Update(result)
 			}
@@ -387,4 +496,61 @@ func (c *KafkaConsumerGroupBridge) Subscribe(ctx *resolve.Context, options Graph
 	return nil
 }
 
+type messageHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// messageEnvelope exposes the metadata of a message next to its value
+type messageEnvelope struct {
+	Key       *string         `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	Headers   []messageHeader `json:"headers"`
+	Topic     string          `json:"topic"`
+	Partition int32           `json:"partition"`
+	Offset    int64           `json:"offset"`
+	Timestamp string          `json:"timestamp"`
+}
+
+// messageResult decodes the message value and returns the update of the subscription
+func (c *KafkaConsumerGroupBridge) messageResult(ctx context.Context, msg *sarama.ConsumerMessage, decoder messageDecoder, envelopeField string) ([]byte, error) {
+	value := msg.Value
+	if decoder != nil {
+		var err error
+		if value, err = decoder.Decode(ctx, value); err != nil {
+			return nil, err
+		}
+	}
+
+	// The "data" field contains the result of your GraphQL request.
+	if envelopeField == "" {
+		return jsonparser.Set([]byte(`{}`), value, "data")
+	}
+
+	envelope := messageEnvelope{
+		Value:     value,
+		Headers:   make([]messageHeader, 0, len(msg.Headers)),
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Timestamp: msg.Timestamp.UTC().Format(time.RFC3339Nano),
+	}
+	if len(value) == 0 {
+		// tombstones have no value
+		envelope.Value = json.RawMessage("null")
+	}
+	if msg.Key != nil {
+		key := string(msg.Key)
+		envelope.Key = &key
+	}
+	for _, header := range msg.Headers {
+		envelope.Headers = append(envelope.Headers, messageHeader{Key: string(header.Key), Value: string(header.Value)})
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+	return jsonparser.Set([]byte(`{}`), data, "data", envelopeField)
+}
+
 var _ sarama.ConsumerGroupHandler = (*kafkaConsumerGroupHandler)(nil)
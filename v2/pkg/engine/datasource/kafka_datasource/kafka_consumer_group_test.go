@@ -2,6 +2,7 @@ package kafka_datasource
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/IBM/sarama"
 	"github.com/IBM/sarama/mocks"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/metrics"
 	log "github.com/jensneuse/abstractlogger"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -319,13 +321,130 @@ func TestKafkaConsumerGroup_Config_StartConsumingLatest(t *testing.T) {
 	require.False(t, session.markMessageCalled)
 }
 
+type consumerLagRecorder struct {
+	metrics.Noop
+	mu   sync.Mutex
+	lags []string
+}
+
+func (c *consumerLagRecorder) KafkaConsumerLag(groupID, topic string, partition int32, lag int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lags = append(c.lags, fmt.Sprintf("%s:%s:%d:%d", groupID, topic, partition, lag))
+}
+
+func TestKafkaConsumerGroup_ConsumerLag(t *testing.T) {
+	ctx := resolve.NewContext(context.Background())
+
+	consumedMsgCh := make(chan *sarama.ConsumerMessage)
+	var mockTopicName = "test.mock.topic"
+
+	recorder := &consumerLagRecorder{}
+	kg := &kafkaConsumerGroupHandler{
+		ctx:      ctx.Context(),
+		messages: consumedMsgCh,
+		log:      logger(),
+		options: &GraphQLSubscriptionOptions{
+			Topics:   []string{mockTopicName},
+			GroupID:  "test.consumer.group",
+			ClientID: "test.client.id",
+		},
+		metrics: recorder,
+	}
+	session := &mockConsumerGroupSession{
+		resetOffsetParams: make(map[string]interface{}),
+	}
+	claim := &mockConsumerGroupClaim{
+		topicName:           mockTopicName,
+		messages:            make(chan *sarama.ConsumerMessage, 2),
+		highWaterMarkOffset: 10,
+	}
+	for _, offset := range []int64{7, 9} {
+		claim.messages <- &sarama.ConsumerMessage{
+			Topic:     mockTopicName,
+			Partition: defaultPartition,
+			Offset:    offset,
+			Value:     []byte("value"),
+		}
+	}
+	close(claim.messages)
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- kg.ConsumeClaim(session, claim)
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-consumedMsgCh:
+		case <-time.After(15 * time.Second):
+			require.Fail(t, "the message could not be consumed")
+		}
+	}
+	require.NoError(t, <-errCh)
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	require.Equal(t, []string{
+		"test.consumer.group:test.mock.topic:0:2",
+		"test.consumer.group:test.mock.topic:0:0",
+	}, recorder.lags)
+}
+
+type startTimestampOffsets map[int32]int64
+
+func (s startTimestampOffsets) GetOffset(topic string, partitionID int32, time int64) (int64, error) {
+	if time == sarama.OffsetNewest {
+		return 100, nil
+	}
+	return s[partitionID], nil
+}
+
+func TestKafkaConsumerGroup_Config_StartTimestamp(t *testing.T) {
+	var mockTopicName = "test.mock.topic"
+
+	kg := &kafkaConsumerGroupHandler{
+		log: logger(),
+		options: &GraphQLSubscriptionOptions{
+			StartTimestamp: 1700000000000,
+			Topics:         []string{mockTopicName},
+			GroupID:        "test.consumer.group",
+			ClientID:       "test.client.id",
+		},
+		// no message has been produced to partition 1 after the timestamp
+		offsets: startTimestampOffsets{0: 42, 1: -1},
+	}
+
+	session := &mockConsumerGroupSession{
+		claims:            map[string][]int32{mockTopicName: {0}},
+		resetOffsetParams: make(map[string]interface{}),
+		markOffsetParams:  make(map[string]interface{}),
+	}
+	require.NoError(t, kg.Setup(session))
+	require.Equal(t, int64(42), session.resetOffsetParams["offset"])
+	require.Equal(t, int64(42), session.markOffsetParams["offset"])
+
+	session = &mockConsumerGroupSession{
+		claims:            map[string][]int32{mockTopicName: {0, 1}},
+		resetOffsetParams: make(map[string]interface{}),
+		markOffsetParams:  make(map[string]interface{}),
+	}
+	// partition 0 isn't moved again after a rebalance
+	require.NoError(t, kg.Setup(session))
+	require.Equal(t, int32(1), session.resetOffsetParams["partition"])
+	require.Equal(t, int64(100), session.resetOffsetParams["offset"])
+	require.Equal(t, int64(100), session.markOffsetParams["offset"])
+}
+
 type mockConsumerGroupSession struct {
+	claims            map[string][]int32
 	markMessageCalled bool
 	resetOffsetParams map[string]interface{}
+	markOffsetParams  map[string]interface{}
 }
 
 func (m *mockConsumerGroupSession) Claims() map[string][]int32 {
-	panic("implement me")
+	return m.claims
 }
 
 func (m *mockConsumerGroupSession) MemberID() string {
@@ -337,7 +456,10 @@ func (m *mockConsumerGroupSession) GenerationID() int32 {
 }
 
 func (m *mockConsumerGroupSession) MarkOffset(topic string, partition int32, offset int64, metadata string) {
-	panic("implement me")
+	m.markOffsetParams["topic"] = topic
+	m.markOffsetParams["partition"] = partition
+	m.markOffsetParams["offset"] = offset
+	m.markOffsetParams["metadata"] = metadata
 }
 
 func (m *mockConsumerGroupSession) Commit() {
@@ -362,8 +484,9 @@ func (m *mockConsumerGroupSession) Context() context.Context {
 var _ sarama.ConsumerGroupSession = (*mockConsumerGroupSession)(nil)
 
 type mockConsumerGroupClaim struct {
-	topicName string
-	messages  chan *sarama.ConsumerMessage
+	topicName           string
+	messages            chan *sarama.ConsumerMessage
+	highWaterMarkOffset int64
 }
 
 func (m *mockConsumerGroupClaim) Topic() string {
@@ -379,7 +502,7 @@ func (m *mockConsumerGroupClaim) InitialOffset() int64 {
 }
 
 func (m *mockConsumerGroupClaim) HighWaterMarkOffset() int64 {
-	return 0
+	return m.highWaterMarkOffset
 }
 
 func (m *mockConsumerGroupClaim) Messages() <-chan *sarama.ConsumerMessage {
@@ -10,6 +10,7 @@ import (
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/metrics"
 )
 
 var (
@@ -17,8 +18,9 @@ var (
 )
 
 type Planner struct {
-	ctx    context.Context
-	config Configuration
+	ctx     context.Context
+	config  Configuration
+	metrics metrics.Metrics
 }
 
 func (p *Planner) UpstreamSchema(_ plan.DataSourceConfiguration) *ast.Document {
@@ -35,10 +37,12 @@ func (p *Planner) ConfigureFetch() resolve.FetchConfiguration {
 
 func (p *Planner) ConfigureSubscription() plan.SubscriptionConfiguration {
 	input, _ := json.Marshal(p.config.Subscription)
+	client := NewKafkaConsumerGroupBridge(p.ctx, abstractlogger.NoopLogger)
+	client.metrics = p.metrics
 	return plan.SubscriptionConfiguration{
 		Input: string(input),
 		DataSource: &SubscriptionSource{
-			client: NewKafkaConsumerGroupBridge(p.ctx, abstractlogger.NoopLogger),
+			client: client,
 		},
 	}
 }
@@ -52,11 +56,15 @@ func (p *Planner) DataSourcePlanningBehavior() plan.DataSourcePlanningBehavior {
 
 func (p *Planner) DownstreamResponseFieldAlias(_ int) (alias string, exists bool) { return }
 
-type Factory struct{}
+type Factory struct {
+	// Metrics receives the consumer lag of the consumer groups, if nil no metrics are reported
+	Metrics metrics.Metrics
+}
 
 func (f *Factory) Planner(ctx context.Context) plan.DataSourcePlanner {
 	return &Planner{
-		ctx: ctx,
+		ctx:     ctx,
+		metrics: f.Metrics,
 	}
 }
 
@@ -210,6 +210,113 @@ func TestKafkaDataSource(t *testing.T) {
 			},
 		},
 	}))
+
+	t.Run("subscription with filter directive", datasourcetesting.RunTest(plan.SubscriptionFilterDirectiveSDL+`
+		type Subscription {
+			orderUpdated(customerId: ID!): Order! @filter(field: "customer.id", argument: "customerId")
+ 		}
+		type Order {
+			id: ID!
+		}
+`, `
+		subscription OrderUpdated($customerId: ID!) {
+			orderUpdated(customerId: $customerId) { id }
+		}
+	`, "OrderUpdated", &plan.SubscriptionResponsePlan{
+		Response: &resolve.GraphQLSubscription{
+			Trigger: resolve.GraphQLSubscriptionTrigger{
+				Input: []byte(fmt.Sprintf(`{"broker_addresses":["localhost:9092"],"topics":["orders"],"group_id":"test.consumer.group","client_id":"test.client.id","kafka_version":"%s","start_consuming_latest":false,"balance_strategy":"%s","isolation_level":"%s","sasl":{"enable":false,"user":"","password":""}}`,
+					testMockKafkaVersion,
+					DefaultBalanceStrategy,
+					DefaultIsolationLevel,
+				)),
+				Source: &SubscriptionSource{
+					client: NewKafkaConsumerGroupBridge(ctx.Context(), logger()),
+				},
+				Filters: []resolve.SubscriptionFilter{
+					&resolve.SubscriptionFieldFilter{
+						FieldPath: []string{"orderUpdated", "customer", "id"},
+						Input:     []byte("$$0$$"),
+						Variables: resolve.NewVariables(
+							&resolve.ContextVariable{
+								Path:     []string{"customerId"},
+								Renderer: resolve.NewPlainVariableRendererWithValidation(`{"type":["string","integer"]}`),
+							},
+						),
+					},
+				},
+			},
+			Response: &resolve.GraphQLResponse{
+				Data: &resolve.Object{
+					Fields: []*resolve.Field{
+						{
+							Name: []byte("orderUpdated"),
+							Position: resolve.Position{
+								Line:   3,
+								Column: 4,
+							},
+							Value: &resolve.Object{
+								Path: []string{"orderUpdated"},
+								Fields: []*resolve.Field{
+									{
+										Name: []byte("id"),
+										Position: resolve.Position{
+											Line:   3,
+											Column: 44,
+										},
+										Value: &resolve.Scalar{
+											Path: []string{"id"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, plan.Configuration{
+		DataSources: []plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{
+					{
+						TypeName:   "Subscription",
+						FieldNames: []string{"orderUpdated"},
+					},
+				},
+				ChildNodes: []plan.TypeField{
+					{
+						TypeName:   "Order",
+						FieldNames: []string{"id"},
+					},
+				},
+				Custom: ConfigJSON(Configuration{
+					Subscription: SubscriptionConfiguration{
+						BrokerAddresses: []string{"localhost:9092"},
+						Topics:          []string{"orders"},
+						GroupID:         "test.consumer.group",
+						ClientID:        "test.client.id",
+						KafkaVersion:    testMockKafkaVersion,
+						BalanceStrategy: DefaultBalanceStrategy,
+						IsolationLevel:  DefaultIsolationLevel,
+					},
+				}),
+				Factory: factory,
+			},
+		},
+		Fields: []plan.FieldConfiguration{
+			{
+				TypeName:  "Subscription",
+				FieldName: "orderUpdated",
+				Arguments: []plan.ArgumentConfiguration{
+					{
+						Name:       "customerId",
+						SourceType: plan.FieldArgumentSource,
+					},
+				},
+			},
+		},
+	}))
 }
 
 var errSubscriptionClientFail = errors.New("subscription client fail error")
@@ -0,0 +1,216 @@
+package kafka_datasource
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// confluentMagicByte starts messages in the Confluent wire format, it's followed by the schema id (4 bytes, big endian)
+const confluentMagicByte = 0
+
+var errNoConfluentWireFormat = errors.New("message is not in the Confluent wire format")
+
+// messageDecoder decodes message values into JSON
+type messageDecoder interface {
+	Decode(ctx context.Context, value []byte) ([]byte, error)
+}
+
+// newMessageDecoder loads the schemas of the decoder configuration, it returns nil if values are JSON
+func newMessageDecoder(config *MessageDecoder, httpClient *http.Client) (messageDecoder, error) {
+	if config == nil {
+		return nil, nil
+	}
+	switch config.Format {
+	case MessageFormatAvro:
+		if config.SchemaRegistryURL != "" {
+			return &registryAvroDecoder{registry: newSchemaRegistry(config.SchemaRegistryURL, httpClient)}, nil
+		}
+		data, err := os.ReadFile(config.SchemaFile)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := parseAvroSchema(data)
+		if err != nil {
+			return nil, err
+		}
+		return &avroMessageDecoder{schema: schema}, nil
+	case MessageFormatProtobuf:
+		descriptor, err := loadProtobufMessageDescriptor(config.SchemaFile, config.MessageType)
+		if err != nil {
+			return nil, err
+		}
+		return &protobufMessageDecoder{descriptor: descriptor, wireFormat: config.SchemaRegistryURL != ""}, nil
+	default:
+		return nil, nil
+	}
+}
+
+type avroMessageDecoder struct {
+	schema *avroSchema
+}
+
+func (a *avroMessageDecoder) Decode(_ context.Context, value []byte) ([]byte, error) {
+	return decodeAvro(a.schema, value)
+}
+
+// registryAvroDecoder decodes Avro messages in the Confluent wire format with the writer schema of the message
+type registryAvroDecoder struct {
+	registry *schemaRegistry
+}
+
+func (r *registryAvroDecoder) Decode(ctx context.Context, value []byte) ([]byte, error) {
+	schemaID, payload, err := splitConfluentWireFormat(value)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := r.registry.avroSchema(ctx, schemaID)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAvro(schema, payload)
+}
+
+type protobufMessageDecoder struct {
+	descriptor protoreflect.MessageDescriptor
+	// wireFormat strips the schema id and the message indexes of the Confluent wire format
+	wireFormat bool
+}
+
+func (p *protobufMessageDecoder) Decode(_ context.Context, value []byte) ([]byte, error) {
+	if p.wireFormat {
+		_, payload, err := splitConfluentWireFormat(value)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = skipProtobufMessageIndexes(payload); err != nil {
+			return nil, err
+		}
+	}
+	message := dynamicpb.NewMessage(p.descriptor)
+	if err := proto.Unmarshal(value, message); err != nil {
+		return nil, err
+	}
+	// unpopulated fields are part of the result, otherwise fields with default values would resolve to null
+	return protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(message)
+}
+
+// loadProtobufMessageDescriptor finds the message in a file descriptor set created by protoc --descriptor_set_out
+func loadProtobufMessageDescriptor(path, messageType string) (protoreflect.MessageDescriptor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var descriptorSet descriptorpb.FileDescriptorSet
+	if err = proto.Unmarshal(data, &descriptorSet); err != nil {
+		return nil, fmt.Errorf("invalid protobuf descriptor set %s: %w", path, err)
+	}
+	files, err := protodesc.NewFiles(&descriptorSet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid protobuf descriptor set %s: %w", path, err)
+	}
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(messageType))
+	if err != nil {
+		return nil, fmt.Errorf("protobuf message %s: %w", messageType, err)
+	}
+	message, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a protobuf message", messageType)
+	}
+	return message, nil
+}
+
+func splitConfluentWireFormat(value []byte) (schemaID uint32, payload []byte, err error) {
+	if len(value) < 5 || value[0] != confluentMagicByte {
+		return 0, nil, errNoConfluentWireFormat
+	}
+	return binary.BigEndian.Uint32(value[1:5]), value[5:], nil
+}
+
+// skipProtobufMessageIndexes skips the indexes of the message type in the schema, they precede Protobuf payloads in the Confluent wire format
+func skipProtobufMessageIndexes(payload []byte) ([]byte, error) {
+	count, n := binary.Varint(payload)
+	if n <= 0 || count < 0 {
+		return nil, errNoConfluentWireFormat
+	}
+	payload = payload[n:]
+	for ; count > 0; count-- {
+		if _, n = binary.Varint(payload); n <= 0 {
+			return nil, errNoConfluentWireFormat
+		}
+		payload = payload[n:]
+	}
+	return payload, nil
+}
+
+// schemaRegistry fetches schemas by their id from a Confluent compatible schema registry.
+// Schemas are immutable, so they are cached for the lifetime of the subscription.
+type schemaRegistry struct {
+	url        string
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	schemas map[uint32]*avroSchema
+}
+
+func newSchemaRegistry(url string, httpClient *http.Client) *schemaRegistry {
+	return &schemaRegistry{
+		url:        strings.TrimSuffix(url, "/"),
+		httpClient: httpClient,
+		schemas:    map[uint32]*avroSchema{},
+	}
+}
+
+func (s *schemaRegistry) avroSchema(ctx context.Context, id uint32) (*avroSchema, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if schema, ok := s.schemas[id]; ok {
+		return schema, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/schemas/ids/%d", s.url, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json, application/json")
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("schema registry: unexpected status %d for schema %d", res.StatusCode, id)
+	}
+
+	var body struct {
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("schema registry: invalid response for schema %d: %w", id, err)
+	}
+	// the schema type is omitted for Avro schemas
+	if body.SchemaType != "" && body.SchemaType != "AVRO" {
+		return nil, fmt.Errorf("schema registry: schema %d is not an avro schema: %s", id, body.SchemaType)
+	}
+	schema, err := parseAvroSchema([]byte(body.Schema))
+	if err != nil {
+		return nil, err
+	}
+	s.schemas[id] = schema
+	return schema, nil
+}
@@ -0,0 +1,156 @@
+package kafka_datasource
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func confluentWireFormat(schemaID uint32, payload []byte) []byte {
+	return append(binary.BigEndian.AppendUint32([]byte{confluentMagicByte}, schemaID), payload...)
+}
+
+func TestMessageDecoder_Avro(t *testing.T) {
+	t.Run("schema file", func(t *testing.T) {
+		schemaFile := filepath.Join(t.TempDir(), "order.avsc")
+		require.NoError(t, os.WriteFile(schemaFile, []byte(testAvroOrderSchema), 0o600))
+
+		decoder, err := newMessageDecoder(&MessageDecoder{Format: MessageFormatAvro, SchemaFile: schemaFile}, http.DefaultClient)
+		require.NoError(t, err)
+		data, err := decoder.Decode(context.Background(), testAvroOrder())
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"status":"SHIPPED"`)
+	})
+
+	t.Run("schema registry", func(t *testing.T) {
+		requests := atomic.NewInt64(0)
+		registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Inc()
+			switch r.URL.Path {
+			case "/schemas/ids/7":
+				_, _ = w.Write([]byte(`{"schema":"{\"type\":\"record\",\"name\":\"Stock\",\"fields\":[{\"name\":\"inStock\",\"type\":\"int\"}]}"}`))
+			case "/schemas/ids/8":
+				_, _ = w.Write([]byte(`{"schema":"syntax = \"proto3\";","schemaType":"PROTOBUF"}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer registry.Close()
+
+		decoder, err := newMessageDecoder(&MessageDecoder{Format: MessageFormatAvro, SchemaRegistryURL: registry.URL + "/"}, registry.Client())
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			data, err := decoder.Decode(context.Background(), confluentWireFormat(7, avroLong(nil, 3)))
+			require.NoError(t, err)
+			assert.Equal(t, `{"inStock":3}`, string(data))
+		}
+		// the schema is cached
+		assert.Equal(t, int64(1), requests.Load())
+
+		_, err = decoder.Decode(context.Background(), confluentWireFormat(8, nil))
+		assert.EqualError(t, err, "schema registry: schema 8 is not an avro schema: PROTOBUF")
+		_, err = decoder.Decode(context.Background(), confluentWireFormat(9, nil))
+		assert.EqualError(t, err, "schema registry: unexpected status 404 for schema 9")
+		_, err = decoder.Decode(context.Background(), avroLong(nil, 3))
+		assert.ErrorIs(t, err, errNoConfluentWireFormat)
+	})
+}
+
+func TestMessageDecoder_Protobuf(t *testing.T) {
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("order.proto"),
+		Package: proto.String("shop.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Order"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{Name: proto.String("order_id"), JsonName: proto.String("orderId"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+					{Name: proto.String("quantity"), JsonName: proto.String("quantity"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				},
+			},
+		},
+	}
+	descriptorSet, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	require.NoError(t, err)
+	schemaFile := filepath.Join(t.TempDir(), "order.pb")
+	require.NoError(t, os.WriteFile(schemaFile, descriptorSet, 0o600))
+
+	fileDescriptor, err := protodesc.NewFile(file, nil)
+	require.NoError(t, err)
+	orderDescriptor := fileDescriptor.Messages().ByName("Order")
+	order := dynamicpb.NewMessage(orderDescriptor)
+	order.Set(orderDescriptor.Fields().ByName("order_id"), protoreflect.ValueOfString("o-1"))
+	payload, err := proto.Marshal(order)
+	require.NoError(t, err)
+
+	t.Run("plain messages", func(t *testing.T) {
+		decoder, err := newMessageDecoder(&MessageDecoder{Format: MessageFormatProtobuf, SchemaFile: schemaFile, MessageType: "shop.v1.Order"}, http.DefaultClient)
+		require.NoError(t, err)
+		data, err := decoder.Decode(context.Background(), payload)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"orderId":"o-1","quantity":0}`, string(data))
+	})
+
+	t.Run("confluent wire format", func(t *testing.T) {
+		decoder, err := newMessageDecoder(&MessageDecoder{Format: MessageFormatProtobuf, SchemaFile: schemaFile, MessageType: "shop.v1.Order", SchemaRegistryURL: "http://localhost"}, http.DefaultClient)
+		require.NoError(t, err)
+		// a single message index of 0 is encoded as the count 0
+		data, err := decoder.Decode(context.Background(), confluentWireFormat(1, append(avroLong(nil, 0), payload...)))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"orderId":"o-1","quantity":0}`, string(data))
+	})
+
+	t.Run("unknown message type", func(t *testing.T) {
+		_, err := newMessageDecoder(&MessageDecoder{Format: MessageFormatProtobuf, SchemaFile: schemaFile, MessageType: "shop.v1.Invoice"}, http.DefaultClient)
+		assert.ErrorContains(t, err, "protobuf message shop.v1.Invoice")
+	})
+}
+
+func TestKafkaConsumerGroupBridge_messageResult(t *testing.T) {
+	bridge := NewKafkaConsumerGroupBridge(context.Background(), nil)
+	msg := &sarama.ConsumerMessage{
+		Topic:     "stock",
+		Partition: 2,
+		Offset:    15,
+		Key:       []byte("trilby"),
+		Value:     []byte(`{"inStock":2}`),
+		Headers:   []*sarama.RecordHeader{{Key: []byte("source"), Value: []byte("warehouse")}},
+		Timestamp: time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC),
+	}
+
+	t.Run("value", func(t *testing.T) {
+		result, err := bridge.messageResult(context.Background(), msg, nil, "")
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"inStock":2}}`, string(result))
+	})
+
+	t.Run("envelope", func(t *testing.T) {
+		result, err := bridge.messageResult(context.Background(), msg, nil, "stock")
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"stock":{"key":"trilby","value":{"inStock":2},"headers":[{"key":"source","value":"warehouse"}],"topic":"stock","partition":2,"offset":15,"timestamp":"2023-11-14T22:13:20Z"}}}`, string(result))
+	})
+
+	t.Run("tombstone", func(t *testing.T) {
+		tombstone := &sarama.ConsumerMessage{Topic: "stock", Offset: 16}
+		result, err := bridge.messageResult(context.Background(), tombstone, nil, "stock")
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"stock":{"key":null,"value":null,"headers":[],"topic":"stock","partition":0,"offset":16,"timestamp":"0001-01-01T00:00:00Z"}}}`, string(result))
+	})
+}
@@ -265,6 +265,13 @@ type SubscriptionSource struct {
 	pubSub PubSub
 }
 
+// NewSubscriptionSource returns a subscription data source for the pubsub, its input is {"topic":"..."}
+func NewSubscriptionSource(pubSub PubSub) *SubscriptionSource {
+	return &SubscriptionSource{
+		pubSub: pubSub,
+	}
+}
+
 func (s *SubscriptionSource) UniqueRequestID(ctx *resolve.Context, input []byte, xxh *xxhash.Digest) error {
 	topic, err := jsonparser.GetString(input, "topic")
 	if err != nil {
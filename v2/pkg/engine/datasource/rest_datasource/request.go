@@ -0,0 +1,50 @@
+package rest_datasource
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/httpclient"
+)
+
+// renameBodyKeys renames the keys of the objects of the JSON body of the input at any depth
+func renameBodyKeys(input []byte, keys map[string]string) ([]byte, error) {
+	body, dataType, _, err := jsonparser.Get(input, httpclient.BODY)
+	if err != nil || (dataType != jsonparser.Object && dataType != jsonparser.Array) {
+		return input, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err = decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err = encodeJSON(buf, renameKeys(value, keys)); err != nil {
+		return nil, err
+	}
+	return httpclient.SetInputBody(append([]byte(nil), input...), buf.Bytes()), nil
+}
+
+func renameKeys(value interface{}, keys map[string]string) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		renamed := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			if original, ok := keys[key]; ok {
+				key = original
+			}
+			renamed[key] = renameKeys(item, keys)
+		}
+		return renamed
+	case []interface{}:
+		for i := range value {
+			value[i] = renameKeys(value[i], keys)
+		}
+		return value
+	default:
+		return value
+	}
+}
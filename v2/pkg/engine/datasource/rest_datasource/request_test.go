@@ -0,0 +1,33 @@
+package rest_datasource
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/httpclient"
+)
+
+func TestSource_LoadWithBodyKeys(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	source := &Source{client: http.DefaultClient, bodyKeys: map[string]string{"firstName": "first-name"}}
+	var input []byte
+	input = httpclient.SetInputMethod(input, []byte("POST"))
+	input = httpclient.SetInputURL(input, []byte(server.URL))
+	input = httpclient.SetInputBody(input, []byte(`{"name":"Rex & Co","age":1.50,"owners":[{"firstName":"Jane"},{"firstName":null}]}`))
+	require.NoError(t, source.Load(context.Background(), input, &bytes.Buffer{}))
+	assert.Equal(t, `{"age":1.50,"name":"Rex & Co","owners":[{"first-name":"Jane"},{"first-name":null}]}`, body)
+}
@@ -0,0 +1,463 @@
+package rest_datasource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/httpclient"
+)
+
+const defaultMaxPages = 10
+
+var templateFuncs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		buf := &bytes.Buffer{}
+		if err := encodeJSON(buf, value); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	},
+	// list wraps a single value into a list, e.g. for an XML element which occurs only once
+	"list": func(value interface{}) []interface{} {
+		switch value := value.(type) {
+		case nil:
+			return []interface{}{}
+		case []interface{}:
+			return value
+		default:
+			return []interface{}{value}
+		}
+	},
+}
+
+// responseTransformation loads all pages of a response and turns them into the JSON response of the field
+type responseTransformation struct {
+	response   ResponseConfiguration
+	pagination *PaginationConfiguration
+	template   *template.Template
+}
+
+// newResponseTransformation returns nil if the response body is used as is
+func newResponseTransformation(fetch FetchConfiguration) (*responseTransformation, error) {
+	if fetch.Response == nil && fetch.Pagination == nil {
+		return nil, nil
+	}
+	t := &responseTransformation{
+		pagination: fetch.Pagination,
+	}
+	if fetch.Response != nil {
+		t.response = *fetch.Response
+	}
+	switch t.response.Decoder {
+	case ResponseDecoderAuto, ResponseDecoderJSON, ResponseDecoderXML, ResponseDecoderForm:
+	default:
+		return nil, fmt.Errorf("unknown response decoder %q", t.response.Decoder)
+	}
+	if t.response.Template != "" {
+		tmpl, err := template.New("response").Funcs(templateFuncs).Parse(t.response.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid response template: %w", err)
+		}
+		t.template = tmpl
+	}
+	return t, nil
+}
+
+func (t *responseTransformation) load(ctx context.Context, client *http.Client, input []byte, w io.Writer) error {
+	if httpclient.IsInputFlagSet(input, httpclient.TRACE) {
+		// the trace extension would be added to the raw body before it gets decoded
+		input = jsonparser.Delete(append([]byte(nil), input...), httpclient.TRACE)
+	}
+
+	page, header, err := t.fetch(ctx, client, input)
+	if err != nil {
+		return err
+	}
+	data := valueAtPath(page, t.response.SelectPath)
+	if t.pagination != nil {
+		if data, err = t.paginate(ctx, client, input, page, header, data); err != nil {
+			return err
+		}
+	}
+	data = applyFields(data, t.response.Fields)
+
+	if t.template == nil {
+		return encodeJSON(w, data)
+	}
+	rendered := &bytes.Buffer{}
+	if err = t.template.Execute(rendered, data); err != nil {
+		return fmt.Errorf("executing response template: %w", err)
+	}
+	out := &bytes.Buffer{}
+	if err = json.Compact(out, rendered.Bytes()); err != nil {
+		return fmt.Errorf("response template rendered invalid JSON: %w", err)
+	}
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+func (t *responseTransformation) fetch(ctx context.Context, client *http.Client, input []byte) (page interface{}, header http.Header, err error) {
+	ctx = httpclient.WithResponseHeadersObserver(ctx, func(h http.Header) {
+		header = h
+	})
+	body := &bytes.Buffer{}
+	if err = httpclient.Do(client, ctx, input, body); err != nil {
+		return nil, nil, err
+	}
+	page, err = decodeResponse(t.response.Decoder, header.Get(httpclient.ContentTypeHeader), body.Bytes())
+	return page, header, err
+}
+
+// paginate appends the items of the following pages to the items of the first page
+func (t *responseTransformation) paginate(ctx context.Context, client *http.Client, input []byte, page interface{}, header http.Header, data interface{}) (interface{}, error) {
+	items, ok := data.([]interface{})
+	if !ok {
+		return data, nil
+	}
+	maxPages := t.pagination.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+	for pages := 1; pages < maxPages; pages++ {
+		next, ok := t.nextInput(input, page, header)
+		if !ok || bytes.Equal(next, input) {
+			break
+		}
+		var err error
+		if page, header, err = t.fetch(ctx, client, next); err != nil {
+			return nil, err
+		}
+		pageItems, ok := valueAtPath(page, t.response.SelectPath).([]interface{})
+		if !ok {
+			break
+		}
+		items = append(items, pageItems...)
+		input = next
+	}
+	return items, nil
+}
+
+// nextInput returns the input of the request for the page following the page of the input
+func (t *responseTransformation) nextInput(input []byte, page interface{}, header http.Header) ([]byte, bool) {
+	if len(t.pagination.NextPath) == 0 {
+		next := nextLink(header.Values("Link"))
+		if next == "" {
+			return nil, false
+		}
+		return setNextURL(input, next)
+	}
+	next := scalarString(valueAtPath(page, t.pagination.NextPath))
+	if next == "" {
+		return nil, false
+	}
+	if t.pagination.CursorParameter == "" {
+		return setNextURL(input, next)
+	}
+	return setQueryParameter(input, t.pagination.CursorParameter, next)
+}
+
+// setNextURL replaces the URL of the input with the next URL which might be relative to the current one.
+// The query parameters are dropped because the next URL contains them.
+// Next URLs of another origin aren't followed, because the input contains the headers of the current origin.
+func setNextURL(input []byte, next string) ([]byte, bool) {
+	current, err := jsonparser.GetString(input, httpclient.URL)
+	if err != nil {
+		return nil, false
+	}
+	base, err := url.Parse(current)
+	if err != nil {
+		return nil, false
+	}
+	reference, err := url.Parse(next)
+	if err != nil {
+		return nil, false
+	}
+	resolved := base.ResolveReference(reference)
+	if resolved.Scheme != base.Scheme || resolved.Host != base.Host {
+		return nil, false
+	}
+	value, err := jsonString(resolved.String())
+	if err != nil {
+		return nil, false
+	}
+	output := jsonparser.Delete(append([]byte(nil), input...), httpclient.QUERYPARAMS)
+	output, err = jsonparser.Set(output, value, httpclient.URL)
+	return output, err == nil
+}
+
+type queryParameter struct {
+	Name  string          `json:"name"`
+	Value json.RawMessage `json:"value"`
+}
+
+// setQueryParameter replaces the query parameter of the input with the name
+func setQueryParameter(input []byte, name, value string) ([]byte, bool) {
+	var params []queryParameter
+	if raw, _, _, err := jsonparser.Get(input, httpclient.QUERYPARAMS); err == nil {
+		if err = json.Unmarshal(raw, &params); err != nil {
+			return nil, false
+		}
+	}
+	out := params[:0]
+	for i := range params {
+		if params[i].Name != name {
+			out = append(out, params[i])
+		}
+	}
+	encoded, err := jsonString(value)
+	if err != nil {
+		return nil, false
+	}
+	buf := &bytes.Buffer{}
+	if err = encodeJSON(buf, append(out, queryParameter{Name: name, Value: encoded})); err != nil {
+		return nil, false
+	}
+	raw := buf.Bytes()
+	return httpclient.SetInputQueryParams(append([]byte(nil), input...), raw), true
+}
+
+// nextLink returns the URL of the rel="next" link of Link headers, e.g. `<https://example.com/items?page=2>; rel="next"`
+func nextLink(headers []string) string {
+	for _, header := range headers {
+		for _, link := range strings.Split(header, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if len(target) < 2 || target[0] != '<' || target[len(target)-1] != '>' {
+				continue
+			}
+			for _, param := range parts[1:] {
+				key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func scalarString(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	default:
+		return ""
+	}
+}
+
+func valueAtPath(value interface{}, path []string) interface{} {
+	for _, element := range path {
+		switch current := value.(type) {
+		case map[string]interface{}:
+			value = current[element]
+		case []interface{}:
+			index, err := strconv.Atoi(element)
+			if err != nil || index < 0 || index >= len(current) {
+				return nil
+			}
+			value = current[index]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+func applyFields(data interface{}, fields map[string][]string) interface{} {
+	if len(fields) == 0 {
+		return data
+	}
+	switch data := data.(type) {
+	case []interface{}:
+		for i := range data {
+			data[i] = applyFields(data[i], fields)
+		}
+	case map[string]interface{}:
+		// all values are read before they are set, so fields can be swapped
+		values := make(map[string]interface{}, len(fields))
+		for name, path := range fields {
+			values[name] = valueAtPath(data, path)
+		}
+		for name, value := range values {
+			data[name] = value
+		}
+	}
+	return data
+}
+
+func encodeJSON(w io.Writer, value interface{}) error {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	_, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+// jsonString encodes the string without escaping HTML characters because URLs are read from the input as is
+func jsonString(value string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	err := encodeJSON(buf, value)
+	return buf.Bytes(), err
+}
+
+func decodeResponse(decoder ResponseDecoder, contentType string, body []byte) (interface{}, error) {
+	if decoder == ResponseDecoderAuto {
+		decoder = decoderForContentType(contentType)
+	}
+	switch decoder {
+	case ResponseDecoderXML:
+		return decodeXML(body)
+	case ResponseDecoderForm:
+		return decodeForm(body)
+	default:
+		return decodeJSON(body)
+	}
+}
+
+func decoderForContentType(contentType string) ResponseDecoder {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ResponseDecoderJSON
+	}
+	switch {
+	case mediaType == "application/xml", mediaType == "text/xml", strings.HasSuffix(mediaType, "+xml"):
+		return ResponseDecoderXML
+	case mediaType == "application/x-www-form-urlencoded":
+		return ResponseDecoderForm
+	default:
+		return ResponseDecoderJSON
+	}
+}
+
+func decodeJSON(body []byte) (interface{}, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid json response: %w", err)
+	}
+	return value, nil
+}
+
+func decodeForm(body []byte) (interface{}, error) {
+	values, err := url.ParseQuery(strings.TrimSpace(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid form response: %w", err)
+	}
+	object := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		if len(value) == 1 {
+			object[key] = value[0]
+			continue
+		}
+		list := make([]interface{}, len(value))
+		for i := range value {
+			list[i] = value[i]
+		}
+		object[key] = list
+	}
+	return object, nil
+}
+
+type xmlElement struct {
+	name       string
+	attributes []xml.Attr
+	children   []*xmlElement
+	text       strings.Builder
+}
+
+// decodeXML returns an object with the root element as its only field
+func decodeXML(body []byte) (interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	var (
+		root  *xmlElement
+		stack []*xmlElement
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid xml response: %w", err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			element := &xmlElement{name: token.Name.Local, attributes: token.Attr}
+			if len(stack) == 0 {
+				root = element
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, element)
+			}
+			stack = append(stack, element)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) != 0 {
+				stack[len(stack)-1].text.Write(token)
+			}
+		}
+	}
+	if root == nil {
+		return nil, nil
+	}
+	return map[string]interface{}{root.name: root.value()}, nil
+}
+
+func (e *xmlElement) value() interface{} {
+	text := strings.TrimSpace(e.text.String())
+	if len(e.attributes) == 0 && len(e.children) == 0 {
+		return text
+	}
+	object := make(map[string]interface{}, len(e.attributes)+len(e.children)+1)
+	for _, attribute := range e.attributes {
+		if attribute.Name.Space == "xmlns" || attribute.Name.Local == "xmlns" {
+			continue
+		}
+		object["@"+attribute.Name.Local] = attribute.Value
+	}
+	for _, child := range e.children {
+		value := child.value()
+		existing, ok := object[child.name]
+		if !ok {
+			object[child.name] = value
+			continue
+		}
+		// element values are never lists, so a list is made of repeated elements
+		if list, isList := existing.([]interface{}); isList {
+			object[child.name] = append(list, value)
+		} else {
+			object[child.name] = []interface{}{existing, value}
+		}
+	}
+	if text != "" {
+		object["#text"] = text
+	}
+	return object
+}
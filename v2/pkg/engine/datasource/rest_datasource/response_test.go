@@ -0,0 +1,287 @@
+package rest_datasource
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/httpclient"
+)
+
+func TestSource_LoadWithTransformation(t *testing.T) {
+	newSource := func(t *testing.T, fetch FetchConfiguration) *Source {
+		transformation, err := newResponseTransformation(fetch)
+		require.NoError(t, err)
+		return &Source{client: http.DefaultClient, transformation: transformation}
+	}
+	load := func(t *testing.T, source *Source, url string) string {
+		var input []byte
+		input = httpclient.SetInputMethod(input, []byte("GET"))
+		input = httpclient.SetInputURL(input, []byte(url))
+		out := &bytes.Buffer{}
+		require.NoError(t, source.Load(context.Background(), input, out))
+		return out.String()
+	}
+
+	t.Run("select path and fields", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"status":"ok","result":{"users":[{"id":1,"attributes":{"full_name":"Jane <Doe>"}},{"id":2,"attributes":{"full_name":"John"}}]}}`))
+		}))
+		defer server.Close()
+
+		source := newSource(t, FetchConfiguration{
+			Response: &ResponseConfiguration{
+				SelectPath: []string{"result", "users"},
+				Fields: map[string][]string{
+					"name": {"attributes", "full_name"},
+				},
+			},
+		})
+		assert.Equal(t, `[{"attributes":{"full_name":"Jane <Doe>"},"id":1,"name":"Jane <Doe>"},{"attributes":{"full_name":"John"},"id":2,"name":"John"}]`, load(t, source, server.URL))
+	})
+
+	t.Run("select list item", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"items":[{"id":1},{"id":2}]}`))
+		}))
+		defer server.Close()
+
+		source := newSource(t, FetchConfiguration{
+			Response: &ResponseConfiguration{SelectPath: []string{"items", "1"}},
+		})
+		assert.Equal(t, `{"id":2}`, load(t, source, server.URL))
+	})
+
+	t.Run("template", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"results":[{"id":1}],"meta":{"total":42,"next":null}}`))
+		}))
+		defer server.Close()
+
+		source := newSource(t, FetchConfiguration{
+			Response: &ResponseConfiguration{
+				Template: `{
+					"items": {{ json .results }},
+					"total": {{ .meta.total }},
+					"next": {{ json .meta.next }}
+				}`,
+			},
+		})
+		assert.Equal(t, `{"items":[{"id":1}],"total":42,"next":null}`, load(t, source, server.URL))
+	})
+
+	t.Run("template rendering invalid JSON", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"name":"Jane"}`))
+		}))
+		defer server.Close()
+
+		source := newSource(t, FetchConfiguration{
+			Response: &ResponseConfiguration{Template: `{"name":{{ .name }}}`},
+		})
+		var input []byte
+		input = httpclient.SetInputMethod(input, []byte("GET"))
+		input = httpclient.SetInputURL(input, []byte(server.URL))
+		err := source.Load(context.Background(), input, &bytes.Buffer{})
+		assert.ErrorContains(t, err, "response template rendered invalid JSON")
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+				<catalog xmlns="urn:books">
+					<book id="1" available="true"><title>Go</title><author>Alan</author><author>Brian</author></book>
+					<book id="2"><title>GraphQL &amp; REST</title><price currency="EUR">9.99</price></book>
+				</catalog>`))
+		}))
+		defer server.Close()
+
+		source := newSource(t, FetchConfiguration{
+			Response: &ResponseConfiguration{
+				SelectPath: []string{"catalog", "book"},
+				Fields: map[string][]string{
+					"id":    {"@id"},
+					"price": {"price", "#text"},
+				},
+			},
+		})
+		assert.Equal(t, `[`+
+			`{"@available":"true","@id":"1","author":["Alan","Brian"],"id":"1","price":null,"title":"Go"},`+
+			`{"@id":"2","id":"2","price":"9.99","title":"GraphQL & REST"}`+
+			`]`, load(t, source, server.URL))
+	})
+
+	t.Run("xml element occurring once as list", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/xml")
+			_, _ = w.Write([]byte(`<catalog><book><title>Go</title></book></catalog>`))
+		}))
+		defer server.Close()
+
+		source := newSource(t, FetchConfiguration{
+			Response: &ResponseConfiguration{Template: `{{ json (list .catalog.book) }}`},
+		})
+		assert.Equal(t, `[{"title":"Go"}]`, load(t, source, server.URL))
+	})
+
+	t.Run("form", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the content type doesn't match the body, the configured decoder wins
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("access_token=abc&scope=read&scope=write\n"))
+		}))
+		defer server.Close()
+
+		source := newSource(t, FetchConfiguration{
+			Response: &ResponseConfiguration{Decoder: ResponseDecoderForm},
+		})
+		assert.Equal(t, `{"access_token":"abc","scope":["read","write"]}`, load(t, source, server.URL))
+	})
+
+	t.Run("pagination with link header", func(t *testing.T) {
+		var requests int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			page := r.URL.Query().Get("page")
+			if page == "" {
+				page = "1"
+			}
+			w.Header().Set("Link", fmt.Sprintf(`</items?page=1>; rel="first", </items?page=%d&per_page=1>; rel="next"`, requests+1))
+			_, _ = w.Write([]byte(fmt.Sprintf(`{"items":[{"page":%s}]}`, page)))
+		}))
+		defer server.Close()
+
+		source := newSource(t, FetchConfiguration{
+			Response: &ResponseConfiguration{SelectPath: []string{"items"}},
+			Pagination: &PaginationConfiguration{
+				MaxPages: 3,
+			},
+		})
+		assert.Equal(t, `[{"page":1},{"page":2},{"page":3}]`, load(t, source, server.URL+"/items"))
+		assert.Equal(t, 3, requests)
+	})
+
+	t.Run("pagination with cursor", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "10", r.URL.Query().Get("limit"))
+			switch r.URL.Query().Get("cursor") {
+			case "":
+				_, _ = w.Write([]byte(`{"data":[1,2],"paging":{"cursor":"a&b"}}`))
+			case "a&b":
+				_, _ = w.Write([]byte(`{"data":[3],"paging":{"cursor":null}}`))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}))
+		defer server.Close()
+
+		source := newSource(t, FetchConfiguration{
+			Response: &ResponseConfiguration{SelectPath: []string{"data"}},
+			Pagination: &PaginationConfiguration{
+				NextPath:        []string{"paging", "cursor"},
+				CursorParameter: "cursor",
+			},
+		})
+		var input []byte
+		input = httpclient.SetInputMethod(input, []byte("GET"))
+		input = httpclient.SetInputURL(input, []byte(server.URL))
+		input = httpclient.SetInputQueryParams(input, []byte(`[{"name":"limit","value":"10"},{"name":"cursor","value":""}]`))
+		out := &bytes.Buffer{}
+		require.NoError(t, source.Load(context.Background(), input, out))
+		assert.Equal(t, `[1,2,3]`, out.String())
+	})
+
+	t.Run("pagination with next url in body", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("offset") == "" {
+				_, _ = w.Write([]byte(`{"values":["a"],"next":"/values?offset=1"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"values":["b"],"next":"/values?offset=1"}`))
+		}))
+		defer server.Close()
+
+		source := newSource(t, FetchConfiguration{
+			Response: &ResponseConfiguration{SelectPath: []string{"values"}},
+			Pagination: &PaginationConfiguration{
+				NextPath: []string{"next"},
+			},
+		})
+		// the last page links to itself
+		assert.Equal(t, `["a","b"]`, load(t, source, server.URL+"/values"))
+	})
+
+	t.Run("pagination doesn't follow links to other origins", func(t *testing.T) {
+		other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected request to other origin with Authorization %q", r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(`[2]`))
+		}))
+		defer other.Close()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			w.Header().Set("Link", fmt.Sprintf(`<%s/items?page=2>; rel="next"`, other.URL))
+			_, _ = w.Write([]byte(`[1]`))
+		}))
+		defer server.Close()
+
+		source := newSource(t, FetchConfiguration{Pagination: &PaginationConfiguration{}})
+		var input []byte
+		input = httpclient.SetInputMethod(input, []byte("GET"))
+		input = httpclient.SetInputURL(input, []byte(server.URL+"/items"))
+		input = httpclient.SetInputHeader(input, []byte(`{"Authorization":["Bearer token"]}`))
+		out := &bytes.Buffer{}
+		require.NoError(t, source.Load(context.Background(), input, out))
+		assert.Equal(t, `[1]`, out.String())
+	})
+
+	t.Run("failing page", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("page") == "2" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Link", `<?page=2>; rel="next"`)
+			_, _ = w.Write([]byte(`[1]`))
+		}))
+		defer server.Close()
+
+		source := newSource(t, FetchConfiguration{Pagination: &PaginationConfiguration{}})
+		var input []byte
+		input = httpclient.SetInputMethod(input, []byte("GET"))
+		input = httpclient.SetInputURL(input, []byte(server.URL))
+		err := source.Load(context.Background(), input, &bytes.Buffer{})
+		assert.ErrorIs(t, err, httpclient.ErrNonOkResponse)
+	})
+}
+
+func TestNewResponseTransformation(t *testing.T) {
+	t.Run("without configuration", func(t *testing.T) {
+		transformation, err := newResponseTransformation(FetchConfiguration{URL: "https://example.com"})
+		assert.NoError(t, err)
+		assert.Nil(t, transformation)
+	})
+
+	t.Run("unknown decoder", func(t *testing.T) {
+		_, err := newResponseTransformation(FetchConfiguration{Response: &ResponseConfiguration{Decoder: "yaml"}})
+		assert.EqualError(t, err, `unknown response decoder "yaml"`)
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		_, err := newResponseTransformation(FetchConfiguration{Response: &ResponseConfiguration{Template: `{{ .name`}})
+		assert.ErrorContains(t, err, "invalid response template")
+	})
+}
+
+func TestNextLink(t *testing.T) {
+	assert.Equal(t, "https://example.com/items?page=2", nextLink([]string{`<https://example.com/items?page=1>; rel="prev"`, `<https://example.com/items?page=2>; rel="next last"`}))
+	assert.Equal(t, "/items?page=2", nextLink([]string{`</items?page=2>;rel=next`}))
+	assert.Equal(t, "", nextLink([]string{`<https://example.com/items?page=1>; rel="prev"`}))
+	assert.Equal(t, "", nextLink(nil))
+}
@@ -27,6 +27,7 @@ type Planner struct {
 	datasourceConfiguration plan.DataSourceConfiguration
 	dataSourcePlannerConfig plan.DataSourcePlannerConfiguration
 	rootField               int
+	rootFieldIsList         bool
 	operationDefinition     int
 }
 
@@ -109,6 +110,51 @@ type FetchConfiguration struct {
 	Header http.Header
 	Query  []QueryConfiguration
 	Body   string
+	// BodyKeys renames keys of the JSON body at any depth, e.g. {"firstName": "first-name"}
+	// for fields of input types which aren't named like the properties of the upstream
+	BodyKeys map[string]string
+	// Response decodes and reshapes the response body, if nil the body has to be JSON in the shape of the field
+	Response *ResponseConfiguration
+	// Pagination fetches the following pages of a list response and concatenates them
+	Pagination *PaginationConfiguration
+}
+
+type ResponseDecoder string
+
+const (
+	// ResponseDecoderAuto chooses the decoder by the Content-Type of the response and falls back to JSON
+	ResponseDecoderAuto ResponseDecoder = ""
+	ResponseDecoderJSON ResponseDecoder = "json"
+	// ResponseDecoderXML turns elements into objects, attributes into "@name" fields and the text of elements with attributes or children into "#text".
+	// Repeated elements become lists, all values are strings.
+	ResponseDecoderXML ResponseDecoder = "xml"
+	// ResponseDecoderForm turns an application/x-www-form-urlencoded body into an object, repeated keys become lists
+	ResponseDecoderForm ResponseDecoder = "form"
+)
+
+// ResponseConfiguration transforms the decoded response body in the order of its fields
+type ResponseConfiguration struct {
+	Decoder ResponseDecoder
+	// SelectPath selects the data at the path of the decoded body, e.g. ["result", "items"] to unwrap an envelope.
+	// Numeric elements select list items.
+	SelectPath []string
+	// Fields sets fields of the selected object, or of every object of the selected list,
+	// to the value at the path relative to the object, e.g. {"name": ["attributes", "full_name"]}
+	Fields map[string][]string
+	// Template is a text/template rendering the selected data into the JSON response, e.g. `{"items":{{ json .results }},"total":{{ .meta.total }}}`.
+	// The json function renders a value as JSON.
+	Template string
+}
+
+// PaginationConfiguration follows the next page either from the rel="next" URL of the Link header or from the body.
+// Next URLs are only followed on the origin of the first page, because the pages are requested with its headers, e.g. credentials.
+type PaginationConfiguration struct {
+	// NextPath is the path of the next page cursor or URL in the decoded body. If empty the Link header is used.
+	NextPath []string
+	// CursorParameter is the query parameter the cursor at NextPath is sent in. If empty the value at NextPath is the URL of the next page.
+	CursorParameter string
+	// MaxPages limits the number of fetched pages, it defaults to 10
+	MaxPages int
 }
 
 type QueryConfiguration struct {
@@ -131,6 +177,9 @@ func (p *Planner) EnterField(ref int) {
 		return
 	}
 	p.rootField = ref
+	if definition, ok := p.v.Walker.FieldDefinition(ref); ok {
+		p.rootFieldIsList = p.v.Definition.TypeIsList(p.v.Definition.FieldDefinitionType(definition))
+	}
 }
 
 func (p *Planner) allowField(ref int) bool {
@@ -168,12 +217,24 @@ func (p *Planner) configureInput() []byte {
 
 func (p *Planner) ConfigureFetch() resolve.FetchConfiguration {
 	input := p.configureInput()
-	return resolve.FetchConfiguration{
+	transformation, err := newResponseTransformation(p.config.Fetch)
+	if err != nil {
+		p.v.Walker.StopWithInternalErr(err)
+		return resolve.FetchConfiguration{}
+	}
+	fetch := resolve.FetchConfiguration{
 		Input: string(input),
 		DataSource: &Source{
-			client: p.client,
+			client:         p.client,
+			bodyKeys:       p.config.Fetch.BodyKeys,
+			transformation: transformation,
 		},
 	}
+	if p.rootFieldIsList {
+		// a JSON array can't be merged into the parent object, so it's merged at the name of the field
+		fetch.PostProcessing.MergePath = []string{p.v.Operation.FieldNameString(p.rootField)}
+	}
+	return fetch
 }
 
 func (p *Planner) ConfigureSubscription() plan.SubscriptionConfiguration {
@@ -254,9 +315,19 @@ func (p *Planner) marshalQueryParams(params []QueryConfiguration) ([]byte, error
 }
 
 type Source struct {
-	client *http.Client
+	client         *http.Client
+	bodyKeys       map[string]string
+	transformation *responseTransformation
 }
 
 func (s *Source) Load(ctx context.Context, input []byte, w io.Writer) (err error) {
+	if len(s.bodyKeys) != 0 {
+		if input, err = renameBodyKeys(input, s.bodyKeys); err != nil {
+			return err
+		}
+	}
+	if s.transformation != nil {
+		return s.transformation.load(ctx, s.client, input, w)
+	}
 	return httpclient.Do(s.client, ctx, input, w)
 }
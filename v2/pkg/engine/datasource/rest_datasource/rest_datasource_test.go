@@ -15,6 +15,7 @@ const (
 	schema = `
 		type Query {
 			friend: Friend
+			friends: [Friend]
 			withArgument(id: String!, name: String, optional: String): Friend
 			withArrayArguments(names: [String]): Friend
 			withIntArgument(limit: Int): Friend
@@ -1407,6 +1408,76 @@ func TestFastHttpJsonDataSourcePlanning(t *testing.T) {
 			DisableResolveFieldPositions: true,
 		},
 	))
+	t.Run("get request with list response", datasourcetesting.RunTest(schema, `
+		query {
+			friends {
+				name
+			}
+		}`, "",
+		&plan.SynchronousResponsePlan{
+			Response: &resolve.GraphQLResponse{
+				Data: &resolve.Object{
+					Fetch: &resolve.SingleFetch{
+						FetchConfiguration: resolve.FetchConfiguration{
+							Input:      `{"method":"GET","url":"https://example.com/friends"}`,
+							DataSource: &Source{},
+							PostProcessing: resolve.PostProcessingConfiguration{
+								MergePath: []string{"friends"},
+							},
+						},
+						DataSourceIdentifier: []byte("rest_datasource.Source"),
+					},
+					Fields: []*resolve.Field{
+						{
+							Name: []byte("friends"),
+							Value: &resolve.Array{
+								Path:     []string{"friends"},
+								Nullable: true,
+								Item: &resolve.Object{
+									Nullable: true,
+									Fields: []*resolve.Field{
+										{
+											Name: []byte("name"),
+											Value: &resolve.String{
+												Path:     []string{"name"},
+												Nullable: true,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		plan.Configuration{
+			DataSources: []plan.DataSourceConfiguration{
+				{
+					RootNodes: []plan.TypeField{
+						{
+							TypeName:   "Query",
+							FieldNames: []string{"friends"},
+						},
+					},
+					ChildNodes: []plan.TypeField{
+						{
+							TypeName:   "Friend",
+							FieldNames: []string{"name"},
+						},
+					},
+					Custom: ConfigJSON(Configuration{
+						Fetch: FetchConfiguration{
+							URL:    "https://example.com/friends",
+							Method: "GET",
+						},
+					}),
+					Factory: &Factory{},
+				},
+			},
+			DisableResolveFieldPositions: true,
+		},
+	))
 }
 
 //func TestHttpJsonDataSource_Load(t *testing.T) {
@@ -179,9 +179,24 @@ type DataSourcePlanner interface {
 	UpstreamSchema(dataSourceConfig DataSourceConfiguration) *ast.Document
 }
 
+// SubscriptionFilterDirectiveName is the name of the directive filtering the events of a subscription field per subscriber
+const SubscriptionFilterDirectiveName = "filter"
+
+// SubscriptionFilterDirectiveSDL contains the definition of the @filter directive, it has to be part of the schema to filter subscriptions.
+// An event is only sent to a subscriber if the value at the dot separated path "field" of the root field in the event
+// equals the value of the argument "argument" of the subscriber or the claim "claim" of the subscriber, only "sub" is supported.
+// Repeated directives have to match all.
+// Operations selecting the field without the argument of a filter are rejected.
+//
+//	orderUpdated(customerId: ID!): Order @filter(field: "customer.id", argument: "customerId")
+const SubscriptionFilterDirectiveSDL = `directive @filter(field: String!, argument: String, claim: String) repeatable on FIELD_DEFINITION`
+
 type SubscriptionConfiguration struct {
 	Input          string
 	Variables      resolve.Variables
 	DataSource     resolve.SubscriptionDataSource
 	PostProcessing resolve.PostProcessingConfiguration
+	// Filter decides for every subscriber whether an event is sent to it, in addition to @filter directives.
+	// It allows subscribers with different arguments or claims to share a single trigger.
+	Filter resolve.SubscriptionFilter
 }
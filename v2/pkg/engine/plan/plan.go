@@ -9,6 +9,7 @@ type Kind int
 const (
 	SynchronousResponseKind Kind = iota + 1
 	SubscriptionResponseKind
+	LiveQueryResponseKind
 )
 
 type Plan interface {
@@ -41,3 +42,17 @@ func (s *SubscriptionResponsePlan) SetFlushInterval(interval int64) {
 func (_ *SubscriptionResponsePlan) PlanKind() Kind {
 	return SubscriptionResponseKind
 }
+
+// LiveQueryResponsePlan streams the results of a query marked with @live
+type LiveQueryResponsePlan struct {
+	Response      *resolve.GraphQLLiveQuery
+	FlushInterval int64
+}
+
+func (l *LiveQueryResponsePlan) SetFlushInterval(interval int64) {
+	l.FlushInterval = interval
+}
+
+func (_ *LiveQueryResponsePlan) PlanKind() Kind {
+	return LiveQueryResponseKind
+}
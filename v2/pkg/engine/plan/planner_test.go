@@ -555,6 +555,58 @@ func TestPlanner_Plan(t *testing.T) {
 		))
 	})
 
+	t.Run("subscription filter", func(t *testing.T) {
+		schema := SubscriptionFilterDirectiveSDL + `
+			schema {
+				query: Query
+				subscription: Subscription
+			}
+
+			type Query {
+				hello: String
+			}
+
+			type Subscription {
+				orderUpdated(customerId: ID): Order @filter(field: "customer.id", argument: "customerId")
+			}
+
+			type Order {
+				id: ID!
+			}
+		`
+		cfg := Configuration{
+			DataSources: []DataSourceConfiguration{
+				{
+					RootNodes: []TypeField{
+						{
+							TypeName:   "Subscription",
+							FieldNames: []string{"orderUpdated"},
+						},
+					},
+					ChildNodes: []TypeField{
+						{
+							TypeName:   "Order",
+							FieldNames: []string{"id"},
+						},
+					},
+				},
+			},
+			DisableResolveFieldPositions: true,
+		}
+
+		t.Run("should write into error report when the filter argument is missing", func(t *testing.T) {
+			var report operationreport.Report
+			_ = testLogic(schema, `
+				subscription OrderUpdated {
+					orderUpdated {
+						id
+					}
+				}
+			`, "OrderUpdated", cfg, &report)
+			assert.Equal(t, "external: argument: customerId is required on field: orderUpdated but missing, locations: [], path: []", report.Error())
+		})
+	})
+
 	t.Run("unescape response json", func(t *testing.T) {
 		schema := `
 			scalar JSON
@@ -13,6 +13,7 @@ import (
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astvisitor"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer/literal"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/operationreport"
 )
 
 type DataSourceDebugger interface {
@@ -1099,6 +1100,53 @@ func (v *Visitor) configureSubscription(config objectFetchConfiguration) {
 	config.trigger.PostProcessing = subscription.PostProcessing
 	v.resolveInputTemplates(config, &subscription.Input, &config.trigger.Variables)
 	config.trigger.Input = []byte(subscription.Input)
+	config.trigger.Filters = v.subscriptionFieldFilters(config)
+	if subscription.Filter != nil {
+		config.trigger.Filters = append(config.trigger.Filters, subscription.Filter)
+	}
+}
+
+// subscriptionFieldFilters creates a resolve.SubscriptionFieldFilter for every @filter directive of the subscription field.
+// Filters comparing the event with an argument require the argument, the operation is rejected if it's not set.
+func (v *Visitor) subscriptionFieldFilters(config objectFetchConfiguration) (filters []resolve.SubscriptionFilter) {
+	fieldDefinition := config.fieldDefinitionRef
+	if fieldDefinition == -1 || !v.Definition.FieldDefinitionHasDirectives(fieldDefinition) {
+		return nil
+	}
+	fieldName := v.Definition.FieldDefinitionNameString(fieldDefinition)
+	for _, directive := range v.Definition.FieldDefinitions[fieldDefinition].Directives.Refs {
+		if v.Definition.DirectiveNameString(directive) != SubscriptionFilterDirectiveName {
+			continue
+		}
+		field, ok := v.Definition.DirectiveArgumentValueByName(directive, []byte("field"))
+		if !ok {
+			continue
+		}
+		filter := &resolve.SubscriptionFieldFilter{
+			FieldPath:      append([]string{fieldName}, strings.Split(v.Definition.ValueContentString(field), ".")...),
+			PostProcessing: config.trigger.PostProcessing,
+		}
+		if claim, ok := v.Definition.DirectiveArgumentValueByName(directive, []byte("claim")); ok {
+			filter.Claim = v.Definition.ValueContentString(claim)
+			filters = append(filters, filter)
+			continue
+		}
+		argument, ok := v.Definition.DirectiveArgumentValueByName(directive, []byte("argument"))
+		if !ok {
+			continue
+		}
+		argumentName := v.Definition.ValueContentString(argument)
+		if _, ok := v.Operation.FieldArgument(config.fieldRef, []byte(argumentName)); !ok {
+			// without the argument the subscriber would receive the events of all other subscribers
+			v.Walker.StopWithExternalErr(operationreport.ErrArgumentRequiredOnField([]byte(argumentName), []byte(fieldName)))
+			return nil
+		}
+		input := fmt.Sprintf("{{ .arguments.%s }}", argumentName)
+		v.resolveInputTemplates(config, &input, &filter.Variables)
+		filter.Input = []byte(input)
+		filters = append(filters, filter)
+	}
+	return filters
 }
 
 func (v *Visitor) configureObjectFetch(config objectFetchConfiguration) {
@@ -56,6 +56,13 @@ func (d *ResolveInputTemplates) traverseTrigger(trigger *resolve.GraphQLSubscrip
 	d.resolveInputTemplate(trigger.Variables, string(trigger.Input), &trigger.InputTemplate)
 	trigger.Input = nil
 	trigger.Variables = nil
+	for _, filter := range trigger.Filters {
+		if fieldFilter, ok := filter.(*resolve.SubscriptionFieldFilter); ok {
+			d.resolveInputTemplate(fieldFilter.Variables, string(fieldFilter.Input), &fieldFilter.ValueTemplate)
+			fieldFilter.Input = nil
+			fieldFilter.Variables = nil
+		}
+	}
 }
 
 func (d *ResolveInputTemplates) traverseSingleFetch(fetch *resolve.SingleFetch) {
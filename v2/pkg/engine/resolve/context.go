@@ -20,7 +20,11 @@ type Context struct {
 	Extensions            []byte
 	Stats                 Stats
 
-	authorizer Authorizer
+	authorizer     Authorizer
+	claims         *Claims
+	batchLoadCache *BatchLoadCache
+
+	federatedTraceHook FederatedTraceHook
 
 	subgraphErrors error
 }
@@ -46,11 +50,40 @@ type Authorizer interface {
 	AuthorizeObjectField(ctx *Context, dataSourceID string, object json.RawMessage, coordinate GraphCoordinate) (result *AuthorizationDeny, err error)
 }
 
+// Claims describe an authenticated client, they are evaluated by authorizers
+type Claims struct {
+	Subject string
+	Scopes  []string
+}
+
 func (c *Context) WithAuthorizer(authorizer Authorizer) *Context {
 	c.authorizer = authorizer
 	return c
 }
 
+// WithClaims sets the claims of the authenticated client, without claims the client is unauthenticated
+func (c *Context) WithClaims(claims *Claims) *Context {
+	c.claims = claims
+	return c
+}
+
+// Claims returns the claims of the authenticated client or nil if the client is unauthenticated
+func (c *Context) Claims() *Claims {
+	return c.claims
+}
+
+// WithBatchLoadCache shares the responses of identical loads with all other operations resolved with the same cache
+func (c *Context) WithBatchLoadCache(cache *BatchLoadCache) *Context {
+	c.batchLoadCache = cache
+	return c
+}
+
+// WithFederatedTraceHook requests federated traces (ftv1) from the subgraphs and passes the combined trace of the operation to the hook
+func (c *Context) WithFederatedTraceHook(hook FederatedTraceHook) *Context {
+	c.federatedTraceHook = hook
+	return c
+}
+
 func (c *Context) SubgraphErrors() error {
 	return c.subgraphErrors
 }
@@ -121,6 +154,9 @@ func (c *Context) Free() {
 	c.Stats.Reset()
 	c.subgraphErrors = nil
 	c.authorizer = nil
+	c.claims = nil
+	c.batchLoadCache = nil
+	c.federatedTraceHook = nil
 }
 
 type traceStartKey struct{}
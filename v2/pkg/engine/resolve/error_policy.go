@@ -0,0 +1,190 @@
+package resolve
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+
+	"github.com/buger/jsonparser"
+	"github.com/pkg/errors"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astjson"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/pool"
+)
+
+const (
+	// DefaultMaskedErrorMessage replaces the message of masked subgraph errors if no other message is configured
+	DefaultMaskedErrorMessage = "Internal server error"
+
+	// ErrorCodeSubgraphFetchFailed is the code of errors rendered when a fetch to a subgraph failed
+	ErrorCodeSubgraphFetchFailed = "SUBGRAPH_FETCH_FAILED"
+	// ErrorCodeUnauthorized is the code of errors rendered when the Authorizer rejected a fetch or a field
+	ErrorCodeUnauthorized = "UNAUTHORIZED"
+	// ErrorCodeInternalServerError is the code of masked subgraph errors
+	ErrorCodeInternalServerError = "INTERNAL_SERVER_ERROR"
+)
+
+// ErrorPolicy controls how errors are rendered into client responses, see ResolverOptions.ErrorPolicy.
+// If a policy is set, errors rendered by the resolver get a stable extensions.code.
+type ErrorPolicy struct {
+	// MaskedDataSources are the ids of the data sources whose error messages are replaced by MaskedErrorMessage
+	MaskedDataSources []string
+	// MaskAllDataSources masks the errors of all data sources
+	MaskAllDataSources bool
+	// MaskedErrorMessage replaces the messages of masked errors, if empty DefaultMaskedErrorMessage is used
+	MaskedErrorMessage string
+	// AllowedExtensionKeys are the extension keys of unmasked subgraph errors which are passed to the client,
+	// all other extensions are removed
+	AllowedExtensionKeys []string
+	// OnMaskedError is called with the original error of every masked error, e.g. to log it with its correlation id
+	OnMaskedError func(ctx *Context, maskedError MaskedError)
+	// NewCorrelationID returns the id attached to masked errors, defaults to 16 random hex characters
+	NewCorrelationID func() string
+}
+
+// MaskedError is a subgraph error which was masked before it was sent to the client
+type MaskedError struct {
+	DataSourceID string
+	// CorrelationID is sent to the client in extensions.correlationId
+	CorrelationID string
+	// Path is the path in the response at which the subgraph was fetched
+	Path string
+	// Error is the original error object of the subgraph response
+	Error json.RawMessage
+}
+
+type maskedErrorObject struct {
+	Message    string                      `json:"message"`
+	Path       json.RawMessage             `json:"path,omitempty"`
+	Extensions maskedErrorObjectExtensions `json:"extensions"`
+}
+
+type maskedErrorObjectExtensions struct {
+	Code          string `json:"code"`
+	CorrelationID string `json:"correlationId"`
+}
+
+func (e *ErrorPolicy) masksDataSource(dataSourceID string) bool {
+	return e.MaskAllDataSources || slices.Contains(e.MaskedDataSources, dataSourceID)
+}
+
+func (e *ErrorPolicy) maskedErrorMessage() string {
+	if e.MaskedErrorMessage == "" {
+		return DefaultMaskedErrorMessage
+	}
+	return e.MaskedErrorMessage
+}
+
+func (e *ErrorPolicy) correlationID() string {
+	if e.NewCorrelationID != nil {
+		return e.NewCorrelationID()
+	}
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// redactExtensions removes all extensions of an error object which are not allowed
+func (e *ErrorPolicy) redactExtensions(errorObject []byte) ([]byte, error) {
+	extensions, dataType, _, err := jsonparser.Get(errorObject, "extensions")
+	if dataType == jsonparser.NotExist {
+		return errorObject, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := []byte(`{}`)
+	if dataType == jsonparser.Object {
+		for _, key := range e.AllowedExtensionKeys {
+			value, valueType, _, err := jsonparser.Get(extensions, key)
+			if err != nil {
+				continue
+			}
+			if valueType == jsonparser.String {
+				value = append(append([]byte{'"'}, value...), '"')
+			}
+			if allowed, err = jsonparser.Set(allowed, value, key); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	redacted := jsonparser.Delete(append([]byte(nil), errorObject...), "extensions")
+	if len(allowed) == len(`{}`) {
+		return redacted, nil
+	}
+	return jsonparser.Set(redacted, allowed, "extensions")
+}
+
+// applyErrorCode adds extensions.code to an error object rendered by the resolver, it's a noop without policy
+func applyErrorCode(policy *ErrorPolicy, data *astjson.JSON, errorRef int, code string) error {
+	if policy == nil {
+		return nil
+	}
+	extensions, err := data.AppendObject([]byte(`{"code":"` + code + `"}`))
+	if err != nil {
+		return err
+	}
+	data.SetObjectField(errorRef, extensions, []string{"extensions"})
+	return nil
+}
+
+// applyErrorPolicy masks the errors of a subgraph response or removes their extensions which are not allowed
+func (l *Loader) applyErrorPolicy(errorsRef int, res *result) error {
+	if l.errorPolicy == nil || !l.data.NodeIsDefined(errorsRef) || l.data.Nodes[errorsRef].Kind != astjson.NodeKindArray {
+		return nil
+	}
+	mask := l.errorPolicy.masksDataSource(res.subgraphName)
+
+	buf := pool.BytesBuffer.Get()
+	defer pool.BytesBuffer.Put(buf)
+	for i, errorRef := range l.data.Nodes[errorsRef].ArrayValues {
+		if l.data.Nodes[errorRef].Kind != astjson.NodeKindObject {
+			continue
+		}
+		buf.Reset()
+		err := l.data.PrintNode(l.data.Nodes[errorRef], buf)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		var rendered []byte
+		if mask {
+			rendered, err = l.maskError(buf.Bytes(), res)
+		} else {
+			rendered, err = l.errorPolicy.redactExtensions(buf.Bytes())
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		ref, err := l.data.AppendObject(rendered)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		l.data.Nodes[errorsRef].ArrayValues[i] = ref
+	}
+	return nil
+}
+
+func (l *Loader) maskError(errorObject []byte, res *result) ([]byte, error) {
+	masked := maskedErrorObject{
+		Message: l.errorPolicy.maskedErrorMessage(),
+		Extensions: maskedErrorObjectExtensions{
+			Code:          ErrorCodeInternalServerError,
+			CorrelationID: l.errorPolicy.correlationID(),
+		},
+	}
+	if path, dataType, _, err := jsonparser.Get(errorObject, "path"); err == nil && dataType == jsonparser.Array {
+		masked.Path = path
+	}
+	if l.errorPolicy.OnMaskedError != nil {
+		l.errorPolicy.OnMaskedError(l.ctx, MaskedError{
+			DataSourceID:  res.subgraphName,
+			CorrelationID: masked.Extensions.CorrelationID,
+			Path:          l.renderPath(),
+			Error:         append(json.RawMessage(nil), errorObject...),
+		})
+	}
+	return json.Marshal(masked)
+}
@@ -0,0 +1,128 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingDataSource struct{}
+
+func (failingDataSource) Load(ctx context.Context, input []byte, w io.Writer) error {
+	return errors.New("connection refused")
+}
+
+func TestResolver_ErrorPolicy(t *testing.T) {
+	response := func(dataSource DataSource) *GraphQLResponse {
+		return &GraphQLResponse{
+			Data: &Object{
+				Nullable: false,
+				Fetch: &SingleFetch{
+					FetchConfiguration: FetchConfiguration{
+						DataSource: dataSource,
+						PostProcessing: PostProcessingConfiguration{
+							SelectResponseDataPath:   []string{"data"},
+							SelectResponseErrorsPath: []string{"errors"},
+						},
+					},
+					Info: &FetchInfo{
+						DataSourceID: "products",
+					},
+				},
+				Fields: []*Field{
+					{
+						Name: []byte("name"),
+						Value: &String{
+							Path:     []string{"name"},
+							Nullable: true,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	subgraphErrors := FakeDataSource(`{"errors":[{"message":"pq: relation \"products\" does not exist","path":["name"],"extensions":{"code":"DB_ERROR","stacktrace":["main.go:12"]}}],"data":{"name":null}}`)
+
+	resolve := func(t *testing.T, policy *ErrorPolicy, dataSource DataSource) string {
+		t.Helper()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		resolver := New(ctx, ResolverOptions{
+			MaxConcurrency: 1024,
+			ErrorPolicy:    policy,
+		})
+
+		buf := &bytes.Buffer{}
+		err := resolver.ResolveGraphQLResponse(&Context{ctx: context.Background()}, response(dataSource), nil, buf)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	t.Run("without policy errors are passed through", func(t *testing.T) {
+		assert.Equal(t,
+			`{"errors":[{"message":"pq: relation \"products\" does not exist","path":["name"],"extensions":{"code":"DB_ERROR","stacktrace":["main.go:12"]}}],"data":{"name":null}}`,
+			resolve(t, nil, subgraphErrors),
+		)
+	})
+
+	t.Run("extensions are allow-listed", func(t *testing.T) {
+		assert.Equal(t,
+			`{"errors":[{"message":"pq: relation \"products\" does not exist","path":["name"],"extensions":{"code":"DB_ERROR"}}],"data":{"name":null}}`,
+			resolve(t, &ErrorPolicy{AllowedExtensionKeys: []string{"code"}}, subgraphErrors),
+		)
+		assert.Equal(t,
+			`{"errors":[{"message":"pq: relation \"products\" does not exist","path":["name"]}],"data":{"name":null}}`,
+			resolve(t, &ErrorPolicy{}, subgraphErrors),
+		)
+	})
+
+	t.Run("errors of masked data sources are masked", func(t *testing.T) {
+		var maskedErrors []MaskedError
+		policy := &ErrorPolicy{
+			MaskedDataSources: []string{"products"},
+			NewCorrelationID: func() string {
+				return "abc123"
+			},
+			OnMaskedError: func(ctx *Context, maskedError MaskedError) {
+				maskedErrors = append(maskedErrors, maskedError)
+			},
+		}
+
+		assert.Equal(t,
+			`{"errors":[{"message":"Internal server error","path":["name"],"extensions":{"code":"INTERNAL_SERVER_ERROR","correlationId":"abc123"}}],"data":{"name":null}}`,
+			resolve(t, policy, subgraphErrors),
+		)
+		require.Len(t, maskedErrors, 1)
+		assert.Equal(t, "products", maskedErrors[0].DataSourceID)
+		assert.Equal(t, "abc123", maskedErrors[0].CorrelationID)
+		assert.Contains(t, string(maskedErrors[0].Error), `pq: relation \"products\" does not exist`)
+	})
+
+	t.Run("errors of other data sources are not masked", func(t *testing.T) {
+		policy := &ErrorPolicy{
+			MaskedDataSources:    []string{"users"},
+			AllowedExtensionKeys: []string{"code"},
+		}
+		assert.Contains(t, resolve(t, policy, subgraphErrors), `pq: relation`)
+
+		policy = &ErrorPolicy{MaskAllDataSources: true, MaskedErrorMessage: "Something went wrong"}
+		assert.Contains(t, resolve(t, policy, subgraphErrors), `{"message":"Something went wrong","path":["name"]`)
+	})
+
+	t.Run("fetch failures get a code", func(t *testing.T) {
+		assert.Equal(t,
+			`{"errors":[{"message":"Failed to fetch from Subgraph 'products' at path 'query'.","extensions":{"code":"SUBGRAPH_FETCH_FAILED"}}],"data":null}`,
+			resolve(t, &ErrorPolicy{}, failingDataSource{}),
+		)
+		assert.Equal(t,
+			`{"errors":[{"message":"Failed to fetch from Subgraph 'products' at path 'query'."}],"data":null}`,
+			resolve(t, nil, failingDataSource{}),
+		)
+	})
+}
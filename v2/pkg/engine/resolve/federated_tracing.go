@@ -0,0 +1,186 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"sync"
+	"time"
+
+	apollotrace "github.com/99designs/gqlgen/graphql/handler/apollofederatedtracingv1/generated"
+	"github.com/buger/jsonparser"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FederatedTraceHook receives the federated trace (ftv1) of an operation after all fetches of the operation completed.
+// The query plan of the trace mirrors the fetches of the operation, every fetch node contains the timings measured by the
+// resolver and the trace returned by the subgraph in the "ftv1" response extension.
+type FederatedTraceHook interface {
+	OnFederatedTrace(ctx *Context, trace *apollotrace.Trace)
+}
+
+// FederatedTraceRequested returns true if the data source should request a federated trace from the subgraph,
+// e.g. by sending the "apollo-federation-include-trace: ftv1" header
+func FederatedTraceRequested(ctx context.Context) bool {
+	return federatedTraceGroupFromContext(ctx) != nil
+}
+
+type federatedTraceGroupKey struct{}
+
+// federatedTraceGroup collects the nodes of a sequence or parallel node of the query plan
+type federatedTraceGroup struct {
+	mu    sync.Mutex
+	nodes *[]*apollotrace.Trace_QueryPlanNode
+}
+
+func (g *federatedTraceGroup) append(node *apollotrace.Trace_QueryPlanNode) {
+	g.mu.Lock()
+	*g.nodes = append(*g.nodes, node)
+	g.mu.Unlock()
+}
+
+func (g *federatedTraceGroup) sequence() *federatedTraceGroup {
+	sequence := &apollotrace.Trace_QueryPlanNode_SequenceNode{}
+	g.append(&apollotrace.Trace_QueryPlanNode{
+		Node: &apollotrace.Trace_QueryPlanNode_Sequence{Sequence: sequence},
+	})
+	return &federatedTraceGroup{nodes: &sequence.Nodes}
+}
+
+func (g *federatedTraceGroup) parallel() *federatedTraceGroup {
+	parallel := &apollotrace.Trace_QueryPlanNode_ParallelNode{}
+	g.append(&apollotrace.Trace_QueryPlanNode{
+		Node: &apollotrace.Trace_QueryPlanNode_Parallel{Parallel: parallel},
+	})
+	return &federatedTraceGroup{nodes: &parallel.Nodes}
+}
+
+func federatedTraceGroupFromContext(ctx context.Context) *federatedTraceGroup {
+	group, _ := ctx.Value(federatedTraceGroupKey{}).(*federatedTraceGroup)
+	return group
+}
+
+// withFederatedTraceParallelGroup returns ctx with a new parallel group added to the group of groupCtx,
+// ctx is returned unchanged if groupCtx has no group
+func withFederatedTraceParallelGroup(ctx, groupCtx context.Context) context.Context {
+	parent := federatedTraceGroupFromContext(groupCtx)
+	if parent == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, federatedTraceGroupKey{}, parent.parallel())
+}
+
+// federatedTrace is the trace of a single operation
+type federatedTrace struct {
+	start time.Time
+	root  []*apollotrace.Trace_QueryPlanNode
+	// group is the group of fetches which are resolved sequentially by the loader
+	group *federatedTraceGroup
+}
+
+func newFederatedTrace() *federatedTrace {
+	t := &federatedTrace{
+		start: time.Now(),
+	}
+	t.group = &federatedTraceGroup{nodes: &t.root}
+	return t
+}
+
+func (t *federatedTrace) finish() *apollotrace.Trace {
+	end := time.Now()
+	trace := &apollotrace.Trace{
+		StartTime:  timestamppb.New(t.start),
+		EndTime:    timestamppb.New(end),
+		DurationNs: uint64(end.Sub(t.start).Nanoseconds()),
+	}
+	switch len(t.root) {
+	case 0:
+	case 1:
+		trace.QueryPlan = t.root[0]
+	default:
+		trace.QueryPlan = &apollotrace.Trace_QueryPlanNode{
+			Node: &apollotrace.Trace_QueryPlanNode_Sequence{
+				Sequence: &apollotrace.Trace_QueryPlanNode_SequenceNode{Nodes: t.root},
+			},
+		}
+	}
+	return trace
+}
+
+// federatedTraceContext returns ctx with the current sequential group if federated tracing is enabled
+func (l *Loader) federatedTraceContext(ctx context.Context) context.Context {
+	if l.federatedTrace == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, federatedTraceGroupKey{}, l.federatedTrace.group)
+}
+
+// federatedTraceParallelContext returns ctx with a new parallel group if federated tracing is enabled
+func (l *Loader) federatedTraceParallelContext(ctx context.Context) context.Context {
+	if l.federatedTrace == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, federatedTraceGroupKey{}, l.federatedTrace.group.parallel())
+}
+
+// enterFederatedTraceSequence starts a new sequential group and returns a func to restore the previous group
+func (l *Loader) enterFederatedTraceSequence() func() {
+	if l.federatedTrace == nil {
+		return func() {}
+	}
+	parent := l.federatedTrace.group
+	l.federatedTrace.group = parent.sequence()
+	return func() {
+		l.federatedTrace.group = parent
+	}
+}
+
+// federatedTraceFetchNode creates the query plan node of a fetch, nested fetches are wrapped in a flatten node with the current path.
+// If the response contains no ftv1 extension, the node contains the timings of the resolver only.
+func (l *Loader) federatedTraceFetchNode(info *FetchInfo, sent, received time.Time, out *bytes.Buffer) *apollotrace.Trace_QueryPlanNode {
+	fetch := &apollotrace.Trace_QueryPlanNode_FetchNode{
+		SentTimeOffset: uint64(sent.Sub(l.federatedTrace.start).Nanoseconds()),
+		SentTime:       timestamppb.New(sent),
+		ReceivedTime:   timestamppb.New(received),
+	}
+	if info != nil {
+		fetch.ServiceName = info.DataSourceID
+	}
+	if encoded, err := jsonparser.GetString(out.Bytes(), "extensions", "ftv1"); err == nil {
+		fetch.Trace, fetch.TraceParsingFailed = decodeFederatedTrace(encoded)
+	}
+	node := &apollotrace.Trace_QueryPlanNode{
+		Node: &apollotrace.Trace_QueryPlanNode_Fetch{Fetch: fetch},
+	}
+	if len(l.path) == 0 {
+		return node
+	}
+	path := make([]*apollotrace.Trace_QueryPlanNode_ResponsePathElement, len(l.path))
+	for i := range l.path {
+		path[i] = &apollotrace.Trace_QueryPlanNode_ResponsePathElement{
+			Id: &apollotrace.Trace_QueryPlanNode_ResponsePathElement_FieldName{FieldName: l.path[i]},
+		}
+	}
+	return &apollotrace.Trace_QueryPlanNode{
+		Node: &apollotrace.Trace_QueryPlanNode_Flatten{
+			Flatten: &apollotrace.Trace_QueryPlanNode_FlattenNode{
+				ResponsePath: path,
+				Node:         node,
+			},
+		},
+	}
+}
+
+// decodeFederatedTrace decodes the base64 encoded protobuf trace of the ftv1 extension
+func decodeFederatedTrace(encoded string) (trace *apollotrace.Trace, parsingFailed bool) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, true
+	}
+	trace = &apollotrace.Trace{}
+	if err = proto.Unmarshal(data, trace); err != nil {
+		return nil, true
+	}
+	return trace, false
+}
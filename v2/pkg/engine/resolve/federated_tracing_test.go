@@ -0,0 +1,229 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"testing"
+
+	apollotrace "github.com/99designs/gqlgen/graphql/handler/apollofederatedtracingv1/generated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+	"google.golang.org/protobuf/proto"
+)
+
+type federatedTraceRecorder struct {
+	traces []*apollotrace.Trace
+}
+
+func (f *federatedTraceRecorder) OnFederatedTrace(ctx *Context, trace *apollotrace.Trace) {
+	f.traces = append(f.traces, trace)
+}
+
+// ftv1DataSource responds with data and the ftv1 extension if a federated trace was requested
+type ftv1DataSource struct {
+	data      string
+	ftv1      string
+	requested atomic.Bool
+}
+
+func (f *ftv1DataSource) Load(ctx context.Context, input []byte, w io.Writer) error {
+	if !FederatedTraceRequested(ctx) {
+		_, err := fmt.Fprintf(w, `{"data":%s}`, f.data)
+		return err
+	}
+	f.requested.Store(true)
+	_, err := fmt.Fprintf(w, `{"data":%s,"extensions":{"ftv1":%q}}`, f.data, f.ftv1)
+	return err
+}
+
+func encodeFederatedTrace(t *testing.T, durationNs uint64) string {
+	t.Helper()
+	data, err := proto.Marshal(&apollotrace.Trace{DurationNs: durationNs})
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestResolver_FederatedTracing(t *testing.T) {
+	singleFetch := func(dataSourceID string, dataSource DataSource) *SingleFetch {
+		return &SingleFetch{
+			FetchConfiguration: FetchConfiguration{
+				DataSource: dataSource,
+				PostProcessing: PostProcessingConfiguration{
+					SelectResponseDataPath: []string{"data"},
+				},
+			},
+			Info: &FetchInfo{
+				DataSourceID: dataSourceID,
+			},
+		}
+	}
+
+	fetchNode := func(t *testing.T, node *apollotrace.Trace_QueryPlanNode) *apollotrace.Trace_QueryPlanNode_FetchNode {
+		t.Helper()
+		fetch, ok := node.Node.(*apollotrace.Trace_QueryPlanNode_Fetch)
+		require.True(t, ok, "expected fetch node, got %T", node.Node)
+		return fetch.Fetch
+	}
+
+	resolve := func(t *testing.T, response *GraphQLResponse, hook FederatedTraceHook) string {
+		t.Helper()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		resolver := New(ctx, ResolverOptions{MaxConcurrency: 1024})
+
+		resolveCtx := &Context{ctx: context.Background()}
+		if hook != nil {
+			resolveCtx.WithFederatedTraceHook(hook)
+		}
+		buf := &bytes.Buffer{}
+		err := resolver.ResolveGraphQLResponse(resolveCtx, response, nil, buf)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	t.Run("root and nested fetch", func(t *testing.T) {
+		accounts := &ftv1DataSource{data: `{"user":{"name":"Jens"}}`, ftv1: encodeFederatedTrace(t, 100)}
+		reviews := &ftv1DataSource{data: `{"rating":5}`, ftv1: encodeFederatedTrace(t, 200)}
+		response := &GraphQLResponse{
+			Data: &Object{
+				Fetch: singleFetch("accounts", accounts),
+				Fields: []*Field{
+					{
+						Name: []byte("user"),
+						Value: &Object{
+							Path:  []string{"user"},
+							Fetch: singleFetch("reviews", reviews),
+							Fields: []*Field{
+								{
+									Name:  []byte("name"),
+									Value: &String{Path: []string{"name"}},
+								},
+								{
+									Name:  []byte("rating"),
+									Value: &Integer{Path: []string{"rating"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		recorder := &federatedTraceRecorder{}
+		out := resolve(t, response, recorder)
+		assert.Equal(t, `{"data":{"user":{"name":"Jens","rating":5}}}`, out)
+		assert.True(t, accounts.requested.Load())
+		assert.True(t, reviews.requested.Load())
+
+		require.Len(t, recorder.traces, 1)
+		trace := recorder.traces[0]
+		assert.NotNil(t, trace.StartTime)
+		assert.NotNil(t, trace.EndTime)
+		assert.NotZero(t, trace.DurationNs)
+
+		sequence, ok := trace.QueryPlan.Node.(*apollotrace.Trace_QueryPlanNode_Sequence)
+		require.True(t, ok)
+		require.Len(t, sequence.Sequence.Nodes, 2)
+
+		root := fetchNode(t, sequence.Sequence.Nodes[0])
+		assert.Equal(t, "accounts", root.ServiceName)
+		assert.False(t, root.TraceParsingFailed)
+		assert.Equal(t, uint64(100), root.Trace.GetDurationNs())
+		assert.NotNil(t, root.SentTime)
+		assert.NotNil(t, root.ReceivedTime)
+
+		flatten, ok := sequence.Sequence.Nodes[1].Node.(*apollotrace.Trace_QueryPlanNode_Flatten)
+		require.True(t, ok)
+		require.Len(t, flatten.Flatten.ResponsePath, 1)
+		assert.Equal(t, "user", flatten.Flatten.ResponsePath[0].GetFieldName())
+		nested := fetchNode(t, flatten.Flatten.Node)
+		assert.Equal(t, "reviews", nested.ServiceName)
+		assert.Equal(t, uint64(200), nested.Trace.GetDurationNs())
+		assert.GreaterOrEqual(t, nested.SentTimeOffset, root.SentTimeOffset)
+	})
+
+	t.Run("parallel fetches", func(t *testing.T) {
+		products := &ftv1DataSource{data: `{"product":"Table"}`, ftv1: encodeFederatedTrace(t, 1)}
+		inventory := &ftv1DataSource{data: `{"stock":3}`, ftv1: encodeFederatedTrace(t, 1)}
+		response := &GraphQLResponse{
+			Data: &Object{
+				Fetch: &ParallelFetch{
+					Fetches: []Fetch{
+						singleFetch("products", products),
+						singleFetch("inventory", inventory),
+					},
+				},
+				Fields: []*Field{
+					{
+						Name:  []byte("product"),
+						Value: &String{Path: []string{"product"}},
+					},
+					{
+						Name:  []byte("stock"),
+						Value: &Integer{Path: []string{"stock"}},
+					},
+				},
+			},
+		}
+
+		recorder := &federatedTraceRecorder{}
+		out := resolve(t, response, recorder)
+		assert.Equal(t, `{"data":{"product":"Table","stock":3}}`, out)
+
+		require.Len(t, recorder.traces, 1)
+		parallel, ok := recorder.traces[0].QueryPlan.Node.(*apollotrace.Trace_QueryPlanNode_Parallel)
+		require.True(t, ok)
+		require.Len(t, parallel.Parallel.Nodes, 2)
+		services := []string{
+			fetchNode(t, parallel.Parallel.Nodes[0]).ServiceName,
+			fetchNode(t, parallel.Parallel.Nodes[1]).ServiceName,
+		}
+		assert.ElementsMatch(t, []string{"products", "inventory"}, services)
+	})
+
+	t.Run("invalid ftv1 extension", func(t *testing.T) {
+		dataSource := &ftv1DataSource{data: `{"name":"Jens"}`, ftv1: "not base64"}
+		response := &GraphQLResponse{
+			Data: &Object{
+				Fetch: singleFetch("accounts", dataSource),
+				Fields: []*Field{
+					{
+						Name:  []byte("name"),
+						Value: &String{Path: []string{"name"}},
+					},
+				},
+			},
+		}
+
+		recorder := &federatedTraceRecorder{}
+		resolve(t, response, recorder)
+
+		require.Len(t, recorder.traces, 1)
+		fetch := fetchNode(t, recorder.traces[0].QueryPlan)
+		assert.True(t, fetch.TraceParsingFailed)
+		assert.Nil(t, fetch.Trace)
+	})
+
+	t.Run("without hook no trace is requested", func(t *testing.T) {
+		dataSource := &ftv1DataSource{data: `{"name":"Jens"}`}
+		response := &GraphQLResponse{
+			Data: &Object{
+				Fetch: singleFetch("accounts", dataSource),
+				Fields: []*Field{
+					{
+						Name:  []byte("name"),
+						Value: &String{Path: []string{"name"}},
+					},
+				},
+			},
+		}
+
+		out := resolve(t, response, nil)
+		assert.Equal(t, `{"data":{"name":"Jens"}}`, out)
+		assert.False(t, dataSource.requested.Load())
+	})
+}
@@ -0,0 +1,302 @@
+package resolve
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// GraphQLLiveQuery is a query marked with @live. It's resolved like a subscription:
+// the query is re-executed on every poll interval and invalidation event, and its result is sent whenever it changed.
+type GraphQLLiveQuery struct {
+	Response *GraphQLResponse
+	// Input identifies the operation, live queries with the same input, variables and request headers share their executions,
+	// headers of the client connection, e.g. of a websocket upgrade, are ignored
+	Input []byte
+	// PollInterval re-executes the query periodically, polling is disabled if it is 0
+	PollInterval time.Duration
+	// Invalidations re-execute the query on every event of their source
+	Invalidations []LiveQueryInvalidation
+	// Patch sends JSON patches (RFC 6902) against the previous result instead of complete results after the first one
+	Patch bool
+}
+
+// LiveQueryInvalidation is a stream of events invalidating the result of a live query, e.g. the pubsub topic of an entity
+type LiveQueryInvalidation struct {
+	Source SubscriptionDataSource
+	Input  []byte
+}
+
+// ResolveGraphQLLiveQuery streams the results of the live query to the writer until the context of ctx is done
+func (r *Resolver) ResolveGraphQLLiveQuery(ctx *Context, liveQuery *GraphQLLiveQuery, writer SubscriptionResponseWriter) error {
+	return r.ResolveGraphQLSubscription(ctx, r.liveQuerySubscription(liveQuery), writer)
+}
+
+// AsyncResolveGraphQLLiveQuery streams the results of the live query to the writer until it's unsubscribed
+func (r *Resolver) AsyncResolveGraphQLLiveQuery(ctx *Context, liveQuery *GraphQLLiveQuery, writer SubscriptionResponseWriter, id SubscriptionIdentifier) error {
+	return r.AsyncResolveGraphQLSubscription(ctx, r.liveQuerySubscription(liveQuery), writer, id)
+}
+
+// liveQuerySubscription resolves the live query as a subscription, so that identical live queries share one trigger
+// which executes the query once for all of its subscriptions
+func (r *Resolver) liveQuerySubscription(liveQuery *GraphQLLiveQuery) *GraphQLSubscription {
+	return &GraphQLSubscription{
+		Trigger: GraphQLSubscriptionTrigger{
+			Source: &liveQuerySource{
+				resolver:  r,
+				liveQuery: liveQuery,
+			},
+			InputTemplate: InputTemplate{
+				Segments: []TemplateSegment{
+					{SegmentType: StaticSegmentType, Data: []byte(`{}`)},
+				},
+			},
+		},
+		Response:  liveQuery.Response,
+		liveQuery: liveQuery,
+	}
+}
+
+// liveQuerySource is the trigger of a live query, its updates are the changed results of the query
+type liveQuerySource struct {
+	resolver  *Resolver
+	liveQuery *GraphQLLiveQuery
+}
+
+func (s *liveQuerySource) UniqueRequestID(ctx *Context, input []byte, xxh *xxhash.Digest) error {
+	// the result depends on the operation, its variables, the headers forwarded to the origins and the claims of the client
+	write := func(data []byte) {
+		_, _ = xxh.Write(data)
+		_, _ = xxh.Write([]byte{0})
+	}
+	write([]byte("liveQuery"))
+	write(s.liveQuery.Input)
+	write(input)
+	write(ctx.Variables)
+	keys := make([]string, 0, len(ctx.Request.Header))
+	for key := range ctx.Request.Header {
+		if isConnectionHeader(key) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		write([]byte(key))
+		for _, value := range ctx.Request.Header[key] {
+			write([]byte(value))
+		}
+	}
+	if claims := ctx.Claims(); claims != nil {
+		write([]byte(claims.Subject))
+		for _, scope := range claims.Scopes {
+			write([]byte(scope))
+		}
+	}
+	return nil
+}
+
+// hopByHopHeaders are only meaningful for a single connection (RFC 9110, section 7.6.1)
+var hopByHopHeaders = map[string]struct{}{
+	"Connection":          {},
+	"Keep-Alive":          {},
+	"Proxy-Authenticate":  {},
+	"Proxy-Authorization": {},
+	"Proxy-Connection":    {},
+	"Te":                  {},
+	"Trailer":             {},
+	"Transfer-Encoding":   {},
+	"Upgrade":             {},
+}
+
+// isConnectionHeader returns true for the headers of the client connection, e.g. the per connection Sec-WebSocket-Key
+// of a websocket upgrade request. They're never forwarded to the origins, so they don't identify the live query.
+func isConnectionHeader(key string) bool {
+	key = http.CanonicalHeaderKey(key)
+	if _, ok := hopByHopHeaders[key]; ok {
+		return true
+	}
+	return strings.HasPrefix(key, "Sec-Websocket-")
+}
+
+func (s *liveQuerySource) Start(ctx *Context, input []byte, updater SubscriptionUpdater) error {
+	invalidated := make(liveQueryInvalidator, 1)
+	for i := range s.liveQuery.Invalidations {
+		invalidation := s.liveQuery.Invalidations[i]
+		if err := invalidation.Source.Start(ctx, invalidation.Input, invalidated); err != nil {
+			return err
+		}
+	}
+	go s.run(ctx, updater, invalidated)
+	return nil
+}
+
+func (s *liveQuerySource) run(ctx *Context, updater SubscriptionUpdater, invalidated <-chan struct{}) {
+	var poll <-chan time.Time
+	if s.liveQuery.PollInterval > 0 {
+		ticker := time.NewTicker(s.liveQuery.PollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+	done := ctx.Context().Done()
+	var previous []byte
+	for {
+		result, err := s.execute(ctx)
+		if err != nil && s.resolver.options.Debug {
+			fmt.Printf("resolver:live:execute:error:%v\n", err)
+		}
+		if err == nil && !bytes.Equal(result, previous) {
+			updater.Update(result)
+			previous = result
+		}
+		select {
+		case <-done:
+			return
+		case <-poll:
+		case <-invalidated:
+		}
+	}
+}
+
+// execute resolves the query with a copy of the trigger context, so that no state is carried over between executions
+func (s *liveQuerySource) execute(ctx *Context) ([]byte, error) {
+	execCtx := ctx.clone(ctx.Context())
+	execCtx.Stats.Reset()
+	execCtx.subgraphErrors = nil
+	execCtx.batchLoadCache = nil
+	buf := &bytes.Buffer{}
+	if err := s.resolver.ResolveGraphQLResponse(execCtx, s.liveQuery.Response, nil, buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// liveQueryInvalidator receives the invalidation events of a live query, events arriving during an execution are coalesced
+type liveQueryInvalidator chan struct{}
+
+func (l liveQueryInvalidator) Update(_ []byte) {
+	select {
+	case l <- struct{}{}:
+	default:
+	}
+}
+
+func (l liveQueryInvalidator) Done() {}
+
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// liveQueryPatch returns the message patching the previous result of a live query into the current one: {"patch":[...]}.
+// Objects are patched per field, arrays are patched per item if their length didn't change and replaced otherwise.
+func liveQueryPatch(previous, current []byte) ([]byte, error) {
+	var prev, cur any
+	if err := decodeJSONValue(previous, &prev); err != nil {
+		return nil, err
+	}
+	if err := decodeJSONValue(current, &cur); err != nil {
+		return nil, err
+	}
+	operations, err := diffJSONValues(nil, "", prev, cur)
+	if err != nil {
+		return nil, err
+	}
+	if operations == nil {
+		operations = []jsonPatchOperation{}
+	}
+	return encodeJSONValue(struct {
+		Patch []jsonPatchOperation `json:"patch"`
+	}{Patch: operations})
+}
+
+func diffJSONValues(operations []jsonPatchOperation, path string, previous, current any) ([]jsonPatchOperation, error) {
+	switch prev := previous.(type) {
+	case map[string]any:
+		cur, ok := current.(map[string]any)
+		if !ok {
+			break
+		}
+		var err error
+		for _, key := range sortedKeys(prev) {
+			fieldPath := path + "/" + jsonPointerEscaper.Replace(key)
+			value, exists := cur[key]
+			if !exists {
+				operations = append(operations, jsonPatchOperation{Op: "remove", Path: fieldPath})
+				continue
+			}
+			if operations, err = diffJSONValues(operations, fieldPath, prev[key], value); err != nil {
+				return nil, err
+			}
+		}
+		for _, key := range sortedKeys(cur) {
+			if _, exists := prev[key]; exists {
+				continue
+			}
+			if operations, err = appendJSONPatchOperation(operations, "add", path+"/"+jsonPointerEscaper.Replace(key), cur[key]); err != nil {
+				return nil, err
+			}
+		}
+		return operations, nil
+	case []any:
+		cur, ok := current.([]any)
+		if !ok || len(cur) != len(prev) {
+			break
+		}
+		var err error
+		for i := range prev {
+			if operations, err = diffJSONValues(operations, path+"/"+strconv.Itoa(i), prev[i], cur[i]); err != nil {
+				return nil, err
+			}
+		}
+		return operations, nil
+	}
+	if reflect.DeepEqual(previous, current) {
+		return operations, nil
+	}
+	return appendJSONPatchOperation(operations, "replace", path, current)
+}
+
+func appendJSONPatchOperation(operations []jsonPatchOperation, op, path string, value any) ([]jsonPatchOperation, error) {
+	data, err := encodeJSONValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return append(operations, jsonPatchOperation{Op: op, Path: path, Value: data}), nil
+}
+
+func sortedKeys(object map[string]any) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func decodeJSONValue(data []byte, value *any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
+
+func encodeJSONValue(value any) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
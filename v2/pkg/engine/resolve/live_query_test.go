@@ -0,0 +1,235 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+)
+
+func TestLiveQueryPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		current  string
+		patch    string
+	}{
+		{
+			name:     "replaced field",
+			previous: `{"data":{"user":{"name":"Jens","age":30}}}`,
+			current:  `{"data":{"user":{"name":"Jannik","age":30}}}`,
+			patch:    `{"patch":[{"op":"replace","path":"/data/user/name","value":"Jannik"}]}`,
+		},
+		{
+			name:     "added and removed fields",
+			previous: `{"data":{"user":{"name":"Jens"}},"errors":[{"message":"failed"}]}`,
+			current:  `{"data":{"user":null}}`,
+			patch:    `{"patch":[{"op":"replace","path":"/data/user","value":null},{"op":"remove","path":"/errors"}]}`,
+		},
+		{
+			name:     "items of an array with the same length",
+			previous: `{"data":{"ids":[1,2,3]}}`,
+			current:  `{"data":{"ids":[1,5,3],"a/b~c":true}}`,
+			patch:    `{"patch":[{"op":"replace","path":"/data/ids/1","value":5},{"op":"add","path":"/data/a~1b~0c","value":true}]}`,
+		},
+		{
+			name:     "array with a different length",
+			previous: `{"data":{"ids":[1,2]}}`,
+			current:  `{"data":{"ids":[1,2,3]}}`,
+			patch:    `{"patch":[{"op":"replace","path":"/data/ids","value":[1,2,3]}]}`,
+		},
+		{
+			name:     "unchanged",
+			previous: `{"data":{"html":"<b>"}}`,
+			current:  `{"data":{"html":"<b>"}}`,
+			patch:    `{"patch":[]}`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			patch, err := liveQueryPatch([]byte(tc.previous), []byte(tc.current))
+			require.NoError(t, err)
+			assert.Equal(t, tc.patch, string(patch))
+		})
+	}
+}
+
+// liveQueryDataSource returns the current counter and counts its loads
+type liveQueryDataSource struct {
+	counter atomic.Int64
+	loads   atomic.Int64
+}
+
+func (l *liveQueryDataSource) Load(ctx context.Context, input []byte, w io.Writer) error {
+	counter := l.counter.Load()
+	l.loads.Inc()
+	_, err := fmt.Fprintf(w, `{"counter":%d}`, counter)
+	return err
+}
+
+// liveQueryInvalidationSource hands out the updaters of the started invalidations
+type liveQueryInvalidationSource struct {
+	mux      sync.Mutex
+	updaters []SubscriptionUpdater
+}
+
+func (l *liveQueryInvalidationSource) UniqueRequestID(ctx *Context, input []byte, xxh *xxhash.Digest) error {
+	_, err := xxh.Write(input)
+	return err
+}
+
+func (l *liveQueryInvalidationSource) Start(ctx *Context, input []byte, updater SubscriptionUpdater) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.updaters = append(l.updaters, updater)
+	return nil
+}
+
+func (l *liveQueryInvalidationSource) invalidate() {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	for _, updater := range l.updaters {
+		updater.Update([]byte(`{}`))
+	}
+}
+
+func (l *liveQueryInvalidationSource) started() int {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return len(l.updaters)
+}
+
+func TestResolver_ResolveGraphQLLiveQuery(t *testing.T) {
+	liveQuery := func(ds *liveQueryDataSource) *GraphQLLiveQuery {
+		return &GraphQLLiveQuery{
+			Input: []byte("counter"),
+			Response: &GraphQLResponse{
+				Info: &GraphQLResponseInfo{OperationType: ast.OperationTypeQuery},
+				Data: &Object{
+					Fetch: &SingleFetch{
+						FetchConfiguration: FetchConfiguration{DataSource: ds},
+					},
+					Fields: []*Field{
+						{Name: []byte("counter"), Value: &Integer{Path: []string{"counter"}}},
+					},
+				},
+			},
+		}
+	}
+	subscribe := func(t *testing.T, resolver *Resolver, live *GraphQLLiveQuery, variables string, header http.Header, id int64) *SubscriptionRecorder {
+		ctx := NewContext(context.Background())
+		ctx.Variables = []byte(variables)
+		ctx.Request.Header = header
+		recorder := &SubscriptionRecorder{buf: &bytes.Buffer{}}
+		err := resolver.AsyncResolveGraphQLLiveQuery(ctx, live, recorder, SubscriptionIdentifier{ConnectionID: id, SubscriptionID: id})
+		require.NoError(t, err)
+		return recorder
+	}
+
+	t.Run("identical live queries share their executions and only receive changed results", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		resolver := newResolver(ctx)
+
+		ds := &liveQueryDataSource{}
+		ds.counter.Store(1)
+		invalidation := &liveQueryInvalidationSource{}
+		withInvalidation := func(patch bool) *GraphQLLiveQuery {
+			live := liveQuery(ds)
+			live.Invalidations = []LiveQueryInvalidation{{Source: invalidation, Input: []byte(`{"topic":"counter"}`)}}
+			live.Patch = patch
+			return live
+		}
+
+		full := subscribe(t, resolver, withInvalidation(false), `{"a":1}`, nil, 1)
+		full.AwaitMessages(t, 1, time.Second)
+		patched := subscribe(t, resolver, withInvalidation(true), `{"a":1}`, nil, 2)
+		patched.AwaitMessages(t, 1, time.Second)
+		assert.Equal(t, int64(1), ds.loads.Load())
+		assert.Equal(t, 1, invalidation.started())
+
+		// an unchanged result isn't sent
+		invalidation.invalidate()
+		assert.Eventually(t, func() bool { return ds.loads.Load() == 2 }, time.Second, time.Millisecond)
+
+		ds.counter.Store(2)
+		invalidation.invalidate()
+		full.AwaitMessages(t, 2, time.Second)
+		patched.AwaitMessages(t, 2, time.Second)
+		assert.Equal(t, int64(3), ds.loads.Load())
+
+		assert.Equal(t, []string{`{"data":{"counter":1}}`, `{"data":{"counter":2}}`}, full.Messages())
+		assert.Equal(t, []string{`{"data":{"counter":1}}`, `{"patch":[{"op":"replace","path":"/data/counter","value":2}]}`}, patched.Messages())
+
+		// different variables don't share the execution
+		other := subscribe(t, resolver, withInvalidation(false), `{"a":2}`, nil, 3)
+		other.AwaitMessages(t, 1, time.Second)
+		assert.Equal(t, 2, invalidation.started())
+	})
+
+	t.Run("connection headers of the subscribers don't prevent sharing executions", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		resolver := newResolver(ctx)
+
+		ds := &liveQueryDataSource{}
+		invalidation := &liveQueryInvalidationSource{}
+		live := liveQuery(ds)
+		live.Invalidations = []LiveQueryInvalidation{{Source: invalidation, Input: []byte(`{"topic":"counter"}`)}}
+		upgradeHeader := func(key, authorization string) http.Header {
+			return http.Header{
+				"Authorization":          []string{authorization},
+				"Connection":             []string{"Upgrade"},
+				"Upgrade":                []string{"websocket"},
+				"Sec-Websocket-Key":      []string{key},
+				"Sec-Websocket-Protocol": []string{"graphql-ws"},
+			}
+		}
+
+		first := subscribe(t, resolver, live, `{}`, upgradeHeader("dGhlIHNhbXBsZSBub25jZQ==", "token"), 1)
+		first.AwaitMessages(t, 1, time.Second)
+		second := subscribe(t, resolver, live, `{}`, upgradeHeader("x3JJHMbDL1EzLkh9GBhXDw==", "token"), 2)
+		second.AwaitMessages(t, 1, time.Second)
+		assert.Equal(t, int64(1), ds.loads.Load())
+		assert.Equal(t, 1, invalidation.started())
+
+		// other request headers may be forwarded to the origins, so they don't share the execution
+		other := subscribe(t, resolver, live, `{}`, upgradeHeader("dGhlIHNhbXBsZSBub25jZQ==", "other"), 3)
+		other.AwaitMessages(t, 1, time.Second)
+		assert.Equal(t, 2, invalidation.started())
+	})
+
+	t.Run("polling", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		resolver := newResolver(ctx)
+
+		ds := &liveQueryDataSource{}
+		live := liveQuery(ds)
+		live.PollInterval = time.Millisecond
+
+		recorder := subscribe(t, resolver, live, `{}`, nil, 1)
+		recorder.AwaitMessages(t, 1, time.Second)
+		ds.counter.Store(1)
+		recorder.AwaitMessages(t, 2, time.Second)
+
+		assert.Equal(t, []string{`{"data":{"counter":0}}`, `{"data":{"counter":1}}`}, recorder.Messages())
+		// the query is still polled, but unchanged results aren't sent
+		assert.Eventually(t, func() bool { return ds.loads.Load() > 3 }, time.Second, time.Millisecond)
+		assert.Len(t, recorder.Messages(), 2)
+
+		require.NoError(t, resolver.AsyncUnsubscribeSubscription(SubscriptionIdentifier{ConnectionID: 1, SubscriptionID: 1}))
+		recorder.AwaitComplete(t, time.Second)
+	})
+}
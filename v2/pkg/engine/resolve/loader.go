@@ -15,10 +15,12 @@ import (
 	"github.com/buger/jsonparser"
 	"github.com/pkg/errors"
 	"github.com/tidwall/gjson"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astjson"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/metrics"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/pool"
 )
 
@@ -30,6 +32,14 @@ type Loader struct {
 	path         []string
 	traceOptions RequestTraceOptions
 	info         *GraphQLResponseInfo
+	// singleFlight is shared across all loaders of a Resolver, nil if single flight is disabled
+	singleFlight *singleFlight
+	errorPolicy  *ErrorPolicy
+	tracer       trace.Tracer
+	// metrics is nil for loaders not created by a Resolver
+	metrics metrics.Metrics
+	// federatedTrace is nil unless the context has a FederatedTraceHook
+	federatedTrace *federatedTrace
 }
 
 func (l *Loader) Free() {
@@ -39,6 +49,7 @@ func (l *Loader) Free() {
 	l.dataRoot = -1
 	l.errorsRoot = -1
 	l.path = l.path[:0]
+	l.federatedTrace = nil
 }
 
 func (l *Loader) LoadGraphQLResponseData(ctx *Context, response *GraphQLResponse, resolvable *Resolvable) (err error) {
@@ -48,6 +59,17 @@ func (l *Loader) LoadGraphQLResponseData(ctx *Context, response *GraphQLResponse
 	l.traceOptions = resolvable.requestTraceOptions
 	l.ctx = ctx
 	l.info = response.Info
+	if l.info == nil {
+		l.info = &GraphQLResponseInfo{
+			OperationType: ast.OperationTypeQuery,
+		}
+	}
+	if ctx.federatedTraceHook != nil {
+		l.federatedTrace = newFederatedTrace()
+		defer func() {
+			ctx.federatedTraceHook.OnFederatedTrace(ctx, l.federatedTrace.finish())
+		}()
+	}
 	return l.walkNode(response.Data, []int{resolvable.dataRoot})
 }
 
@@ -181,7 +203,7 @@ func (l *Loader) resolveAndMergeFetch(fetch Fetch, items []int) error {
 		res := &result{
 			out: pool.BytesBuffer.Get(),
 		}
-		err := l.loadSingleFetch(l.ctx.ctx, f, items, res)
+		err := l.loadSingleFetch(l.federatedTraceContext(l.ctx.ctx), f, items, res)
 		if err != nil {
 			return errors.WithStack(err)
 		}
@@ -192,6 +214,7 @@ func (l *Loader) resolveAndMergeFetch(fetch Fetch, items []int) error {
 				Path: l.renderPath(),
 			}
 		}
+		defer l.enterFederatedTraceSequence()()
 		for i := range f.Fetches {
 			err := l.resolveAndMergeFetch(f.Fetches[i], items)
 			if err != nil {
@@ -205,7 +228,7 @@ func (l *Loader) resolveAndMergeFetch(fetch Fetch, items []int) error {
 			}
 		}
 		results := make([]*result, len(f.Fetches))
-		g, ctx := errgroup.WithContext(l.ctx.ctx)
+		g, ctx := errgroup.WithContext(l.federatedTraceParallelContext(l.ctx.ctx))
 		for i := range f.Fetches {
 			i := i
 			results[i] = &result{}
@@ -239,7 +262,7 @@ func (l *Loader) resolveAndMergeFetch(fetch Fetch, items []int) error {
 			}
 		}
 		results := make([]*result, len(items))
-		g, ctx := errgroup.WithContext(l.ctx.ctx)
+		g, ctx := errgroup.WithContext(l.federatedTraceParallelContext(l.ctx.ctx))
 		for i := range items {
 			i := i
 			results[i] = &result{
@@ -263,7 +286,7 @@ func (l *Loader) resolveAndMergeFetch(fetch Fetch, items []int) error {
 		res := &result{
 			out: pool.BytesBuffer.Get(),
 		}
-		err := l.loadEntityFetch(l.ctx.ctx, f, items, res)
+		err := l.loadEntityFetch(l.federatedTraceContext(l.ctx.ctx), f, items, res)
 		if err != nil {
 			return errors.WithStack(err)
 		}
@@ -272,7 +295,7 @@ func (l *Loader) resolveAndMergeFetch(fetch Fetch, items []int) error {
 		res := &result{
 			out: pool.BytesBuffer.Get(),
 		}
-		err := l.loadBatchEntityFetch(l.ctx.ctx, f, items, res)
+		err := l.loadBatchEntityFetch(l.federatedTraceContext(l.ctx.ctx), f, items, res)
 		if err != nil {
 			return errors.WithStack(err)
 		}
@@ -295,7 +318,7 @@ func (l *Loader) loadFetch(ctx context.Context, fetch Fetch, items []int, res *r
 		if l.traceOptions.Enable {
 			f.Traces = make([]*SingleFetch, len(items))
 		}
-		g, ctx := errgroup.WithContext(l.ctx.ctx)
+		g, ctx := errgroup.WithContext(withFederatedTraceParallelGroup(l.ctx.ctx, ctx))
 		for i := range items {
 			i := i
 			results[i] = &result{
@@ -374,6 +397,9 @@ func (l *Loader) mergeResult(res *result, items []int) error {
 	}
 	if res.postProcessing.SelectResponseErrorsPath != nil {
 		ref := l.data.Get(node, res.postProcessing.SelectResponseErrorsPath)
+		if err = l.applyErrorPolicy(ref, res); err != nil {
+			return errors.WithStack(err)
+		}
 		l.mergeErrors(ref)
 	}
 	if res.postProcessing.SelectResponseDataPath != nil {
@@ -516,12 +542,18 @@ func (l *Loader) renderErrorsFailedToFetch(res *result) error {
 		if err != nil {
 			return errors.WithStack(err)
 		}
+		if err = applyErrorCode(l.errorPolicy, l.data, errorObject, ErrorCodeSubgraphFetchFailed); err != nil {
+			return errors.WithStack(err)
+		}
 		l.data.Nodes[l.errorsRoot].ArrayValues = append(l.data.Nodes[l.errorsRoot].ArrayValues, errorObject)
 	} else {
 		errorObject, err := l.data.AppendObject([]byte(fmt.Sprintf(`{"message":"Failed to fetch from Subgraph '%s' at path '%s'."}`, res.subgraphName, path)))
 		if err != nil {
 			return errors.WithStack(err)
 		}
+		if err = applyErrorCode(l.errorPolicy, l.data, errorObject, ErrorCodeSubgraphFetchFailed); err != nil {
+			return errors.WithStack(err)
+		}
 		l.data.Nodes[l.errorsRoot].ArrayValues = append(l.data.Nodes[l.errorsRoot].ArrayValues, errorObject)
 	}
 	return nil
@@ -539,12 +571,18 @@ func (l *Loader) renderAuthorizationRejectedErrors(res *result) error {
 				if err != nil {
 					return errors.WithStack(err)
 				}
+				if err = applyErrorCode(l.errorPolicy, l.data, errorObject, ErrorCodeUnauthorized); err != nil {
+					return errors.WithStack(err)
+				}
 				l.data.Nodes[l.errorsRoot].ArrayValues = append(l.data.Nodes[l.errorsRoot].ArrayValues, errorObject)
 			} else {
 				errorObject, err := l.data.AppendObject([]byte(fmt.Sprintf(`{"message":"Unauthorized Subgraph request at path '%s'. Reason: %s"}`, path, reason)))
 				if err != nil {
 					return errors.WithStack(err)
 				}
+				if err = applyErrorCode(l.errorPolicy, l.data, errorObject, ErrorCodeUnauthorized); err != nil {
+					return errors.WithStack(err)
+				}
 				l.data.Nodes[l.errorsRoot].ArrayValues = append(l.data.Nodes[l.errorsRoot].ArrayValues, errorObject)
 			}
 		}
@@ -555,12 +593,18 @@ func (l *Loader) renderAuthorizationRejectedErrors(res *result) error {
 				if err != nil {
 					return errors.WithStack(err)
 				}
+				if err = applyErrorCode(l.errorPolicy, l.data, errorObject, ErrorCodeUnauthorized); err != nil {
+					return errors.WithStack(err)
+				}
 				l.data.Nodes[l.errorsRoot].ArrayValues = append(l.data.Nodes[l.errorsRoot].ArrayValues, errorObject)
 			} else {
 				errorObject, err := l.data.AppendObject([]byte(fmt.Sprintf(`{"message":"Unauthorized request to Subgraph '%s' at path '%s'. Reason: %s"}`, res.subgraphName, path, reason)))
 				if err != nil {
 					return errors.WithStack(err)
 				}
+				if err = applyErrorCode(l.errorPolicy, l.data, errorObject, ErrorCodeUnauthorized); err != nil {
+					return errors.WithStack(err)
+				}
 				l.data.Nodes[l.errorsRoot].ArrayValues = append(l.data.Nodes[l.errorsRoot].ArrayValues, errorObject)
 			}
 		}
@@ -623,7 +667,7 @@ func (l *Loader) loadSingleFetch(ctx context.Context, fetch *SingleFetch, items
 	if !authorized {
 		return nil
 	}
-	res.err = l.executeSourceLoad(ctx, fetch.DataSource, fetchInput, res.out, fetch.Trace)
+	res.err = l.executeSourceLoad(ctx, fetch.FetchKind(), fetch.Info, fetch.DataSource, fetchInput, res.out, fetch.Trace)
 	return nil
 }
 
@@ -703,7 +747,7 @@ func (l *Loader) loadEntityFetch(ctx context.Context, fetch *EntityFetch, items
 	if !authorized {
 		return nil
 	}
-	res.err = l.executeSourceLoad(ctx, fetch.DataSource, fetchInput, res.out, fetch.Trace)
+	res.err = l.executeSourceLoad(ctx, fetch.FetchKind(), fetch.Info, fetch.DataSource, fetchInput, res.out, fetch.Trace)
 	return nil
 }
 
@@ -824,7 +868,7 @@ WithNextItem:
 	if !authorized {
 		return nil
 	}
-	res.err = l.executeSourceLoad(ctx, fetch.DataSource, fetchInput, res.out, fetch.Trace)
+	res.err = l.executeSourceLoad(ctx, fetch.FetchKind(), fetch.Info, fetch.DataSource, fetchInput, res.out, fetch.Trace)
 	return nil
 }
 
@@ -890,9 +934,71 @@ func setSingleFlightStats(ctx context.Context, stats *SingleFlightStats) context
 	return context.WithValue(ctx, singleFlightStatsKey{}, stats)
 }
 
-func (l *Loader) executeSourceLoad(ctx context.Context, source DataSource, input []byte, out *bytes.Buffer, trace *DataSourceLoadTrace) (err error) {
+// loadSource loads from the data source, deduplicating identical concurrent loads if single flight is enabled.
+// If the operation is part of a batch, the batch load cache is used instead to deduplicate loads across the batch.
+// Loads are never deduplicated for mutations, as they are not idempotent.
+func (l *Loader) loadSource(ctx context.Context, info *FetchInfo, source DataSource, input []byte, out *bytes.Buffer) error {
+	flights := l.singleFlight
+	if l.ctx.batchLoadCache != nil {
+		flights = l.ctx.batchLoadCache.loads
+	}
+	if flights == nil || SingleFlightDisallowed(ctx) {
+		return source.Load(ctx, input, out)
+	}
+	dataSourceID := ""
+	if info != nil {
+		dataSourceID = info.DataSourceID
+	}
+	shared, err := flights.load(ctx, dataSourceID, input, out, func(ctx context.Context, out *bytes.Buffer) error {
+		return source.Load(ctx, input, out)
+	})
+	if stats := GetSingleFlightStats(ctx); stats != nil {
+		stats.SingleFlightUsed = true
+		stats.SingleFlightSharedResponse = shared
+	}
+	return err
+}
+
+// mergeExtensions adds the extensions of the client request to the extensions of the fetch input.
+// Extensions of the input, e.g. the persisted query of the subgraph request, take precedence.
+func mergeExtensions(input, extensions []byte) ([]byte, error) {
+	existing, dataType, _, err := jsonparser.Get(input, "body", "extensions")
+	if err != nil || dataType != jsonparser.Object {
+		return jsonparser.Set(input, extensions, "body", "extensions")
+	}
+	merged := append([]byte(nil), existing...)
+	err = jsonparser.ObjectEach(extensions, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
+		if _, _, _, err := jsonparser.Get(merged, string(key)); err == nil {
+			return nil
+		}
+		if dataType == jsonparser.String {
+			value = append(append([]byte{'"'}, value...), '"')
+		}
+		merged, err = jsonparser.Set(merged, value, string(key))
+		return err
+	})
+	if err != nil {
+		// extensions which aren't an object can't be merged
+		return jsonparser.Set(input, extensions, "body", "extensions")
+	}
+	return jsonparser.Set(input, merged, "body", "extensions")
+}
+
+func (l *Loader) executeSourceLoad(ctx context.Context, kind FetchKind, info *FetchInfo, source DataSource, input []byte, out *bytes.Buffer, trace *DataSourceLoadTrace) (err error) {
+	ctx, span := l.startFetchSpan(ctx, kind, info)
+	start := time.Now()
+	defer func() {
+		endFetchSpan(span, err)
+		if l.metrics != nil {
+			dataSourceID := ""
+			if info != nil {
+				dataSourceID = info.DataSourceID
+			}
+			l.metrics.FetchCompleted(dataSourceID, time.Since(start), err != nil)
+		}
+	}()
 	if l.ctx.Extensions != nil {
-		input, err = jsonparser.Set(input, l.ctx.Extensions, "body", "extensions")
+		input, err = mergeExtensions(input, l.ctx.Extensions)
 		if err != nil {
 			return errors.WithStack(err)
 		}
@@ -1004,7 +1110,11 @@ func (l *Loader) executeSourceLoad(ctx context.Context, source DataSource, input
 	if l.info != nil && l.info.OperationType == ast.OperationTypeMutation {
 		ctx = context.WithValue(ctx, disallowSingleFlightContextKey{}, true)
 	}
-	err = source.Load(ctx, input, out)
+	sent := time.Now()
+	err = l.loadSource(ctx, info, source, input, out)
+	if group := federatedTraceGroupFromContext(ctx); group != nil {
+		group.append(l.federatedTraceFetchNode(info, sent, time.Now(), out))
+	}
 	if l.traceOptions.Enable {
 		stats := GetSingleFlightStats(ctx)
 		if stats != nil {
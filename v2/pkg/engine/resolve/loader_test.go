@@ -591,6 +591,69 @@ func TestLoader_LoadGraphQLResponseDataWithExtensions(t *testing.T) {
 	assert.Equal(t, expected, out.String())
 }
 
+func TestLoader_LoadGraphQLResponseDataWithExtensionsAndPersistedQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	productsService := mockedDS(t, ctrl,
+		`{"method":"POST","url":"http://products","body":{"query":"query{topProducts{name}}","extensions":{"persistedQuery":{"version":1,"sha256Hash":"abc"},"foo":"bar","baz":{"qux":1}}}}`,
+		`{"topProducts":[{"name":"Table"}]}`)
+	response := &GraphQLResponse{
+		Data: &Object{
+			Fetch: &SingleFetch{
+				InputTemplate: InputTemplate{
+					Segments: []TemplateSegment{
+						{
+							Data:        []byte(`{"method":"POST","url":"http://products","body":{"query":"query{topProducts{name}}","extensions":{"persistedQuery":{"version":1,"sha256Hash":"abc"}}}}`),
+							SegmentType: StaticSegmentType,
+						},
+					},
+				},
+				FetchConfiguration: FetchConfiguration{
+					DataSource: productsService,
+					PostProcessing: PostProcessingConfiguration{
+						SelectResponseDataPath: []string{"data"},
+					},
+				},
+			},
+			Fields: []*Field{
+				{
+					Name: []byte("topProducts"),
+					Value: &Array{
+						Path: []string{"topProducts"},
+						Item: &Object{
+							Fields: []*Field{
+								{
+									Name: []byte("name"),
+									Value: &String{
+										Path: []string{"name"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	// the persisted query of the client must not replace the one of the subgraph request
+	ctx := &Context{
+		ctx:        context.Background(),
+		Extensions: []byte(`{"persistedQuery":{"version":1,"sha256Hash":"client"},"foo":"bar","baz":{"qux":1}}`),
+	}
+	resolvable := &Resolvable{
+		storage: &astjson.JSON{},
+	}
+	loader := &Loader{}
+	err := resolvable.Init(ctx, nil, ast.OperationTypeQuery)
+	assert.NoError(t, err)
+	err = loader.LoadGraphQLResponseData(ctx, response, resolvable)
+	assert.NoError(t, err)
+	ctrl.Finish()
+	out := &bytes.Buffer{}
+	err = resolvable.storage.PrintNode(resolvable.storage.Nodes[resolvable.storage.RootNode], out)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"errors":[],"data":{"topProducts":[{"name":"Table"}]}}`, out.String())
+}
+
 func BenchmarkLoader_LoadGraphQLResponseData(b *testing.B) {
 
 	productsService := FakeDataSource(`{"data":{"topProducts":[{"name":"Table","__typename":"Product","upc":"1"},{"name":"Couch","__typename":"Product","upc":"2"},{"name":"Chair","__typename":"Product","upc":"3"}]}}`)
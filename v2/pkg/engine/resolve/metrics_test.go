@@ -0,0 +1,165 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/metrics"
+)
+
+type metricsRecorder struct {
+	metrics.Noop
+	mu                  sync.Mutex
+	fetches             []string
+	activeSubscriptions []string
+	droppedUpdates      []string
+}
+
+func (m *metricsRecorder) FetchCompleted(dataSourceID string, _ time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fetches = append(m.fetches, fmt.Sprintf("%s:%t", dataSourceID, failed))
+}
+
+func (m *metricsRecorder) ActiveSubscriptions(subscriptions, triggers int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.activeSubscriptions = append(m.activeSubscriptions, fmt.Sprintf("%d:%d", subscriptions, triggers))
+}
+
+func (m *metricsRecorder) SubscriptionUpdatesDropped(policy string, count int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.droppedUpdates = append(m.droppedUpdates, fmt.Sprintf("%s:%d", policy, count))
+}
+
+func (m *metricsRecorder) recordedDroppedUpdates() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.droppedUpdates...)
+}
+
+func (m *metricsRecorder) recordedActiveSubscriptions() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.activeSubscriptions...)
+}
+
+func TestResolver_Metrics(t *testing.T) {
+	t.Run("fetches", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		recorder := &metricsRecorder{}
+		resolver := New(ctx, ResolverOptions{
+			MaxConcurrency: 1024,
+			Metrics:        recorder,
+		})
+
+		response := func(dataSource DataSource) *GraphQLResponse {
+			return &GraphQLResponse{
+				Data: &Object{
+					Fetch: &SingleFetch{
+						FetchConfiguration: FetchConfiguration{
+							DataSource: dataSource,
+							PostProcessing: PostProcessingConfiguration{
+								SelectResponseDataPath: []string{"data"},
+							},
+						},
+						Info: &FetchInfo{
+							DataSourceID: "products",
+						},
+					},
+					Fields: []*Field{
+						{
+							Name: []byte("name"),
+							Value: &String{
+								Path:     []string{"name"},
+								Nullable: true,
+							},
+						},
+					},
+				},
+			}
+		}
+
+		buf := &bytes.Buffer{}
+		err := resolver.ResolveGraphQLResponse(&Context{ctx: context.Background()}, response(FakeDataSource(`{"data":{"name":"Table"}}`)), nil, buf)
+		require.NoError(t, err)
+		buf.Reset()
+		err = resolver.ResolveGraphQLResponse(&Context{ctx: context.Background()}, response(failingDataSource{}), nil, buf)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"products:false", "products:true"}, recorder.fetches)
+	})
+
+	t.Run("active subscriptions", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		recorder := &metricsRecorder{}
+		resolver := New(ctx, ResolverOptions{
+			MaxConcurrency: 1024,
+			Metrics:        recorder,
+		})
+
+		fakeStream := createFakeStream(func(counter int) (message string, done bool) {
+			return fmt.Sprintf(`{"data":{"counter":%d}}`, counter), false
+		}, time.Millisecond, nil)
+		subscription := &GraphQLSubscription{
+			Trigger: GraphQLSubscriptionTrigger{
+				Source: fakeStream,
+				InputTemplate: InputTemplate{
+					Segments: []TemplateSegment{
+						{
+							SegmentType: StaticSegmentType,
+							Data:        []byte(`{"method":"POST","url":"http://localhost:4000","body":{"query":"subscription { counter }"}}`),
+						},
+					},
+				},
+				PostProcessing: PostProcessingConfiguration{
+					SelectResponseDataPath: []string{"data"},
+				},
+			},
+			Response: &GraphQLResponse{
+				Data: &Object{
+					Fields: []*Field{
+						{
+							Name: []byte("counter"),
+							Value: &Integer{
+								Path: []string{"counter"},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		for i := int64(1); i <= 2; i++ {
+			writer := &SubscriptionRecorder{
+				buf:      &bytes.Buffer{},
+				messages: []string{},
+			}
+			err := resolver.AsyncResolveGraphQLSubscription(&Context{ctx: context.Background()}, subscription, writer, SubscriptionIdentifier{ConnectionID: i, SubscriptionID: 1})
+			require.NoError(t, err)
+		}
+		assert.Eventually(t, func() bool {
+			recorded := recorder.recordedActiveSubscriptions()
+			return len(recorded) == 2
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, []string{"1:1", "2:1"}, recorder.recordedActiveSubscriptions())
+
+		require.NoError(t, resolver.AsyncUnsubscribeSubscription(SubscriptionIdentifier{ConnectionID: 1, SubscriptionID: 1}))
+		require.NoError(t, resolver.AsyncUnsubscribeClient(2))
+		assert.Eventually(t, func() bool {
+			recorded := recorder.recordedActiveSubscriptions()
+			return recorded[len(recorded)-1] == "0:0"
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, []string{"1:1", "2:1", "1:1", "0:0"}, recorder.recordedActiveSubscriptions()[:4])
+	})
+}
@@ -38,6 +38,8 @@ type Resolvable struct {
 
 	authorizationBuf          *bytes.Buffer
 	authorizationBufObjectRef int
+
+	errorPolicy *ErrorPolicy
 }
 
 func NewResolvable() *Resolvable {
@@ -479,6 +481,9 @@ func (r *Resolvable) addRejectFieldError(reason string, field *Field) {
 		message = fmt.Sprintf("Unauthorized to load field '%s'. Reason: %s", fieldPath, reason)
 	}
 	ref := r.storage.AppendErrorWithMessage(message, r.path)
+	if err := applyErrorCode(r.errorPolicy, r.storage, ref, ErrorCodeUnauthorized); err != nil {
+		r.authorizationError = err
+	}
 	r.storage.Nodes[r.errorsRoot].ArrayValues = append(r.storage.Nodes[r.errorsRoot].ArrayValues, ref)
 	r.popNodePathElement(nodePath)
 }
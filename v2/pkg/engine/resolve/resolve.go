@@ -13,10 +13,12 @@ import (
 	"github.com/alitto/pond"
 	"github.com/buger/jsonparser"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/atomic"
 
 	"github.com/TykTechnologies/graphql-go-tools/v2/internal/pkg/xcontext"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/metrics"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/pool"
 )
 
@@ -43,6 +45,7 @@ type Resolver struct {
 	connectionIDs atomic.Int64
 
 	reporter Reporter
+	metrics  metrics.Metrics
 }
 
 type tools struct {
@@ -64,25 +67,65 @@ type ResolverOptions struct {
 	Debug bool
 
 	Reporter Reporter
+
+	// EnableSingleFlight deduplicates concurrent identical upstream requests
+	// Requests are identical if they go to the same data source with the same rendered input, including forwarded headers
+	// Mutations are never deduplicated
+	EnableSingleFlight bool
+
+	// ErrorPolicy masks and redacts subgraph errors and adds codes to errors rendered by the resolver
+	// If nil, subgraph errors are passed to the client unchanged
+	ErrorPolicy *ErrorPolicy
+
+	// TracerProvider creates the OpenTelemetry tracer of the fetch spans, if nil the global provider is used
+	TracerProvider trace.TracerProvider
+
+	// Metrics receives the fetch durations and the number of active subscriptions and triggers, if nil no metrics are reported
+	Metrics metrics.Metrics
+
+	// SubscriptionBufferSize is the number of updates buffered per subscription while its client is busy,
+	// defaults to DefaultSubscriptionBufferSize
+	SubscriptionBufferSize int
+
+	// SubscriptionOverflowPolicy decides what happens to the updates of a subscription if its buffer is full,
+	// defaults to OverflowPolicyDropOldest
+	SubscriptionOverflowPolicy OverflowPolicy
 }
 
 // New returns a new Resolver, ctx.Done() is used to cancel all active subscriptions & streams
 func New(ctx context.Context, options ResolverOptions) *Resolver {
 	//options.Debug = true
+	var sf *singleFlight
+	if options.EnableSingleFlight {
+		sf = newSingleFlight()
+	}
+	if options.SubscriptionBufferSize <= 0 {
+		options.SubscriptionBufferSize = DefaultSubscriptionBufferSize
+	}
+	tracer := newTracer(options.TracerProvider)
+	m := metrics.OrNoop(options.Metrics)
 	resolver := &Resolver{
 		ctx:     ctx,
 		options: options,
 		toolPool: sync.Pool{
 			New: func() interface{} {
+				resolvable := NewResolvable()
+				resolvable.errorPolicy = options.ErrorPolicy
 				return &tools{
-					resolvable: NewResolvable(),
-					loader:     &Loader{},
+					resolvable: resolvable,
+					loader: &Loader{
+						singleFlight: sf,
+						errorPolicy:  options.ErrorPolicy,
+						tracer:       tracer,
+						metrics:      m,
+					},
 				}
 			},
 		},
 		events:   make(chan subscriptionEvent),
 		triggers: make(map[uint64]*trigger),
 		reporter: options.Reporter,
+		metrics:  m,
 	}
 	/*if options.MaxConcurrency > 0 {
 		semaphore := make(chan struct{}, options.MaxConcurrency)
@@ -119,16 +162,16 @@ func (r *Resolver) putTools(t *tools) {
 }
 
 func (r *Resolver) ResolveGraphQLResponse(ctx *Context, response *GraphQLResponse, data []byte, writer io.Writer) (err error) {
-	if response.Info == nil {
-		response.Info = &GraphQLResponseInfo{
-			OperationType: ast.OperationTypeQuery,
-		}
+	// the response is shared by concurrent executions of a cached plan, so it must not be modified
+	operationType := ast.OperationTypeQuery
+	if response.Info != nil {
+		operationType = response.Info.OperationType
 	}
 
 	t := r.getTools()
 	defer r.putTools(t)
 
-	err = t.resolvable.Init(ctx, data, response.Info.OperationType)
+	err = t.resolvable.Init(ctx, data, operationType)
 	if err != nil {
 		return err
 	}
@@ -146,6 +189,9 @@ type trigger struct {
 	cancel        context.CancelFunc
 	subscriptions map[*Context]*sub
 	inFlight      *sync.WaitGroup
+	// live is set if the trigger executes a live query, liveResult is its latest result which is sent to joining subscriptions
+	live       bool
+	liveResult []byte
 }
 
 func (t *trigger) hasPendingUpdates() bool {
@@ -153,6 +199,9 @@ func (t *trigger) hasPendingUpdates() bool {
 		s.mux.Lock()
 		hasUpdates := s.pendingUpdates != 0
 		s.mux.Unlock()
+		s.bufferMux.Lock()
+		hasUpdates = hasUpdates || s.buffer.pending()
+		s.bufferMux.Unlock()
 		if hasUpdates {
 			return true
 		}
@@ -166,6 +215,27 @@ type sub struct {
 	writer         SubscriptionResponseWriter
 	id             SubscriptionIdentifier
 	pendingUpdates int
+
+	bufferMux sync.Mutex
+	buffer    subscriptionBuffer
+	// inFlight tracks the task sending the buffered updates
+	inFlight sync.WaitGroup
+
+	// liveResult is the last result sent to a live query subscription, patches are computed against it
+	liveResult []byte
+}
+
+// sendSubscriptionUpdates sends the buffered updates of the subscription in order until the buffer is empty
+func (r *Resolver) sendSubscriptionUpdates(ctx *Context, sub *sub) {
+	for {
+		sub.bufferMux.Lock()
+		data, ok := sub.buffer.pop()
+		sub.bufferMux.Unlock()
+		if !ok {
+			return
+		}
+		r.executeSubscriptionUpdate(ctx, sub, data)
+	}
 }
 
 func (r *Resolver) executeSubscriptionUpdate(ctx *Context, sub *sub, sharedInput []byte) {
@@ -176,6 +246,10 @@ func (r *Resolver) executeSubscriptionUpdate(ctx *Context, sub *sub, sharedInput
 		fmt.Printf("resolver:trigger:subscription:update:%d\n", sub.id.SubscriptionID)
 		defer fmt.Printf("resolver:trigger:subscription:update:done:%d\n", sub.id.SubscriptionID)
 	}
+	if sub.resolve.liveQuery != nil {
+		r.sendLiveQueryResult(ctx, sub, sharedInput)
+		return
+	}
 	t := r.getTools()
 	defer r.putTools(t)
 	input := make([]byte, len(sharedInput))
@@ -196,6 +270,33 @@ func (r *Resolver) executeSubscriptionUpdate(ctx *Context, sub *sub, sharedInput
 		return
 	}
 	sub.writer.Flush()
+	recordSubscriptionUpdate(ctx, sub.id)
+	if r.reporter != nil {
+		r.reporter.SubscriptionUpdateSent()
+	}
+}
+
+// sendLiveQueryResult sends a result of a live query, which was resolved once by the trigger for all of its subscriptions
+func (r *Resolver) sendLiveQueryResult(ctx *Context, sub *sub, result []byte) {
+	sub.mux.Lock()
+	sub.pendingUpdates--
+	defer sub.mux.Unlock()
+	if sub.writer == nil {
+		return // subscription was already closed by the client
+	}
+	data := result
+	if sub.resolve.liveQuery.Patch && sub.liveResult != nil {
+		patch, err := liveQueryPatch(sub.liveResult, result)
+		if err == nil {
+			data = patch
+		}
+	}
+	if _, err := sub.writer.Write(data); err != nil {
+		return
+	}
+	sub.writer.Flush()
+	sub.liveResult = result
+	recordSubscriptionUpdate(ctx, sub.id)
 	if r.reporter != nil {
 		r.reporter.SubscriptionUpdateSent()
 	}
@@ -219,7 +320,7 @@ func (r *Resolver) handleEvent(event subscriptionEvent) {
 	case subscriptionEventKindAddSubscription:
 		r.handleAddSubscription(event.triggerID, event.addSubscription)
 	case subscriptionEventKindRemoveSubscription:
-		r.handleRemoveSubscription(event.id)
+		r.handleRemoveSubscription(event.id, event.removed)
 	case subscriptionEventKindRemoveClient:
 		r.handleRemoveClient(event.id.ConnectionID)
 	case subscriptionEventKindTriggerUpdate:
@@ -229,6 +330,18 @@ func (r *Resolver) handleEvent(event subscriptionEvent) {
 	case subscriptionEventKindUnknown:
 		panic("unknown event")
 	}
+	if event.kind != subscriptionEventKindTriggerUpdate {
+		r.reportActiveSubscriptions()
+	}
+}
+
+// reportActiveSubscriptions reports the number of subscriptions and triggers, it must only be called from the event loop
+func (r *Resolver) reportActiveSubscriptions() {
+	subscriptions := 0
+	for _, trig := range r.triggers {
+		subscriptions += len(trig.subscriptions)
+	}
+	r.metrics.ActiveSubscriptions(subscriptions, len(r.triggers))
 }
 
 func (r *Resolver) handleTriggerDone(triggerID uint64) {
@@ -263,6 +376,9 @@ func (r *Resolver) handleAddSubscription(triggerID uint64, add *addSubscription)
 	trig, ok := r.triggers[triggerID]
 	if ok {
 		trig.subscriptions[add.ctx] = s
+		if trig.live && trig.liveResult != nil {
+			r.pushSubscriptionUpdate(trig, add.ctx, s, trig.liveResult)
+		}
 		return
 	}
 	if r.options.Debug {
@@ -279,6 +395,8 @@ func (r *Resolver) handleAddSubscription(triggerID uint64, add *addSubscription)
 		id:            triggerID,
 		subscriptions: make(map[*Context]*sub),
 		cancel:        cancel,
+		inFlight:      &sync.WaitGroup{},
+		live:          add.resolve.liveQuery != nil,
 	}
 	r.triggers[triggerID] = trig
 	trig.subscriptions[add.ctx] = s
@@ -288,7 +406,9 @@ func (r *Resolver) handleAddSubscription(triggerID uint64, add *addSubscription)
 	}
 }
 
-func (r *Resolver) handleRemoveSubscription(id SubscriptionIdentifier) {
+// handleRemoveSubscription removes the subscription, removed is closed once it has no more updates in flight
+func (r *Resolver) handleRemoveSubscription(id SubscriptionIdentifier, removed chan struct{}) {
+	var removedSubs []*sub
 	for u := range r.triggers {
 		trig := r.triggers[u]
 		for ctx, s := range trig.subscriptions {
@@ -298,6 +418,7 @@ func (r *Resolver) handleRemoveSubscription(id SubscriptionIdentifier) {
 				s.writer = nil
 				s.mux.Unlock()
 				delete(trig.subscriptions, ctx)
+				removedSubs = append(removedSubs, s)
 				if r.options.Debug {
 					fmt.Printf("resolver:trigger:subscription:done:%d:%d\n", trig.id, id.SubscriptionID)
 				}
@@ -307,6 +428,15 @@ func (r *Resolver) handleRemoveSubscription(id SubscriptionIdentifier) {
 			r.shutdownTrigger(trig.id)
 		}
 	}
+	if removed == nil {
+		return
+	}
+	go func() {
+		for _, s := range removedSubs {
+			s.inFlight.Wait()
+		}
+		close(removed)
+	}()
 }
 
 func (r *Resolver) handleRemoveClient(id int64) {
@@ -337,18 +467,66 @@ func (r *Resolver) handleTriggerUpdate(id uint64, data []byte) {
 	if r.options.Debug {
 		fmt.Printf("resolver:trigger:update:%d\n", id)
 	}
-	wg := &sync.WaitGroup{}
-	wg.Add(len(trig.subscriptions))
-	trig.inFlight = wg
+	if trig.live {
+		trig.liveResult = data
+	}
 	for c, s := range trig.subscriptions {
-		c, s := c, s
-		r.triggerUpdatePool.Submit(func() {
-			r.executeSubscriptionUpdate(c, s, data)
-			wg.Done()
-		})
+		r.pushSubscriptionUpdate(trig, c, s, data)
+	}
+	if len(trig.subscriptions) == 0 {
+		r.shutdownTrigger(id)
+		r.reportActiveSubscriptions()
 	}
 }
 
+// pushSubscriptionUpdate buffers the update for the subscription and schedules the task sending its updates if necessary.
+// Filtered updates are skipped before buffering, so they don't take up space in the buffer of the subscription.
+func (r *Resolver) pushSubscriptionUpdate(trig *trigger, c *Context, s *sub, data []byte) {
+	if skipSubscriptionEvent(c, &s.resolve.Trigger, data) {
+		return
+	}
+	policy := r.options.SubscriptionOverflowPolicy
+	s.bufferMux.Lock()
+	schedule, dropped, disconnect := s.buffer.push(data, r.options.SubscriptionBufferSize, policy)
+	s.bufferMux.Unlock()
+	if dropped != 0 {
+		r.metrics.SubscriptionUpdatesDropped(policy.String(), dropped)
+	}
+	if disconnect {
+		r.disconnectSlowSubscription(trig, c, s)
+		return
+	}
+	if !schedule {
+		return
+	}
+	// a single task per subscription sends its updates, so that they are sent in order
+	trig.inFlight.Add(1)
+	s.inFlight.Add(1)
+	r.triggerUpdatePool.Submit(func() {
+		defer trig.inFlight.Done()
+		defer s.inFlight.Done()
+		r.sendSubscriptionUpdates(c, s)
+	})
+}
+
+// disconnectSlowSubscription removes a subscription which overflowed its buffer and completes it with an error.
+// The client is completed asynchronously, because it might still be busy with a previous update.
+func (r *Resolver) disconnectSlowSubscription(trig *trigger, ctx *Context, s *sub) {
+	delete(trig.subscriptions, ctx)
+	if r.options.Debug {
+		fmt.Printf("resolver:trigger:subscription:overflow:%d:%d\n", trig.id, s.id.SubscriptionID)
+	}
+	go func() {
+		s.mux.Lock()
+		defer s.mux.Unlock()
+		if s.writer == nil {
+			return
+		}
+		_ = writeFlushComplete(s.writer, errSubscriptionBufferOverflow)
+		s.writer = nil
+	}()
+}
+
 func (r *Resolver) shutdownTrigger(id uint64) {
 	trig, ok := r.triggers[id]
 	if !ok {
@@ -382,6 +560,7 @@ func (r *Resolver) handleShutdown() {
 		fmt.Printf("resolver:trigger:shutdown:done\n")
 	}
 	r.triggers = make(map[uint64]*trigger)
+	r.reportActiveSubscriptions()
 }
 
 type SubscriptionIdentifier struct {
@@ -464,6 +643,8 @@ func (r *Resolver) ResolveGraphQLSubscription(ctx *Context, subscription *GraphQ
 	if r.options.Debug {
 		fmt.Printf("resolver:trigger:unsubscribe:sync:%d:%d\n", uniqueID, id.SubscriptionID)
 	}
+	// the caller might reuse ctx and writer after returning, so the updates in flight have to be sent before
+	removed := make(chan struct{})
 	select {
 	case <-r.ctx.Done():
 		return ErrResolverClosed
@@ -471,8 +652,14 @@ func (r *Resolver) ResolveGraphQLSubscription(ctx *Context, subscription *GraphQ
 		triggerID: uniqueID,
 		kind:      subscriptionEventKindRemoveSubscription,
 		id:        id,
+		removed:   removed,
 	}:
 	}
+	select {
+	case <-r.ctx.Done():
+		return ErrResolverClosed
+	case <-removed:
+	}
 	return nil
 }
 
@@ -580,6 +767,8 @@ type subscriptionEvent struct {
 	kind            subscriptionEventKind
 	data            []byte
 	addSubscription *addSubscription
+	// removed is closed once the subscription of a remove event has no more updates in flight
+	removed chan struct{}
 }
 
 type addSubscription struct {
@@ -4126,7 +4126,7 @@ func TestResolver_ResolveGraphQLSubscription(t *testing.T) {
 		assert.NoError(t, err)
 		recorder.AwaitComplete(t, defaultTimeout)
 		assert.Equal(t, 3, len(recorder.Messages()))
-		assert.ElementsMatch(t, []string{
+		assert.Equal(t, []string{
 			`{"data":{"counter":0}}`,
 			`{"data":{"counter":1}}`,
 			`{"data":{"counter":2}}`,
@@ -4153,7 +4153,7 @@ func TestResolver_ResolveGraphQLSubscription(t *testing.T) {
 		assert.NoError(t, err)
 		recorder.AwaitComplete(t, defaultTimeout)
 		assert.Equal(t, 3, len(recorder.Messages()))
-		assert.ElementsMatch(t, []string{
+		assert.Equal(t, []string{
 			`{"data":{"counter":0}}`,
 			`{"data":{"counter":1}}`,
 			`{"data":{"counter":2}}`,
@@ -4180,7 +4180,7 @@ func TestResolver_ResolveGraphQLSubscription(t *testing.T) {
 		assert.NoError(t, err)
 		recorder.AwaitComplete(t, defaultTimeout)
 		assert.Equal(t, 3, len(recorder.Messages()))
-		assert.ElementsMatch(t, []string{
+		assert.Equal(t, []string{
 			`{"data":{"counter":0}}`,
 			`{"data":{"counter":1}}`,
 			`{"data":{"counter":2}}`,
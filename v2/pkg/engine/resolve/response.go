@@ -10,6 +10,9 @@ import (
 type GraphQLSubscription struct {
 	Trigger  GraphQLSubscriptionTrigger
 	Response *GraphQLResponse
+
+	// liveQuery is set if the subscription streams the results of a live query, its updates are complete responses
+	liveQuery *GraphQLLiveQuery
 }
 
 type GraphQLSubscriptionTrigger struct {
@@ -18,6 +21,8 @@ type GraphQLSubscriptionTrigger struct {
 	Variables      Variables
 	Source         SubscriptionDataSource
 	PostProcessing PostProcessingConfiguration
+	// Filters decide for every subscriber whether an event is sent to it, all filters have to pass
+	Filters []SubscriptionFilter
 }
 
 type GraphQLResponse struct {
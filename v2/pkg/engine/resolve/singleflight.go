@@ -0,0 +1,128 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/httpclient"
+)
+
+// singleFlight deduplicates concurrent identical upstream requests.
+// Two loads are identical if they target the same data source and their final rendered input,
+// including forwarded headers and extensions, is byte for byte the same.
+// The first load (the leader) calls the data source, all loads arriving while it's in flight wait for it and share its response.
+type singleFlight struct {
+	mu       sync.Mutex
+	inflight map[uint64]*inflightLoad
+	// retain keeps successful loads after they finished, so that later identical loads reuse their response
+	retain bool
+	// onWait is called when a load starts waiting for an in-flight load, it's used by tests
+	onWait func()
+}
+
+type inflightLoad struct {
+	done chan struct{}
+	out  []byte
+	err  error
+	// headers are the upstream response headers of the leader, they're reported to the observers of the waiters,
+	// e.g. to let the response cache see their Cache-Control
+	headers []http.Header
+	// canceled is true if the load failed because the context of the leader ended
+	canceled bool
+}
+
+func newSingleFlight() *singleFlight {
+	return &singleFlight{
+		inflight: make(map[uint64]*inflightLoad),
+	}
+}
+
+// BatchLoadCache shares the responses of identical loads between all operations of a batch.
+// In contrast to single flight, successful responses are kept for the lifetime of the cache,
+// so loads of different operations don't have to be concurrent to be deduplicated.
+// A BatchLoadCache must only be used for the operations of a single request, see Context.WithBatchLoadCache.
+type BatchLoadCache struct {
+	loads *singleFlight
+}
+
+func NewBatchLoadCache() *BatchLoadCache {
+	return &BatchLoadCache{
+		loads: &singleFlight{
+			inflight: make(map[uint64]*inflightLoad),
+			retain:   true,
+		},
+	}
+}
+
+func (s *singleFlight) key(dataSourceID string, input []byte) uint64 {
+	xxh := xxhash.New()
+	_, _ = xxh.WriteString(dataSourceID)
+	_, _ = xxh.Write([]byte{0})
+	_, _ = xxh.Write(input)
+	return xxh.Sum64()
+}
+
+// load calls fn, or waits for an identical in-flight load and writes its response to out.
+// shared is true when the response of another load was used.
+// A waiting load returns early with the error of its own context if it's cancelled before the leader finishes.
+// If the context of the leader ends before it finishes, its waiters don't share the error but retry the load, one of them as the new leader.
+// The response headers of the leader are reported to the response headers observers of the waiters.
+func (s *singleFlight) load(ctx context.Context, dataSourceID string, input []byte, out *bytes.Buffer, fn func(ctx context.Context, out *bytes.Buffer) error) (shared bool, err error) {
+	key := s.key(dataSourceID, input)
+
+	for {
+		s.mu.Lock()
+		flight, ok := s.inflight[key]
+		if !ok {
+			break
+		}
+		s.mu.Unlock()
+		if s.onWait != nil {
+			s.onWait()
+		}
+		select {
+		case <-flight.done:
+			if flight.canceled {
+				continue
+			}
+			for _, header := range flight.headers {
+				httpclient.ObserveResponseHeaders(ctx, header)
+			}
+			out.Write(flight.out)
+			return true, flight.err
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+	flight := &inflightLoad{
+		done: make(chan struct{}),
+	}
+	s.inflight[key] = flight
+	s.mu.Unlock()
+
+	defer func() {
+		if !s.retain || flight.err != nil {
+			s.mu.Lock()
+			delete(s.inflight, key)
+			s.mu.Unlock()
+		}
+		close(flight.done)
+	}()
+
+	start := out.Len()
+	err = fn(httpclient.WithResponseHeadersObserver(ctx, func(header http.Header) {
+		flight.headers = append(flight.headers, header.Clone())
+	}), out)
+
+	// the response is copied because out is owned by the leader and returned to a pool after merging
+	flight.out = make([]byte, out.Len()-start)
+	copy(flight.out, out.Bytes()[start:])
+	flight.err = err
+	flight.canceled = err != nil && ctx.Err() != nil
+
+	return false, err
+}
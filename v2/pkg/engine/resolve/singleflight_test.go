@@ -0,0 +1,386 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astjson"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/httpclient"
+)
+
+type blockingDataSource struct {
+	calls   atomic.Int64
+	started chan struct{}
+	release chan struct{}
+	data    []byte
+	err     error
+}
+
+func newBlockingDataSource(data string) *blockingDataSource {
+	return &blockingDataSource{
+		started: make(chan struct{}, 16),
+		release: make(chan struct{}),
+		data:    []byte(data),
+	}
+}
+
+func (b *blockingDataSource) Load(ctx context.Context, input []byte, w io.Writer) (err error) {
+	b.calls.Inc()
+	b.started <- struct{}{}
+	<-b.release
+	if b.err != nil {
+		return b.err
+	}
+	_, err = w.Write(b.data)
+	return err
+}
+
+// countWaiters counts the loads which start waiting for an in-flight load of sf
+func countWaiters(sf *singleFlight) *atomic.Int64 {
+	waiters := atomic.NewInt64(0)
+	sf.onWait = func() {
+		waiters.Inc()
+	}
+	return waiters
+}
+
+func waitForWaiters(t *testing.T, waiters *atomic.Int64, expected int64) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return waiters.Load() == expected
+	}, time.Second, time.Millisecond)
+}
+
+func TestSingleFlight(t *testing.T) {
+	load := func(sf *singleFlight, ds *blockingDataSource, dataSourceID, input string) (out *bytes.Buffer, shared bool, err error) {
+		out = &bytes.Buffer{}
+		shared, err = sf.load(context.Background(), dataSourceID, []byte(input), out, func(ctx context.Context, out *bytes.Buffer) error {
+			return ds.Load(ctx, []byte(input), out)
+		})
+		return out, shared, err
+	}
+
+	t.Run("concurrent identical loads share one response", func(t *testing.T) {
+		sf := newSingleFlight()
+		waiters := countWaiters(sf)
+		ds := newBlockingDataSource(`{"data":{"hero":"R2-D2"}}`)
+
+		type loadResult struct {
+			out    string
+			shared bool
+			err    error
+		}
+		results := make([]loadResult, 3)
+		wg := &sync.WaitGroup{}
+		run := func(i int) {
+			defer wg.Done()
+			out, shared, err := load(sf, ds, "swapi", `{"body":{"query":"{hero}"}}`)
+			results[i] = loadResult{out: out.String(), shared: shared, err: err}
+		}
+
+		wg.Add(1)
+		go run(0)
+		<-ds.started
+		wg.Add(2)
+		go run(1)
+		go run(2)
+		waitForWaiters(t, waiters, 2)
+		close(ds.release)
+		wg.Wait()
+
+		assert.Equal(t, int64(1), ds.calls.Load())
+		assert.Equal(t, []loadResult{
+			{out: `{"data":{"hero":"R2-D2"}}`, shared: false},
+			{out: `{"data":{"hero":"R2-D2"}}`, shared: true},
+			{out: `{"data":{"hero":"R2-D2"}}`, shared: true},
+		}, results)
+		assert.Empty(t, sf.inflight)
+	})
+
+	t.Run("errors are shared", func(t *testing.T) {
+		sf := newSingleFlight()
+		waiters := countWaiters(sf)
+		ds := newBlockingDataSource("")
+		ds.err = errors.New("upstream unavailable")
+
+		var sharedErr error
+		done := make(chan struct{})
+		go func() {
+			_, _, sharedErr = load(sf, ds, "swapi", "input")
+			close(done)
+		}()
+		<-ds.started
+		go func() {
+			_, _, _ = load(sf, ds, "swapi", "input")
+		}()
+		waitForWaiters(t, waiters, 1)
+		close(ds.release)
+		<-done
+
+		assert.EqualError(t, sharedErr, "upstream unavailable")
+		assert.Equal(t, int64(1), ds.calls.Load())
+	})
+
+	t.Run("loads with a different data source or input are not shared", func(t *testing.T) {
+		sf := newSingleFlight()
+		ds := newBlockingDataSource(`{}`)
+		close(ds.release)
+
+		for _, call := range [][2]string{{"a", "input"}, {"b", "input"}, {"a", "other"}} {
+			out, shared, err := load(sf, ds, call[0], call[1])
+			require.NoError(t, err)
+			assert.False(t, shared)
+			assert.Equal(t, `{}`, out.String())
+		}
+		assert.Equal(t, int64(3), ds.calls.Load())
+	})
+
+	t.Run("batch load cache keeps successful responses", func(t *testing.T) {
+		cache := NewBatchLoadCache()
+		ds := newBlockingDataSource(`{}`)
+		close(ds.release)
+
+		for i, expectShared := range []bool{false, true, true} {
+			out, shared, err := load(cache.loads, ds, "swapi", "input")
+			require.NoError(t, err)
+			assert.Equal(t, expectShared, shared, i)
+			assert.Equal(t, `{}`, out.String())
+		}
+		assert.Equal(t, int64(1), ds.calls.Load())
+
+		failing := newBlockingDataSource("")
+		failing.err = errors.New("upstream unavailable")
+		close(failing.release)
+		for i := 0; i < 2; i++ {
+			_, shared, err := load(cache.loads, failing, "swapi", "failing")
+			assert.EqualError(t, err, "upstream unavailable")
+			assert.False(t, shared)
+		}
+		assert.Equal(t, int64(2), failing.calls.Load())
+	})
+
+	t.Run("response headers of the leader are reported to every load", func(t *testing.T) {
+		cache := NewBatchLoadCache()
+		header := http.Header{"Cache-Control": []string{"private"}}
+
+		for i, expectShared := range []bool{false, true} {
+			var observed []http.Header
+			ctx := httpclient.WithResponseHeadersObserver(context.Background(), func(header http.Header) {
+				observed = append(observed, header)
+			})
+			shared, err := cache.loads.load(ctx, "swapi", []byte("input"), &bytes.Buffer{}, func(ctx context.Context, out *bytes.Buffer) error {
+				httpclient.ObserveResponseHeaders(ctx, header)
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, expectShared, shared, i)
+			assert.Equal(t, []http.Header{header}, observed, i)
+		}
+	})
+
+	t.Run("waiting load is cancelled with its context", func(t *testing.T) {
+		sf := newSingleFlight()
+		waiters := countWaiters(sf)
+		ds := newBlockingDataSource(`{}`)
+
+		go func() {
+			_, _, _ = load(sf, ds, "swapi", "input")
+		}()
+		<-ds.started
+
+		ctx, cancel := context.WithCancel(context.Background())
+		errCh := make(chan error)
+		go func() {
+			_, err := sf.load(ctx, "swapi", []byte("input"), &bytes.Buffer{}, func(ctx context.Context, out *bytes.Buffer) error {
+				return ds.Load(ctx, []byte("input"), out)
+			})
+			errCh <- err
+		}()
+		waitForWaiters(t, waiters, 1)
+		cancel()
+		assert.ErrorIs(t, <-errCh, context.Canceled)
+		close(ds.release)
+		assert.Equal(t, int64(1), ds.calls.Load())
+	})
+	t.Run("waiting load retries if the leader is cancelled", func(t *testing.T) {
+		sf := newSingleFlight()
+		waiters := countWaiters(sf)
+		ds := newBlockingDataSource(`{}`)
+		close(ds.release)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		leaderStarted := make(chan struct{})
+		errCh := make(chan error)
+		go func() {
+			_, err := sf.load(ctx, "swapi", []byte("input"), &bytes.Buffer{}, func(ctx context.Context, out *bytes.Buffer) error {
+				close(leaderStarted)
+				<-ctx.Done()
+				return ctx.Err()
+			})
+			errCh <- err
+		}()
+		<-leaderStarted
+
+		type loadResult struct {
+			out    string
+			shared bool
+			err    error
+		}
+		resultCh := make(chan loadResult)
+		go func() {
+			out, shared, err := load(sf, ds, "swapi", "input")
+			resultCh <- loadResult{out: out.String(), shared: shared, err: err}
+		}()
+		waitForWaiters(t, waiters, 1)
+		cancel()
+
+		assert.ErrorIs(t, <-errCh, context.Canceled)
+		assert.Equal(t, loadResult{out: `{}`, shared: false}, <-resultCh)
+		assert.Equal(t, int64(1), ds.calls.Load())
+		assert.Empty(t, sf.inflight)
+	})
+}
+
+func TestLoader_SingleFlight(t *testing.T) {
+	newResponse := func(ds DataSource, operationType ast.OperationType) *GraphQLResponse {
+		return &GraphQLResponse{
+			Info: &GraphQLResponseInfo{
+				OperationType: operationType,
+			},
+			Data: &Object{
+				Fetch: &SingleFetch{
+					InputTemplate: InputTemplate{
+						Segments: []TemplateSegment{
+							{
+								Data:        []byte(`{"method":"POST","url":"http://swapi","header":{"Authorization":["token"]},"body":{"query":"{hero}"}}`),
+								SegmentType: StaticSegmentType,
+							},
+						},
+					},
+					FetchConfiguration: FetchConfiguration{
+						DataSource: ds,
+						PostProcessing: PostProcessingConfiguration{
+							SelectResponseDataPath: []string{"data"},
+						},
+					},
+					Info: &FetchInfo{
+						DataSourceID: "swapi",
+					},
+				},
+				Fields: []*Field{
+					{
+						Name: []byte("hero"),
+						Value: &String{
+							Path: []string{"hero"},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	type loadResult struct {
+		data  string
+		trace *DataSourceLoadTrace
+	}
+
+	load := func(t *testing.T, sf *singleFlight, ds DataSource, operationType ast.OperationType) loadResult {
+		ctx := NewContext(context.Background())
+		ctx.RequestTracingOptions.Enable = true
+		ctx.RequestTracingOptions.ExcludeLoadStats = true
+		ctx.RequestTracingOptions.ExcludeInput = true
+		ctx.RequestTracingOptions.ExcludeOutput = true
+		ctx.RequestTracingOptions.ExcludeRawInputData = true
+		response := newResponse(ds, operationType)
+		resolvable := &Resolvable{
+			storage: &astjson.JSON{},
+		}
+		loader := &Loader{
+			singleFlight: sf,
+		}
+		require.NoError(t, resolvable.Init(ctx, nil, operationType))
+		require.NoError(t, loader.LoadGraphQLResponseData(ctx, response, resolvable))
+		out := &bytes.Buffer{}
+		require.NoError(t, resolvable.storage.PrintNode(resolvable.storage.Nodes[resolvable.storage.RootNode], out))
+		return loadResult{
+			data:  out.String(),
+			trace: response.Data.Fetch.(*SingleFetch).Trace,
+		}
+	}
+
+	t.Run("queries are deduplicated", func(t *testing.T) {
+		sf := newSingleFlight()
+		waiters := countWaiters(sf)
+		ds := newBlockingDataSource(`{"data":{"hero":"R2-D2"}}`)
+
+		results := make([]loadResult, 2)
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[0] = load(t, sf, ds, ast.OperationTypeQuery)
+		}()
+		<-ds.started
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[1] = load(t, sf, ds, ast.OperationTypeQuery)
+		}()
+		waitForWaiters(t, waiters, 1)
+		close(ds.release)
+		wg.Wait()
+
+		assert.Equal(t, int64(1), ds.calls.Load())
+		for i, result := range results {
+			assert.Equal(t, `{"errors":[],"data":{"hero":"R2-D2"}}`, result.data)
+			require.NotNil(t, result.trace)
+			assert.True(t, result.trace.SingleFlightUsed)
+			assert.Equal(t, i == 1, result.trace.SingleFlightSharedResponse)
+		}
+	})
+
+	t.Run("mutations are never deduplicated", func(t *testing.T) {
+		sf := newSingleFlight()
+		ds := newBlockingDataSource(`{"data":{"hero":"R2-D2"}}`)
+
+		results := make([]loadResult, 2)
+		wg := &sync.WaitGroup{}
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i] = load(t, sf, ds, ast.OperationTypeMutation)
+			}(i)
+		}
+		<-ds.started
+		<-ds.started
+		close(ds.release)
+		wg.Wait()
+
+		assert.Equal(t, int64(2), ds.calls.Load())
+		for _, result := range results {
+			assert.Equal(t, `{"errors":[],"data":{"hero":"R2-D2"}}`, result.data)
+			assert.False(t, result.trace.SingleFlightUsed)
+			assert.False(t, result.trace.SingleFlightSharedResponse)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		ds := newBlockingDataSource(`{"data":{"hero":"R2-D2"}}`)
+		close(ds.release)
+		result := load(t, nil, ds, ast.OperationTypeQuery)
+		assert.Equal(t, `{"errors":[],"data":{"hero":"R2-D2"}}`, result.data)
+		assert.False(t, result.trace.SingleFlightUsed)
+	})
+}
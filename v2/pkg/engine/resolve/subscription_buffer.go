@@ -0,0 +1,87 @@
+package resolve
+
+// DefaultSubscriptionBufferSize is the number of updates buffered per subscription if ResolverOptions.SubscriptionBufferSize is 0
+const DefaultSubscriptionBufferSize = 128
+
+// OverflowPolicy decides what happens to the updates of a subscription if its client can't keep up and the buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowPolicyDropOldest drops the oldest buffered update to make room for the new one
+	OverflowPolicyDropOldest OverflowPolicy = iota
+	// OverflowPolicyDropNewest drops the new update and keeps the buffered ones
+	OverflowPolicyDropNewest
+	// OverflowPolicyCoalesce drops all buffered updates, so that only the latest update is sent to the client
+	OverflowPolicyCoalesce
+	// OverflowPolicyDisconnect completes the subscription with an error
+	OverflowPolicyDisconnect
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowPolicyDropOldest:
+		return "drop_oldest"
+	case OverflowPolicyDropNewest:
+		return "drop_newest"
+	case OverflowPolicyCoalesce:
+		return "coalesce"
+	case OverflowPolicyDisconnect:
+		return "disconnect"
+	}
+	return "unknown"
+}
+
+var errSubscriptionBufferOverflow = []byte(`{"errors":[{"message":"subscription closed because the client could not keep up with the updates"}]}`)
+
+// subscriptionBuffer holds the updates of a subscription which were not sent to its client yet.
+// Updates are sent in order by a single task per subscription, which is scheduled when the first update is buffered.
+// It must be guarded by sub.bufferMux.
+type subscriptionBuffer struct {
+	updates   [][]byte
+	scheduled bool
+}
+
+// push adds the update to the buffer and applies the policy if the buffer is full.
+// It returns whether a task has to be scheduled to send the updates,
+// the number of dropped updates and whether the subscription has to be disconnected.
+func (b *subscriptionBuffer) push(data []byte, size int, policy OverflowPolicy) (schedule bool, dropped int, disconnect bool) {
+	if len(b.updates) >= size {
+		switch policy {
+		case OverflowPolicyDropNewest:
+			return false, 1, false
+		case OverflowPolicyCoalesce:
+			dropped = len(b.updates)
+			b.updates = b.updates[:0]
+		case OverflowPolicyDisconnect:
+			dropped = len(b.updates) + 1
+			b.updates = nil
+			return false, dropped, true
+		default:
+			dropped = 1
+			copy(b.updates, b.updates[1:])
+			b.updates = b.updates[:len(b.updates)-1]
+		}
+	}
+	b.updates = append(b.updates, data)
+	if b.scheduled {
+		return false, dropped, false
+	}
+	b.scheduled = true
+	return true, dropped, false
+}
+
+// pop removes the oldest update from the buffer, the task sending the updates ends if it returns false
+func (b *subscriptionBuffer) pop() ([]byte, bool) {
+	if len(b.updates) == 0 {
+		b.scheduled = false
+		return nil, false
+	}
+	data := b.updates[0]
+	b.updates[0] = nil
+	b.updates = b.updates[1:]
+	return data, true
+}
+
+func (b *subscriptionBuffer) pending() bool {
+	return len(b.updates) != 0 || b.scheduled
+}
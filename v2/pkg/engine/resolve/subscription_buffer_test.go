@@ -0,0 +1,186 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionBuffer(t *testing.T) {
+	pushAll := func(b *subscriptionBuffer, policy OverflowPolicy, updates ...string) (dropped int, disconnect bool) {
+		for _, update := range updates {
+			_, d, dc := b.push([]byte(update), 2, policy)
+			dropped += d
+			disconnect = disconnect || dc
+		}
+		return dropped, disconnect
+	}
+	popAll := func(b *subscriptionBuffer) (updates []string) {
+		for {
+			data, ok := b.pop()
+			if !ok {
+				return updates
+			}
+			updates = append(updates, string(data))
+		}
+	}
+
+	t.Run("schedules a single task", func(t *testing.T) {
+		b := &subscriptionBuffer{}
+		schedule, _, _ := b.push([]byte("a"), 2, OverflowPolicyDropOldest)
+		assert.True(t, schedule)
+		schedule, _, _ = b.push([]byte("b"), 2, OverflowPolicyDropOldest)
+		assert.False(t, schedule)
+		assert.Equal(t, []string{"a", "b"}, popAll(b))
+		assert.False(t, b.pending())
+
+		schedule, _, _ = b.push([]byte("c"), 2, OverflowPolicyDropOldest)
+		assert.True(t, schedule)
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		b := &subscriptionBuffer{}
+		dropped, disconnect := pushAll(b, OverflowPolicyDropOldest, "a", "b", "c", "d")
+		assert.Equal(t, 2, dropped)
+		assert.False(t, disconnect)
+		assert.Equal(t, []string{"c", "d"}, popAll(b))
+	})
+
+	t.Run("drop newest", func(t *testing.T) {
+		b := &subscriptionBuffer{}
+		dropped, disconnect := pushAll(b, OverflowPolicyDropNewest, "a", "b", "c", "d")
+		assert.Equal(t, 2, dropped)
+		assert.False(t, disconnect)
+		assert.Equal(t, []string{"a", "b"}, popAll(b))
+	})
+
+	t.Run("coalesce", func(t *testing.T) {
+		b := &subscriptionBuffer{}
+		dropped, disconnect := pushAll(b, OverflowPolicyCoalesce, "a", "b", "c")
+		assert.Equal(t, 2, dropped)
+		assert.False(t, disconnect)
+		assert.Equal(t, []string{"c"}, popAll(b))
+	})
+
+	t.Run("disconnect", func(t *testing.T) {
+		b := &subscriptionBuffer{}
+		dropped, disconnect := pushAll(b, OverflowPolicyDisconnect, "a", "b", "c")
+		assert.Equal(t, 3, dropped)
+		assert.True(t, disconnect)
+		assert.Empty(t, popAll(b))
+	})
+}
+
+// slowSubscriptionRecorder blocks on the first flush until it is released
+type slowSubscriptionRecorder struct {
+	SubscriptionRecorder
+	flushed    chan struct{}
+	release    chan struct{}
+	firstFlush sync.Once
+}
+
+func (s *slowSubscriptionRecorder) Flush() {
+	s.SubscriptionRecorder.Flush()
+	s.firstFlush.Do(func() {
+		close(s.flushed)
+		<-s.release
+	})
+}
+
+func TestResolver_SlowSubscriber(t *testing.T) {
+	run := func(t *testing.T, policy OverflowPolicy, filters ...SubscriptionFilter) (*slowSubscriptionRecorder, *metricsRecorder) {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		recorder := &slowSubscriptionRecorder{
+			SubscriptionRecorder: SubscriptionRecorder{buf: &bytes.Buffer{}},
+			flushed:              make(chan struct{}),
+			release:              make(chan struct{}),
+		}
+		m := &metricsRecorder{}
+		resolver := New(ctx, ResolverOptions{
+			MaxConcurrency:             1024,
+			Metrics:                    m,
+			SubscriptionBufferSize:     2,
+			SubscriptionOverflowPolicy: policy,
+		})
+
+		// all updates after the first one are sent while the client is busy with the first one
+		stream := createFakeStream(func(counter int) (message string, done bool) {
+			if counter > 0 {
+				<-recorder.flushed
+			}
+			return fmt.Sprintf(`{"data":{"counter":%d}}`, counter), counter == 9
+		}, 0, nil)
+		plan := &GraphQLSubscription{
+			Trigger: GraphQLSubscriptionTrigger{
+				Source: stream,
+				InputTemplate: InputTemplate{
+					Segments: []TemplateSegment{
+						{SegmentType: StaticSegmentType, Data: []byte(`{}`)},
+					},
+				},
+				PostProcessing: PostProcessingConfiguration{
+					SelectResponseDataPath: []string{"data"},
+				},
+				Filters: filters,
+			},
+			Response: &GraphQLResponse{
+				Data: &Object{
+					Fields: []*Field{
+						{Name: []byte("counter"), Value: &Integer{Path: []string{"counter"}}},
+					},
+				},
+			},
+		}
+
+		err := resolver.AsyncResolveGraphQLSubscription(&Context{}, plan, recorder, SubscriptionIdentifier{ConnectionID: 1, SubscriptionID: 1})
+		require.NoError(t, err)
+
+		<-recorder.flushed
+		stream.AwaitIsDone(t, time.Second)
+		close(recorder.release)
+		recorder.AwaitComplete(t, time.Second)
+		return recorder, m
+	}
+
+	t.Run("updates are sent in order and overflowing updates are dropped", func(t *testing.T) {
+		recorder, m := run(t, OverflowPolicyDropOldest)
+		assert.Equal(t, []string{
+			`{"data":{"counter":0}}`,
+			`{"data":{"counter":8}}`,
+			`{"data":{"counter":9}}`,
+		}, recorder.Messages())
+		assert.Len(t, m.recordedDroppedUpdates(), 7)
+		assert.Contains(t, m.recordedDroppedUpdates(), "drop_oldest:1")
+	})
+
+	t.Run("slow subscriber is disconnected", func(t *testing.T) {
+		recorder, m := run(t, OverflowPolicyDisconnect)
+		assert.Equal(t, []string{
+			`{"data":{"counter":0}}`,
+			string(errSubscriptionBufferOverflow),
+		}, recorder.Messages())
+		assert.Equal(t, []string{"disconnect:3"}, m.recordedDroppedUpdates())
+	})
+	t.Run("filtered updates don't overflow the buffer", func(t *testing.T) {
+		// only the first and the last update are sent to the subscriber
+		filter := SubscriptionFilterFunc(func(ctx *Context, data []byte) (bool, error) {
+			counter, err := jsonparser.GetInt(data, "data", "counter")
+			return counter != 0 && counter != 9, err
+		})
+		recorder, m := run(t, OverflowPolicyDisconnect, filter)
+		assert.Equal(t, []string{
+			`{"data":{"counter":0}}`,
+			`{"data":{"counter":9}}`,
+		}, recorder.Messages())
+		assert.Empty(t, m.recordedDroppedUpdates())
+	})
+}
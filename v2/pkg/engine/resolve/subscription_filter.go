@@ -0,0 +1,111 @@
+package resolve
+
+import (
+	"bytes"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/pool"
+)
+
+// SubscriptionFilter decides for every subscriber of a trigger whether an event is sent to it.
+// Filters are evaluated before the event is buffered for the subscriber, so skipped events don't cause any fetches.
+// They run on the event loop of the resolver and must not block.
+type SubscriptionFilter interface {
+	// SkipEvent returns true if the event must not be sent to the subscriber of ctx.
+	// data is the event payload as received from the data source.
+	SkipEvent(ctx *Context, data []byte) (skip bool, err error)
+}
+
+// SubscriptionFilterFunc is a SubscriptionFilter implemented by a function, e.g. a predicate on the claims of the subscriber.
+type SubscriptionFilterFunc func(ctx *Context, data []byte) (skip bool, err error)
+
+func (f SubscriptionFilterFunc) SkipEvent(ctx *Context, data []byte) (skip bool, err error) {
+	return f(ctx, data)
+}
+
+// SubscriptionFilterClaimSubject is the claim of a SubscriptionFieldFilter comparing the event with the subject of the subscriber
+const SubscriptionFilterClaimSubject = "sub"
+
+// SubscriptionFieldFilter skips events if the value at FieldPath doesn't equal the value of the subscriber,
+// which is either rendered from its arguments or taken from its claims.
+// Strings and numbers are compared by their content, so an ID argument matches both representations.
+type SubscriptionFieldFilter struct {
+	// FieldPath is the path of the value in the response data of the subscription, starting with the root field name
+	FieldPath []string
+	// Input and Variables are resolved into ValueTemplate by the postprocessor like the input of the trigger
+	Input     []byte
+	Variables Variables
+	// ValueTemplate renders the expected value from the variables of the subscriber
+	ValueTemplate InputTemplate
+	// Claim is the claim holding the expected value, it takes precedence over ValueTemplate.
+	// Only SubscriptionFilterClaimSubject is supported.
+	Claim string
+	// PostProcessing is the post processing of the trigger, it's used to find the response data in the event
+	PostProcessing PostProcessingConfiguration
+}
+
+func (f *SubscriptionFieldFilter) SkipEvent(ctx *Context, data []byte) (bool, error) {
+	actual, ok := f.eventValue(data)
+	if !ok {
+		return true, nil
+	}
+	if f.Claim != "" {
+		if f.Claim != SubscriptionFilterClaimSubject || ctx.Claims() == nil {
+			return true, nil
+		}
+		return !bytes.Equal(actual, []byte(ctx.Claims().Subject)), nil
+	}
+
+	buf := pool.BytesBuffer.Get()
+	defer pool.BytesBuffer.Put(buf)
+	if err := f.ValueTemplate.Render(ctx, nil, buf); err != nil {
+		return false, err
+	}
+	return !bytes.Equal(actual, unquoteFilterValue(buf.Bytes())), nil
+}
+
+// eventValue returns the value at FieldPath in the event, events of data sources merging their payload
+// into the response data, e.g. pubsub, don't contain the root field
+func (f *SubscriptionFieldFilter) eventValue(data []byte) ([]byte, bool) {
+	if len(f.PostProcessing.SelectResponseDataPath) != 0 {
+		selected, _, _, err := jsonparser.Get(data, f.PostProcessing.SelectResponseDataPath...)
+		if err != nil {
+			return nil, false
+		}
+		data = selected
+	}
+	path := f.FieldPath
+	if mergePath := f.PostProcessing.MergePath; len(mergePath) != 0 && len(path) >= len(mergePath) {
+		for i := range mergePath {
+			if mergePath[i] != path[i] {
+				return nil, false
+			}
+		}
+		path = path[len(mergePath):]
+	}
+	value, valueType, _, err := jsonparser.Get(data, path...)
+	if err != nil || valueType == jsonparser.Null {
+		return nil, false
+	}
+	return value, true
+}
+
+func unquoteFilterValue(value []byte) []byte {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// skipSubscriptionEvent evaluates the filters of the trigger for the subscriber of ctx.
+// Events are skipped if any filter skips them or fails.
+func skipSubscriptionEvent(ctx *Context, trigger *GraphQLSubscriptionTrigger, data []byte) bool {
+	for _, filter := range trigger.Filters {
+		skip, err := filter.SkipEvent(ctx, data)
+		if err != nil || skip {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionFieldFilter(t *testing.T) {
+	argumentFilter := func(postProcessing PostProcessingConfiguration) *SubscriptionFieldFilter {
+		return &SubscriptionFieldFilter{
+			FieldPath: []string{"orderUpdated", "customer", "id"},
+			ValueTemplate: InputTemplate{
+				Segments: []TemplateSegment{
+					{
+						SegmentType:        VariableSegmentType,
+						VariableKind:       ContextVariableKind,
+						VariableSourcePath: []string{"customerId"},
+						Renderer:           NewPlainVariableRenderer(),
+					},
+				},
+			},
+			PostProcessing: postProcessing,
+		}
+	}
+	skip := func(t *testing.T, filter SubscriptionFilter, ctx *Context, event string) bool {
+		t.Helper()
+		skip, err := filter.SkipEvent(ctx, []byte(event))
+		require.NoError(t, err)
+		return skip
+	}
+
+	t.Run("argument", func(t *testing.T) {
+		filter := argumentFilter(PostProcessingConfiguration{})
+		ctx := &Context{Variables: []byte(`{"customerId":"1"}`)}
+
+		assert.False(t, skip(t, filter, ctx, `{"orderUpdated":{"customer":{"id":"1"}}}`))
+		assert.False(t, skip(t, filter, ctx, `{"orderUpdated":{"customer":{"id":1}}}`))
+		assert.True(t, skip(t, filter, ctx, `{"orderUpdated":{"customer":{"id":"2"}}}`))
+		assert.True(t, skip(t, filter, ctx, `{"orderUpdated":{"customer":null}}`))
+		assert.True(t, skip(t, filter, ctx, `{"orderUpdated":{}}`))
+	})
+
+	t.Run("selected response data", func(t *testing.T) {
+		filter := argumentFilter(PostProcessingConfiguration{SelectResponseDataPath: []string{"data"}})
+		ctx := &Context{Variables: []byte(`{"customerId":"1"}`)}
+
+		assert.False(t, skip(t, filter, ctx, `{"data":{"orderUpdated":{"customer":{"id":"1"}}}}`))
+		assert.True(t, skip(t, filter, ctx, `{"data":{"orderUpdated":{"customer":{"id":"2"}}}}`))
+		assert.True(t, skip(t, filter, ctx, `{"errors":[{"message":"failed"}]}`))
+	})
+
+	t.Run("merged response data", func(t *testing.T) {
+		filter := argumentFilter(PostProcessingConfiguration{MergePath: []string{"orderUpdated"}})
+		ctx := &Context{Variables: []byte(`{"customerId":"1"}`)}
+
+		assert.False(t, skip(t, filter, ctx, `{"customer":{"id":"1"}}`))
+		assert.True(t, skip(t, filter, ctx, `{"customer":{"id":"2"}}`))
+	})
+
+	t.Run("claim", func(t *testing.T) {
+		filter := &SubscriptionFieldFilter{
+			FieldPath: []string{"orderUpdated", "customer", "id"},
+			Claim:     SubscriptionFilterClaimSubject,
+		}
+		event := `{"orderUpdated":{"customer":{"id":"jane"}}}`
+
+		assert.False(t, skip(t, filter, (&Context{}).WithClaims(&Claims{Subject: "jane"}), event))
+		assert.True(t, skip(t, filter, (&Context{}).WithClaims(&Claims{Subject: "john"}), event))
+		assert.True(t, skip(t, filter, &Context{}, event))
+	})
+}
+
+func TestResolver_SubscriptionFilter(t *testing.T) {
+	c, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resolver := newResolver(c)
+	started := make(chan struct{})
+	stream := createFakeStream(func(counter int) (message string, done bool) {
+		<-started
+		return fmt.Sprintf(`{"data":{"counter":%d}}`, counter), counter == 5
+	}, 0, nil)
+
+	subscribe := func(id int64, filter SubscriptionFilter) *SubscriptionRecorder {
+		plan := &GraphQLSubscription{
+			Trigger: GraphQLSubscriptionTrigger{
+				Source: stream,
+				InputTemplate: InputTemplate{
+					Segments: []TemplateSegment{
+						{SegmentType: StaticSegmentType, Data: []byte(`{}`)},
+					},
+				},
+				PostProcessing: PostProcessingConfiguration{
+					SelectResponseDataPath: []string{"data"},
+				},
+				Filters: []SubscriptionFilter{filter},
+			},
+			Response: &GraphQLResponse{
+				Data: &Object{
+					Fields: []*Field{
+						{Name: []byte("counter"), Value: &Integer{Path: []string{"counter"}}},
+					},
+				},
+			},
+		}
+		recorder := &SubscriptionRecorder{buf: &bytes.Buffer{}}
+		err := resolver.AsyncResolveGraphQLSubscription(&Context{}, plan, recorder, SubscriptionIdentifier{ConnectionID: id, SubscriptionID: id})
+		require.NoError(t, err)
+		return recorder
+	}
+
+	parity := func(remainder int64) SubscriptionFilter {
+		return SubscriptionFilterFunc(func(ctx *Context, data []byte) (bool, error) {
+			counter, err := jsonparser.GetInt(data, "data", "counter")
+			return counter%2 != remainder, err
+		})
+	}
+
+	// both subscriptions share the trigger of the stream
+	even := subscribe(1, parity(0))
+	odd := subscribe(2, parity(1))
+	close(started)
+
+	even.AwaitComplete(t, time.Second)
+	odd.AwaitComplete(t, time.Second)
+	assert.Equal(t, []string{`{"data":{"counter":0}}`, `{"data":{"counter":2}}`, `{"data":{"counter":4}}`}, even.Messages())
+	assert.Equal(t, []string{`{"data":{"counter":1}}`, `{"data":{"counter":3}}`, `{"data":{"counter":5}}`}, odd.Messages())
+}
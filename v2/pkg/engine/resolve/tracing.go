@@ -0,0 +1,94 @@
+package resolve
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the OpenTelemetry tracer creating the spans of the resolver
+const TracerName = "github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+
+const (
+	// AttributeDataSourceID is the id of the data source of a fetch span
+	AttributeDataSourceID = attribute.Key("graphql.datasource.id")
+	// AttributeFetchKind is the kind of fetch of a fetch span, e.g. "single" or "entity_batch"
+	AttributeFetchKind = attribute.Key("graphql.fetch.kind")
+	// AttributeFetchStatus is "ok" or "error" depending on the outcome of a fetch
+	AttributeFetchStatus = attribute.Key("graphql.fetch.status")
+	// AttributeSubscriptionID is the id of the subscription of a subscription update event
+	AttributeSubscriptionID = attribute.Key("graphql.subscription.id")
+
+	fetchStatusOK    = "ok"
+	fetchStatusError = "error"
+
+	fetchSpanName               = "Fetch"
+	subscriptionUpdateEventName = "subscription update"
+)
+
+func (k FetchKind) String() string {
+	switch k {
+	case FetchKindSingle:
+		return "single"
+	case FetchKindParallel:
+		return "parallel"
+	case FetchKindSerial:
+		return "serial"
+	case FetchKindParallelListItem:
+		return "parallel_list_item"
+	case FetchKindEntity:
+		return "entity"
+	case FetchKindEntityBatch:
+		return "entity_batch"
+	case FetchKindMulti:
+		return "multi"
+	default:
+		return "unknown"
+	}
+}
+
+// newTracer returns the tracer of the provider, the global provider is used if provider is nil
+func newTracer(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(TracerName)
+}
+
+func (l *Loader) startFetchSpan(ctx context.Context, kind FetchKind, info *FetchInfo) (context.Context, trace.Span) {
+	tracer := l.tracer
+	if tracer == nil {
+		tracer = newTracer(nil)
+	}
+	attributes := []attribute.KeyValue{AttributeFetchKind.String(kind.String())}
+	if info != nil {
+		attributes = append(attributes, AttributeDataSourceID.String(info.DataSourceID))
+	}
+	return tracer.Start(ctx, fetchSpanName, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+}
+
+func endFetchSpan(span trace.Span, err error) {
+	if err != nil {
+		span.SetAttributes(AttributeFetchStatus.String(fetchStatusError))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetAttributes(AttributeFetchStatus.String(fetchStatusOK))
+	}
+	span.End()
+}
+
+// recordSubscriptionUpdate adds an event to the span of the subscriber, it's a noop if the subscriber context has no recording span
+func recordSubscriptionUpdate(ctx *Context, id SubscriptionIdentifier) {
+	if ctx.ctx == nil {
+		return
+	}
+	span := trace.SpanFromContext(ctx.ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.AddEvent(subscriptionUpdateEventName, trace.WithAttributes(AttributeSubscriptionID.Int64(id.SubscriptionID)))
+}
@@ -0,0 +1,169 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestResolver_Tracing(t *testing.T) {
+	newTracing := func(t *testing.T) (*tracetest.InMemoryExporter, *sdktrace.TracerProvider) {
+		exporter := tracetest.NewInMemoryExporter()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+		t.Cleanup(func() {
+			_ = provider.Shutdown(context.Background())
+		})
+		return exporter, provider
+	}
+
+	attributes := func(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+		values := make(map[attribute.Key]attribute.Value, len(span.Attributes))
+		for _, kv := range span.Attributes {
+			values[kv.Key] = kv.Value
+		}
+		return values
+	}
+
+	response := func(dataSource DataSource) *GraphQLResponse {
+		return &GraphQLResponse{
+			Data: &Object{
+				Fetch: &SingleFetch{
+					FetchConfiguration: FetchConfiguration{
+						DataSource: dataSource,
+						PostProcessing: PostProcessingConfiguration{
+							SelectResponseDataPath: []string{"data"},
+						},
+					},
+					Info: &FetchInfo{
+						DataSourceID: "products",
+					},
+				},
+				Fields: []*Field{
+					{
+						Name: []byte("name"),
+						Value: &String{
+							Path:     []string{"name"},
+							Nullable: true,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	resolve := func(t *testing.T, provider *sdktrace.TracerProvider, dataSource DataSource) {
+		t.Helper()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		resolver := New(ctx, ResolverOptions{
+			MaxConcurrency: 1024,
+			TracerProvider: provider,
+		})
+
+		parentCtx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+		defer parent.End()
+
+		buf := &bytes.Buffer{}
+		err := resolver.ResolveGraphQLResponse(&Context{ctx: parentCtx}, response(dataSource), nil, buf)
+		require.NoError(t, err)
+	}
+
+	t.Run("fetch span", func(t *testing.T) {
+		exporter, provider := newTracing(t)
+		resolve(t, provider, FakeDataSource(`{"data":{"name":"Table"}}`))
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		fetch, parent := spans[0], spans[1]
+		assert.Equal(t, "Fetch", fetch.Name)
+		assert.Equal(t, parent.SpanContext.SpanID(), fetch.Parent.SpanID())
+		assert.Equal(t, map[attribute.Key]attribute.Value{
+			AttributeDataSourceID: attribute.StringValue("products"),
+			AttributeFetchKind:    attribute.StringValue("single"),
+			AttributeFetchStatus:  attribute.StringValue("ok"),
+		}, attributes(fetch))
+		assert.Equal(t, codes.Unset, fetch.Status.Code)
+	})
+
+	t.Run("failed fetch", func(t *testing.T) {
+		exporter, provider := newTracing(t)
+		resolve(t, provider, failingDataSource{})
+
+		fetch := exporter.GetSpans()[0]
+		assert.Equal(t, attribute.StringValue("error"), attributes(fetch)[AttributeFetchStatus])
+		assert.Equal(t, codes.Error, fetch.Status.Code)
+		assert.Equal(t, "connection refused", fetch.Status.Description)
+		require.Len(t, fetch.Events, 1)
+		assert.Equal(t, "exception", fetch.Events[0].Name)
+	})
+
+	t.Run("subscription updates", func(t *testing.T) {
+		exporter, provider := newTracing(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		resolver := New(ctx, ResolverOptions{
+			MaxConcurrency: 1024,
+			TracerProvider: provider,
+		})
+
+		fakeStream := createFakeStream(func(counter int) (message string, done bool) {
+			return fmt.Sprintf(`{"data":{"counter":%d}}`, counter), counter == 1
+		}, 0, nil)
+		subscription := &GraphQLSubscription{
+			Trigger: GraphQLSubscriptionTrigger{
+				Source: fakeStream,
+				InputTemplate: InputTemplate{
+					Segments: []TemplateSegment{
+						{
+							SegmentType: StaticSegmentType,
+							Data:        []byte(`{"method":"POST","url":"http://localhost:4000","body":{"query":"subscription { counter }"}}`),
+						},
+					},
+				},
+				PostProcessing: PostProcessingConfiguration{
+					SelectResponseDataPath: []string{"data"},
+				},
+			},
+			Response: &GraphQLResponse{
+				Data: &Object{
+					Fields: []*Field{
+						{
+							Name: []byte("counter"),
+							Value: &Integer{
+								Path: []string{"counter"},
+							},
+						},
+					},
+				},
+			},
+		}
+		recorder := &SubscriptionRecorder{
+			buf:      &bytes.Buffer{},
+			messages: []string{},
+		}
+
+		parentCtx, parent := provider.Tracer("test").Start(context.Background(), "subscription")
+		err := resolver.AsyncResolveGraphQLSubscription(&Context{ctx: parentCtx}, subscription, recorder, SubscriptionIdentifier{ConnectionID: 1, SubscriptionID: 7})
+		require.NoError(t, err)
+		recorder.AwaitComplete(t, 10*time.Second)
+		parent.End()
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		require.Len(t, spans[0].Events, 2)
+		for _, event := range spans[0].Events {
+			assert.Equal(t, "subscription update", event.Name)
+			assert.Equal(t, []attribute.KeyValue{AttributeSubscriptionID.Int64(7)}, event.Attributes)
+		}
+	})
+}
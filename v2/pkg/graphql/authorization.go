@@ -0,0 +1,241 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+)
+
+// AuthorizationDirectivesSDL contains the definitions of the @authenticated and @requiresScopes directives.
+// They have to be part of the schema to use the DirectiveAuthorizer.
+const AuthorizationDirectivesSDL = `scalar federation__Scope
+directive @authenticated on FIELD_DEFINITION | OBJECT | INTERFACE | SCALAR | ENUM
+directive @requiresScopes(scopes: [[federation__Scope!]!]!) on FIELD_DEFINITION | OBJECT | INTERFACE | SCALAR | ENUM`
+
+const (
+	authorizationDenyReasonUnauthenticated = "not authenticated"
+	authorizationDenyReasonMissingScopes   = "missing required scopes"
+)
+
+var (
+	authenticatedDirectiveName  = []byte("authenticated")
+	requiresScopesDirectiveName = []byte("requiresScopes")
+	requiresScopesArgumentName  = []byte("scopes")
+)
+
+// authorizationRule are the requirements of a field derived from the directives of the field,
+// its enclosing type, its return type and the same field on implemented interfaces
+type authorizationRule struct {
+	authenticated bool
+	// scopes contains the alternatives of every @requiresScopes directive, one alternative of each directive has to be granted
+	scopes [][][]string
+}
+
+func (a *authorizationRule) hasRequirements() bool {
+	return a.authenticated || len(a.scopes) > 0
+}
+
+func (a *authorizationRule) evaluate(claims *resolve.Claims) *resolve.AuthorizationDeny {
+	if !a.hasRequirements() {
+		return nil
+	}
+	if claims == nil {
+		return &resolve.AuthorizationDeny{Reason: authorizationDenyReasonUnauthenticated}
+	}
+	for _, alternatives := range a.scopes {
+		granted := slices.ContainsFunc(alternatives, func(scopes []string) bool {
+			for _, scope := range scopes {
+				if !slices.Contains(claims.Scopes, scope) {
+					return false
+				}
+			}
+			return true
+		})
+		if !granted {
+			return &resolve.AuthorizationDeny{Reason: authorizationDenyReasonMissingScopes}
+		}
+	}
+	return nil
+}
+
+var _ resolve.Authorizer = (*DirectiveAuthorizer)(nil)
+
+// DirectiveAuthorizer is a resolve.Authorizer evaluating the @authenticated and @requiresScopes directives of the schema
+// against the claims of the resolve.Context. Unauthorized fields are resolved as null with an error.
+// Unauthorized mutation fields are rejected before they are sent to the subgraph.
+type DirectiveAuthorizer struct {
+	definition       *ast.Document
+	rules            map[string]map[string]*authorizationRule
+	mutationTypeName string
+}
+
+// NewDirectiveAuthorizer creates an authorizer for the authorization directives of the schema.
+func NewDirectiveAuthorizer(schema *Schema) *DirectiveAuthorizer {
+	return &DirectiveAuthorizer{
+		definition:       &schema.document,
+		rules:            authorizationRules(&schema.document),
+		mutationTypeName: schema.MutationTypeName(),
+	}
+}
+
+// HasAuthorizationRule returns true if the field has authorization requirements.
+func (d *DirectiveAuthorizer) HasAuthorizationRule(typeName, fieldName string) bool {
+	_, ok := d.rules[typeName][fieldName]
+	return ok
+}
+
+// FieldConfigurations sets HasAuthorizationRule on all fields with authorization requirements,
+// configurations for fields without configuration are added.
+// The field configurations of an engine using the authorizer have to be passed through it, e.g. the generated ones.
+func (d *DirectiveAuthorizer) FieldConfigurations(fieldConfigs plan.FieldConfigurations) plan.FieldConfigurations {
+	return markAuthorizationRules(fieldConfigs, d.definition, d.rules)
+}
+
+func (d *DirectiveAuthorizer) AuthorizePreFetch(ctx *resolve.Context, dataSourceID string, input json.RawMessage, coordinate resolve.GraphCoordinate) (result *resolve.AuthorizationDeny, err error) {
+	// queries are authorized per field to return partial results, mutations must not be sent to the subgraph at all
+	if coordinate.TypeName != d.mutationTypeName {
+		return nil, nil
+	}
+	return d.authorize(ctx, coordinate), nil
+}
+
+func (d *DirectiveAuthorizer) AuthorizeObjectField(ctx *resolve.Context, dataSourceID string, object json.RawMessage, coordinate resolve.GraphCoordinate) (result *resolve.AuthorizationDeny, err error) {
+	return d.authorize(ctx, coordinate), nil
+}
+
+func (d *DirectiveAuthorizer) authorize(ctx *resolve.Context, coordinate resolve.GraphCoordinate) *resolve.AuthorizationDeny {
+	rule, ok := d.rules[coordinate.TypeName][coordinate.FieldName]
+	if !ok {
+		return nil
+	}
+	return rule.evaluate(ctx.Claims())
+}
+
+func markAuthorizationRules(fieldConfigs plan.FieldConfigurations, definition *ast.Document, rules map[string]map[string]*authorizationRule) plan.FieldConfigurations {
+	for _, coordinate := range authorizationRuleCoordinates(definition, rules) {
+		if fieldConfig := fieldConfigs.ForTypeField(coordinate.TypeName, coordinate.FieldName); fieldConfig != nil {
+			fieldConfig.HasAuthorizationRule = true
+			continue
+		}
+		fieldConfigs = append(fieldConfigs, plan.FieldConfiguration{
+			TypeName:             coordinate.TypeName,
+			FieldName:            coordinate.FieldName,
+			HasAuthorizationRule: true,
+		})
+	}
+	return fieldConfigs
+}
+
+// authorizationRuleCoordinates returns the sorted coordinates of all fields which have to be authorized.
+// Interface fields are included if the field of an implementing type has requirements,
+// because the planner marks fields by their enclosing type while the resolver authorizes the concrete type.
+func authorizationRuleCoordinates(definition *ast.Document, rules map[string]map[string]*authorizationRule) []resolve.GraphCoordinate {
+	marked := make(map[resolve.GraphCoordinate]struct{})
+	for typeName, fields := range rules {
+		for fieldName := range fields {
+			marked[resolve.GraphCoordinate{TypeName: typeName, FieldName: fieldName}] = struct{}{}
+		}
+		node, ok := definition.Index.FirstNodeByNameStr(typeName)
+		if !ok || node.Kind != ast.NodeKindObjectTypeDefinition {
+			continue
+		}
+		for _, interfaceTypeRef := range definition.ObjectTypeDefinitions[node.Ref].ImplementsInterfaces.Refs {
+			interfaceNode, ok := definition.Index.FirstNodeByNameBytes(definition.TypeNameBytes(interfaceTypeRef))
+			if !ok || interfaceNode.Kind != ast.NodeKindInterfaceTypeDefinition {
+				continue
+			}
+			interfaceTypeName := definition.InterfaceTypeDefinitionNameString(interfaceNode.Ref)
+			for fieldName := range fields {
+				if _, ok := definition.NodeFieldDefinitionByName(interfaceNode, []byte(fieldName)); ok {
+					marked[resolve.GraphCoordinate{TypeName: interfaceTypeName, FieldName: fieldName}] = struct{}{}
+				}
+			}
+		}
+	}
+
+	coordinates := make([]resolve.GraphCoordinate, 0, len(marked))
+	for coordinate := range marked {
+		coordinates = append(coordinates, coordinate)
+	}
+	slices.SortFunc(coordinates, func(a, b resolve.GraphCoordinate) int {
+		if a.TypeName != b.TypeName {
+			return strings.Compare(a.TypeName, b.TypeName)
+		}
+		return strings.Compare(a.FieldName, b.FieldName)
+	})
+	return coordinates
+}
+
+// authorizationRules collects the requirements of all fields of object and interface types, fields without requirements are omitted
+func authorizationRules(definition *ast.Document) map[string]map[string]*authorizationRule {
+	rules := make(map[string]map[string]*authorizationRule)
+	collect := func(typeNode ast.Node, typeName string, interfaces []int) {
+		for _, fieldDefinition := range definition.NodeFieldDefinitions(typeNode) {
+			rule := &authorizationRule{}
+			addAuthorizationRequirements(definition, definition.FieldDefinitions[fieldDefinition].Directives.Refs, rule)
+			addAuthorizationRequirements(definition, definition.NodeDirectives(typeNode), rule)
+			addAuthorizationRequirements(definition, definition.NodeDirectives(definition.FieldDefinitionTypeNode(fieldDefinition)), rule)
+			fieldName := definition.FieldDefinitionNameBytes(fieldDefinition)
+			for _, interfaceTypeRef := range interfaces {
+				interfaceNode, ok := definition.Index.FirstNodeByNameBytes(definition.TypeNameBytes(interfaceTypeRef))
+				if !ok || interfaceNode.Kind != ast.NodeKindInterfaceTypeDefinition {
+					continue
+				}
+				addAuthorizationRequirements(definition, definition.NodeDirectives(interfaceNode), rule)
+				if interfaceField, ok := definition.InterfaceTypeDefinitionFieldWithName(interfaceNode.Ref, fieldName); ok {
+					addAuthorizationRequirements(definition, definition.FieldDefinitions[interfaceField].Directives.Refs, rule)
+				}
+			}
+			if !rule.hasRequirements() {
+				continue
+			}
+			if rules[typeName] == nil {
+				rules[typeName] = make(map[string]*authorizationRule)
+			}
+			rules[typeName][string(fieldName)] = rule
+		}
+	}
+
+	for i := range definition.ObjectTypeDefinitions {
+		collect(ast.Node{Kind: ast.NodeKindObjectTypeDefinition, Ref: i}, definition.ObjectTypeDefinitionNameString(i), definition.ObjectTypeDefinitions[i].ImplementsInterfaces.Refs)
+	}
+	for i := range definition.InterfaceTypeDefinitions {
+		collect(ast.Node{Kind: ast.NodeKindInterfaceTypeDefinition, Ref: i}, definition.InterfaceTypeDefinitionNameString(i), definition.InterfaceTypeDefinitions[i].ImplementsInterfaces.Refs)
+	}
+	return rules
+}
+
+func addAuthorizationRequirements(definition *ast.Document, directives []int, rule *authorizationRule) {
+	for _, directive := range directives {
+		directiveName := definition.DirectiveNameBytes(directive)
+		switch {
+		case bytes.Equal(directiveName, authenticatedDirectiveName):
+			rule.authenticated = true
+		case bytes.Equal(directiveName, requiresScopesDirectiveName):
+			value, ok := definition.DirectiveArgumentValueByName(directive, requiresScopesArgumentName)
+			if !ok || value.Kind != ast.ValueKindList {
+				continue
+			}
+			var alternatives [][]string
+			for _, alternativeRef := range definition.ListValues[value.Ref].Refs {
+				alternative := definition.Values[alternativeRef]
+				if alternative.Kind != ast.ValueKindList {
+					continue
+				}
+				scopes := make([]string, 0, len(definition.ListValues[alternative.Ref].Refs))
+				for _, scopeRef := range definition.ListValues[alternative.Ref].Refs {
+					if scope := definition.Values[scopeRef]; scope.Kind == ast.ValueKindString {
+						scopes = append(scopes, definition.StringValueContentString(scope.Ref))
+					}
+				}
+				alternatives = append(alternatives, scopes)
+			}
+			rule.scopes = append(rule.scopes, alternatives)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/jensneuse/abstractlogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/graphql_datasource"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+)
+
+const authorizationTestSchema = AuthorizationDirectivesSDL + `
+schema { query: Query mutation: Mutation }
+type Query {
+	products: [Product]
+	me: User
+	node: Node
+}
+type Mutation {
+	deleteProduct(id: ID!): Boolean @requiresScopes(scopes: [["write:products"], ["admin"]])
+}
+type Product {
+	name: String
+	price: Int @requiresScopes(scopes: [["read:prices"]])
+}
+type User @authenticated {
+	name: String
+}
+interface Node {
+	id: ID!
+}
+type Secret implements Node @requiresScopes(scopes: [["read:secrets", "admin"]]) {
+	id: ID!
+	code: String
+}
+`
+
+func TestDirectiveAuthorizer(t *testing.T) {
+	schema, err := NewSchemaFromString(authorizationTestSchema)
+	require.NoError(t, err)
+	authorizer := NewDirectiveAuthorizer(schema)
+
+	t.Run("rules", func(t *testing.T) {
+		assert.True(t, authorizer.HasAuthorizationRule("Mutation", "deleteProduct"))
+		assert.True(t, authorizer.HasAuthorizationRule("Product", "price"))
+		assert.False(t, authorizer.HasAuthorizationRule("Product", "name"))
+		assert.True(t, authorizer.HasAuthorizationRule("Query", "me"))
+		assert.True(t, authorizer.HasAuthorizationRule("User", "name"))
+		assert.True(t, authorizer.HasAuthorizationRule("Secret", "id"))
+		assert.False(t, authorizer.HasAuthorizationRule("Node", "id"))
+		assert.False(t, authorizer.HasAuthorizationRule("Query", "products"))
+	})
+
+	t.Run("evaluate", func(t *testing.T) {
+		authorize := func(claims *resolve.Claims, typeName, fieldName string) *resolve.AuthorizationDeny {
+			ctx := resolve.NewContext(context.Background()).WithClaims(claims)
+			deny, err := authorizer.AuthorizeObjectField(ctx, "", nil, resolve.GraphCoordinate{TypeName: typeName, FieldName: fieldName})
+			require.NoError(t, err)
+			return deny
+		}
+
+		assert.Nil(t, authorize(nil, "Product", "name"))
+		assert.Equal(t, &resolve.AuthorizationDeny{Reason: "not authenticated"}, authorize(nil, "User", "name"))
+		assert.Nil(t, authorize(&resolve.Claims{Subject: "jane"}, "User", "name"))
+		assert.Equal(t, &resolve.AuthorizationDeny{Reason: "missing required scopes"}, authorize(&resolve.Claims{Scopes: []string{"read:secrets"}}, "Secret", "code"))
+		assert.Nil(t, authorize(&resolve.Claims{Scopes: []string{"admin", "read:secrets"}}, "Secret", "code"))
+		assert.Nil(t, authorize(&resolve.Claims{Scopes: []string{"admin"}}, "Mutation", "deleteProduct"))
+	})
+
+	t.Run("field configurations", func(t *testing.T) {
+		fieldConfigs := authorizer.FieldConfigurations(newGraphQLFieldConfigsV2Generator(schema).Generate())
+		for _, coordinate := range [][2]string{{"Mutation", "deleteProduct"}, {"Product", "price"}, {"Query", "me"}, {"Secret", "code"}, {"Node", "id"}} {
+			fieldConfig := fieldConfigs.ForTypeField(coordinate[0], coordinate[1])
+			require.NotNil(t, fieldConfig, coordinate)
+			assert.True(t, fieldConfig.HasAuthorizationRule, coordinate)
+		}
+		assert.Len(t, fieldConfigs.ForTypeField("Mutation", "deleteProduct").Arguments, 1)
+		assert.Nil(t, fieldConfigs.ForTypeField("Product", "name"))
+	})
+}
+
+func TestExecutionEngineV2_DirectiveAuthorizer(t *testing.T) {
+	schema, err := NewSchemaFromString(authorizationTestSchema)
+	require.NoError(t, err)
+
+	newEngine := func(t *testing.T, calls *atomic.Int64, response string) *ExecutionEngineV2 {
+		engineConf := NewEngineV2Configuration(schema)
+		authorizer := NewDirectiveAuthorizer(schema)
+		engineConf.SetAuthorizer(authorizer)
+		engineConf.SetFieldConfigurations(authorizer.FieldConfigurations(newGraphQLFieldConfigsV2Generator(schema).Generate()))
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				ID: "products",
+				RootNodes: []plan.TypeField{
+					{TypeName: "Query", FieldNames: []string{"products", "me", "node"}},
+					{TypeName: "Mutation", FieldNames: []string{"deleteProduct"}},
+				},
+				ChildNodes: []plan.TypeField{
+					{TypeName: "Product", FieldNames: []string{"name", "price"}},
+					{TypeName: "User", FieldNames: []string{"name"}},
+					{TypeName: "Node", FieldNames: []string{"id"}},
+					{TypeName: "Secret", FieldNames: []string{"id", "code"}},
+				},
+				Factory: &graphql_datasource.Factory{
+					HTTPClient: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							calls.Inc()
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Body:       io.NopCloser(bytes.NewBufferString(response)),
+							}
+						}),
+					},
+				},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch: graphql_datasource.FetchConfiguration{
+						URL:    "https://example.com/",
+						Method: "POST",
+					},
+					UpstreamSchema: authorizationTestSchema,
+				}),
+			},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		engine, err := NewExecutionEngineV2(ctx, abstractlogger.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
+
+	execute := func(t *testing.T, engine *ExecutionEngineV2, query string, claims *resolve.Claims) string {
+		t.Helper()
+		writer := NewEngineResultWriter()
+		require.NoError(t, engine.Execute(context.Background(), &Request{Query: query}, &writer, WithClaims(claims)))
+		return writer.String()
+	}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		calls := atomic.NewInt64(0)
+		engine := newEngine(t, calls, `{"data":{"me":{"name":"Jane"}}}`)
+
+		assert.Equal(t,
+			`{"errors":[{"message":"Unauthorized to load field 'Query.me'. Reason: not authenticated","path":["me"]}],"data":{"me":null}}`,
+			execute(t, engine, `{ me { name } }`, nil),
+		)
+		assert.Equal(t,
+			`{"data":{"me":{"name":"Jane"}}}`,
+			execute(t, engine, `{ me { name } }`, &resolve.Claims{Subject: "jane"}),
+		)
+	})
+
+	t.Run("missing scopes return partial results", func(t *testing.T) {
+		calls := atomic.NewInt64(0)
+		engine := newEngine(t, calls, `{"data":{"products":[{"name":"Table","price":100}]}}`)
+
+		assert.Equal(t,
+			`{"errors":[{"message":"Unauthorized to load field 'Query.products.price'. Reason: missing required scopes","path":["products",0,"price"]}],"data":{"products":[{"name":"Table","price":null}]}}`,
+			execute(t, engine, `{ products { name price } }`, &resolve.Claims{Subject: "jane"}),
+		)
+		assert.Equal(t,
+			`{"data":{"products":[{"name":"Table","price":100}]}}`,
+			execute(t, engine, `{ products { name price } }`, &resolve.Claims{Subject: "jane", Scopes: []string{"read:prices"}}),
+		)
+	})
+
+	t.Run("type directives apply to interface fields", func(t *testing.T) {
+		calls := atomic.NewInt64(0)
+		engine := newEngine(t, calls, `{"data":{"node":{"__typename":"Secret","id":"1","code":"1234"}}}`)
+
+		assert.Equal(t,
+			`{"errors":[{"message":"Unauthorized to load field 'Query.node.id'. Reason: missing required scopes","path":["node","id"]}],"data":{"node":null}}`,
+			execute(t, engine, `{ node { id } }`, &resolve.Claims{Scopes: []string{"read:secrets"}}),
+		)
+		assert.Equal(t,
+			`{"data":{"node":{"id":"1","code":"1234"}}}`,
+			execute(t, engine, `{ node { id ... on Secret { code } } }`, &resolve.Claims{Scopes: []string{"read:secrets", "admin"}}),
+		)
+	})
+
+	t.Run("unauthorized mutations are not sent to the subgraph", func(t *testing.T) {
+		calls := atomic.NewInt64(0)
+		engine := newEngine(t, calls, `{"data":{"deleteProduct":true}}`)
+
+		assert.Equal(t,
+			`{"errors":[{"message":"Unauthorized request to Subgraph 'products' at path 'mutation'. Reason: missing required scopes"}],"data":null}`,
+			execute(t, engine, `mutation { deleteProduct(id: "1") }`, &resolve.Claims{Scopes: []string{"write:prices"}}),
+		)
+		assert.Equal(t, int64(0), calls.Load())
+
+		assert.Equal(t,
+			`{"data":{"deleteProduct":true}}`,
+			execute(t, engine, `mutation { deleteProduct(id: "1") }`, &resolve.Claims{Scopes: []string{"admin"}}),
+		)
+		assert.Equal(t, int64(1), calls.Load())
+	})
+}
@@ -0,0 +1,263 @@
+package graphql
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astvisitor"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/operationreport"
+)
+
+// CacheControlDirectiveSDL contains the definition of the @cacheControl directive.
+// It has to be part of the schema to use cache hints.
+const CacheControlDirectiveSDL = `enum CacheControlScope { PUBLIC PRIVATE }
+directive @cacheControl(maxAge: Int, scope: CacheControlScope, inheritMaxAge: Boolean) on FIELD_DEFINITION | OBJECT | INTERFACE | UNION`
+
+var (
+	cacheControlDirectiveName = []byte("cacheControl")
+	cacheControlMaxAge        = []byte("maxAge")
+	cacheControlScope         = []byte("scope")
+	cacheControlInheritMaxAge = []byte("inheritMaxAge")
+)
+
+type CacheControlScope int
+
+const (
+	CacheControlScopePublic CacheControlScope = iota
+	CacheControlScopePrivate
+)
+
+// CacheControlPolicy describes for how long and by whom a response can be cached.
+type CacheControlPolicy struct {
+	MaxAge time.Duration
+	Scope  CacheControlScope
+}
+
+// Restrict returns a policy which allows only what both policies allow,
+// the lower max-age wins and the scope is private if one of the scopes is private.
+func (c CacheControlPolicy) Restrict(other CacheControlPolicy) CacheControlPolicy {
+	if other.MaxAge < c.MaxAge {
+		c.MaxAge = other.MaxAge
+	}
+	if other.Scope == CacheControlScopePrivate {
+		c.Scope = CacheControlScopePrivate
+	}
+	return c
+}
+
+// Cacheable returns true if the response can be stored for at least a second.
+func (c CacheControlPolicy) Cacheable() bool {
+	return c.MaxAge >= time.Second
+}
+
+// HeaderValue returns the value of the Cache-Control header for the policy.
+func (c CacheControlPolicy) HeaderValue() string {
+	if !c.Cacheable() {
+		return "no-store"
+	}
+	value := "max-age=" + strconv.FormatInt(int64(c.MaxAge/time.Second), 10)
+	if c.Scope == CacheControlScopePrivate {
+		return value + ", private"
+	}
+	return value + ", public"
+}
+
+// parseCacheControlHeader reads the restrictions of a Cache-Control response header.
+// s-maxage takes precedence over max-age as the response cache is a shared cache.
+func parseCacheControlHeader(values []string) (maxAge time.Duration, hasMaxAge bool, private bool) {
+	var sharedMaxAge time.Duration
+	var hasSharedMaxAge bool
+	for _, value := range values {
+		for _, directive := range strings.Split(value, ",") {
+			name, argument, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			switch strings.ToLower(name) {
+			case "no-store", "no-cache":
+				return 0, true, private
+			case "private":
+				private = true
+			case "max-age", "s-maxage":
+				seconds, err := strconv.ParseInt(strings.Trim(argument, `"`), 10, 64)
+				if err != nil || seconds < 0 {
+					seconds = 0
+				}
+				if strings.EqualFold(name, "s-maxage") {
+					sharedMaxAge, hasSharedMaxAge = time.Duration(seconds)*time.Second, true
+				} else {
+					maxAge, hasMaxAge = time.Duration(seconds)*time.Second, true
+				}
+			}
+		}
+	}
+	if hasSharedMaxAge {
+		return sharedMaxAge, true, private
+	}
+	return maxAge, hasMaxAge, private
+}
+
+// cacheControlCollector restricts a policy by the Cache-Control headers of upstream responses.
+type cacheControlCollector struct {
+	mu     sync.Mutex
+	policy CacheControlPolicy
+}
+
+func newCacheControlCollector(policy CacheControlPolicy) *cacheControlCollector {
+	return &cacheControlCollector{
+		policy: policy,
+	}
+}
+
+func (c *cacheControlCollector) observe(header http.Header) {
+	values := header.Values("Cache-Control")
+	if len(values) == 0 {
+		return
+	}
+	maxAge, hasMaxAge, private := parseCacheControlHeader(values)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if hasMaxAge && maxAge < c.policy.MaxAge {
+		c.policy.MaxAge = maxAge
+	}
+	if private {
+		c.policy.Scope = CacheControlScopePrivate
+	}
+}
+
+func (c *cacheControlCollector) result() CacheControlPolicy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.policy
+}
+
+// cacheControlCalculator computes the policy of an operation from the @cacheControl hints of the schema.
+// Root fields and fields returning composite types without hint get the default max-age,
+// scalar fields without hint inherit the max-age of their parent field.
+// Fields with an authorization rule make the policy private, their values depend on the client.
+type cacheControlCalculator struct {
+	*astvisitor.Walker
+	operation, definition *ast.Document
+	fieldConfigs          plan.FieldConfigurations
+	operationName         []byte
+	defaultMaxAge         time.Duration
+	maxAges               []time.Duration
+	policy                CacheControlPolicy
+	restricted            bool
+}
+
+func calculateCacheControlPolicy(request *Request, schema *Schema, fieldConfigs plan.FieldConfigurations, defaultMaxAge time.Duration) (CacheControlPolicy, error) {
+	walker := astvisitor.NewWalker(48)
+	calculator := &cacheControlCalculator{
+		Walker:        &walker,
+		operation:     &request.document,
+		definition:    &schema.document,
+		fieldConfigs:  fieldConfigs,
+		operationName: []byte(request.OperationName),
+		defaultMaxAge: defaultMaxAge,
+	}
+	walker.RegisterEnterOperationVisitor(calculator)
+	walker.RegisterFieldVisitor(calculator)
+
+	report := operationreport.Report{}
+	walker.Walk(&request.document, &schema.document, &report)
+	if report.HasErrors() {
+		return CacheControlPolicy{}, report
+	}
+
+	if !calculator.restricted {
+		calculator.policy.MaxAge = defaultMaxAge
+	}
+	return calculator.policy, nil
+}
+
+func (c *cacheControlCalculator) EnterOperationDefinition(ref int) {
+	if len(c.operationName) > 0 && !bytes.Equal(c.operation.OperationDefinitionNameBytes(ref), c.operationName) {
+		c.SkipNode()
+	}
+}
+
+func (c *cacheControlCalculator) EnterField(ref int) {
+	isRoot := len(c.maxAges) == 0
+	parentMaxAge := c.defaultMaxAge
+	if !isRoot {
+		parentMaxAge = c.maxAges[len(c.maxAges)-1]
+	}
+
+	fieldDefinition, ok := c.FieldDefinition(ref)
+	if !ok {
+		// __typename doesn't restrict the policy
+		c.maxAges = append(c.maxAges, parentMaxAge)
+		return
+	}
+
+	fieldConfig := c.fieldConfigs.ForTypeField(c.definition.NodeNameString(c.EnclosingTypeDefinition), c.operation.FieldNameString(ref))
+	if fieldConfig != nil && fieldConfig.HasAuthorizationRule {
+		c.policy.Scope = CacheControlScopePrivate
+	}
+
+	typeNode := c.definition.FieldDefinitionTypeNode(fieldDefinition)
+	isComposite := typeNode.Kind == ast.NodeKindObjectTypeDefinition ||
+		typeNode.Kind == ast.NodeKindInterfaceTypeDefinition ||
+		typeNode.Kind == ast.NodeKindUnionTypeDefinition
+
+	var (
+		maxAge    time.Duration
+		hasMaxAge bool
+	)
+	if directive, exists := c.definition.FieldDefinitionDirectiveByName(fieldDefinition, cacheControlDirectiveName); exists {
+		var inheritMaxAge bool
+		maxAge, hasMaxAge, inheritMaxAge = c.applyHint(directive)
+		if inheritMaxAge && !isRoot {
+			maxAge, hasMaxAge = parentMaxAge, true
+		}
+	}
+	if isComposite {
+		for _, directive := range c.definition.NodeDirectives(typeNode) {
+			if !bytes.Equal(c.definition.DirectiveNameBytes(directive), cacheControlDirectiveName) {
+				continue
+			}
+			typeMaxAge, typeHasMaxAge, _ := c.applyHint(directive)
+			if !hasMaxAge && typeHasMaxAge {
+				maxAge, hasMaxAge = typeMaxAge, true
+			}
+		}
+	}
+	if !hasMaxAge {
+		if isRoot || isComposite {
+			maxAge = c.defaultMaxAge
+		} else {
+			maxAge = parentMaxAge
+		}
+	}
+
+	if !c.restricted || maxAge < c.policy.MaxAge {
+		c.policy.MaxAge = maxAge
+		c.restricted = true
+	}
+	c.maxAges = append(c.maxAges, maxAge)
+}
+
+func (c *cacheControlCalculator) LeaveField(ref int) {
+	c.maxAges = c.maxAges[:len(c.maxAges)-1]
+}
+
+// applyHint reads the arguments of a @cacheControl directive and applies its scope to the policy
+func (c *cacheControlCalculator) applyHint(directive int) (maxAge time.Duration, hasMaxAge bool, inheritMaxAge bool) {
+	if value, ok := c.definition.DirectiveArgumentValueByName(directive, cacheControlMaxAge); ok && value.Kind == ast.ValueKindInteger {
+		maxAge, hasMaxAge = time.Duration(c.definition.IntValueAsInt(value.Ref))*time.Second, true
+	}
+	if value, ok := c.definition.DirectiveArgumentValueByName(directive, cacheControlScope); ok && value.Kind == ast.ValueKindEnum {
+		if c.definition.EnumValueNameString(value.Ref) == "PRIVATE" {
+			c.policy.Scope = CacheControlScopePrivate
+		}
+	}
+	if value, ok := c.definition.DirectiveArgumentValueByName(directive, cacheControlInheritMaxAge); ok && value.Kind == ast.ValueKindBoolean {
+		inheritMaxAge = bool(c.definition.BooleanValue(value.Ref))
+	}
+	return maxAge, hasMaxAge, inheritMaxAge
+}
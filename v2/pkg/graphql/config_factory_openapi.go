@@ -0,0 +1,790 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	restDataSource "github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/rest_datasource"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
+)
+
+// OpenAPIJSONScalar is the scalar used for schemas without a GraphQL representation,
+// e.g. objects without properties, oneOf/anyOf schemas and responses without a JSON body
+const OpenAPIJSONScalar = "JSON"
+
+type openAPIEngineConfigFactoryOptions struct {
+	httpClient    *http.Client
+	baseURL       string
+	staticHeaders http.Header
+}
+
+type OpenAPIEngineConfigFactoryOption func(options *openAPIEngineConfigFactoryOptions)
+
+func WithOpenAPIHttpClient(client *http.Client) OpenAPIEngineConfigFactoryOption {
+	return func(options *openAPIEngineConfigFactoryOptions) {
+		options.httpClient = client
+	}
+}
+
+// WithOpenAPIBaseURL sets the URL the paths of the document are relative to, it overrides the servers of the document
+func WithOpenAPIBaseURL(baseURL string) OpenAPIEngineConfigFactoryOption {
+	return func(options *openAPIEngineConfigFactoryOptions) {
+		options.baseURL = baseURL
+	}
+}
+
+// WithOpenAPIStaticHeaders sets headers which are sent with every request, e.g. credentials of the API
+func WithOpenAPIStaticHeaders(header http.Header) OpenAPIEngineConfigFactoryOption {
+	return func(options *openAPIEngineConfigFactoryOptions) {
+		options.staticHeaders = header
+	}
+}
+
+// OpenAPIEngineConfigFactory is used to create a GraphQL schema and a v2 engine config from an OpenAPI 3 document.
+// Every operation of the document becomes a root field with its own REST data source:
+// GET operations are added to the Query type, all other operations to the Mutation type.
+// Fields are named after the operationId, or the method and path of operations without one.
+//
+// Path, query and header parameters become arguments of the field, a JSON request body becomes the "input" argument.
+// Component schemas become object types for responses and input types with an "Input" suffix for request bodies,
+// string enums become enums and schemas without a GraphQL representation become the JSON scalar.
+// Properties which aren't valid GraphQL names are camel cased, the request body is sent with the original names.
+type OpenAPIEngineConfigFactory struct {
+	httpClient    *http.Client
+	baseURL       string
+	staticHeaders http.Header
+	document      []byte
+}
+
+func NewOpenAPIEngineConfigFactory(document []byte, opts ...OpenAPIEngineConfigFactoryOption) *OpenAPIEngineConfigFactory {
+	options := openAPIEngineConfigFactoryOptions{
+		httpClient: &http.Client{
+			Timeout: time.Second * 10,
+			Transport: &http.Transport{
+				MaxIdleConnsPerHost: 1024,
+				TLSHandshakeTimeout: 0 * time.Second,
+			},
+		},
+	}
+
+	for _, optFunc := range opts {
+		optFunc(&options)
+	}
+
+	return &OpenAPIEngineConfigFactory{
+		httpClient:    options.httpClient,
+		baseURL:       options.baseURL,
+		staticHeaders: options.staticHeaders,
+		document:      document,
+	}
+}
+
+// SDL returns the GraphQL schema generated from the document
+func (o *OpenAPIEngineConfigFactory) SDL() (string, error) {
+	generator, err := o.generate()
+	if err != nil {
+		return "", err
+	}
+	return generator.sdl(), nil
+}
+
+func (o *OpenAPIEngineConfigFactory) EngineV2Configuration() (EngineV2Configuration, error) {
+	generator, err := o.generate()
+	if err != nil {
+		return EngineV2Configuration{}, err
+	}
+	schema, err := NewSchemaFromString(generator.sdl())
+	if err != nil {
+		return EngineV2Configuration{}, fmt.Errorf("openapi: invalid generated schema: %w", err)
+	}
+
+	conf := NewEngineV2Configuration(schema)
+	conf.SetDataSources(generator.dataSources)
+	conf.SetFieldConfigurations(generator.fieldConfigs)
+	return conf, nil
+}
+
+func (o *OpenAPIEngineConfigFactory) generate() (*openAPIGenerator, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(o.document)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	if err = doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+
+	generator := &openAPIGenerator{
+		doc:           doc,
+		baseURL:       o.baseURL,
+		staticHeaders: o.staticHeaders,
+		factory:       &restDataSource.Factory{Client: o.httpClient},
+		types:         map[string]*openAPIType{},
+		outputTypes:   map[*openapi3.Schema]string{},
+		inputTypes:    map[*openapi3.Schema]string{},
+		enumTypes:     map[*openapi3.Schema]string{},
+		rootFields:    map[string]bool{},
+	}
+	if err = generator.generate(); err != nil {
+		return nil, err
+	}
+	return generator, nil
+}
+
+const (
+	openAPITypeKindObject = "type"
+	openAPITypeKindInput  = "input"
+	openAPITypeKindEnum   = "enum"
+)
+
+type openAPIType struct {
+	kind        string
+	name        string
+	description string
+	fields      []openAPIField
+	values      []string
+}
+
+type openAPIField struct {
+	name        string
+	description string
+	// key is the name of the property of an input field which is named differently
+	key string
+	// typeRef is the GraphQL type of the field, e.g. "[Pet!]!"
+	typeRef   string
+	arguments []openAPIField
+}
+
+type openAPIGenerator struct {
+	doc           *openapi3.T
+	baseURL       string
+	staticHeaders http.Header
+	factory       plan.PlannerFactory
+
+	// types are the generated types by name, typeNames keeps their order
+	types       map[string]*openAPIType
+	typeNames   []string
+	outputTypes map[*openapi3.Schema]string
+	inputTypes  map[*openapi3.Schema]string
+	enumTypes   map[*openapi3.Schema]string
+	usesJSON    bool
+
+	queries    []openAPIField
+	mutations  []openAPIField
+	rootFields map[string]bool
+
+	dataSources  []plan.DataSourceConfiguration
+	fieldConfigs plan.FieldConfigurations
+}
+
+var openAPIMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+func (g *openAPIGenerator) generate() error {
+	paths := make([]string, 0, len(g.doc.Paths))
+	for path := range g.doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		item := g.doc.Paths[path]
+		for _, method := range openAPIMethods {
+			operation := item.GetOperation(method)
+			if operation == nil {
+				continue
+			}
+			if err := g.addOperation(path, method, item, operation); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(g.queries) == 0 {
+		return errors.New("openapi: the document has no GET operations to generate the Query type from")
+	}
+	return nil
+}
+
+func (g *openAPIGenerator) addOperation(path, method string, item *openapi3.PathItem, operation *openapi3.Operation) error {
+	rootTypeName := "Query"
+	if method != http.MethodGet {
+		rootTypeName = "Mutation"
+	}
+	fieldName := g.rootFieldName(rootTypeName, method, path, operation.OperationID)
+	typeNameHint := openAPIPascalCase(fieldName)
+
+	baseURL, err := g.serverURL(item, operation)
+	if err != nil {
+		return err
+	}
+	fetch := restDataSource.FetchConfiguration{
+		URL:    strings.TrimSuffix(baseURL, "/") + path,
+		Method: method,
+		Header: http.Header{},
+	}
+	for name, values := range g.staticHeaders {
+		fetch.Header[name] = append([]string(nil), values...)
+	}
+
+	field := openAPIField{
+		name:        fieldName,
+		description: openAPIDescription(operation.Summary, operation.Description),
+	}
+	fieldConfig := plan.FieldConfiguration{
+		TypeName:              rootTypeName,
+		FieldName:             fieldName,
+		DisableDefaultMapping: true,
+	}
+	argumentNames := map[string]bool{}
+
+	for _, parameterRef := range openAPIParameters(item, operation) {
+		parameter := parameterRef.Value
+		if parameter.In == openapi3.ParameterInCookie {
+			continue
+		}
+		argumentName := openAPIUniqueName(openAPICamelCase(parameter.Name), argumentNames)
+		typeRef := g.typeRef(parameter.Schema, typeNameHint+openAPIPascalCase(parameter.Name), true)
+		if parameter.Required || parameter.In == openapi3.ParameterInPath {
+			typeRef += "!"
+		}
+		field.arguments = append(field.arguments, openAPIField{
+			name:        argumentName,
+			description: parameter.Description,
+			typeRef:     typeRef,
+		})
+		fieldConfig.Arguments = append(fieldConfig.Arguments, plan.ArgumentConfiguration{
+			Name:       argumentName,
+			SourceType: plan.FieldArgumentSource,
+		})
+
+		template := fmt.Sprintf("{{ .arguments.%s }}", argumentName)
+		switch parameter.In {
+		case openapi3.ParameterInPath:
+			fetch.URL = strings.ReplaceAll(fetch.URL, "{"+parameter.Name+"}", template)
+		case openapi3.ParameterInQuery:
+			fetch.Query = append(fetch.Query, restDataSource.QueryConfiguration{Name: parameter.Name, Value: template})
+		case openapi3.ParameterInHeader:
+			fetch.Header.Add(parameter.Name, template)
+		}
+	}
+
+	if body := openAPIRequestBodySchema(operation); body != nil {
+		argumentName := openAPIUniqueName("input", argumentNames)
+		typeRef := g.typeRef(body, typeNameHint, true)
+		if operation.RequestBody.Value.Required {
+			typeRef += "!"
+		}
+		field.arguments = append(field.arguments, openAPIField{
+			name:        argumentName,
+			description: operation.RequestBody.Value.Description,
+			typeRef:     typeRef,
+		})
+		fieldConfig.Arguments = append(fieldConfig.Arguments, plan.ArgumentConfiguration{
+			Name:         argumentName,
+			SourceType:   plan.FieldArgumentSource,
+			RenderConfig: plan.RenderArgumentAsJSONValue,
+		})
+		fetch.Body = fmt.Sprintf("{{ .arguments.%s }}", argumentName)
+		fetch.Header.Set("Content-Type", "application/json")
+		if fetch.BodyKeys, err = g.bodyKeys(typeRef); err != nil {
+			return fmt.Errorf("openapi: request body of %s %s: %w", method, path, err)
+		}
+	}
+
+	field.typeRef = g.typeRef(openAPIResponseSchema(operation), typeNameHint+"Response", false)
+	// the REST data source merges list responses at the name of the field
+	fieldConfig.DisableDefaultMapping = !strings.HasPrefix(field.typeRef, "[")
+	if len(fetch.Header) == 0 {
+		fetch.Header = nil
+	}
+
+	if rootTypeName == "Query" {
+		g.queries = append(g.queries, field)
+	} else {
+		g.mutations = append(g.mutations, field)
+	}
+	g.fieldConfigs = append(g.fieldConfigs, fieldConfig)
+	g.dataSources = append(g.dataSources, plan.DataSourceConfiguration{
+		ID: rootTypeName + "." + fieldName,
+		RootNodes: []plan.TypeField{
+			{
+				TypeName:   rootTypeName,
+				FieldNames: []string{fieldName},
+			},
+		},
+		ChildNodes: g.childNodes(field.typeRef),
+		Factory:    g.factory,
+		Custom: restDataSource.ConfigJSON(restDataSource.Configuration{
+			Fetch: fetch,
+		}),
+	})
+	return nil
+}
+
+// serverURL returns the URL of the first server of the operation, its path or the document with the defaults of its variables
+func (g *openAPIGenerator) serverURL(item *openapi3.PathItem, operation *openapi3.Operation) (string, error) {
+	if g.baseURL != "" {
+		return g.baseURL, nil
+	}
+	servers := g.doc.Servers
+	if len(item.Servers) != 0 {
+		servers = item.Servers
+	}
+	if operation.Servers != nil && len(*operation.Servers) != 0 {
+		servers = *operation.Servers
+	}
+	if len(servers) == 0 {
+		return "", errors.New("openapi: the document has no servers, a base URL is required")
+	}
+	serverURL := servers[0].URL
+	for name, variable := range servers[0].Variables {
+		serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", variable.Default)
+	}
+	return serverURL, nil
+}
+
+func (g *openAPIGenerator) rootFieldName(rootTypeName, method, path, operationID string) string {
+	name := openAPICamelCase(operationID)
+	if name == "" {
+		// e.g. GET /pets/{petId} becomes getPetsByPetId
+		name = strings.ToLower(method)
+		for _, segment := range strings.Split(path, "/") {
+			if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+				name += "By" + openAPIPascalCase(strings.Trim(segment, "{}"))
+				continue
+			}
+			name += openAPIPascalCase(segment)
+		}
+	}
+	unique := openAPIUniqueName(rootTypeName+"."+name, g.rootFields)
+	return strings.TrimPrefix(unique, rootTypeName+".")
+}
+
+// typeRef returns the GraphQL type of a schema without the non-null modifier of the schema itself,
+// nameHint is used to name object types of schemas which aren't components
+func (g *openAPIGenerator) typeRef(schemaRef *openapi3.SchemaRef, nameHint string, input bool) string {
+	if schemaRef == nil || schemaRef.Value == nil {
+		return g.jsonScalar()
+	}
+	if schemaRef.Ref != "" {
+		nameHint = schemaRef.Ref[strings.LastIndex(schemaRef.Ref, "/")+1:]
+	}
+	schema := schemaRef.Value
+	if len(schema.OneOf) != 0 || len(schema.AnyOf) != 0 {
+		return g.jsonScalar()
+	}
+	if len(schema.AllOf) != 0 {
+		return g.objectType(schema, nameHint, input)
+	}
+
+	switch schema.Type {
+	case openapi3.TypeString:
+		if len(schema.Enum) != 0 {
+			return g.enumType(schema, nameHint)
+		}
+		return "String"
+	case openapi3.TypeInteger:
+		return "Int"
+	case openapi3.TypeNumber:
+		return "Float"
+	case openapi3.TypeBoolean:
+		return "Boolean"
+	case openapi3.TypeArray:
+		item := g.typeRef(schema.Items, nameHint+"Item", input)
+		if schema.Items != nil && schema.Items.Value != nil && !schema.Items.Value.Nullable {
+			item += "!"
+		}
+		return "[" + item + "]"
+	default:
+		if len(schema.Properties) == 0 {
+			return g.jsonScalar()
+		}
+		return g.objectType(schema, nameHint, input)
+	}
+}
+
+func (g *openAPIGenerator) jsonScalar() string {
+	g.usesJSON = true
+	return OpenAPIJSONScalar
+}
+
+func (g *openAPIGenerator) objectType(schema *openapi3.Schema, nameHint string, input bool) string {
+	cache, kind := g.outputTypes, openAPITypeKindObject
+	if input {
+		cache, kind = g.inputTypes, openAPITypeKindInput
+		nameHint += "Input"
+	}
+	if name, ok := cache[schema]; ok {
+		return name
+	}
+	objectType := &openAPIType{
+		kind:        kind,
+		name:        g.addTypeName(nameHint),
+		description: schema.Description,
+	}
+	// the type is cached before its fields are generated, so recursive schemas refer to it
+	cache[schema] = objectType.name
+	g.types[objectType.name] = objectType
+
+	properties, required := openAPIProperties(schema)
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fieldNames := map[string]bool{}
+	for _, name := range names {
+		fieldName := openAPIUniqueName(openAPIFieldName(name), fieldNames)
+		typeRef := g.typeRef(properties[name], openAPITypeName(objectType.name, input)+openAPIPascalCase(name), input)
+		if required[name] {
+			typeRef += "!"
+		}
+		var description string
+		if properties[name].Ref == "" && properties[name].Value != nil {
+			description = properties[name].Value.Description
+		}
+		field := openAPIField{
+			name:        fieldName,
+			description: description,
+			typeRef:     typeRef,
+		}
+		if fieldName != name && input {
+			field.key = name
+		}
+		objectType.fields = append(objectType.fields, field)
+		if fieldName != name && !input {
+			g.fieldConfigs = append(g.fieldConfigs, plan.FieldConfiguration{
+				TypeName:  objectType.name,
+				FieldName: fieldName,
+				Path:      []string{name},
+			})
+		}
+	}
+	return objectType.name
+}
+
+// bodyKeys returns the property names of the fields of the input type and its nested types which are named differently.
+// The keys are renamed regardless of their type, so a field name must refer to the same property in all types.
+func (g *openAPIGenerator) bodyKeys(typeRef string) (map[string]string, error) {
+	keys := map[string]string{}
+	if err := g.collectBodyKeys(typeRef, keys, map[string]bool{}); err != nil {
+		return nil, err
+	}
+	for fieldName, key := range keys {
+		if fieldName == key {
+			delete(keys, fieldName)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return keys, nil
+}
+
+func (g *openAPIGenerator) collectBodyKeys(typeRef string, keys map[string]string, visited map[string]bool) error {
+	name := strings.Trim(typeRef, "[]!")
+	inputType, ok := g.types[name]
+	if !ok || inputType.kind != openAPITypeKindInput || visited[name] {
+		return nil
+	}
+	visited[name] = true
+	for _, field := range inputType.fields {
+		key := field.key
+		if key == "" {
+			key = field.name
+		}
+		if existing, ok := keys[field.name]; ok && existing != key {
+			return fmt.Errorf("the field %s refers to the properties %q and %q", field.name, existing, key)
+		}
+		keys[field.name] = key
+		if err := g.collectBodyKeys(field.typeRef, keys, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openAPITypeName removes the Input suffix of input types to name their nested types
+func openAPITypeName(name string, input bool) string {
+	if input {
+		return strings.TrimSuffix(name, "Input")
+	}
+	return name
+}
+
+func (g *openAPIGenerator) enumType(schema *openapi3.Schema, nameHint string) string {
+	if name, ok := g.enumTypes[schema]; ok {
+		return name
+	}
+	values := make([]string, 0, len(schema.Enum))
+	for _, value := range schema.Enum {
+		name, ok := value.(string)
+		if !ok || !openAPIValidEnumValue(name) {
+			// enums with values which aren't GraphQL names are represented as strings
+			g.enumTypes[schema] = "String"
+			return "String"
+		}
+		values = append(values, name)
+	}
+	enumType := &openAPIType{
+		kind:        openAPITypeKindEnum,
+		name:        g.addTypeName(nameHint),
+		description: schema.Description,
+		values:      values,
+	}
+	g.enumTypes[schema] = enumType.name
+	g.types[enumType.name] = enumType
+	return enumType.name
+}
+
+// addTypeName returns an unused type name for the hint and reserves it
+func (g *openAPIGenerator) addTypeName(nameHint string) string {
+	name := openAPIPascalCase(nameHint)
+	if name == "" || !unicode.IsLetter(rune(name[0])) {
+		name = "Type" + name
+	}
+	switch name {
+	case "Query", "Mutation", "Subscription", OpenAPIJSONScalar, "String", "Int", "Float", "Boolean", "ID":
+		name += "Type"
+	}
+	base := name
+	for i := 2; g.types[name] != nil; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	g.typeNames = append(g.typeNames, name)
+	// the type is added by the caller, the placeholder reserves the name
+	g.types[name] = &openAPIType{}
+	return name
+}
+
+// childNodes returns the fields of all object types reachable from the type
+func (g *openAPIGenerator) childNodes(typeRef string) []plan.TypeField {
+	var childNodes []plan.TypeField
+	visited := map[string]bool{}
+	queue := []string{strings.Trim(typeRef, "[]!")}
+	for len(queue) != 0 {
+		name := queue[0]
+		queue = queue[1:]
+		objectType := g.types[name]
+		if visited[name] || objectType == nil || objectType.kind != openAPITypeKindObject {
+			continue
+		}
+		visited[name] = true
+		typeField := plan.TypeField{TypeName: name}
+		for _, field := range objectType.fields {
+			typeField.FieldNames = append(typeField.FieldNames, field.name)
+			queue = append(queue, strings.Trim(field.typeRef, "[]!"))
+		}
+		childNodes = append(childNodes, typeField)
+	}
+	return childNodes
+}
+
+func (g *openAPIGenerator) sdl() string {
+	sb := &strings.Builder{}
+	writeOpenAPIFields(sb, "type", "Query", "", g.queries)
+	if len(g.mutations) != 0 {
+		writeOpenAPIFields(sb, "type", "Mutation", "", g.mutations)
+	}
+	for _, name := range g.typeNames {
+		t := g.types[name]
+		if t.kind == openAPITypeKindEnum {
+			sb.WriteString("\n")
+			writeOpenAPIDescription(sb, "", t.description)
+			sb.WriteString("enum " + t.name + " {\n")
+			for _, value := range t.values {
+				sb.WriteString("  " + value + "\n")
+			}
+			sb.WriteString("}\n")
+			continue
+		}
+		writeOpenAPIFields(sb, t.kind, t.name, t.description, t.fields)
+	}
+	if g.usesJSON {
+		sb.WriteString("\nscalar " + OpenAPIJSONScalar + "\n")
+	}
+	return strings.TrimPrefix(sb.String(), "\n")
+}
+
+func writeOpenAPIFields(sb *strings.Builder, kind, name, description string, fields []openAPIField) {
+	sb.WriteString("\n")
+	writeOpenAPIDescription(sb, "", description)
+	sb.WriteString(kind + " " + name + " {\n")
+	for _, field := range fields {
+		writeOpenAPIDescription(sb, "  ", field.description)
+		sb.WriteString("  " + field.name)
+		if len(field.arguments) != 0 {
+			arguments := make([]string, 0, len(field.arguments))
+			for _, argument := range field.arguments {
+				arguments = append(arguments, argument.name+": "+argument.typeRef)
+			}
+			sb.WriteString("(" + strings.Join(arguments, ", ") + ")")
+		}
+		sb.WriteString(": " + field.typeRef + "\n")
+	}
+	sb.WriteString("}\n")
+}
+
+func writeOpenAPIDescription(sb *strings.Builder, indent, description string) {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return
+	}
+	sb.WriteString(indent + `"""` + strings.ReplaceAll(description, `"""`, `\"""`) + `"""` + "\n")
+}
+
+func openAPIDescription(summary, description string) string {
+	if summary != "" {
+		return summary
+	}
+	return description
+}
+
+// openAPIParameters returns the parameters of the operation, which override the parameters of the path with the same name and location
+func openAPIParameters(item *openapi3.PathItem, operation *openapi3.Operation) openapi3.Parameters {
+	parameters := make(openapi3.Parameters, 0, len(item.Parameters)+len(operation.Parameters))
+	for _, parameter := range item.Parameters {
+		if parameter.Value == nil || operation.Parameters.GetByInAndName(parameter.Value.In, parameter.Value.Name) != nil {
+			continue
+		}
+		parameters = append(parameters, parameter)
+	}
+	for _, parameter := range operation.Parameters {
+		if parameter.Value != nil {
+			parameters = append(parameters, parameter)
+		}
+	}
+	return parameters
+}
+
+func openAPIRequestBodySchema(operation *openapi3.Operation) *openapi3.SchemaRef {
+	if operation.RequestBody == nil || operation.RequestBody.Value == nil {
+		return nil
+	}
+	return openAPIJSONSchema(operation.RequestBody.Value.Content)
+}
+
+// openAPIResponseSchema returns the schema of the first successful JSON response, or the default response
+func openAPIResponseSchema(operation *openapi3.Operation) *openapi3.SchemaRef {
+	codes := make([]string, 0, len(operation.Responses))
+	for code := range operation.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	codes = append(codes, "default")
+	for _, code := range codes {
+		response := operation.Responses[code]
+		if response == nil || response.Value == nil {
+			continue
+		}
+		if schema := openAPIJSONSchema(response.Value.Content); schema != nil {
+			return schema
+		}
+	}
+	return nil
+}
+
+func openAPIJSONSchema(content openapi3.Content) *openapi3.SchemaRef {
+	if mediaType := content.Get("application/json"); mediaType != nil {
+		return mediaType.Schema
+	}
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		if strings.HasSuffix(mediaType, "+json") {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	if len(mediaTypes) == 0 {
+		return nil
+	}
+	sort.Strings(mediaTypes)
+	return content[mediaTypes[0]].Schema
+}
+
+// openAPIProperties returns the properties and required properties of an object schema, including the ones of allOf schemas
+func openAPIProperties(schema *openapi3.Schema) (openapi3.Schemas, map[string]bool) {
+	properties := openapi3.Schemas{}
+	required := map[string]bool{}
+	var collect func(schema *openapi3.Schema)
+	collect = func(schema *openapi3.Schema) {
+		for _, allOf := range schema.AllOf {
+			if allOf.Value != nil {
+				collect(allOf.Value)
+			}
+		}
+		for name, property := range schema.Properties {
+			properties[name] = property
+		}
+		for _, name := range schema.Required {
+			required[name] = true
+		}
+	}
+	collect(schema)
+	return properties, required
+}
+
+var openAPINameRegex = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+func openAPIValidEnumValue(value string) bool {
+	switch value {
+	case "true", "false", "null":
+		return false
+	}
+	return openAPINameRegex.MatchString(value)
+}
+
+// openAPIFieldName keeps valid GraphQL names and camel cases the others, e.g. first-name becomes firstName
+func openAPIFieldName(name string) string {
+	if openAPINameRegex.MatchString(name) && !strings.HasPrefix(name, "__") {
+		return name
+	}
+	return openAPICamelCase(name)
+}
+
+func openAPIWords(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return !(r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)))
+	})
+}
+
+func openAPIPascalCase(name string) string {
+	sb := strings.Builder{}
+	for _, word := range openAPIWords(name) {
+		sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return sb.String()
+}
+
+func openAPICamelCase(name string) string {
+	pascal := openAPIPascalCase(name)
+	if pascal == "" {
+		return ""
+	}
+	camel := strings.ToLower(pascal[:1]) + pascal[1:]
+	if unicode.IsDigit(rune(camel[0])) {
+		camel = "_" + camel
+	}
+	return camel
+}
+
+// openAPIUniqueName returns the name or the name with a number if it is already used and marks it as used
+func openAPIUniqueName(name string, used map[string]bool) string {
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	used[unique] = true
+	return unique
+}
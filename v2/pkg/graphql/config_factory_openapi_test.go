@@ -0,0 +1,403 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jensneuse/abstractlogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	restDataSource "github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/rest_datasource"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
+)
+
+const openAPIPetStore = `
+openapi: 3.0.3
+info:
+  title: Pet Store
+  version: 1.0.0
+servers:
+  - url: https://{environment}.example.com/v1
+    variables:
+      environment:
+        default: api
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List all pets
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+        - name: tags
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+        - name: X-Request-ID
+          in: header
+          schema:
+            type: string
+      responses:
+        "200":
+          description: A list of pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Pet"
+    post:
+      operationId: create_pet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/NewPet"
+      responses:
+        "201":
+          description: The created pet
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pet"
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      responses:
+        "200":
+          description: The pet
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pet"
+    delete:
+      operationId: deletePet
+      responses:
+        "204":
+          description: The pet was deleted
+components:
+  schemas:
+    NewPet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        status:
+          $ref: "#/components/schemas/Status"
+        owner:
+          type: object
+          properties:
+            first-name:
+              type: string
+    Pet:
+      description: A pet of the store
+      allOf:
+        - $ref: "#/components/schemas/NewPet"
+        - type: object
+          required: [id]
+          properties:
+            id:
+              type: integer
+            parent:
+              $ref: "#/components/schemas/Pet"
+            attributes:
+              type: object
+              additionalProperties: true
+    Status:
+      type: string
+      enum: [available, pending, sold]
+`
+
+const openAPIPetStoreSDL = `type Query {
+  """List all pets"""
+  listPets(limit: Int, tags: [String!], xRequestID: String): [Pet!]
+  getPetsByPetId(petId: Int!): Pet
+}
+
+type Mutation {
+  createPet(input: NewPetInput!): Pet
+  deletePet(petId: Int!): JSON
+}
+
+"""A pet of the store"""
+type Pet {
+  attributes: JSON
+  id: Int!
+  name: String!
+  owner: PetOwner
+  parent: Pet
+  status: Status
+}
+
+type PetOwner {
+  firstName: String
+}
+
+enum Status {
+  available
+  pending
+  sold
+}
+
+input NewPetInput {
+  name: String!
+  owner: NewPetOwnerInput
+  status: Status
+}
+
+input NewPetOwnerInput {
+  firstName: String
+}
+
+scalar JSON
+`
+
+func TestOpenAPIEngineConfigFactory_SDL(t *testing.T) {
+	sdl, err := NewOpenAPIEngineConfigFactory([]byte(openAPIPetStore)).SDL()
+	require.NoError(t, err)
+	assert.Equal(t, openAPIPetStoreSDL, sdl)
+
+	_, err = NewSchemaFromString(sdl)
+	assert.NoError(t, err)
+}
+
+func TestOpenAPIEngineConfigFactory_EngineV2Configuration(t *testing.T) {
+	client := &http.Client{}
+	config, err := NewOpenAPIEngineConfigFactory([]byte(openAPIPetStore),
+		WithOpenAPIHttpClient(client),
+		WithOpenAPIStaticHeaders(http.Header{"Authorization": {"Bearer token"}}),
+	).EngineV2Configuration()
+	require.NoError(t, err)
+
+	factory := &restDataSource.Factory{Client: client}
+	petChildNodes := []plan.TypeField{
+		{TypeName: "Pet", FieldNames: []string{"attributes", "id", "name", "owner", "parent", "status"}},
+		{TypeName: "PetOwner", FieldNames: []string{"firstName"}},
+	}
+	expectedDataSources := []plan.DataSourceConfiguration{
+		{
+			ID:         "Query.listPets",
+			RootNodes:  []plan.TypeField{{TypeName: "Query", FieldNames: []string{"listPets"}}},
+			ChildNodes: petChildNodes,
+			Factory:    factory,
+			Custom: restDataSource.ConfigJSON(restDataSource.Configuration{
+				Fetch: restDataSource.FetchConfiguration{
+					URL:    "https://api.example.com/v1/pets",
+					Method: http.MethodGet,
+					Header: http.Header{
+						"Authorization": {"Bearer token"},
+						"X-Request-Id":  {"{{ .arguments.xRequestID }}"},
+					},
+					Query: []restDataSource.QueryConfiguration{
+						{Name: "limit", Value: "{{ .arguments.limit }}"},
+						{Name: "tags", Value: "{{ .arguments.tags }}"},
+					},
+				},
+			}),
+		},
+		{
+			ID:         "Mutation.createPet",
+			RootNodes:  []plan.TypeField{{TypeName: "Mutation", FieldNames: []string{"createPet"}}},
+			ChildNodes: petChildNodes,
+			Factory:    factory,
+			Custom: restDataSource.ConfigJSON(restDataSource.Configuration{
+				Fetch: restDataSource.FetchConfiguration{
+					URL:    "https://api.example.com/v1/pets",
+					Method: http.MethodPost,
+					Header: http.Header{
+						"Authorization": {"Bearer token"},
+						"Content-Type":  {"application/json"},
+					},
+					Body:     "{{ .arguments.input }}",
+					BodyKeys: map[string]string{"firstName": "first-name"},
+				},
+			}),
+		},
+		{
+			ID:         "Query.getPetsByPetId",
+			RootNodes:  []plan.TypeField{{TypeName: "Query", FieldNames: []string{"getPetsByPetId"}}},
+			ChildNodes: petChildNodes,
+			Factory:    factory,
+			Custom: restDataSource.ConfigJSON(restDataSource.Configuration{
+				Fetch: restDataSource.FetchConfiguration{
+					URL:    "https://api.example.com/v1/pets/{{ .arguments.petId }}",
+					Method: http.MethodGet,
+					Header: http.Header{"Authorization": {"Bearer token"}},
+				},
+			}),
+		},
+		{
+			ID:        "Mutation.deletePet",
+			RootNodes: []plan.TypeField{{TypeName: "Mutation", FieldNames: []string{"deletePet"}}},
+			Factory:   factory,
+			Custom: restDataSource.ConfigJSON(restDataSource.Configuration{
+				Fetch: restDataSource.FetchConfiguration{
+					URL:    "https://api.example.com/v1/pets/{{ .arguments.petId }}",
+					Method: http.MethodDelete,
+					Header: http.Header{"Authorization": {"Bearer token"}},
+				},
+			}),
+		},
+	}
+	assert.Equal(t, expectedDataSources, config.DataSources())
+
+	expectedFieldConfigs := plan.FieldConfigurations{
+		{
+			TypeName:  "PetOwner",
+			FieldName: "firstName",
+			Path:      []string{"first-name"},
+		},
+		{
+			TypeName:  "Query",
+			FieldName: "listPets",
+			Arguments: plan.ArgumentsConfigurations{
+				{Name: "limit", SourceType: plan.FieldArgumentSource},
+				{Name: "tags", SourceType: plan.FieldArgumentSource},
+				{Name: "xRequestID", SourceType: plan.FieldArgumentSource},
+			},
+		},
+		{
+			TypeName:              "Mutation",
+			FieldName:             "createPet",
+			DisableDefaultMapping: true,
+			Arguments: plan.ArgumentsConfigurations{
+				{Name: "input", SourceType: plan.FieldArgumentSource, RenderConfig: plan.RenderArgumentAsJSONValue},
+			},
+		},
+		{
+			TypeName:              "Query",
+			FieldName:             "getPetsByPetId",
+			DisableDefaultMapping: true,
+			Arguments: plan.ArgumentsConfigurations{
+				{Name: "petId", SourceType: plan.FieldArgumentSource},
+			},
+		},
+		{
+			TypeName:              "Mutation",
+			FieldName:             "deletePet",
+			DisableDefaultMapping: true,
+			Arguments: plan.ArgumentsConfigurations{
+				{Name: "petId", SourceType: plan.FieldArgumentSource},
+			},
+		},
+	}
+	assert.Equal(t, expectedFieldConfigs, config.FieldConfigurations())
+}
+
+func TestOpenAPIEngineConfigFactory_Execution(t *testing.T) {
+	var createdPet string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/pets":
+			assert.Equal(t, "2", r.URL.Query().Get("limit"))
+			_, _ = w.Write([]byte(`[{"id":1,"name":"Rex","status":"available","owner":{"first-name":"Jane"}},{"id":2,"name":"Tom","status":"sold"}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/pets":
+			body, _ := io.ReadAll(r.Body)
+			createdPet = string(body)
+			_, _ = w.Write([]byte(`{"id":3,"name":"Bello","status":"pending"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/pets/1":
+			_, _ = w.Write([]byte(`{"id":1,"name":"Rex","parent":{"id":4,"name":"Max"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	config, err := NewOpenAPIEngineConfigFactory([]byte(openAPIPetStore),
+		WithOpenAPIHttpClient(server.Client()),
+		WithOpenAPIBaseURL(server.URL),
+	).EngineV2Configuration()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine, err := NewExecutionEngineV2(ctx, abstractlogger.Noop{}, config)
+	require.NoError(t, err)
+
+	execute := func(t *testing.T, query string, variables string) string {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: query, Variables: json.RawMessage(variables)}, &resultWriter)
+		require.NoError(t, err)
+		return resultWriter.String()
+	}
+
+	t.Run("query with query parameters and renamed fields", func(t *testing.T) {
+		response := execute(t, `{ listPets(limit: 2) { id name status owner { firstName } } }`, "")
+		assert.Equal(t, `{"data":{"listPets":[{"id":1,"name":"Rex","status":"available","owner":{"firstName":"Jane"}},{"id":2,"name":"Tom","status":"sold","owner":null}]}}`, response)
+	})
+
+	t.Run("query with path parameter", func(t *testing.T) {
+		response := execute(t, `query Pet($id: Int!) { getPetsByPetId(petId: $id) { name parent { id name } } }`, `{"id":1}`)
+		assert.Equal(t, `{"data":{"getPetsByPetId":{"name":"Rex","parent":{"id":4,"name":"Max"}}}}`, response)
+	})
+
+	t.Run("mutation with request body", func(t *testing.T) {
+		response := execute(t, `mutation Create($pet: NewPetInput!) { createPet(input: $pet) { id name status } }`, `{"pet":{"name":"Bello","status":"pending","owner":{"firstName":"Jane"}}}`)
+		assert.Equal(t, `{"data":{"createPet":{"id":3,"name":"Bello","status":"pending"}}}`, response)
+		assert.JSONEq(t, `{"name":"Bello","status":"pending","owner":{"first-name":"Jane"}}`, createdPet)
+	})
+}
+
+func TestOpenAPIEngineConfigFactory_Errors(t *testing.T) {
+	t.Run("invalid document", func(t *testing.T) {
+		_, err := NewOpenAPIEngineConfigFactory([]byte(`openapi: 3.0.3`)).SDL()
+		assert.ErrorContains(t, err, "openapi:")
+	})
+
+	t.Run("no servers", func(t *testing.T) {
+		_, err := NewOpenAPIEngineConfigFactory([]byte(`
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+paths:
+  /pets:
+    get:
+      responses:
+        "200": {description: pets}
+`)).SDL()
+		assert.EqualError(t, err, "openapi: the document has no servers, a base URL is required")
+	})
+
+	t.Run("request body with conflicting property names", func(t *testing.T) {
+		_, err := NewOpenAPIEngineConfigFactory([]byte(`
+openapi: 3.0.3
+info: {title: Pets, version: 1.0.0}
+servers: [{url: "https://example.com"}]
+paths:
+  /pets:
+    get:
+      responses:
+        "200": {description: pets}
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                first-name: {type: string}
+                owner:
+                  type: object
+                  properties:
+                    firstName: {type: string}
+      responses:
+        "200": {description: pet}
+`)).SDL()
+		assert.EqualError(t, err, `openapi: request body of POST /pets: the field firstName refers to the properties "first-name" and "firstName"`)
+	})
+}
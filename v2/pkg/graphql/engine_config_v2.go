@@ -4,15 +4,19 @@ import (
 	"errors"
 	"net/http"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
 	graphqlDataSource "github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/graphql_datasource"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/federation/federationdata"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/metrics"
 )
 
 const (
 	DefaultFlushIntervalInMilliseconds = 1000
+	DefaultMaxBatchSize                = 10
 )
 
 type EngineV2Configuration struct {
@@ -20,6 +24,18 @@ type EngineV2Configuration struct {
 	plannerConfig            plan.Configuration
 	websocketBeforeStartHook WebsocketBeforeStartHook
 	dataLoaderConfig         dataLoaderConfig
+	maxBatchSize             int
+	responseCache            ResponseCache
+	responseCacheOptions     ResponseCacheOptions
+	errorPolicy              *resolve.ErrorPolicy
+	authorizer               resolve.Authorizer
+	tracerProvider           trace.TracerProvider
+	metrics                  metrics.Metrics
+	federatedTraceHook       resolve.FederatedTraceHook
+	rateLimiter              CustomExecutionEngineV2RateLimiterStage
+	subscriptionBufferSize   int
+	subscriptionOverflow     resolve.OverflowPolicy
+	liveQueries              *LiveQueryConfiguration
 }
 
 func NewEngineV2Configuration(schema *Schema) EngineV2Configuration {
@@ -33,6 +49,7 @@ func NewEngineV2Configuration(schema *Schema) EngineV2Configuration {
 		dataLoaderConfig: dataLoaderConfig{
 			EnableSingleFlightLoader: false,
 		},
+		maxBatchSize: DefaultMaxBatchSize,
 	}
 }
 
@@ -72,6 +89,74 @@ func (e *EngineV2Configuration) EnableSingleFlight(enable bool) {
 	e.dataLoaderConfig.EnableSingleFlightLoader = enable
 }
 
+// SetMaxBatchSize - sets the maximum number of operations in a batch, a size of 0 or less disables the limit
+func (e *EngineV2Configuration) SetMaxBatchSize(size int) {
+	e.maxBatchSize = size
+}
+
+// SetResponseCache - enables caching of whole query responses, the max-age of a response is derived from
+// the @cacheControl hints of the schema and the Cache-Control headers of upstream responses
+func (e *EngineV2Configuration) SetResponseCache(cache ResponseCache, options ResponseCacheOptions) {
+	e.responseCache = cache
+	e.responseCacheOptions = options
+}
+
+// SetErrorPolicy - sets the policy to mask and redact subgraph errors, it also adds codes to errors originating from the engine
+func (e *EngineV2Configuration) SetErrorPolicy(policy *resolve.ErrorPolicy) {
+	e.errorPolicy = policy
+}
+
+// SetAuthorizer - sets the authorizer which is called for fields with an authorization rule, e.g. a DirectiveAuthorizer.
+// Field infos are required by authorizers, so they are included in all plans.
+func (e *EngineV2Configuration) SetAuthorizer(authorizer resolve.Authorizer) {
+	e.authorizer = authorizer
+	e.updateIncludeInfo()
+}
+
+// SetTracerProvider - sets the OpenTelemetry tracer provider of the engine, if not set the global provider is used
+func (e *EngineV2Configuration) SetTracerProvider(provider trace.TracerProvider) {
+	e.tracerProvider = provider
+}
+
+// SetMetrics - sets the metrics receiving the operation, plan cache, fetch and subscription measurements of the engine, e.g. metrics.NewPrometheus.
+// Fetch metrics are labeled with the data source id of the field infos, so they are included in all plans.
+func (e *EngineV2Configuration) SetMetrics(m metrics.Metrics) {
+	e.metrics = m
+	e.updateIncludeInfo()
+}
+
+// SetFederatedTraceHook - sets the hook receiving the federated trace (ftv1) of every operation.
+// Federated traces are requested from all GraphQL data sources and their services are named by the data source id of the field infos,
+// so they are included in all plans.
+func (e *EngineV2Configuration) SetFederatedTraceHook(hook resolve.FederatedTraceHook) {
+	e.federatedTraceHook = hook
+	e.updateIncludeInfo()
+}
+
+// SetRateLimiter - sets the rate limiter which is called for every operation before planning, e.g. a RateLimiter.
+// Responses served from the response cache are not charged.
+func (e *EngineV2Configuration) SetRateLimiter(rateLimiter CustomExecutionEngineV2RateLimiterStage) {
+	e.rateLimiter = rateLimiter
+}
+
+// SetSubscriptionBuffer - sets the number of updates buffered per subscription while its client is busy
+// and the policy applied to the updates of clients which can't keep up, see resolve.OverflowPolicy.
+func (e *EngineV2Configuration) SetSubscriptionBuffer(size int, policy resolve.OverflowPolicy) {
+	e.subscriptionBufferSize = size
+	e.subscriptionOverflow = policy
+}
+
+// SetLiveQueries - enables queries marked with @live, which are re-executed on the poll interval and on invalidation events
+// and stream their changed results, see LiveQueryConfiguration. The schema has to contain LiveDirectiveSDL.
+func (e *EngineV2Configuration) SetLiveQueries(config LiveQueryConfiguration) {
+	e.liveQueries = &config
+}
+
+// updateIncludeInfo includes field infos in all plans if they are required by the authorizer, the metrics or the federated trace hook
+func (e *EngineV2Configuration) updateIncludeInfo() {
+	e.plannerConfig.IncludeInfo = e.authorizer != nil || e.metrics != nil || e.federatedTraceHook != nil
+}
+
 // SetWebsocketBeforeStartHook - sets before start hook which will be called before processing any operation sent over websockets
 func (e *EngineV2Configuration) SetWebsocketBeforeStartHook(hook WebsocketBeforeStartHook) {
 	e.websocketBeforeStartHook = hook
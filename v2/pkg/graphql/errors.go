@@ -10,6 +10,15 @@ import (
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/operationreport"
 )
 
+// Error codes of errors returned before execution, they are only set if an error policy is configured,
+// see EngineV2Configuration.SetErrorPolicy
+const (
+	ErrorCodeGraphQLParseFailed      = "GRAPHQL_PARSE_FAILED"
+	ErrorCodeGraphQLValidationFailed = "GRAPHQL_VALIDATION_FAILED"
+	ErrorCodeBadUserInput            = "BAD_USER_INPUT"
+	ErrorCodePlanningFailed          = "PLANNING_FAILED"
+)
+
 type Errors interface {
 	error
 	WriteResponse(writer io.Writer) (n int, err error)
@@ -23,6 +32,9 @@ func RequestErrorsFromError(err error) RequestErrors {
 	if errors, ok := err.(RequestErrors); ok {
 		return errors
 	}
+	if rateLimitErr, ok := err.(*RateLimitExceededError); ok {
+		return rateLimitErr.RequestErrors()
+	}
 	if report, ok := err.(operationreport.Report); ok {
 		if len(report.ExternalErrors) == 0 {
 			return RequestErrors{
@@ -98,6 +110,18 @@ func (o RequestErrors) WriteResponse(writer io.Writer) (n int, err error) {
 	return writer.Write(responseBytes)
 }
 
+// withCode returns a copy of the errors in which every error without code has the given code
+func (o RequestErrors) withCode(code string) RequestErrors {
+	errors := make(RequestErrors, len(o))
+	for i := range o {
+		errors[i] = o[i]
+		if errors[i].Extensions == nil {
+			errors[i].Extensions = &RequestErrorExtensions{Code: code}
+		}
+	}
+	return errors
+}
+
 func (o RequestErrors) Count() int {
 	return len(o)
 }
@@ -111,19 +135,28 @@ func (o RequestErrors) ErrorByIndex(i int) error {
 }
 
 type RequestError struct {
-	Message   string                   `json:"message"`
-	Locations []graphqlerrors.Location `json:"locations,omitempty"`
-	Path      ErrorPath                `json:"path"`
+	Message    string                   `json:"message"`
+	Locations  []graphqlerrors.Location `json:"locations,omitempty"`
+	Path       ErrorPath                `json:"path"`
+	Extensions *RequestErrorExtensions  `json:"extensions,omitempty"`
+}
+
+type RequestErrorExtensions struct {
+	Code string `json:"code,omitempty"`
+	// RetryAfter is the number of seconds after which a rate limited operation can be retried
+	RetryAfter int `json:"retryAfter,omitempty"`
 }
 
 func (o RequestError) MarshalJSON() ([]byte, error) {
 	if o.Path.Len() == 0 {
 		return json.Marshal(struct {
-			Message   string                   `json:"message"`
-			Locations []graphqlerrors.Location `json:"locations,omitempty"`
+			Message    string                   `json:"message"`
+			Locations  []graphqlerrors.Location `json:"locations,omitempty"`
+			Extensions *RequestErrorExtensions  `json:"extensions,omitempty"`
 		}{
-			Message:   o.Message,
-			Locations: o.Locations,
+			Message:    o.Message,
+			Locations:  o.Locations,
+			Extensions: o.Extensions,
 		})
 	}
 	path, err := o.Path.MarshalJSON()
@@ -131,13 +164,15 @@ func (o RequestError) MarshalJSON() ([]byte, error) {
 		return nil, err
 	}
 	return json.Marshal(struct {
-		Message   string                   `json:"message"`
-		Locations []graphqlerrors.Location `json:"locations,omitempty"`
-		Path      json.RawMessage          `json:"path"`
+		Message    string                   `json:"message"`
+		Locations  []graphqlerrors.Location `json:"locations,omitempty"`
+		Path       json.RawMessage          `json:"path"`
+		Extensions *RequestErrorExtensions  `json:"extensions,omitempty"`
 	}{
-		Message:   o.Message,
-		Locations: o.Locations,
-		Path:      path,
+		Message:    o.Message,
+		Locations:  o.Locations,
+		Path:       path,
+		Extensions: o.Extensions,
 	})
 }
 
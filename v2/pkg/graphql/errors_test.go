@@ -67,6 +67,21 @@ func TestOperationValidationErrors_WriteResponse(t *testing.T) {
 	assert.Equal(t, expectedResponse, buf.String())
 }
 
+func TestOperationValidationErrors_WriteResponse_WithCode(t *testing.T) {
+	validationErrs := RequestErrors{
+		{Message: "error in operation"},
+		{Message: "coded error", Extensions: &RequestErrorExtensions{Code: "CUSTOM"}},
+	}.withCode(ErrorCodeGraphQLValidationFailed)
+
+	buf := new(bytes.Buffer)
+	_, err := validationErrs.WriteResponse(buf)
+
+	expectedResponse := `{"errors":[{"message":"error in operation","extensions":{"code":"GRAPHQL_VALIDATION_FAILED"}},{"message":"coded error","extensions":{"code":"CUSTOM"}}],"data":null}`
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedResponse, buf.String())
+}
+
 func TestOperationValidationError_Error(t *testing.T) {
 	validatonErr := RequestError{
 		Message: "error in operation",
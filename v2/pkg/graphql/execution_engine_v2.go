@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/andybalholm/brotli"
 	lru "github.com/hashicorp/golang-lru"
@@ -22,6 +23,7 @@ import (
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/postprocess"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/metrics"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/operationreport"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/pool"
 )
@@ -29,6 +31,7 @@ import (
 type EngineResultWriter struct {
 	buf           *bytes.Buffer
 	flushCallback func(data []byte)
+	cacheControl  *CacheControlPolicy
 }
 
 func (e *EngineResultWriter) Complete() {
@@ -83,6 +86,18 @@ func (e *EngineResultWriter) Reset() {
 	e.buf.Reset()
 }
 
+func (e *EngineResultWriter) SetCacheControl(policy CacheControlPolicy) {
+	e.cacheControl = &policy
+}
+
+// CacheControl returns the cache control policy of the response, it's only set if a response cache is configured.
+func (e *EngineResultWriter) CacheControl() (policy CacheControlPolicy, ok bool) {
+	if e.cacheControl == nil {
+		return CacheControlPolicy{}, false
+	}
+	return *e.cacheControl, true
+}
+
 func (e *EngineResultWriter) AsHTTPResponse(status int, headers http.Header) *http.Response {
 	b := &bytes.Buffer{}
 
@@ -155,6 +170,7 @@ type ExecutionEngineV2 struct {
 	resolver                      *resolve.Resolver
 	executionPlanCache            *lru.Cache
 	customExecutionEngineExecutor *CustomExecutionEngineV2Executor
+	metrics                       metrics.Metrics
 }
 
 type WebsocketBeforeStartHook interface {
@@ -205,6 +221,14 @@ func WithHeaderModifier(modifier postprocess.HeaderModifier) ExecutionOptionsV2
 	}
 }
 
+// WithClaims sets the claims of the authenticated client which are evaluated by the authorizer of the engine.
+// Without claims the client is unauthenticated.
+func WithClaims(claims *resolve.Claims) ExecutionOptionsV2 {
+	return func(postProcessor *postprocess.Processor, resolveContext *resolve.Context) {
+		resolveContext.WithClaims(claims)
+	}
+}
+
 func NewExecutionEngineV2(ctx context.Context, logger abstractlogger.Logger, engineConfig EngineV2Configuration) (*ExecutionEngineV2, error) {
 	executionPlanCache, err := lru.New(1024)
 	if err != nil {
@@ -229,15 +253,25 @@ func NewExecutionEngineV2(ctx context.Context, logger abstractlogger.Logger, eng
 		config:  engineConfig,
 		planner: plan.NewPlanner(ctx, engineConfig.plannerConfig),
 		resolver: resolve.New(ctx, resolve.ResolverOptions{
-			MaxConcurrency: 1024,
+			MaxConcurrency:     1024,
+			EnableSingleFlight: engineConfig.dataLoaderConfig.EnableSingleFlightLoader,
+			ErrorPolicy:        engineConfig.errorPolicy,
+			TracerProvider:     engineConfig.tracerProvider,
+			Metrics:            engineConfig.metrics,
+
+			SubscriptionBufferSize:     engineConfig.subscriptionBufferSize,
+			SubscriptionOverflowPolicy: engineConfig.subscriptionOverflow,
 		}),
 		executionPlanCache: executionPlanCache,
+		metrics:            metrics.OrNoop(engineConfig.metrics),
 	}
 
 	executor, err := NewCustomExecutionEngineV2Executor(executionEngine)
 	if err != nil {
 		return nil, err
 	}
+	executor.tracer = newTracer(engineConfig.tracerProvider)
+	executor.ExecutionStages.OptionalStages.RateLimiterStage = engineConfig.rateLimiter
 	executionEngine.customExecutionEngineExecutor = executor
 	return executionEngine, nil
 }
@@ -250,7 +284,10 @@ func (e *ExecutionEngineV2) Normalize(operation *Request) error {
 		}
 
 		if !result.Successful {
-			return result.Errors
+			if !operation.isParsed {
+				return e.withErrorCode(result.Errors, ErrorCodeGraphQLParseFailed)
+			}
+			return e.withErrorCode(result.Errors, ErrorCodeGraphQLValidationFailed)
 		}
 	}
 	return nil
@@ -262,7 +299,7 @@ func (e *ExecutionEngineV2) ValidateForSchema(operation *Request) error {
 		return err
 	}
 	if !result.Valid {
-		return result.Errors
+		return e.withErrorCode(result.Errors, ErrorCodeGraphQLValidationFailed)
 	}
 	return nil
 }
@@ -273,12 +310,18 @@ func (e *ExecutionEngineV2) InputValidation(operation *Request) error {
 		return err
 	}
 	if !result.Valid {
-		return result.Errors
+		return e.withErrorCode(result.Errors, ErrorCodeBadUserInput)
 	}
 	return nil
 }
 
 func (e *ExecutionEngineV2) Setup(ctx context.Context, postProcessor *postprocess.Processor, resolveContext *resolve.Context, operation *Request, options ...ExecutionOptionsV2) {
+	if e.config.authorizer != nil {
+		resolveContext.WithAuthorizer(e.config.authorizer)
+	}
+	if e.config.federatedTraceHook != nil {
+		resolveContext.WithFederatedTraceHook(e.config.federatedTraceHook)
+	}
 	for i := range options {
 		options[i](postProcessor, resolveContext)
 	}
@@ -287,7 +330,10 @@ func (e *ExecutionEngineV2) Setup(ctx context.Context, postProcessor *postproces
 func (e *ExecutionEngineV2) Plan(postProcessor *postprocess.Processor, operation *Request, report *operationreport.Report) (plan.Plan, error) {
 	cachedPlan := e.getCachedPlan(postProcessor, &operation.document, &e.config.schema.document, operation.OperationName, report)
 	if report.HasErrors() {
-		return nil, report
+		return nil, e.withErrorCode(report, ErrorCodePlanningFailed)
+	}
+	if isLiveQuery, _ := operation.IsLiveQuery(); isLiveQuery {
+		return e.liveQueryPlan(operation, cachedPlan)
 	}
 	return cachedPlan, nil
 }
@@ -299,6 +345,8 @@ func (e *ExecutionEngineV2) Resolve(resolveContext *resolve.Context, planResult
 		err = e.resolver.ResolveGraphQLResponse(resolveContext, p.Response, nil, writer)
 	case *plan.SubscriptionResponsePlan:
 		err = e.resolver.AsyncResolveGraphQLSubscription(resolveContext, p.Response, writer, resolve.SubscriptionIdentifier{})
+	case *plan.LiveQueryResponsePlan:
+		err = e.resolver.ResolveGraphQLLiveQuery(resolveContext, p.Response, writer)
 	default:
 		return errors.New("execution of operation is not possible")
 	}
@@ -309,7 +357,31 @@ func (e *ExecutionEngineV2) Resolve(resolveContext *resolve.Context, planResult
 func (e *ExecutionEngineV2) Teardown() {
 }
 
-func (e *ExecutionEngineV2) Execute(ctx context.Context, operation *Request, writer resolve.SubscriptionResponseWriter, options ...ExecutionOptionsV2) error {
+// withErrorCode adds the code to request errors if an error policy is configured
+func (e *ExecutionEngineV2) withErrorCode(err error, code string) error {
+	if e.config.errorPolicy == nil {
+		return err
+	}
+	switch errs := err.(type) {
+	case RequestErrors:
+		return errs.withCode(code)
+	case *operationreport.Report:
+		if len(errs.ExternalErrors) > 0 {
+			return RequestErrorsFromOperationReport(*errs).withCode(code)
+		}
+	}
+	return err
+}
+
+func (e *ExecutionEngineV2) Execute(ctx context.Context, operation *Request, writer resolve.SubscriptionResponseWriter, options ...ExecutionOptionsV2) (err error) {
+	start := time.Now()
+	defer func() {
+		operationType, _ := operation.OperationType()
+		e.metrics.OperationCompleted(operationTypeName(operationType), operation.OperationName, time.Since(start), err != nil)
+	}()
+	if e.config.responseCache != nil {
+		return e.executeWithResponseCache(ctx, operation, writer, options...)
+	}
 	return e.customExecutionEngineExecutor.Execute(ctx, operation, writer, options...)
 }
 
@@ -357,9 +429,11 @@ func (e *ExecutionEngineV2) getCachedPlan(postProcessor *postprocess.Processor,
 
 	if cached, ok := e.executionPlanCache.Get(cacheKey); ok {
 		if p, ok := cached.(plan.Plan); ok {
+			e.metrics.PlanCacheLookup(true)
 			return p
 		}
 	}
+	e.metrics.PlanCacheLookup(false)
 
 	e.plannerMu.Lock()
 	defer e.plannerMu.Unlock()
@@ -378,6 +452,7 @@ func (e *ExecutionEngineV2) GetWebsocketBeforeStartHook() WebsocketBeforeStartHo
 }
 
 var (
+	_ CacheControlWriter        = (*EngineResultWriter)(nil)
 	_ CustomExecutionEngineV2   = (*ExecutionEngineV2)(nil)
 	_ ExecutionEngineV2Executor = (*ExecutionEngineV2)(nil)
 )
@@ -0,0 +1,75 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/postprocess"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+)
+
+var (
+	ErrBatchTooLarge       = errors.New("the batch exceeds the maximum batch size")
+	ErrBatchedSubscription = errors.New("subscriptions can't be part of a batch")
+	ErrBatchedLiveQuery    = errors.New("live queries can't be part of a batch")
+)
+
+// BatchResult is the result of a single operation of a batch
+type BatchResult struct {
+	// Response is the response written by the engine, it's empty if Err is set
+	Response []byte
+	// Err is the error returned by the execution of the operation, see ExecutionEngineV2.Execute
+	Err error
+}
+
+// ExecuteBatch executes the operations of an Apollo-style batch concurrently and returns their results in order.
+// All operations of the batch share a resolve.BatchLoadCache, so identical fetches are sent only once per batch.
+// The size of the batch is limited by EngineV2Configuration.SetMaxBatchSize.
+func (e *ExecutionEngineV2) ExecuteBatch(ctx context.Context, operations []*Request, options ...ExecutionOptionsV2) ([]BatchResult, error) {
+	if len(operations) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if e.config.maxBatchSize > 0 && len(operations) > e.config.maxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	batchOptions := make([]ExecutionOptionsV2, 0, len(options)+1)
+	batchOptions = append(batchOptions, options...)
+	batchOptions = append(batchOptions, withBatchLoadCache(resolve.NewBatchLoadCache()))
+
+	results := make([]BatchResult, len(operations))
+	wg := &sync.WaitGroup{}
+	wg.Add(len(operations))
+	for i := range operations {
+		go func(i int) {
+			defer wg.Done()
+			results[i] = e.executeBatchOperation(ctx, operations[i], batchOptions...)
+		}(i)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+func (e *ExecutionEngineV2) executeBatchOperation(ctx context.Context, operation *Request, options ...ExecutionOptionsV2) BatchResult {
+	// parse errors are ignored here, they are returned by Execute
+	if operationType, err := operation.OperationType(); err == nil && operationType == OperationTypeSubscription {
+		return BatchResult{Err: RequestErrorsFromError(ErrBatchedSubscription)}
+	}
+	if isLiveQuery, err := operation.IsLiveQuery(); err == nil && isLiveQuery {
+		return BatchResult{Err: RequestErrorsFromError(ErrBatchedLiveQuery)}
+	}
+
+	writer := NewEngineResultWriter()
+	if err := e.Execute(ctx, operation, &writer, options...); err != nil {
+		return BatchResult{Err: err}
+	}
+	return BatchResult{Response: writer.Bytes()}
+}
+
+func withBatchLoadCache(cache *resolve.BatchLoadCache) ExecutionOptionsV2 {
+	return func(postProcessor *postprocess.Processor, resolveContext *resolve.Context) {
+		resolveContext.WithBatchLoadCache(cache)
+	}
+}
@@ -0,0 +1,158 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jensneuse/abstractlogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/graphql_datasource"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
+)
+
+func TestExecutionEngineV2_ExecuteBatch(t *testing.T) {
+	newEngine := func(t *testing.T, upstreamCalls *atomic.Int64, maxBatchSize int) *ExecutionEngineV2 {
+		schema := starwarsSchema(t)
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetMaxBatchSize(maxBatchSize)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{
+					{TypeName: "Query", FieldNames: []string{"hero"}},
+				},
+				ChildNodes: []plan.TypeField{
+					{TypeName: "Character", FieldNames: []string{"name"}},
+				},
+				Factory: &graphql_datasource.Factory{
+					HTTPClient: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							upstreamCalls.Inc()
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Body:       io.NopCloser(bytes.NewBufferString(`{"data":{"hero":{"name":"Luke Skywalker"}}}`)),
+							}
+						}),
+					},
+				},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch: graphql_datasource.FetchConfiguration{
+						URL:    "https://example.com/",
+						Method: "POST",
+					},
+					UpstreamSchema: string(schema.Document()),
+				}),
+			},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		engine, err := NewExecutionEngineV2(ctx, abstractlogger.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
+
+	t.Run("results are returned in order and identical fetches are deduplicated", func(t *testing.T) {
+		upstreamCalls := atomic.NewInt64(0)
+		engine := newEngine(t, upstreamCalls, DefaultMaxBatchSize)
+
+		results, err := engine.ExecuteBatch(context.Background(), []*Request{
+			{Query: "{ hero { name } }"},
+			{Query: "query Hero { hero { name } }", OperationName: "Hero"},
+			{Query: "{ villain { name } }"},
+			{Query: "subscription { remainingJedis }"},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 4)
+
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, `{"data":{"hero":{"name":"Luke Skywalker"}}}`, string(results[0].Response))
+		assert.NoError(t, results[1].Err)
+		assert.Equal(t, `{"data":{"hero":{"name":"Luke Skywalker"}}}`, string(results[1].Response))
+		assert.ErrorContains(t, results[2].Err, "field: villain not defined on type: Query")
+		assert.Empty(t, results[2].Response)
+		assert.Equal(t, RequestErrors{{Message: ErrBatchedSubscription.Error()}}, results[3].Err)
+
+		assert.Equal(t, int64(1), upstreamCalls.Load())
+	})
+
+	t.Run("fetches are not shared between batches", func(t *testing.T) {
+		upstreamCalls := atomic.NewInt64(0)
+		engine := newEngine(t, upstreamCalls, DefaultMaxBatchSize)
+
+		for i := 0; i < 2; i++ {
+			results, err := engine.ExecuteBatch(context.Background(), []*Request{{Query: "{ hero { name } }"}})
+			require.NoError(t, err)
+			assert.NoError(t, results[0].Err)
+		}
+		assert.Equal(t, int64(2), upstreamCalls.Load())
+	})
+
+	t.Run("max batch size", func(t *testing.T) {
+		engine := newEngine(t, atomic.NewInt64(0), 2)
+
+		_, err := engine.ExecuteBatch(context.Background(), []*Request{
+			{Query: "{ hero { name } }"},
+			{Query: "{ hero { name } }"},
+			{Query: "{ hero { name } }"},
+		})
+		assert.ErrorIs(t, err, ErrBatchTooLarge)
+
+		_, err = engine.ExecuteBatch(context.Background(), nil)
+		assert.ErrorIs(t, err, ErrEmptyBatch)
+	})
+}
+
+func TestUnmarshalBatchRequest(t *testing.T) {
+	t.Run("single request", func(t *testing.T) {
+		requests, isBatch, err := UnmarshalBatchRequest(strings.NewReader(`{"query":"{ hero { name } }"}`))
+		require.NoError(t, err)
+		assert.False(t, isBatch)
+		require.Len(t, requests, 1)
+		assert.Equal(t, "{ hero { name } }", requests[0].Query)
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		requests, isBatch, err := UnmarshalBatchRequest(strings.NewReader(` [{"query":"{ a }"},{"query":"query B { b }","operationName":"B","variables":{"c":1}}]`))
+		require.NoError(t, err)
+		assert.True(t, isBatch)
+		require.Len(t, requests, 2)
+		assert.Equal(t, "{ a }", requests[0].Query)
+		assert.Equal(t, "B", requests[1].OperationName)
+		assert.Equal(t, `{"c":1}`, string(requests[1].Variables))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, _, err := UnmarshalBatchRequest(strings.NewReader(""))
+		assert.ErrorIs(t, err, ErrEmptyRequest)
+
+		_, isBatch, err := UnmarshalBatchRequest(strings.NewReader("[]"))
+		assert.True(t, isBatch)
+		assert.ErrorIs(t, err, ErrEmptyBatch)
+
+		_, _, err = UnmarshalBatchRequest(strings.NewReader(`[{"query":"{ a }"},null]`))
+		assert.ErrorIs(t, err, ErrEmptyRequest)
+
+		_, _, err = UnmarshalBatchRequest(strings.NewReader(`[{"query":`))
+		assert.Error(t, err)
+	})
+
+	t.Run("http request headers", func(t *testing.T) {
+		r, err := http.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`[{"query":"{ a }"},{"query":"{ b }"}]`))
+		require.NoError(t, err)
+		r.Header.Set("Authorization", "token")
+
+		requests, isBatch, err := UnmarshalHttpBatchRequest(r)
+		require.NoError(t, err)
+		assert.True(t, isBatch)
+		for _, request := range requests {
+			assert.Equal(t, "token", request.request.Header.Get("Authorization"))
+		}
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"sync"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/postprocess"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
@@ -27,6 +29,12 @@ type CustomExecutionEngineV2InputValidationStage interface {
 	InputValidation(operation *Request) error
 }
 
+// CustomExecutionEngineV2RateLimiterStage rejects operations exceeding a rate limit, it is called after the setup of the resolve context,
+// so that the claims and the request of the client are available.
+type CustomExecutionEngineV2RateLimiterStage interface {
+	RateLimit(operation *Request, resolveContext *resolve.Context) error
+}
+
 type CustomExecutionEngineV2ResolverStage interface {
 	Setup(ctx context.Context, postProcessor *postprocess.Processor, resolveContext *resolve.Context, operation *Request, options ...ExecutionOptionsV2)
 	Plan(postProcessor *postprocess.Processor, operation *Request, report *operationreport.Report) (plan.Plan, error)
@@ -62,11 +70,13 @@ type CustomExecutionEngineV2OptionalStages struct {
 	NormalizerStage      CustomExecutionEngineV2NormalizerStage
 	ValidatorStage       CustomExecutionEngineV2ValidatorStage
 	InputValidationStage CustomExecutionEngineV2InputValidationStage
+	RateLimiterStage     CustomExecutionEngineV2RateLimiterStage
 }
 
 type CustomExecutionEngineV2Executor struct {
 	ExecutionStages              CustomExecutionEngineV2Stages
 	internalExecutionContextPool sync.Pool
+	tracer                       trace.Tracer
 }
 
 func NewCustomExecutionEngineV2Executor(executionEngineV2 CustomExecutionEngineV2) (*CustomExecutionEngineV2Executor, error) {
@@ -91,6 +101,7 @@ func NewCustomExecutionEngineV2ExecutorByStages(executionStages CustomExecutionE
 				return newInternalExecutionContext()
 			},
 		},
+		tracer: newTracer(nil),
 	}, nil
 }
 
@@ -103,28 +114,49 @@ func (c *CustomExecutionEngineV2Executor) putExecutionCtx(ctx *internalExecution
 	c.internalExecutionContextPool.Put(ctx)
 }
 
-func (c *CustomExecutionEngineV2Executor) Execute(ctx context.Context, operation *Request, writer resolve.SubscriptionResponseWriter, options ...ExecutionOptionsV2) error {
+func (c *CustomExecutionEngineV2Executor) Execute(ctx context.Context, operation *Request, writer resolve.SubscriptionResponseWriter, options ...ExecutionOptionsV2) (err error) {
 	if !c.ExecutionStages.AllRequiredStagesProvided() {
 		return ErrRequiredStagesMissing
 	}
 
-	var err error
+	ctx, span := c.tracer.Start(ctx, operationSpanName)
+	if operation.OperationName != "" {
+		span.SetAttributes(AttributeOperationName.String(operation.OperationName))
+	}
+	defer func() {
+		if err != nil {
+			recordSpanError(span, err)
+		}
+		span.End()
+	}()
+
 	if c.ExecutionStages.OptionalStages != nil && c.ExecutionStages.OptionalStages.NormalizerStage != nil {
-		err = c.ExecutionStages.OptionalStages.NormalizerStage.Normalize(operation)
+		err = c.traceStage(ctx, normalizeSpanName, func() error {
+			return c.ExecutionStages.OptionalStages.NormalizerStage.Normalize(operation)
+		})
 		if err != nil {
 			return err
 		}
 	}
 
+	if operationType, err := operation.OperationType(); err == nil {
+		span.SetAttributes(AttributeOperationType.String(operationTypeName(operationType)))
+	}
+
 	if c.ExecutionStages.OptionalStages != nil && c.ExecutionStages.OptionalStages.ValidatorStage != nil {
-		err = c.ExecutionStages.OptionalStages.ValidatorStage.ValidateForSchema(operation)
+		err = c.traceStage(ctx, validateSpanName, func() error {
+			return c.ExecutionStages.OptionalStages.ValidatorStage.ValidateForSchema(operation)
+		})
 		if err != nil {
 			return err
 		}
 	}
 
 	if c.ExecutionStages.OptionalStages != nil && c.ExecutionStages.OptionalStages.InputValidationStage != nil {
-		if err := c.ExecutionStages.OptionalStages.InputValidationStage.InputValidation(operation); err != nil {
+		err = c.traceStage(ctx, inputValidationSpanName, func() error {
+			return c.ExecutionStages.OptionalStages.InputValidationStage.InputValidation(operation)
+		})
+		if err != nil {
 			return err
 		}
 	}
@@ -134,12 +166,28 @@ func (c *CustomExecutionEngineV2Executor) Execute(ctx context.Context, operation
 	execContext.prepare(ctx, operation.Variables, operation.request)
 	c.ExecutionStages.RequiredStages.ResolverStage.Setup(ctx, execContext.postProcessor, execContext.resolveContext, operation, options...)
 
-	var report operationreport.Report
-	planResult, err := c.ExecutionStages.RequiredStages.ResolverStage.Plan(execContext.postProcessor, operation, &report)
+	if c.ExecutionStages.OptionalStages != nil && c.ExecutionStages.OptionalStages.RateLimiterStage != nil && !rateLimitChecked(ctx) {
+		err = c.traceStage(ctx, rateLimitSpanName, func() error {
+			return c.ExecutionStages.OptionalStages.RateLimiterStage.RateLimit(operation, execContext.resolveContext)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var planResult plan.Plan
+	err = c.traceStage(ctx, planSpanName, func() error {
+		var report operationreport.Report
+		planResult, err = c.ExecutionStages.RequiredStages.ResolverStage.Plan(execContext.postProcessor, operation, &report)
+		if err != nil {
+			return err
+		} else if report.HasErrors() {
+			return report
+		}
+		return nil
+	})
 	if err != nil {
 		return err
-	} else if report.HasErrors() {
-		return report
 	}
 
 	err = c.ExecutionStages.RequiredStages.ResolverStage.Resolve(execContext.resolveContext, planResult, writer)
@@ -151,6 +199,29 @@ func (c *CustomExecutionEngineV2Executor) Execute(ctx context.Context, operation
 	return nil
 }
 
+type rateLimitCheckedKey struct{}
+
+// withRateLimitChecked marks the operation as charged already, e.g. by the response cache
+func withRateLimitChecked(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rateLimitCheckedKey{}, true)
+}
+
+func rateLimitChecked(ctx context.Context) bool {
+	checked, _ := ctx.Value(rateLimitCheckedKey{}).(bool)
+	return checked
+}
+
+// traceStage runs a stage of the execution in a child span of the operation span
+func (c *CustomExecutionEngineV2Executor) traceStage(ctx context.Context, name string, stage func() error) error {
+	_, span := c.tracer.Start(ctx, name)
+	defer span.End()
+	err := stage()
+	if err != nil {
+		recordSpanError(span, err)
+	}
+	return err
+}
+
 // Interface Guards
 var (
 	_ ExecutionEngineV2Executor = (*CustomExecutionEngineV2Executor)(nil)
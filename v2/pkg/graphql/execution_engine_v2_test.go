@@ -5,6 +5,7 @@ import (
 	"compress/flate"
 	"compress/gzip"
 	"context"
+	"encoding/base64"
 	"fmt"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/rest_datasource"
 	"io"
@@ -12,11 +13,18 @@ import (
 	"net/http/httptest"
 	"sync"
 	"testing"
+	"time"
 
+	apollotrace "github.com/99designs/gqlgen/graphql/handler/apollofederatedtracingv1/generated"
 	"github.com/andybalholm/brotli"
 	"github.com/jensneuse/abstractlogger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/graphql_datasource"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/httpclient"
@@ -24,6 +32,7 @@ import (
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/postprocess"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/metrics"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/operationreport"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/starwars"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/testing/federationtesting"
@@ -1316,6 +1325,277 @@ func testNetHttpClient(t *testing.T, testCase roundTripperTestCase) *http.Client
 	}
 }
 
+func TestExecutionEngineV2_ErrorPolicy(t *testing.T) {
+	newEngine := func(t *testing.T, policy *resolve.ErrorPolicy) *ExecutionEngineV2 {
+		engineConf := NewEngineV2Configuration(starwarsSchema(t))
+		engineConf.SetErrorPolicy(policy)
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		engine, err := NewExecutionEngineV2(ctx, abstractlogger.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
+
+	execute := func(engine *ExecutionEngineV2, query string) error {
+		writer := NewEngineResultWriter()
+		return engine.Execute(context.Background(), &Request{Query: query}, &writer)
+	}
+
+	t.Run("errors get codes if a policy is configured", func(t *testing.T) {
+		engine := newEngine(t, &resolve.ErrorPolicy{})
+
+		requestErrors := RequestErrorsFromError(execute(engine, "{ hero { "))
+		require.Len(t, requestErrors, 1)
+		assert.Equal(t, &RequestErrorExtensions{Code: ErrorCodeGraphQLParseFailed}, requestErrors[0].Extensions)
+
+		requestErrors = RequestErrorsFromError(execute(engine, "{ villain { name } }"))
+		require.Len(t, requestErrors, 1)
+		assert.Equal(t, &RequestErrorExtensions{Code: ErrorCodeGraphQLValidationFailed}, requestErrors[0].Extensions)
+	})
+
+	t.Run("errors have no codes without policy", func(t *testing.T) {
+		engine := newEngine(t, nil)
+
+		requestErrors := RequestErrorsFromError(execute(engine, "{ villain { name } }"))
+		require.Len(t, requestErrors, 1)
+		assert.Nil(t, requestErrors[0].Extensions)
+	})
+}
+
+func TestExecutionEngineV2_Tracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer func() {
+		_ = provider.Shutdown(context.Background())
+	}()
+
+	schemaSDL := `type Query { hello: String }`
+	schema, err := NewSchemaFromString(schemaSDL)
+	require.NoError(t, err)
+
+	var traceParent string
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetTracerProvider(provider)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			ID: "hello",
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"hello"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						traceParent = req.Header.Get("traceparent")
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(bytes.NewBufferString(`{"data":{"hello":"world"}}`)),
+						}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://example.com/",
+					Method: "POST",
+				},
+				UpstreamSchema: schemaSDL,
+			}),
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine, err := NewExecutionEngineV2(ctx, abstractlogger.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	t.Run("operation", func(t *testing.T) {
+		exporter.Reset()
+		writer := NewEngineResultWriter()
+		err = engine.Execute(context.Background(), &Request{OperationName: "Hello", Query: `query Hello { hello }`}, &writer)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"world"}}`, writer.String())
+
+		spans := exporter.GetSpans()
+		spanNames := make([]string, 0, len(spans))
+		for _, span := range spans {
+			spanNames = append(spanNames, span.Name)
+		}
+		assert.Equal(t, []string{"Normalize", "Validate", "Plan", "Fetch", "GraphQL Operation"}, spanNames)
+
+		operation := spans[len(spans)-1]
+		assert.ElementsMatch(t, []attribute.KeyValue{
+			AttributeOperationName.String("Hello"),
+			AttributeOperationType.String("query"),
+		}, operation.Attributes)
+		for _, span := range spans[:len(spans)-1] {
+			assert.Equal(t, operation.SpanContext.SpanID(), span.Parent.SpanID(), span.Name)
+		}
+
+		fetch := spans[3]
+		assert.Equal(t, fmt.Sprintf("00-%s-%s-01", fetch.SpanContext.TraceID(), fetch.SpanContext.SpanID()), traceParent)
+	})
+
+	t.Run("failing stage", func(t *testing.T) {
+		exporter.Reset()
+		writer := NewEngineResultWriter()
+		err = engine.Execute(context.Background(), &Request{Query: `{ goodbye }`}, &writer)
+		require.Error(t, err)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "Normalize", spans[0].Name)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Equal(t, "GraphQL Operation", spans[1].Name)
+		assert.Equal(t, codes.Error, spans[1].Status.Code)
+		assert.Empty(t, spans[1].Attributes)
+	})
+}
+
+type metricsRecorder struct {
+	metrics.Noop
+	mu               sync.Mutex
+	operations       []string
+	planCacheLookups []bool
+	fetches          []string
+}
+
+func (m *metricsRecorder) OperationCompleted(operationType, operationName string, _ time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.operations = append(m.operations, fmt.Sprintf("%s:%s:%t", operationType, operationName, failed))
+}
+
+func (m *metricsRecorder) PlanCacheLookup(hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.planCacheLookups = append(m.planCacheLookups, hit)
+}
+
+func (m *metricsRecorder) FetchCompleted(dataSourceID string, _ time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fetches = append(m.fetches, fmt.Sprintf("%s:%t", dataSourceID, failed))
+}
+
+func TestExecutionEngineV2_Metrics(t *testing.T) {
+	schemaSDL := `type Query { hello: String }`
+	schema, err := NewSchemaFromString(schemaSDL)
+	require.NoError(t, err)
+
+	recorder := &metricsRecorder{}
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetMetrics(recorder)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			ID: "hello",
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"hello"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(bytes.NewBufferString(`{"data":{"hello":"world"}}`)),
+						}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://example.com/",
+					Method: "POST",
+				},
+				UpstreamSchema: schemaSDL,
+			}),
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine, err := NewExecutionEngineV2(ctx, abstractlogger.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		writer := NewEngineResultWriter()
+		err = engine.Execute(context.Background(), &Request{OperationName: "Hello", Query: `query Hello { hello }`}, &writer)
+		require.NoError(t, err)
+	}
+	writer := NewEngineResultWriter()
+	err = engine.Execute(context.Background(), &Request{Query: `{ goodbye }`}, &writer)
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"query:Hello:false", "query:Hello:false", "query::true"}, recorder.operations)
+	assert.Equal(t, []bool{false, true}, recorder.planCacheLookups)
+	assert.Equal(t, []string{"hello:false", "hello:false"}, recorder.fetches)
+}
+
+type federatedTraceRecorder struct {
+	traces []*apollotrace.Trace
+}
+
+func (f *federatedTraceRecorder) OnFederatedTrace(_ *resolve.Context, trace *apollotrace.Trace) {
+	f.traces = append(f.traces, trace)
+}
+
+func TestExecutionEngineV2_FederatedTracing(t *testing.T) {
+	schemaSDL := `type Query { hello: String }`
+	schema, err := NewSchemaFromString(schemaSDL)
+	require.NoError(t, err)
+
+	subgraphTrace, err := proto.Marshal(&apollotrace.Trace{DurationNs: 42})
+	require.NoError(t, err)
+
+	var includeTraceHeader string
+	recorder := &federatedTraceRecorder{}
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetFederatedTraceHook(recorder)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			ID: "hello",
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"hello"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						includeTraceHeader = req.Header.Get(graphql_datasource.FederatedTracingHeader)
+						body := fmt.Sprintf(`{"data":{"hello":"world"},"extensions":{"ftv1":%q}}`, base64.StdEncoding.EncodeToString(subgraphTrace))
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(bytes.NewBufferString(body)),
+						}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://example.com/",
+					Method: "POST",
+				},
+				UpstreamSchema: schemaSDL,
+			}),
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine, err := NewExecutionEngineV2(ctx, abstractlogger.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	writer := NewEngineResultWriter()
+	err = engine.Execute(context.Background(), &Request{Query: `{ hello }`}, &writer)
+	require.NoError(t, err)
+	assert.Equal(t, `{"data":{"hello":"world"}}`, writer.String())
+	assert.Equal(t, "ftv1", includeTraceHeader)
+
+	require.Len(t, recorder.traces, 1)
+	fetch := recorder.traces[0].GetQueryPlan().GetFetch()
+	require.NotNil(t, fetch)
+	assert.Equal(t, "hello", fetch.ServiceName)
+	assert.Equal(t, uint64(42), fetch.GetTrace().GetDurationNs())
+}
+
 func TestExecutionEngineV2_GetCachedPlan(t *testing.T) {
 	schema, err := NewSchemaFromString(testSubscriptionDefinition)
 	require.NoError(t, err)
@@ -0,0 +1,215 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astprinter"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/pubsub_datasource"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/pool"
+)
+
+// LiveDirectiveSDL contains the definition of the @live directive.
+// It has to be part of the schema to execute live queries, their results are streamed over websockets.
+const LiveDirectiveSDL = `directive @live(patch: Boolean) on QUERY`
+
+var (
+	liveDirectiveName = "live"
+	livePatchArgument = []byte("patch")
+
+	// liveQueryTopicArgument matches the arguments in invalidation topics, it's the syntax of the pubsub data source
+	liveQueryTopicArgument = regexp.MustCompile(`{{ args.([a-zA-Z0-9_]+) }}`)
+)
+
+var ErrLiveQueriesDisabled = errors.New("live queries are not enabled")
+
+// LiveQueryConfiguration configures the execution of queries marked with @live.
+// A live query is re-executed on the poll interval and on every invalidation event of its root fields,
+// its result is sent to the client whenever it changed. With @live(patch: true) the client receives JSON patches
+// against the previous result instead of complete results.
+type LiveQueryConfiguration struct {
+	// PollInterval re-executes live queries periodically, polling is disabled if it is 0
+	PollInterval time.Duration
+	// Invalidations are the events invalidating the results of root fields
+	Invalidations []LiveQueryInvalidationConfiguration
+}
+
+// LiveQueryInvalidationConfiguration re-executes the live queries selecting a root field on every event published on the topic.
+// The topic can contain arguments of the field, e.g. "products.{{ args.id }}", so that only the live queries of an entity are invalidated.
+type LiveQueryInvalidationConfiguration struct {
+	// TypeName is the name of the query type, e.g. Query
+	TypeName  string
+	FieldName string
+	Topic     string
+	PubSub    pubsub_datasource.PubSub
+}
+
+// IsLiveQuery returns true if the operation is a query marked with @live
+func (r *Request) IsLiveQuery() (bool, error) {
+	ref, err := r.operationDefinitionRef()
+	if err != nil || ref == ast.InvalidRef {
+		return false, err
+	}
+	operation := r.document.OperationDefinitions[ref]
+	return operation.OperationType == ast.OperationTypeQuery && operation.Directives.HasDirectiveByName(&r.document, liveDirectiveName), nil
+}
+
+// operationDefinitionRef returns the operation to execute or ast.InvalidRef if there is none
+func (r *Request) operationDefinitionRef() (int, error) {
+	report := r.parseQueryOnce()
+	if report.HasErrors() {
+		return ast.InvalidRef, report
+	}
+
+	for _, rootNode := range r.document.RootNodes {
+		if rootNode.Kind != ast.NodeKindOperationDefinition {
+			continue
+		}
+		if r.OperationName != "" && r.document.OperationDefinitionNameString(rootNode.Ref) != r.OperationName {
+			continue
+		}
+		return rootNode.Ref, nil
+	}
+	return ast.InvalidRef, nil
+}
+
+// liveQueryPatch returns the patch argument of the @live directive, it might have been extracted into a variable by the normalization
+func (r *Request) liveQueryPatch(operationRef int) bool {
+	for _, directiveRef := range r.document.OperationDefinitions[operationRef].Directives.Refs {
+		if r.document.DirectiveNameString(directiveRef) != liveDirectiveName {
+			continue
+		}
+		value, ok := r.document.DirectiveArgumentValueByName(directiveRef, livePatchArgument)
+		if !ok {
+			return false
+		}
+		switch value.Kind {
+		case ast.ValueKindBoolean:
+			return bool(r.document.BooleanValue(value.Ref))
+		case ast.ValueKindVariable:
+			patch, err := jsonparser.GetBoolean(r.Variables, r.document.VariableValueNameString(value.Ref))
+			return err == nil && patch
+		}
+	}
+	return false
+}
+
+// liveQueryPlan streams the results of the planned query, the plan is created per operation because the invalidation topics depend on its variables
+func (e *ExecutionEngineV2) liveQueryPlan(operation *Request, cachedPlan plan.Plan) (plan.Plan, error) {
+	if e.config.liveQueries == nil {
+		return nil, ErrLiveQueriesDisabled
+	}
+	queryPlan, ok := cachedPlan.(*plan.SynchronousResponsePlan)
+	if !ok {
+		return nil, errors.New("live query is not a query")
+	}
+	operationRef, err := operation.operationDefinitionRef()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := pool.Hash64.Get()
+	hash.Reset()
+	defer pool.Hash64.Put(hash)
+	if err = astprinter.Print(&operation.document, &e.config.schema.document, hash); err != nil {
+		return nil, err
+	}
+
+	invalidations, err := e.liveQueryInvalidations(operation, operationRef)
+	if err != nil {
+		return nil, err
+	}
+
+	return &plan.LiveQueryResponsePlan{
+		Response: &resolve.GraphQLLiveQuery{
+			Response:      queryPlan.Response,
+			Input:         strconv.AppendUint(nil, hash.Sum64(), 10),
+			PollInterval:  e.config.liveQueries.PollInterval,
+			Invalidations: invalidations,
+			Patch:         operation.liveQueryPatch(operationRef),
+		},
+		FlushInterval: queryPlan.FlushInterval,
+	}, nil
+}
+
+// liveQueryInvalidations returns the invalidations of the root fields of the operation.
+// Invalidations with a topic referencing an argument which isn't set by the operation are skipped.
+func (e *ExecutionEngineV2) liveQueryInvalidations(operation *Request, operationRef int) ([]resolve.LiveQueryInvalidation, error) {
+	document := &operation.document
+	queryTypeName := e.config.schema.QueryTypeName()
+	var invalidations []resolve.LiveQueryInvalidation
+	for _, selectionRef := range document.SelectionSets[document.OperationDefinitions[operationRef].SelectionSet].SelectionRefs {
+		selection := document.Selections[selectionRef]
+		if selection.Kind != ast.SelectionKindField {
+			continue
+		}
+		fieldName := document.FieldNameString(selection.Ref)
+		for _, config := range e.config.liveQueries.Invalidations {
+			if config.TypeName != queryTypeName || config.FieldName != fieldName {
+				continue
+			}
+			topic, ok := renderLiveQueryTopic(operation, selection.Ref, config.Topic)
+			if !ok {
+				continue
+			}
+			input, err := json.Marshal(struct {
+				Topic string `json:"topic"`
+			}{Topic: topic})
+			if err != nil {
+				return nil, err
+			}
+			invalidations = append(invalidations, resolve.LiveQueryInvalidation{
+				Source: pubsub_datasource.NewSubscriptionSource(config.PubSub),
+				Input:  input,
+			})
+		}
+	}
+	return invalidations, nil
+}
+
+// renderLiveQueryTopic replaces the arguments in the topic with the values of the field arguments
+func renderLiveQueryTopic(operation *Request, fieldRef int, topic string) (string, bool) {
+	rendered := true
+	result := liveQueryTopicArgument.ReplaceAllStringFunc(topic, func(match string) string {
+		value, ok := fieldArgumentValue(operation, fieldRef, liveQueryTopicArgument.FindStringSubmatch(match)[1])
+		rendered = rendered && ok
+		return value
+	})
+	return result, rendered
+}
+
+func fieldArgumentValue(operation *Request, fieldRef int, name string) (string, bool) {
+	document := &operation.document
+	argumentRef, ok := document.FieldArgument(fieldRef, []byte(name))
+	if !ok {
+		return "", false
+	}
+	value := document.ArgumentValue(argumentRef)
+	switch value.Kind {
+	case ast.ValueKindVariable:
+		data, dataType, _, err := jsonparser.Get(operation.Variables, document.VariableValueNameString(value.Ref))
+		if err != nil || dataType == jsonparser.Null {
+			return "", false
+		}
+		if dataType == jsonparser.String {
+			str, err := jsonparser.ParseString(data)
+			return str, err == nil
+		}
+		return string(data), true
+	case ast.ValueKindString:
+		return document.StringValueContentString(value.Ref), true
+	case ast.ValueKindNull:
+		return "", false
+	default:
+		data, err := document.PrintValueBytes(value, nil)
+		return string(data), err == nil
+	}
+}
@@ -0,0 +1,201 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jensneuse/abstractlogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/graphql_datasource"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+)
+
+func TestRequest_IsLiveQuery(t *testing.T) {
+	isLiveQuery := func(query, operationName string) bool {
+		request := Request{Query: query, OperationName: operationName}
+		isLive, err := request.IsLiveQuery()
+		require.NoError(t, err)
+		return isLive
+	}
+
+	assert.True(t, isLiveQuery(`query @live { hello }`, ""))
+	assert.False(t, isLiveQuery(`query { hello }`, ""))
+	assert.False(t, isLiveQuery(`subscription @live { hello }`, ""))
+	assert.True(t, isLiveQuery(`query A { hello } query B @live { hello }`, "B"))
+	assert.False(t, isLiveQuery(`query A { hello } query B @live { hello }`, "A"))
+}
+
+// livePubSub records the subscribed topics and publishes invalidation events to them
+type livePubSub struct {
+	mux      sync.Mutex
+	updaters map[string]resolve.SubscriptionUpdater
+}
+
+func (l *livePubSub) ID() string {
+	return "live"
+}
+
+func (l *livePubSub) Subscribe(ctx context.Context, topic string, updater resolve.SubscriptionUpdater) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.updaters[topic] = updater
+	return nil
+}
+
+func (l *livePubSub) Publish(ctx context.Context, topic string, data []byte) error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if updater, ok := l.updaters[topic]; ok {
+		updater.Update(data)
+	}
+	return nil
+}
+
+func (l *livePubSub) Request(ctx context.Context, topic string, data []byte, w io.Writer) error {
+	return nil
+}
+
+func (l *livePubSub) topics() []string {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	topics := make([]string, 0, len(l.updaters))
+	for topic := range l.updaters {
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+func TestExecutionEngineV2_LiveQuery(t *testing.T) {
+	upstreamSDL := `type Query { product(id: ID!): String }`
+
+	newEngine := func(t *testing.T, ctx context.Context, name *atomic.String, liveQueries *LiveQueryConfiguration) *ExecutionEngineV2 {
+		schema, err := NewSchemaFromString(LiveDirectiveSDL + "\n" + upstreamSDL)
+		require.NoError(t, err)
+
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				ID: "products",
+				RootNodes: []plan.TypeField{
+					{TypeName: "Query", FieldNames: []string{"product"}},
+				},
+				Factory: &graphql_datasource.Factory{
+					HTTPClient: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Body:       io.NopCloser(bytes.NewBufferString(`{"data":{"product":"` + name.Load() + `"}}`)),
+							}
+						}),
+					},
+				},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch: graphql_datasource.FetchConfiguration{
+						URL:    "https://example.com/",
+						Method: "POST",
+					},
+					UpstreamSchema: upstreamSDL,
+				}),
+			},
+		})
+		engineConf.AddFieldConfiguration(plan.FieldConfiguration{
+			TypeName:  "Query",
+			FieldName: "product",
+			Arguments: []plan.ArgumentConfiguration{
+				{Name: "id", SourceType: plan.FieldArgumentSource},
+			},
+		})
+		if liveQueries != nil {
+			engineConf.SetLiveQueries(*liveQueries)
+		}
+
+		engine, err := NewExecutionEngineV2(ctx, abstractlogger.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
+
+	execute := func(t *testing.T, engine *ExecutionEngineV2, query string) (messages <-chan string, cancel context.CancelFunc, done <-chan error) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := make(chan string, 10)
+		writer := NewEngineResultWriter()
+		writer.SetFlushCallback(func(data []byte) {
+			ch <- string(data)
+		})
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- engine.Execute(ctx, &Request{Query: query, Variables: []byte(`{"id":"1"}`)}, &writer)
+		}()
+		return ch, cancel, errCh
+	}
+
+	awaitMessage := func(t *testing.T, messages <-chan string) string {
+		t.Helper()
+		select {
+		case message := <-messages:
+			return message
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for message")
+			return ""
+		}
+	}
+
+	t.Run("results are sent when the entity is invalidated", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		name := atomic.NewString("Table")
+		pubSub := &livePubSub{updaters: map[string]resolve.SubscriptionUpdater{}}
+		engine := newEngine(t, ctx, name, &LiveQueryConfiguration{
+			Invalidations: []LiveQueryInvalidationConfiguration{
+				{TypeName: "Query", FieldName: "product", Topic: "products.{{ args.id }}", PubSub: pubSub},
+			},
+		})
+
+		messages, stop, done := execute(t, engine, `query ($id: ID!) @live { product(id: $id) }`)
+		assert.Equal(t, `{"data":{"product":"Table"}}`, awaitMessage(t, messages))
+		assert.Equal(t, []string{"products.1"}, pubSub.topics())
+
+		name.Store("Chair")
+		require.NoError(t, pubSub.Publish(ctx, "products.1", []byte(`{}`)))
+		assert.Equal(t, `{"data":{"product":"Chair"}}`, awaitMessage(t, messages))
+
+		stop()
+		require.NoError(t, <-done)
+	})
+
+	t.Run("patches are sent after the first result", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		name := atomic.NewString("Table")
+		engine := newEngine(t, ctx, name, &LiveQueryConfiguration{PollInterval: time.Millisecond})
+
+		messages, stop, done := execute(t, engine, `query ($id: ID!) @live(patch: true) { product(id: $id) }`)
+		assert.Equal(t, `{"data":{"product":"Table"}}`, awaitMessage(t, messages))
+
+		name.Store("Chair")
+		assert.Equal(t, `{"patch":[{"op":"replace","path":"/data/product","value":"Chair"}]}`, awaitMessage(t, messages))
+
+		stop()
+		require.NoError(t, <-done)
+	})
+
+	t.Run("live queries are disabled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		engine := newEngine(t, ctx, atomic.NewString("Table"), nil)
+		_, stop, done := execute(t, engine, `query ($id: ID!) @live { product(id: $id) }`)
+		defer stop()
+		assert.ErrorIs(t, <-done, ErrLiveQueriesDisabled)
+	})
+}
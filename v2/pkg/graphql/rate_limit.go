@@ -0,0 +1,171 @@
+package graphql
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astvisitor"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/middleware/operation_complexity"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/operationreport"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ratelimit"
+)
+
+// ErrorCodeRateLimited is the code of errors returned for operations exceeding the rate limit
+const ErrorCodeRateLimited = "RATE_LIMITED"
+
+// RateLimitExceededError is returned for operations exceeding the rate limit of their client.
+type RateLimitExceededError struct {
+	// RetryAfter is the time until the operation would be allowed, it is zero if the cost of the operation exceeds the burst of the limit
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitExceededError) Error() string {
+	if e.RetryAfter == 0 {
+		return "rate limit exceeded"
+	}
+	return fmt.Sprintf("rate limit exceeded, retry after %d seconds", e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds returns RetryAfter rounded up to whole seconds as used by the Retry-After header
+func (e *RateLimitExceededError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// RequestErrors returns the error shown to the client, the extensions contain the code RATE_LIMITED and the retry after seconds
+func (e *RateLimitExceededError) RequestErrors() RequestErrors {
+	return RequestErrors{
+		{
+			Message: e.Error(),
+			Extensions: &RequestErrorExtensions{
+				Code:       ErrorCodeRateLimited,
+				RetryAfter: e.RetryAfterSeconds(),
+			},
+		},
+	}
+}
+
+// RateLimiterOptions configures a RateLimiter.
+type RateLimiterOptions struct {
+	// Limit is the token bucket of every client, the cost of an operation is taken from the bucket of its client
+	Limit ratelimit.Limit
+	// Store holds the buckets of the clients, defaults to a ratelimit.InMemoryStore.
+	// Use a distributed store to share the limits between multiple instances.
+	Store ratelimit.Store
+	// ClientKeyHeader is the request header identifying the client, e.g. an API key
+	ClientKeyHeader string
+	// ClientKey identifies the client of an operation, it takes precedence over ClientKeyHeader.
+	// If neither is set, clients are identified by the subject of their claims.
+	// Operations of clients without key share a single bucket.
+	ClientKey func(operation *Request, resolveContext *resolve.Context) string
+	// FieldWeights are the costs of fields by their coordinate, e.g. "Query.search".
+	// If set, the cost of an operation is the sum of the weights of all selected fields,
+	// otherwise the cost is the complexity calculated by operation_complexity.
+	FieldWeights map[string]int
+	// DefaultFieldWeight is the cost of fields without weight in FieldWeights
+	DefaultFieldWeight int
+}
+
+// RateLimiter is a CustomExecutionEngineV2RateLimiterStage charging the cost of every operation to the token bucket of its client.
+// Every operation costs at least one token.
+type RateLimiter struct {
+	schema  *Schema
+	options RateLimiterOptions
+}
+
+// NewRateLimiter creates a rate limiter for operations of the schema.
+func NewRateLimiter(schema *Schema, options RateLimiterOptions) *RateLimiter {
+	if options.Store == nil {
+		options.Store = ratelimit.NewInMemoryStore()
+	}
+	return &RateLimiter{
+		schema:  schema,
+		options: options,
+	}
+}
+
+func (r *RateLimiter) RateLimit(operation *Request, resolveContext *resolve.Context) error {
+	cost, err := r.Cost(operation)
+	if err != nil {
+		return err
+	}
+	result, err := r.options.Store.Take(resolveContext.Context(), r.clientKey(operation, resolveContext), cost, r.options.Limit)
+	if err != nil {
+		return err
+	}
+	if !result.Allowed {
+		return &RateLimitExceededError{RetryAfter: result.RetryAfter}
+	}
+	return nil
+}
+
+// Cost returns the number of tokens charged for the normalized operation
+func (r *RateLimiter) Cost(operation *Request) (int, error) {
+	report := operationreport.Report{}
+	cost := 0
+	if r.options.FieldWeights != nil {
+		cost = r.fieldWeightsCost(&operation.document, &report)
+	} else {
+		stats, _ := operation_complexity.CalculateOperationComplexity(&operation.document, &r.schema.document, &report)
+		cost = stats.Complexity
+	}
+	if report.HasErrors() {
+		return 0, report
+	}
+	return max(cost, 1), nil
+}
+
+func (r *RateLimiter) fieldWeightsCost(operation *ast.Document, report *operationreport.Report) int {
+	walker := astvisitor.NewWalker(48)
+	visitor := &fieldWeightsVisitor{
+		Walker:        &walker,
+		operation:     operation,
+		definition:    &r.schema.document,
+		weights:       r.options.FieldWeights,
+		defaultWeight: r.options.DefaultFieldWeight,
+	}
+	walker.RegisterEnterFieldVisitor(visitor)
+	walker.Walk(operation, &r.schema.document, report)
+	return visitor.cost
+}
+
+func (r *RateLimiter) clientKey(operation *Request, resolveContext *resolve.Context) string {
+	switch {
+	case r.options.ClientKey != nil:
+		return r.options.ClientKey(operation, resolveContext)
+	case r.options.ClientKeyHeader != "":
+		return operation.request.Header.Get(r.options.ClientKeyHeader)
+	case resolveContext.Claims() != nil:
+		return resolveContext.Claims().Subject
+	}
+	return ""
+}
+
+// fieldWeightsVisitor sums up the weights of all selected fields, __typename is free
+type fieldWeightsVisitor struct {
+	*astvisitor.Walker
+	operation, definition *ast.Document
+	weights               map[string]int
+	defaultWeight         int
+	cost                  int
+}
+
+func (v *fieldWeightsVisitor) EnterField(ref int) {
+	fieldName := v.operation.FieldNameString(ref)
+	if fieldName == "__typename" {
+		return
+	}
+	coordinate := v.definition.NodeNameString(v.EnclosingTypeDefinition) + "." + fieldName
+	if weight, ok := v.weights[coordinate]; ok {
+		v.cost += weight
+		return
+	}
+	v.cost += v.defaultWeight
+}
+
+// Interface Guards
+var (
+	_ CustomExecutionEngineV2RateLimiterStage = (*RateLimiter)(nil)
+)
@@ -0,0 +1,188 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jensneuse/abstractlogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/graphql_datasource"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ratelimit"
+)
+
+const rateLimitTestSchema = `
+schema { query: Query }
+type Query {
+	products(first: Int): [Product]
+	search(term: String): [Product]
+}
+type Product {
+	name: String
+	reviews: [Review]
+}
+type Review {
+	body: String
+}
+`
+
+func TestRateLimiter(t *testing.T) {
+	schema, err := NewSchemaFromString(rateLimitTestSchema)
+	require.NoError(t, err)
+
+	normalizedRequest := func(t *testing.T, query string) *Request {
+		t.Helper()
+		request := &Request{Query: query}
+		result, err := request.Normalize(schema)
+		require.NoError(t, err)
+		require.True(t, result.Successful)
+		return request
+	}
+
+	t.Run("cost by field weights", func(t *testing.T) {
+		limiter := NewRateLimiter(schema, RateLimiterOptions{
+			FieldWeights: map[string]int{
+				"Query.search":    10,
+				"Product.reviews": 5,
+			},
+			DefaultFieldWeight: 1,
+		})
+
+		cost, err := limiter.Cost(normalizedRequest(t, `{ search(term: "table") { __typename name reviews { body } } }`))
+		require.NoError(t, err)
+		assert.Equal(t, 17, cost)
+
+		cost, err = limiter.Cost(normalizedRequest(t, `{ products { ... on Product { name } } }`))
+		require.NoError(t, err)
+		assert.Equal(t, 2, cost)
+	})
+
+	t.Run("cost by complexity", func(t *testing.T) {
+		limiter := NewRateLimiter(schema, RateLimiterOptions{})
+		request := normalizedRequest(t, `{ products { name reviews { body } } }`)
+
+		complexity, err := request.CalculateComplexity(DefaultComplexityCalculator, schema)
+		require.NoError(t, err)
+		cost, err := limiter.Cost(request)
+		require.NoError(t, err)
+		assert.Equal(t, complexity.Complexity, cost)
+		assert.Greater(t, cost, 1)
+	})
+
+	t.Run("every operation costs at least one token", func(t *testing.T) {
+		limiter := NewRateLimiter(schema, RateLimiterOptions{FieldWeights: map[string]int{}})
+		cost, err := limiter.Cost(normalizedRequest(t, `{ products { name } }`))
+		require.NoError(t, err)
+		assert.Equal(t, 1, cost)
+	})
+
+	t.Run("client keys", func(t *testing.T) {
+		rateLimit := func(limiter *RateLimiter, header http.Header, claims *resolve.Claims) error {
+			request := normalizedRequest(t, `{ products { name } }`)
+			request.SetHeader(header)
+			return limiter.RateLimit(request, resolve.NewContext(context.Background()).WithClaims(claims))
+		}
+		limit := ratelimit.Limit{Rate: 0.001, Burst: 1}
+
+		limiter := NewRateLimiter(schema, RateLimiterOptions{Limit: limit, ClientKeyHeader: "X-Api-Key", FieldWeights: map[string]int{}})
+		assert.NoError(t, rateLimit(limiter, http.Header{"X-Api-Key": []string{"a"}}, nil))
+		assert.NoError(t, rateLimit(limiter, http.Header{"X-Api-Key": []string{"b"}}, nil))
+		assert.IsType(t, &RateLimitExceededError{}, rateLimit(limiter, http.Header{"X-Api-Key": []string{"a"}}, nil))
+
+		limiter = NewRateLimiter(schema, RateLimiterOptions{Limit: limit, FieldWeights: map[string]int{}})
+		assert.NoError(t, rateLimit(limiter, nil, &resolve.Claims{Subject: "jane"}))
+		assert.NoError(t, rateLimit(limiter, nil, &resolve.Claims{Subject: "john"}))
+		assert.IsType(t, &RateLimitExceededError{}, rateLimit(limiter, nil, &resolve.Claims{Subject: "jane"}))
+
+		limiter = NewRateLimiter(schema, RateLimiterOptions{
+			Limit:        limit,
+			FieldWeights: map[string]int{},
+			ClientKey: func(operation *Request, resolveContext *resolve.Context) string {
+				return "shared"
+			},
+		})
+		assert.NoError(t, rateLimit(limiter, nil, &resolve.Claims{Subject: "jane"}))
+		assert.IsType(t, &RateLimitExceededError{}, rateLimit(limiter, nil, &resolve.Claims{Subject: "john"}))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		err := &RateLimitExceededError{RetryAfter: 1500 * time.Millisecond}
+		assert.Equal(t, "rate limit exceeded, retry after 2 seconds", err.Error())
+
+		buf := &bytes.Buffer{}
+		_, writeErr := RequestErrorsFromError(err).WriteResponse(buf)
+		require.NoError(t, writeErr)
+		assert.Equal(t, `{"errors":[{"message":"rate limit exceeded, retry after 2 seconds","extensions":{"code":"RATE_LIMITED","retryAfter":2}}],"data":null}`, buf.String())
+	})
+}
+
+func TestExecutionEngineV2_RateLimiter(t *testing.T) {
+	schema, err := NewSchemaFromString(rateLimitTestSchema)
+	require.NoError(t, err)
+
+	calls := atomic.NewInt64(0)
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetRateLimiter(NewRateLimiter(schema, RateLimiterOptions{
+		Limit:        ratelimit.Limit{Rate: 0.5, Burst: 10},
+		FieldWeights: map[string]int{"Query.search": 8},
+	}))
+	engineConf.SetFieldConfigurations(newGraphQLFieldConfigsV2Generator(schema).Generate())
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			ID: "products",
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"products", "search"}},
+			},
+			ChildNodes: []plan.TypeField{
+				{TypeName: "Product", FieldNames: []string{"name", "reviews"}},
+				{TypeName: "Review", FieldNames: []string{"body"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						calls.Inc()
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(bytes.NewBufferString(`{"data":{"search":[]}}`)),
+						}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://example.com/",
+					Method: "POST",
+				},
+				UpstreamSchema: rateLimitTestSchema,
+			}),
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine, err := NewExecutionEngineV2(ctx, abstractlogger.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	execute := func(subject string) error {
+		writer := NewEngineResultWriter()
+		return engine.Execute(context.Background(), &Request{Query: `{ search(term: "table") { name } }`}, &writer, WithClaims(&resolve.Claims{Subject: subject}))
+	}
+
+	require.NoError(t, execute("jane"))
+	err = execute("jane")
+	var rateLimitErr *RateLimitExceededError
+	require.ErrorAs(t, err, &rateLimitErr)
+	assert.Equal(t, 12, rateLimitErr.RetryAfterSeconds())
+	assert.Equal(t, int64(1), calls.Load())
+
+	require.NoError(t, execute("john"))
+	assert.Equal(t, int64(2), calls.Load())
+}
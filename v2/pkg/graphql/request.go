@@ -1,6 +1,7 @@
 package graphql
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"io"
@@ -30,6 +31,7 @@ const (
 var (
 	ErrEmptyRequest = errors.New("the provided request is empty")
 	ErrNilSchema    = errors.New("the provided schema is nil")
+	ErrEmptyBatch   = errors.New("the provided batch is empty")
 )
 
 type Request struct {
@@ -63,6 +65,52 @@ func UnmarshalHttpRequest(r *http.Request, request *Request) error {
 	return UnmarshalRequest(r.Body, request)
 }
 
+// UnmarshalBatchRequest reads either a single request or an Apollo-style batch of requests, which is a JSON array of requests.
+// isBatch reports whether the input was a batch, so that the response can be rendered accordingly.
+func UnmarshalBatchRequest(reader io.Reader) (requests []*Request, isBatch bool, err error) {
+	requestBytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, false, err
+	}
+
+	requestBytes = bytes.TrimLeft(requestBytes, " \t\r\n")
+	if len(requestBytes) == 0 {
+		return nil, false, ErrEmptyRequest
+	}
+
+	if requestBytes[0] != '[' {
+		request := &Request{}
+		if err = json.Unmarshal(requestBytes, request); err != nil {
+			return nil, false, err
+		}
+		return []*Request{request}, false, nil
+	}
+
+	if err = json.Unmarshal(requestBytes, &requests); err != nil {
+		return nil, true, err
+	}
+	if len(requests) == 0 {
+		return nil, true, ErrEmptyBatch
+	}
+	for i := range requests {
+		if requests[i] == nil {
+			return nil, true, ErrEmptyRequest
+		}
+	}
+	return requests, true, nil
+}
+
+func UnmarshalHttpBatchRequest(r *http.Request) (requests []*Request, isBatch bool, err error) {
+	requests, isBatch, err = UnmarshalBatchRequest(r.Body)
+	if err != nil {
+		return nil, isBatch, err
+	}
+	for i := range requests {
+		requests[i].request.Header = r.Header
+	}
+	return requests, isBatch, nil
+}
+
 func MarshalRequest(graphqlRequest Request) ([]byte, error) {
 	return json.Marshal(graphqlRequest)
 }
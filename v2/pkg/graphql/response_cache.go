@@ -0,0 +1,191 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/buger/jsonparser"
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astprinter"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/httpclient"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/pool"
+)
+
+// ResponseCache stores whole responses of query operations, see EngineV2Configuration.SetResponseCache.
+// Implementations must be safe for concurrent use.
+type ResponseCache interface {
+	// Get returns the response stored for the key if it's not expired.
+	Get(key uint64) (*CachedResponse, bool)
+	Set(key uint64, response *CachedResponse)
+}
+
+// CachedResponse is a response stored in a ResponseCache.
+type CachedResponse struct {
+	Body    []byte
+	Expires time.Time
+}
+
+// ResponseCacheOptions configures the response cache of the engine.
+type ResponseCacheOptions struct {
+	// VaryHeaders are the request headers which are part of the cache key, e.g. Accept-Language.
+	// Requests differing in other headers share cached responses.
+	VaryHeaders []string
+	// DefaultMaxAge is the max-age of root fields and fields returning composite types without @cacheControl hint.
+	// If not set, such fields make the response uncacheable.
+	DefaultMaxAge time.Duration
+}
+
+// CacheControlWriter can be implemented by the writer passed to ExecutionEngineV2.Execute
+// to receive the policy of a query response, e.g. to set the Cache-Control header for CDNs.
+// The policy is only computed if a response cache is configured.
+type CacheControlWriter interface {
+	SetCacheControl(policy CacheControlPolicy)
+}
+
+// InMemoryResponseCache is a ResponseCache keeping a limited number of responses in memory.
+type InMemoryResponseCache struct {
+	cache *lru.Cache
+}
+
+// NewInMemoryResponseCache creates a cache which evicts the least recently used responses if it holds more than size responses.
+func NewInMemoryResponseCache(size int) (*InMemoryResponseCache, error) {
+	cache, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &InMemoryResponseCache{
+		cache: cache,
+	}, nil
+}
+
+func (i *InMemoryResponseCache) Get(key uint64) (*CachedResponse, bool) {
+	cached, ok := i.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	response := cached.(*CachedResponse)
+	if !time.Now().Before(response.Expires) {
+		i.cache.Remove(key)
+		return nil, false
+	}
+	return response, true
+}
+
+func (i *InMemoryResponseCache) Set(key uint64, response *CachedResponse) {
+	i.cache.Add(key, response)
+}
+
+// executeWithResponseCache serves queries from the response cache and stores public responses without errors.
+// Mutations, subscriptions and live queries bypass the cache.
+func (e *ExecutionEngineV2) executeWithResponseCache(ctx context.Context, operation *Request, writer resolve.SubscriptionResponseWriter, options ...ExecutionOptionsV2) error {
+	// parse errors are ignored here, they are returned by the executor
+	operationType, err := operation.OperationType()
+	isLiveQuery, _ := operation.IsLiveQuery()
+	if err != nil || operationType != OperationTypeQuery || isLiveQuery {
+		return e.customExecutionEngineExecutor.Execute(ctx, operation, writer, options...)
+	}
+
+	if err := e.Normalize(operation); err != nil {
+		return err
+	}
+	if err := e.ValidateForSchema(operation); err != nil {
+		return err
+	}
+
+	execContext := newInternalExecutionContext()
+	defer execContext.reset()
+	execContext.prepare(ctx, operation.Variables, operation.request)
+	e.Setup(ctx, execContext.postProcessor, execContext.resolveContext, operation, options...)
+
+	// cached responses are charged like executed ones
+	if rateLimiter := e.config.rateLimiter; rateLimiter != nil {
+		if err := rateLimiter.RateLimit(operation, execContext.resolveContext); err != nil {
+			return err
+		}
+		ctx = withRateLimitChecked(ctx)
+	}
+
+	key, err := e.responseCacheKey(operation)
+	if err != nil {
+		return err
+	}
+
+	cache := e.config.responseCache
+	if cached, ok := cache.Get(key); ok {
+		setCacheControl(writer, CacheControlPolicy{MaxAge: time.Until(cached.Expires)})
+		_, err = writer.Write(cached.Body)
+		return err
+	}
+
+	policy, err := calculateCacheControlPolicy(operation, e.config.schema, e.config.FieldConfigurations(), e.config.responseCacheOptions.DefaultMaxAge)
+	if err != nil {
+		return err
+	}
+	// the key doesn't contain the claims, so responses of authenticated clients must not be shared
+	if execContext.resolveContext.Claims() != nil {
+		policy.Scope = CacheControlScopePrivate
+	}
+	collector := newCacheControlCollector(policy)
+	ctx = httpclient.WithResponseHeadersObserver(ctx, collector.observe)
+
+	resultWriter := NewEngineResultWriter()
+	if err = e.customExecutionEngineExecutor.Execute(ctx, operation, &resultWriter, options...); err != nil {
+		return err
+	}
+	policy = collector.result()
+	response := resultWriter.Bytes()
+
+	if policy.Scope == CacheControlScopePublic && policy.Cacheable() && !responseHasErrors(response) {
+		cache.Set(key, &CachedResponse{
+			Body:    append([]byte(nil), response...),
+			Expires: time.Now().Add(policy.MaxAge),
+		})
+	}
+
+	setCacheControl(writer, policy)
+	_, err = writer.Write(response)
+	return err
+}
+
+// responseCacheKey hashes the normalized operation, its variables and the values of the vary headers
+func (e *ExecutionEngineV2) responseCacheKey(operation *Request) (uint64, error) {
+	hash := pool.Hash64.Get()
+	hash.Reset()
+	defer pool.Hash64.Put(hash)
+
+	if err := astprinter.Print(&operation.document, &e.config.schema.document, hash); err != nil {
+		return 0, err
+	}
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.WriteString(operation.OperationName)
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.Write(operation.Variables)
+
+	header := operation.request.Header
+	for _, name := range e.config.responseCacheOptions.VaryHeaders {
+		_, _ = hash.Write([]byte{0})
+		_, _ = hash.WriteString(http.CanonicalHeaderKey(name))
+		for _, value := range header.Values(name) {
+			_, _ = hash.Write([]byte{0})
+			_, _ = hash.WriteString(value)
+		}
+	}
+	return hash.Sum64(), nil
+}
+
+func setCacheControl(writer resolve.SubscriptionResponseWriter, policy CacheControlPolicy) {
+	if cacheControlWriter, ok := writer.(CacheControlWriter); ok {
+		cacheControlWriter.SetCacheControl(policy)
+	}
+}
+
+func responseHasErrors(response []byte) bool {
+	hasErrors := false
+	_, _ = jsonparser.ArrayEach(response, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		hasErrors = true
+	}, "errors")
+	return hasErrors
+}
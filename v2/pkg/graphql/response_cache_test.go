@@ -0,0 +1,340 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jensneuse/abstractlogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/graphql_datasource"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ratelimit"
+)
+
+const cacheControlTestSchema = CacheControlDirectiveSDL + "\n" + AuthorizationDirectivesSDL + `
+schema { query: Query mutation: Mutation }
+type Query {
+	products: [Product] @cacheControl(maxAge: 120)
+	featured: Product
+	me: User
+	secret: String @authenticated @cacheControl(maxAge: 60)
+}
+type Mutation { addProduct(name: String): Product }
+type Product @cacheControl(maxAge: 60) {
+	name: String
+	price: Int @cacheControl(maxAge: 30)
+	reviews: [Review]
+	related: [Product] @cacheControl(inheritMaxAge: true)
+}
+type Review { body: String }
+type User @cacheControl(maxAge: 300, scope: PRIVATE) { name: String }
+`
+
+func TestCalculateCacheControlPolicy(t *testing.T) {
+	schema, err := NewSchemaFromString(cacheControlTestSchema)
+	require.NoError(t, err)
+
+	fieldConfigs := NewDirectiveAuthorizer(schema).FieldConfigurations(nil)
+	run := func(query string, defaultMaxAge time.Duration, expected CacheControlPolicy) func(t *testing.T) {
+		return func(t *testing.T) {
+			request := &Request{Query: query}
+			result, err := request.Normalize(schema)
+			require.NoError(t, err)
+			require.True(t, result.Successful)
+
+			policy, err := calculateCacheControlPolicy(request, schema, fieldConfigs, defaultMaxAge)
+			require.NoError(t, err)
+			assert.Equal(t, expected, policy)
+		}
+	}
+
+	t.Run("field hint", run(`{ products { name } }`, 0, CacheControlPolicy{MaxAge: 120 * time.Second}))
+	t.Run("lowest hint wins", run(`{ products { name price } }`, 0, CacheControlPolicy{MaxAge: 30 * time.Second}))
+	t.Run("type hint", run(`{ featured { name } }`, 0, CacheControlPolicy{MaxAge: 60 * time.Second}))
+	t.Run("inherited max-age", run(`{ products { related { name } } }`, 0, CacheControlPolicy{MaxAge: 120 * time.Second}))
+	t.Run("composite field without hint", run(`{ products { reviews { body } } }`, 0, CacheControlPolicy{MaxAge: 0}))
+	t.Run("default max-age", run(`{ products { reviews { body } } }`, 10*time.Second, CacheControlPolicy{MaxAge: 10 * time.Second}))
+	t.Run("private scope", run(`{ me { name } }`, 0, CacheControlPolicy{MaxAge: 300 * time.Second, Scope: CacheControlScopePrivate}))
+	t.Run("field with authorization rule", run(`{ products { name } secret }`, 0, CacheControlPolicy{MaxAge: 60 * time.Second, Scope: CacheControlScopePrivate}))
+	t.Run("typename", run(`{ __typename }`, 5*time.Second, CacheControlPolicy{MaxAge: 5 * time.Second}))
+}
+
+func TestCacheControlPolicy_HeaderValue(t *testing.T) {
+	assert.Equal(t, "max-age=60, public", CacheControlPolicy{MaxAge: time.Minute}.HeaderValue())
+	assert.Equal(t, "max-age=1, private", CacheControlPolicy{MaxAge: 1500 * time.Millisecond, Scope: CacheControlScopePrivate}.HeaderValue())
+	assert.Equal(t, "no-store", CacheControlPolicy{MaxAge: 500 * time.Millisecond}.HeaderValue())
+}
+
+func TestParseCacheControlHeader(t *testing.T) {
+	run := func(values []string, expectedMaxAge time.Duration, expectedHasMaxAge, expectedPrivate bool) func(t *testing.T) {
+		return func(t *testing.T) {
+			maxAge, hasMaxAge, private := parseCacheControlHeader(values)
+			assert.Equal(t, expectedMaxAge, maxAge)
+			assert.Equal(t, expectedHasMaxAge, hasMaxAge)
+			assert.Equal(t, expectedPrivate, private)
+		}
+	}
+
+	t.Run("max-age", run([]string{"public, max-age=60"}, time.Minute, true, false))
+	t.Run("s-maxage", run([]string{"max-age=60", "s-maxage=30"}, 30*time.Second, true, false))
+	t.Run("private", run([]string{"private, max-age=60"}, time.Minute, true, true))
+	t.Run("no-store", run([]string{"max-age=60, no-store"}, 0, true, false))
+	t.Run("invalid max-age", run([]string{"max-age=abc"}, 0, true, false))
+	t.Run("no restriction", run([]string{"public"}, 0, false, false))
+}
+
+func TestExecutionEngineV2_ResponseCache(t *testing.T) {
+	type upstream struct {
+		calls        *atomic.Int64
+		cacheControl string
+		response     string
+		// release blocks the upstream responses until it's closed, if set
+		release chan struct{}
+	}
+
+	newEngine := func(t *testing.T, upstream *upstream, options ResponseCacheOptions, configure ...func(engineConf *EngineV2Configuration)) *ExecutionEngineV2 {
+		schema, err := NewSchemaFromString(cacheControlTestSchema)
+		require.NoError(t, err)
+
+		cache, err := NewInMemoryResponseCache(16)
+		require.NoError(t, err)
+
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetResponseCache(cache, options)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{
+					{TypeName: "Query", FieldNames: []string{"products", "featured", "me", "secret"}},
+					{TypeName: "Mutation", FieldNames: []string{"addProduct"}},
+				},
+				ChildNodes: []plan.TypeField{
+					{TypeName: "Product", FieldNames: []string{"name", "price", "reviews", "related"}},
+					{TypeName: "User", FieldNames: []string{"name"}},
+				},
+				Factory: &graphql_datasource.Factory{
+					HTTPClient: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							upstream.calls.Inc()
+							if upstream.release != nil {
+								<-upstream.release
+							}
+							header := http.Header{}
+							if upstream.cacheControl != "" {
+								header.Set("Cache-Control", upstream.cacheControl)
+							}
+							return &http.Response{
+								StatusCode: http.StatusOK,
+								Header:     header,
+								Body:       io.NopCloser(bytes.NewBufferString(upstream.response)),
+							}
+						}),
+					},
+				},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch: graphql_datasource.FetchConfiguration{
+						URL:    "https://example.com/",
+						Method: "POST",
+					},
+					UpstreamSchema: cacheControlTestSchema,
+				}),
+			},
+		})
+		for _, c := range configure {
+			c(&engineConf)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		engine, err := NewExecutionEngineV2(ctx, abstractlogger.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
+
+	execute := func(t *testing.T, engine *ExecutionEngineV2, request *Request, options ...ExecutionOptionsV2) (string, CacheControlPolicy) {
+		t.Helper()
+		writer := NewEngineResultWriter()
+		require.NoError(t, engine.Execute(context.Background(), request, &writer, options...))
+		policy, ok := writer.CacheControl()
+		require.True(t, ok)
+		return writer.String(), policy
+	}
+
+	productsResponse := `{"data":{"products":[{"name":"Table"}]}}`
+
+	t.Run("public responses are cached with the lowest max-age", func(t *testing.T) {
+		upstream := &upstream{calls: atomic.NewInt64(0), cacheControl: "max-age=40", response: productsResponse}
+		engine := newEngine(t, upstream, ResponseCacheOptions{})
+
+		response, policy := execute(t, engine, &Request{Query: `{ products { name } }`})
+		assert.Equal(t, productsResponse, response)
+		assert.Equal(t, CacheControlPolicy{MaxAge: 40 * time.Second}, policy)
+
+		response, policy = execute(t, engine, &Request{Query: `query { products { name } }`})
+		assert.Equal(t, productsResponse, response)
+		assert.Equal(t, CacheControlScopePublic, policy.Scope)
+		assert.True(t, policy.MaxAge > 39*time.Second && policy.MaxAge <= 40*time.Second)
+
+		assert.Equal(t, int64(1), upstream.calls.Load())
+	})
+
+	t.Run("vary headers are part of the key", func(t *testing.T) {
+		upstream := &upstream{calls: atomic.NewInt64(0), response: productsResponse}
+		engine := newEngine(t, upstream, ResponseCacheOptions{VaryHeaders: []string{"Accept-Language"}})
+
+		for _, language := range []string{"en", "de", "en"} {
+			request := &Request{Query: `{ products { name } }`}
+			request.SetHeader(http.Header{"Accept-Language": []string{language}, "Authorization": []string{language}})
+			execute(t, engine, request)
+		}
+
+		assert.Equal(t, int64(2), upstream.calls.Load())
+	})
+
+	t.Run("private responses are not cached", func(t *testing.T) {
+		upstream := &upstream{calls: atomic.NewInt64(0), response: `{"data":{"me":{"name":"Jane"}}}`}
+		engine := newEngine(t, upstream, ResponseCacheOptions{})
+
+		for i := 0; i < 2; i++ {
+			_, policy := execute(t, engine, &Request{Query: `{ me { name } }`})
+			assert.Equal(t, CacheControlPolicy{MaxAge: 300 * time.Second, Scope: CacheControlScopePrivate}, policy)
+		}
+
+		assert.Equal(t, int64(2), upstream.calls.Load())
+	})
+
+	t.Run("upstream headers restrict the policy", func(t *testing.T) {
+		upstream := &upstream{calls: atomic.NewInt64(0), cacheControl: "private", response: productsResponse}
+		engine := newEngine(t, upstream, ResponseCacheOptions{})
+
+		_, policy := execute(t, engine, &Request{Query: `{ products { name } }`})
+		assert.Equal(t, CacheControlPolicy{MaxAge: 120 * time.Second, Scope: CacheControlScopePrivate}, policy)
+
+		upstream.cacheControl = "no-store"
+		_, policy = execute(t, engine, &Request{Query: `{ products { name } }`})
+		assert.Equal(t, "no-store", policy.HeaderValue())
+
+		assert.Equal(t, int64(2), upstream.calls.Load())
+	})
+
+	t.Run("upstream headers restrict the policy of deduplicated loads", func(t *testing.T) {
+		upstream := &upstream{calls: atomic.NewInt64(0), cacheControl: "private", response: productsResponse, release: make(chan struct{})}
+		engine := newEngine(t, upstream, ResponseCacheOptions{}, func(engineConf *EngineV2Configuration) {
+			engineConf.EnableSingleFlight(true)
+		})
+
+		policies := make([]CacheControlPolicy, 2)
+		wg := sync.WaitGroup{}
+		for i := range policies {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, policies[i] = execute(t, engine, &Request{Query: `{ products { name } }`})
+			}(i)
+		}
+		// both requests wait for the same upstream request
+		require.Eventually(t, func() bool {
+			return upstream.calls.Load() == 1
+		}, time.Second, time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		close(upstream.release)
+		wg.Wait()
+
+		require.Equal(t, int64(1), upstream.calls.Load())
+		for _, policy := range policies {
+			assert.Equal(t, CacheControlScopePrivate, policy.Scope)
+		}
+
+		execute(t, engine, &Request{Query: `{ products { name } }`})
+		assert.Equal(t, int64(2), upstream.calls.Load())
+	})
+
+	t.Run("responses with errors are not cached", func(t *testing.T) {
+		upstream := &upstream{calls: atomic.NewInt64(0), response: `{"errors":[{"message":"unavailable"}],"data":{"products":null}}`}
+		engine := newEngine(t, upstream, ResponseCacheOptions{})
+
+		for i := 0; i < 2; i++ {
+			execute(t, engine, &Request{Query: `{ products { name } }`})
+		}
+
+		assert.Equal(t, int64(2), upstream.calls.Load())
+	})
+
+	t.Run("responses of authenticated clients are not shared", func(t *testing.T) {
+		upstream := &upstream{calls: atomic.NewInt64(0), response: `{"data":{"secret":"42"}}`}
+		engine := newEngine(t, upstream, ResponseCacheOptions{}, func(engineConf *EngineV2Configuration) {
+			engineConf.SetAuthorizer(NewDirectiveAuthorizer(engineConf.schema))
+			engineConf.SetFieldConfigurations(NewDirectiveAuthorizer(engineConf.schema).FieldConfigurations(nil))
+		})
+
+		response, policy := execute(t, engine, &Request{Query: `{ secret }`}, WithClaims(&resolve.Claims{Subject: "jane"}))
+		assert.Equal(t, `{"data":{"secret":"42"}}`, response)
+		assert.Equal(t, CacheControlScopePrivate, policy.Scope)
+
+		response, policy = execute(t, engine, &Request{Query: `{ secret }`})
+		assert.Equal(t, CacheControlScopePrivate, policy.Scope)
+		assert.NotContains(t, response, `"42"`)
+		assert.Contains(t, response, `"errors"`)
+
+		assert.Equal(t, int64(2), upstream.calls.Load())
+	})
+
+	t.Run("cached responses are rate limited", func(t *testing.T) {
+		upstream := &upstream{calls: atomic.NewInt64(0), response: productsResponse}
+		engine := newEngine(t, upstream, ResponseCacheOptions{}, func(engineConf *EngineV2Configuration) {
+			engineConf.SetRateLimiter(NewRateLimiter(engineConf.schema, RateLimiterOptions{
+				Limit:        ratelimit.Limit{Rate: 0.001, Burst: 2},
+				FieldWeights: map[string]int{},
+			}))
+		})
+
+		execute(t, engine, &Request{Query: `{ products { name } }`})
+		execute(t, engine, &Request{Query: `{ products { name } }`})
+
+		writer := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: `{ products { name } }`}, &writer)
+		var rateLimitErr *RateLimitExceededError
+		assert.ErrorAs(t, err, &rateLimitErr)
+		assert.Equal(t, int64(1), upstream.calls.Load())
+	})
+
+	t.Run("mutations bypass the cache", func(t *testing.T) {
+		upstream := &upstream{calls: atomic.NewInt64(0), response: `{"data":{"addProduct":{"name":"Chair"}}}`}
+		engine := newEngine(t, upstream, ResponseCacheOptions{DefaultMaxAge: time.Minute})
+
+		for i := 0; i < 2; i++ {
+			writer := NewEngineResultWriter()
+			require.NoError(t, engine.Execute(context.Background(), &Request{Query: `mutation { addProduct(name: "Chair") { name } }`}, &writer))
+			_, ok := writer.CacheControl()
+			assert.False(t, ok)
+		}
+
+		assert.Equal(t, int64(2), upstream.calls.Load())
+	})
+}
+
+func TestInMemoryResponseCache(t *testing.T) {
+	cache, err := NewInMemoryResponseCache(1)
+	require.NoError(t, err)
+
+	cache.Set(1, &CachedResponse{Body: []byte("expired"), Expires: time.Now().Add(-time.Second)})
+	_, ok := cache.Get(1)
+	assert.False(t, ok)
+
+	cache.Set(1, &CachedResponse{Body: []byte("one"), Expires: time.Now().Add(time.Minute)})
+	cached, ok := cache.Get(1)
+	require.True(t, ok)
+	assert.Equal(t, "one", string(cached.Body))
+
+	cache.Set(2, &CachedResponse{Body: []byte("two"), Expires: time.Now().Add(time.Minute)})
+	_, ok = cache.Get(1)
+	assert.False(t, ok)
+}
@@ -0,0 +1,51 @@
+package graphql
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the name of the OpenTelemetry tracer creating the spans of the execution engine
+const TracerName = "github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
+
+const (
+	// AttributeOperationName is the name of the executed operation
+	AttributeOperationName = attribute.Key("graphql.operation.name")
+	// AttributeOperationType is the type of the executed operation, e.g. "query"
+	AttributeOperationType = attribute.Key("graphql.operation.type")
+
+	operationSpanName       = "GraphQL Operation"
+	normalizeSpanName       = "Normalize"
+	validateSpanName        = "Validate"
+	inputValidationSpanName = "Input Validation"
+	rateLimitSpanName       = "Rate Limit"
+	planSpanName            = "Plan"
+)
+
+// newTracer returns the tracer of the provider, the global provider is used if provider is nil
+func newTracer(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(TracerName)
+}
+
+func operationTypeName(operationType OperationType) string {
+	switch operationType {
+	case OperationTypeQuery:
+		return "query"
+	case OperationTypeMutation:
+		return "mutation"
+	case OperationTypeSubscription:
+		return "subscription"
+	default:
+		return "unknown"
+	}
+}
+
+func recordSpanError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,163 @@
+// Package http serves GraphQL over HTTP for the ExecutionEngineV2 following the GraphQL-over-HTTP specification.
+// Websocket upgrade requests on the same path are handed over to the subscription websocket handler.
+package http
+
+import (
+	"net/http"
+
+	"github.com/gobwas/ws"
+	"github.com/jensneuse/abstractlogger"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/subscription/websocket"
+)
+
+const (
+	// DefaultMaxRequestBodySize is the maximum size of a request body in bytes if no other limit is configured.
+	DefaultMaxRequestBodySize int64 = 5 << 20
+)
+
+// DefaultCSRFPreventionHeaders are the headers of which at least one has to be present on requests
+// without a preflighted content type when CSRF prevention is enabled.
+var DefaultCSRFPreventionHeaders = []string{
+	"GraphQL-Require-Preflight",
+	"Apollo-Require-Preflight",
+	"X-Apollo-Operation-Name",
+}
+
+// HandlerOptions can be used to pass options to the handler.
+type HandlerOptions struct {
+	Logger abstractlogger.Logger
+	// MaxRequestBodySize limits the size of request bodies in bytes, larger requests are rejected with 413.
+	// If set to 0, DefaultMaxRequestBodySize is used. A negative value disables the limit.
+	MaxRequestBodySize int64
+	// EnableCSRFPrevention rejects requests a browser could send cross-origin without a CORS preflight.
+	// Such requests are GET requests and POST requests with a simple content type, they are only accepted
+	// if they carry one of the CSRFPreventionHeaders.
+	EnableCSRFPrevention bool
+	// CSRFPreventionHeaders overrides DefaultCSRFPreventionHeaders.
+	CSRFPreventionHeaders []string
+	// EnableBatching accepts Apollo-style batches of operations in the body of POST requests.
+	// The size of a batch is limited by graphql.EngineV2Configuration.SetMaxBatchSize.
+	EnableBatching bool
+	// WebsocketUpgrader is used to upgrade websocket requests.
+	// If not set, an upgrader accepting the graphql-ws and graphql-transport-ws protocols is used.
+	WebsocketUpgrader *ws.HTTPUpgrader
+	// WebsocketHandleOptions are passed to the websocket handler of every upgraded connection.
+	WebsocketHandleOptions []websocket.HandleOptionFunc
+	// ExecutionOptions returns additional execution options for a request, e.g. to forward headers to upstreams.
+	ExecutionOptions func(r *http.Request) []graphql.ExecutionOptionsV2
+}
+
+// HandlerOptionFunc can be used to define option functions.
+type HandlerOptionFunc func(opts *HandlerOptions)
+
+// WithLogger is a function that sets a logger for the handler.
+func WithLogger(logger abstractlogger.Logger) HandlerOptionFunc {
+	return func(opts *HandlerOptions) {
+		opts.Logger = logger
+	}
+}
+
+// WithMaxRequestBodySize is a function that sets the maximum size of request bodies in bytes.
+func WithMaxRequestBodySize(size int64) HandlerOptionFunc {
+	return func(opts *HandlerOptions) {
+		opts.MaxRequestBodySize = size
+	}
+}
+
+// WithCSRFPrevention is a function that enables CSRF prevention.
+// If no headers are provided, DefaultCSRFPreventionHeaders are used.
+func WithCSRFPrevention(headers ...string) HandlerOptionFunc {
+	return func(opts *HandlerOptions) {
+		opts.EnableCSRFPrevention = true
+		opts.CSRFPreventionHeaders = headers
+	}
+}
+
+// WithBatching is a function that enables Apollo-style batched requests.
+func WithBatching() HandlerOptionFunc {
+	return func(opts *HandlerOptions) {
+		opts.EnableBatching = true
+	}
+}
+
+// WithWebsocketUpgrader is a function that sets a custom websocket upgrader.
+func WithWebsocketUpgrader(upgrader *ws.HTTPUpgrader) HandlerOptionFunc {
+	return func(opts *HandlerOptions) {
+		opts.WebsocketUpgrader = upgrader
+	}
+}
+
+// WithWebsocketHandleOptions is a function that sets the options of the websocket handler.
+func WithWebsocketHandleOptions(options ...websocket.HandleOptionFunc) HandlerOptionFunc {
+	return func(opts *HandlerOptions) {
+		opts.WebsocketHandleOptions = options
+	}
+}
+
+// WithExecutionOptions is a function that sets a callback returning additional execution options per request.
+func WithExecutionOptions(executionOptions func(r *http.Request) []graphql.ExecutionOptionsV2) HandlerOptionFunc {
+	return func(opts *HandlerOptions) {
+		opts.ExecutionOptions = executionOptions
+	}
+}
+
+// Handler is a GraphQL-over-HTTP handler for the ExecutionEngineV2.
+type Handler struct {
+	engine  *graphql.ExecutionEngineV2
+	options HandlerOptions
+}
+
+// NewHandler creates a new Handler. It can take optional option functions to customize the handler.
+func NewHandler(engine *graphql.ExecutionEngineV2, options ...HandlerOptionFunc) *Handler {
+	definedOptions := HandlerOptions{
+		Logger: abstractlogger.Noop{},
+	}
+
+	for _, optionFunc := range options {
+		optionFunc(&definedOptions)
+	}
+
+	return NewHandlerWithOptions(engine, definedOptions)
+}
+
+// NewHandlerWithOptions creates a new Handler. It requires an option struct to define the behavior.
+func NewHandlerWithOptions(engine *graphql.ExecutionEngineV2, options HandlerOptions) *Handler {
+	// Use noop logger to prevent nil pointers if none was provided
+	if options.Logger == nil {
+		options.Logger = abstractlogger.Noop{}
+	}
+	if options.MaxRequestBodySize == 0 {
+		options.MaxRequestBodySize = DefaultMaxRequestBodySize
+	}
+	if len(options.CSRFPreventionHeaders) == 0 {
+		options.CSRFPreventionHeaders = DefaultCSRFPreventionHeaders
+	}
+	if options.WebsocketUpgrader == nil {
+		options.WebsocketUpgrader = &ws.HTTPUpgrader{
+			Protocol: func(protocol string) bool {
+				switch websocket.Protocol(protocol) {
+				case websocket.ProtocolGraphQLWS, websocket.ProtocolGraphQLTransportWS:
+					return true
+				}
+				return false
+			},
+		}
+	}
+
+	return &Handler{
+		engine:  engine,
+		options: options,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.isWebsocketUpgrade(r) {
+		h.upgrade(w, r)
+		return
+	}
+	h.handleHTTP(w, r)
+}
+
+var _ http.Handler = (*Handler)(nil)
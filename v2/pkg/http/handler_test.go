@@ -0,0 +1,376 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+	"github.com/jensneuse/abstractlogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/datasource/staticdatasource"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/plan"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/engine/resolve"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
+)
+
+func newTestEngine(t *testing.T, configure ...func(engineConf *graphql.EngineV2Configuration)) *graphql.ExecutionEngineV2 {
+	t.Helper()
+	schema, err := graphql.NewSchemaFromString(`
+		schema { query: Query mutation: Mutation }
+		type Query { hello: String }
+		type Mutation { setHello: String }
+	`)
+	require.NoError(t, err)
+
+	engineConf := graphql.NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"hello"}},
+			},
+			Factory: &staticdatasource.Factory{},
+			Custom: staticdatasource.ConfigJSON(staticdatasource.Configuration{
+				Data: `{"hello":"world"}`,
+			}),
+		},
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Mutation", FieldNames: []string{"setHello"}},
+			},
+			Factory: &staticdatasource.Factory{},
+			Custom: staticdatasource.ConfigJSON(staticdatasource.Configuration{
+				Data: `{"setHello":"updated"}`,
+			}),
+		},
+	})
+	for _, configureFunc := range configure {
+		configureFunc(&engineConf)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	engine, err := graphql.NewExecutionEngineV2(ctx, abstractlogger.NoopLogger, engineConf)
+	require.NoError(t, err)
+	return engine
+}
+
+type rateLimiterFunc func(operation *graphql.Request) error
+
+func (f rateLimiterFunc) RateLimit(operation *graphql.Request, _ *resolve.Context) error {
+	return f(operation)
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	engine := newTestEngine(t)
+
+	type response struct {
+		status      int
+		contentType string
+		allow       string
+		body        string
+	}
+
+	do := func(t *testing.T, handler http.Handler, method, target string, header http.Header, body string) response {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return response{
+			status:      rec.Code,
+			contentType: rec.Header().Get("Content-Type"),
+			allow:       rec.Header().Get("Allow"),
+			body:        rec.Body.String(),
+		}
+	}
+
+	jsonHeader := http.Header{"Content-Type": {"application/json"}}
+	graphqlResponseHeader := http.Header{"Content-Type": {"application/json"}, "Accept": {"application/graphql-response+json"}}
+
+	t.Run("post", func(t *testing.T) {
+		handler := NewHandler(engine)
+		assert.Equal(t, response{
+			status:      http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			body:        `{"data":{"hello":"world"}}`,
+		}, do(t, handler, http.MethodPost, "/graphql", jsonHeader, `{"query":"{ hello }"}`))
+		assert.Equal(t, response{
+			status:      http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			body:        `{"data":{"setHello":"updated"}}`,
+		}, do(t, handler, http.MethodPost, "/graphql", jsonHeader, `{"query":"mutation { setHello }"}`))
+	})
+
+	t.Run("get", func(t *testing.T) {
+		handler := NewHandler(engine)
+		query := url.Values{"query": {"query Hello($a: String) { hello }"}, "operationName": {"Hello"}, "variables": {`{"a":"b"}`}}
+		assert.Equal(t, response{
+			status:      http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			body:        `{"data":{"hello":"world"}}`,
+		}, do(t, handler, http.MethodGet, "/graphql?"+query.Encode(), nil, ""))
+	})
+
+	t.Run("mutation over get is rejected", func(t *testing.T) {
+		handler := NewHandler(engine)
+		assert.Equal(t, response{
+			status:      http.StatusMethodNotAllowed,
+			contentType: "application/json; charset=utf-8",
+			allow:       "POST",
+			body:        `{"errors":[{"message":"mutations can only be sent using POST"}],"data":null}`,
+		}, do(t, handler, http.MethodGet, "/graphql?query="+url.QueryEscape("mutation { setHello }"), nil, ""))
+	})
+
+	t.Run("unsupported method", func(t *testing.T) {
+		handler := NewHandler(engine)
+		resp := do(t, handler, http.MethodPut, "/graphql", jsonHeader, `{"query":"{ hello }"}`)
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.status)
+		assert.Equal(t, "GET, POST", resp.allow)
+	})
+
+	t.Run("content negotiation", func(t *testing.T) {
+		handler := NewHandler(engine)
+		for _, tc := range []struct {
+			accept      string
+			status      int
+			contentType string
+		}{
+			{accept: "", status: http.StatusOK, contentType: "application/json; charset=utf-8"},
+			{accept: "*/*", status: http.StatusOK, contentType: "application/json; charset=utf-8"},
+			{accept: "application/graphql-response+json", status: http.StatusOK, contentType: "application/graphql-response+json; charset=utf-8"},
+			{accept: "application/json, application/graphql-response+json", status: http.StatusOK, contentType: "application/graphql-response+json; charset=utf-8"},
+			{accept: "application/json, application/graphql-response+json;q=0.9", status: http.StatusOK, contentType: "application/json; charset=utf-8"},
+			{accept: "text/html", status: http.StatusNotAcceptable, contentType: "application/json; charset=utf-8"},
+		} {
+			header := http.Header{"Content-Type": {"application/json"}}
+			if tc.accept != "" {
+				header.Set("Accept", tc.accept)
+			}
+			resp := do(t, handler, http.MethodPost, "/graphql", header, `{"query":"{ hello }"}`)
+			assert.Equal(t, tc.status, resp.status, tc.accept)
+			assert.Equal(t, tc.contentType, resp.contentType, tc.accept)
+		}
+	})
+
+	t.Run("validation errors", func(t *testing.T) {
+		handler := NewHandler(engine)
+		body := `{"query":"{ goodbye }"}`
+		expectedBody := `{"errors":[{"message":"field: goodbye not defined on type: Query","path":["query","goodbye"]}],"data":null}`
+		assert.Equal(t, response{
+			status:      http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			body:        expectedBody,
+		}, do(t, handler, http.MethodPost, "/graphql", jsonHeader, body))
+		assert.Equal(t, response{
+			status:      http.StatusBadRequest,
+			contentType: "application/graphql-response+json; charset=utf-8",
+			body:        expectedBody,
+		}, do(t, handler, http.MethodPost, "/graphql", graphqlResponseHeader, body))
+	})
+
+	t.Run("planning errors", func(t *testing.T) {
+		handler := NewHandler(engine)
+		body := `{"query":"query Greeting { hello }","operationName":"Goodbye"}`
+		expectedBody := `{"errors":[{"message":"cannot find an operation with name: Goodbye"}],"data":null}`
+		assert.Equal(t, response{
+			status:      http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			body:        expectedBody,
+		}, do(t, handler, http.MethodPost, "/graphql", jsonHeader, body))
+		assert.Equal(t, response{
+			status:      http.StatusBadRequest,
+			contentType: "application/graphql-response+json; charset=utf-8",
+			body:        expectedBody,
+		}, do(t, handler, http.MethodPost, "/graphql", graphqlResponseHeader, body))
+	})
+
+	t.Run("malformed requests", func(t *testing.T) {
+		handler := NewHandler(engine)
+		for _, tc := range []struct {
+			name   string
+			header http.Header
+			body   string
+			status int
+		}{
+			{name: "invalid json", header: jsonHeader, body: `{"query":`, status: http.StatusBadRequest},
+			{name: "missing query", header: jsonHeader, body: `{"operationName":"A"}`, status: http.StatusBadRequest},
+			{name: "empty body", header: jsonHeader, body: ``, status: http.StatusBadRequest},
+			{name: "variables are not an object", header: jsonHeader, body: `{"query":"{ hello }","variables":[1]}`, status: http.StatusBadRequest},
+			{name: "unsupported content type", header: http.Header{"Content-Type": {"text/plain"}}, body: `{"query":"{ hello }"}`, status: http.StatusUnsupportedMediaType},
+			{name: "missing content type", body: `{"query":"{ hello }"}`, status: http.StatusUnsupportedMediaType},
+		} {
+			resp := do(t, handler, http.MethodPost, "/graphql", tc.header, tc.body)
+			assert.Equal(t, tc.status, resp.status, tc.name)
+			assert.Contains(t, resp.body, `"errors"`, tc.name)
+		}
+	})
+
+	t.Run("request body size limit", func(t *testing.T) {
+		handler := NewHandler(engine, WithMaxRequestBodySize(16))
+		resp := do(t, handler, http.MethodPost, "/graphql", jsonHeader, `{"query":"{ hello }"}`)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.status)
+		assert.Equal(t, `{"errors":[{"message":"the request body is too large"}],"data":null}`, resp.body)
+
+		handler = NewHandler(engine, WithMaxRequestBodySize(-1))
+		resp = do(t, handler, http.MethodPost, "/graphql", jsonHeader, `{"query":"{ hello }"}`)
+		assert.Equal(t, http.StatusOK, resp.status)
+	})
+
+	t.Run("csrf prevention", func(t *testing.T) {
+		handler := NewHandler(engine, WithCSRFPrevention())
+		query := "/graphql?query=" + url.QueryEscape("{ hello }")
+
+		assert.Equal(t, http.StatusBadRequest, do(t, handler, http.MethodGet, query, nil, "").status)
+		assert.Equal(t, http.StatusOK, do(t, handler, http.MethodGet, query, http.Header{"Graphql-Require-Preflight": {"1"}}, "").status)
+		assert.Equal(t, http.StatusOK, do(t, handler, http.MethodGet, query, http.Header{"X-Apollo-Operation-Name": {"Hello"}}, "").status)
+		assert.Equal(t, http.StatusOK, do(t, handler, http.MethodPost, "/graphql", jsonHeader, `{"query":"{ hello }"}`).status)
+		assert.Equal(t, http.StatusBadRequest, do(t, handler, http.MethodPost, "/graphql", http.Header{"Content-Type": {"text/plain"}}, `{"query":"{ hello }"}`).status)
+
+		handler = NewHandler(engine, WithCSRFPrevention("X-Custom-Preflight"))
+		assert.Equal(t, http.StatusBadRequest, do(t, handler, http.MethodGet, query, http.Header{"Graphql-Require-Preflight": {"1"}}, "").status)
+		assert.Equal(t, http.StatusOK, do(t, handler, http.MethodGet, query, http.Header{"X-Custom-Preflight": {"1"}}, "").status)
+	})
+
+	t.Run("subscriptions over http are rejected", func(t *testing.T) {
+		handler := NewHandler(engine)
+		resp := do(t, handler, http.MethodPost, "/graphql", graphqlResponseHeader, `{"query":"subscription { hello }"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.status)
+		assert.Equal(t, `{"errors":[{"message":"subscriptions are only supported over websockets"}],"data":null}`, resp.body)
+	})
+
+	t.Run("live queries over http are rejected", func(t *testing.T) {
+		handler := NewHandler(engine)
+		resp := do(t, handler, http.MethodPost, "/graphql", graphqlResponseHeader, `{"query":"query @live { hello }"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.status)
+		assert.Equal(t, `{"errors":[{"message":"live queries are only supported over websockets"}],"data":null}`, resp.body)
+	})
+
+	t.Run("batching", func(t *testing.T) {
+		body := `[{"query":"{ hello }"},{"query":"{ goodbye }"},{"query":"mutation { setHello }"}]`
+
+		resp := do(t, NewHandler(engine), http.MethodPost, "/graphql", jsonHeader, body)
+		assert.Equal(t, http.StatusBadRequest, resp.status)
+		assert.Equal(t, `{"errors":[{"message":"batched requests are not enabled"}],"data":null}`, resp.body)
+
+		handler := NewHandler(engine, WithBatching())
+		assert.Equal(t, response{
+			status:      http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			body:        `[{"data":{"hello":"world"}},{"errors":[{"message":"field: goodbye not defined on type: Query","path":["query","goodbye"]}],"data":null},{"data":{"setHello":"updated"}}]`,
+		}, do(t, handler, http.MethodPost, "/graphql", jsonHeader, body))
+
+		resp = do(t, handler, http.MethodPost, "/graphql", jsonHeader, `[{"query":"{ hello }"},{"operationName":"A"}]`)
+		assert.Equal(t, http.StatusBadRequest, resp.status)
+
+		resp = do(t, handler, http.MethodPost, "/graphql", jsonHeader, `[`+strings.Repeat(`{"query":"{ hello }"},`, graphql.DefaultMaxBatchSize)+`{"query":"{ hello }"}]`)
+		assert.Equal(t, http.StatusBadRequest, resp.status)
+		assert.Equal(t, `{"errors":[{"message":"the batch exceeds the maximum batch size"}],"data":null}`, resp.body)
+	})
+
+	t.Run("execution options", func(t *testing.T) {
+		called := false
+		handler := NewHandler(engine, WithExecutionOptions(func(r *http.Request) []graphql.ExecutionOptionsV2 {
+			called = true
+			return []graphql.ExecutionOptionsV2{graphql.WithUpstreamHeaders(r.Header)}
+		}))
+		assert.Equal(t, http.StatusOK, do(t, handler, http.MethodPost, "/graphql", jsonHeader, `{"query":"{ hello }"}`).status)
+		assert.True(t, called)
+	})
+
+	t.Run("cache control", func(t *testing.T) {
+		cache, err := graphql.NewInMemoryResponseCache(16)
+		require.NoError(t, err)
+		cachingEngine := newTestEngine(t, func(engineConf *graphql.EngineV2Configuration) {
+			engineConf.SetResponseCache(cache, graphql.ResponseCacheOptions{DefaultMaxAge: time.Minute})
+		})
+		handler := NewHandler(cachingEngine)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape("{ hello }"), nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "max-age=60, public", rec.Header().Get("Cache-Control"))
+		assert.Equal(t, `{"data":{"hello":"world"}}`, rec.Body.String())
+
+		req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"mutation { setHello }"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("Cache-Control"))
+
+		rec = httptest.NewRecorder()
+		NewHandler(engine).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape("{ hello }"), nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("Cache-Control"))
+	})
+
+	t.Run("rate limiting", func(t *testing.T) {
+		rateLimitedEngine := newTestEngine(t, func(engineConf *graphql.EngineV2Configuration) {
+			engineConf.SetRateLimiter(rateLimiterFunc(func(operation *graphql.Request) error {
+				if operation.OperationName == "Expensive" {
+					return &graphql.RateLimitExceededError{}
+				}
+				return &graphql.RateLimitExceededError{RetryAfter: 1500 * time.Millisecond}
+			}))
+		})
+		handler := NewHandler(rateLimitedEngine, WithBatching())
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape("{ hello }"), nil))
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+		assert.Equal(t, `{"errors":[{"message":"rate limit exceeded, retry after 2 seconds","extensions":{"code":"RATE_LIMITED","retryAfter":2}}],"data":null}`, rec.Body.String())
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql?operationName=Expensive&query="+url.QueryEscape("query Expensive { hello }"), nil))
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Empty(t, rec.Header().Get("Retry-After"))
+
+		resp := do(t, handler, http.MethodPost, "/graphql", jsonHeader, `[{"query":"{ hello }"}]`)
+		assert.Equal(t, http.StatusOK, resp.status)
+		assert.Equal(t, `[{"errors":[{"message":"rate limit exceeded, retry after 2 seconds","extensions":{"code":"RATE_LIMITED","retryAfter":2}}],"data":null}]`, resp.body)
+	})
+}
+
+func TestHandler_ServeHTTP_Websocket(t *testing.T) {
+	server := httptest.NewServer(NewHandler(newTestEngine(t)))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	dialer := ws.Dialer{
+		Protocols: []string{"graphql-transport-ws"},
+	}
+	conn, _, handshake, err := dialer.Dial(ctx, strings.Replace(server.URL, "http", "ws", 1)+"/graphql")
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, "graphql-transport-ws", handshake.Protocol)
+
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	require.NoError(t, wsutil.WriteClientText(conn, []byte(`{"type":"connection_init"}`)))
+	message, err := wsutil.ReadServerText(conn)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"connection_ack"}`, string(message))
+
+}
+
+func TestNegotiateMediaType(t *testing.T) {
+	mediaType, ok := negotiateMediaType([]string{"application/graphql-response+json;q=0"})
+	assert.False(t, ok)
+	assert.Equal(t, "", mediaType)
+
+	mediaType, ok = negotiateMediaType([]string{"text/html", "application/*;q=0.5"})
+	assert.True(t, ok)
+	assert.Equal(t, ContentTypeJSON, mediaType)
+}
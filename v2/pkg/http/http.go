@@ -0,0 +1,349 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jensneuse/abstractlogger"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/operationreport"
+)
+
+const (
+	httpHeaderContentType  string = "Content-Type"
+	httpHeaderAccept       string = "Accept"
+	httpHeaderAllow        string = "Allow"
+	httpHeaderCacheControl string = "Cache-Control"
+	httpHeaderRetryAfter   string = "Retry-After"
+
+	// ContentTypeJSON is the legacy media type of GraphQL responses.
+	// Responses of this type use 200 OK for every well-formed request, even if it fails validation.
+	ContentTypeJSON string = "application/json"
+	// ContentTypeGraphQLResponseJSON is the media type of GraphQL responses defined by the GraphQL-over-HTTP specification.
+	// Responses of this type use 400 Bad Request for requests which fail before execution, e.g. on validation errors.
+	ContentTypeGraphQLResponseJSON string = "application/graphql-response+json"
+)
+
+var (
+	errMissingQuery            = errors.New("the request does not contain a query")
+	errInvalidVariables        = errors.New("the variables of the request are not a valid JSON object")
+	errMutationOverGet         = errors.New("mutations can only be sent using POST")
+	errSubscriptionOverHTTP    = errors.New("subscriptions are only supported over websockets")
+	errLiveQueryOverHTTP       = errors.New("live queries are only supported over websockets")
+	errMethodNotAllowed        = errors.New("only GET and POST requests are supported")
+	errNotAcceptable           = errors.New("the accepted media types are not supported, use application/graphql-response+json or application/json")
+	errUnsupportedMediaType    = errors.New("the content type of the request must be application/json")
+	errRequestBodyTooLarge     = errors.New("the request body is too large")
+	errCSRFPreventionViolation = errors.New("the request was blocked by CSRF prevention, it must have a content type other than application/x-www-form-urlencoded, multipart/form-data or text/plain or carry a preflight header")
+	errInternalServerError     = errors.New("internal server error")
+	errBatchingDisabled        = errors.New("batched requests are not enabled")
+)
+
+// simpleContentTypes can be sent cross-origin by browsers without a CORS preflight request
+var simpleContentTypes = map[string]struct{}{
+	"application/x-www-form-urlencoded": {},
+	"multipart/form-data":               {},
+	"text/plain":                        {},
+}
+
+func (h *Handler) handleHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set(httpHeaderAllow, "GET, POST")
+		h.writeErrors(w, ContentTypeJSON, http.StatusMethodNotAllowed, errMethodNotAllowed)
+		return
+	}
+
+	mediaType, ok := negotiateMediaType(r.Header.Values(httpHeaderAccept))
+	if !ok {
+		h.writeErrors(w, ContentTypeJSON, http.StatusNotAcceptable, errNotAcceptable)
+		return
+	}
+
+	if h.options.EnableCSRFPrevention && !h.isPreflighted(r) {
+		h.writeErrors(w, mediaType, http.StatusBadRequest, errCSRFPreventionViolation)
+		return
+	}
+
+	requests, isBatch, status, err := h.unmarshalRequests(w, r)
+	if err != nil {
+		h.writeErrors(w, mediaType, status, err)
+		return
+	}
+	if isBatch {
+		h.handleBatch(w, r, mediaType, requests)
+		return
+	}
+	request := requests[0]
+
+	// parse errors are ignored here, they are returned as request errors by the engine
+	if operationType, err := request.OperationType(); err == nil {
+		switch {
+		case operationType == graphql.OperationTypeMutation && r.Method == http.MethodGet:
+			w.Header().Set(httpHeaderAllow, "POST")
+			h.writeErrors(w, mediaType, http.StatusMethodNotAllowed, errMutationOverGet)
+			return
+		case operationType == graphql.OperationTypeSubscription:
+			h.writeErrors(w, mediaType, requestErrorStatus(mediaType), errSubscriptionOverHTTP)
+			return
+		}
+	}
+	if isLiveQuery, err := request.IsLiveQuery(); err == nil && isLiveQuery {
+		h.writeErrors(w, mediaType, requestErrorStatus(mediaType), errLiveQueryOverHTTP)
+		return
+	}
+
+	resultWriter := graphql.NewEngineResultWriter()
+	if err := h.engine.Execute(r.Context(), request, &resultWriter, h.executionOptions(r)...); err != nil {
+		if rateLimitErr, ok := err.(*graphql.RateLimitExceededError); ok {
+			h.writeRateLimitExceeded(w, mediaType, rateLimitErr)
+			return
+		}
+		status, response := h.executionErrorResponse(mediaType, err)
+		h.writeResponse(w, mediaType, status, response)
+		return
+	}
+
+	if policy, ok := resultWriter.CacheControl(); ok {
+		w.Header().Set(httpHeaderCacheControl, policy.HeaderValue())
+	}
+	h.writeResponse(w, mediaType, http.StatusOK, resultWriter.Bytes())
+}
+
+// handleBatch executes an Apollo-style batch and responds with the array of results in the order of the operations.
+// The response status is 200 OK unless the batch as a whole is rejected, the status of each operation is lost.
+func (h *Handler) handleBatch(w http.ResponseWriter, r *http.Request, mediaType string, requests []*graphql.Request) {
+	if !h.options.EnableBatching {
+		h.writeErrors(w, mediaType, http.StatusBadRequest, errBatchingDisabled)
+		return
+	}
+
+	results, err := h.engine.ExecuteBatch(r.Context(), requests, h.executionOptions(r)...)
+	if err != nil {
+		h.writeErrors(w, mediaType, http.StatusBadRequest, err)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte('[')
+	for i := range results {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if results[i].Err != nil {
+			_, response := h.executionErrorResponse(mediaType, results[i].Err)
+			buf.Write(response)
+			continue
+		}
+		buf.Write(results[i].Response)
+	}
+	buf.WriteByte(']')
+
+	h.writeResponse(w, mediaType, http.StatusOK, buf.Bytes())
+}
+
+func (h *Handler) executionOptions(r *http.Request) []graphql.ExecutionOptionsV2 {
+	if h.options.ExecutionOptions == nil {
+		return nil
+	}
+	return h.options.ExecutionOptions(r)
+}
+
+// executionErrorResponse renders the response for an error returned by the engine.
+// Errors happening before execution are shown to the client, all other errors are logged and masked.
+func (h *Handler) executionErrorResponse(mediaType string, err error) (status int, response []byte) {
+	if requestErrors, ok := asRequestErrors(err); ok {
+		return requestErrorStatus(mediaType), h.marshalErrors(requestErrors)
+	}
+	h.options.Logger.Error("http.Handler.executionErrorResponse: on execution",
+		abstractlogger.Error(err),
+	)
+	return http.StatusInternalServerError, h.marshalErrors(graphql.RequestErrors{{Message: errInternalServerError.Error()}})
+}
+
+// unmarshalRequests reads the GraphQL request from the query parameters of a GET request or the body of a POST request.
+// The body of a POST request can contain a batch of requests, GET requests can't be batched.
+// On error, the returned status code should be used for the response.
+func (h *Handler) unmarshalRequests(w http.ResponseWriter, r *http.Request) (requests []*graphql.Request, isBatch bool, status int, err error) {
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		request := &graphql.Request{
+			Query:         query.Get("query"),
+			OperationName: query.Get("operationName"),
+		}
+		if variables := query.Get("variables"); variables != "" {
+			request.Variables = json.RawMessage(variables)
+		}
+		request.SetHeader(r.Header)
+		requests = []*graphql.Request{request}
+	} else {
+		contentType, _, err := mime.ParseMediaType(r.Header.Get(httpHeaderContentType))
+		if err != nil || contentType != ContentTypeJSON {
+			return nil, false, http.StatusUnsupportedMediaType, errUnsupportedMediaType
+		}
+		if h.options.MaxRequestBodySize > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, h.options.MaxRequestBodySize)
+		}
+		requests, isBatch, err = graphql.UnmarshalHttpBatchRequest(r)
+		if err != nil {
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				return nil, false, http.StatusRequestEntityTooLarge, errRequestBodyTooLarge
+			}
+			return nil, false, http.StatusBadRequest, err
+		}
+	}
+
+	for _, request := range requests {
+		if request.Query == "" {
+			return nil, false, http.StatusBadRequest, errMissingQuery
+		}
+		if len(request.Variables) > 0 && !isJSONObjectOrNull(request.Variables) {
+			return nil, false, http.StatusBadRequest, errInvalidVariables
+		}
+	}
+	return requests, isBatch, http.StatusOK, nil
+}
+
+// isPreflighted returns true if a browser would have sent a CORS preflight request before sending the request cross-origin
+func (h *Handler) isPreflighted(r *http.Request) bool {
+	if contentType := r.Header.Get(httpHeaderContentType); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			// browsers treat unparsable content types as simple ones
+			mediaType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+		}
+		if _, simple := simpleContentTypes[mediaType]; !simple {
+			return true
+		}
+	}
+	for _, header := range h.options.CSRFPreventionHeaders {
+		if r.Header.Get(header) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) writeErrors(w http.ResponseWriter, mediaType string, status int, err error) {
+	h.writeResponse(w, mediaType, status, h.marshalErrors(graphql.RequestErrors{{Message: err.Error()}}))
+}
+
+// writeRateLimitExceeded responds with 429 Too Many Requests, the Retry-After header is omitted if the operation can never be allowed
+func (h *Handler) writeRateLimitExceeded(w http.ResponseWriter, mediaType string, err *graphql.RateLimitExceededError) {
+	if seconds := err.RetryAfterSeconds(); seconds > 0 {
+		w.Header().Set(httpHeaderRetryAfter, strconv.Itoa(seconds))
+	}
+	h.writeResponse(w, mediaType, http.StatusTooManyRequests, h.marshalErrors(err.RequestErrors()))
+}
+
+func (h *Handler) marshalErrors(requestErrors graphql.RequestErrors) []byte {
+	buf := &bytes.Buffer{}
+	if _, err := requestErrors.WriteResponse(buf); err != nil {
+		h.options.Logger.Error("http.Handler.marshalErrors",
+			abstractlogger.Error(err),
+		)
+		return []byte(`{"errors":[{"message":"internal server error"}],"data":null}`)
+	}
+	return buf.Bytes()
+}
+
+func (h *Handler) writeResponse(w http.ResponseWriter, mediaType string, status int, response []byte) {
+	w.Header().Set(httpHeaderContentType, mediaType+"; charset=utf-8")
+	w.WriteHeader(status)
+	if _, err := w.Write(response); err != nil {
+		h.options.Logger.Error("http.Handler.writeResponse",
+			abstractlogger.Error(err),
+		)
+	}
+}
+
+// requestErrorStatus returns the status code for requests which failed before execution
+func requestErrorStatus(mediaType string) int {
+	if mediaType == ContentTypeGraphQLResponseJSON {
+		return http.StatusBadRequest
+	}
+	return http.StatusOK
+}
+
+// asRequestErrors returns the errors which can be shown to the client if err happened before execution,
+// e.g. during parsing, validation, rate limiting or planning
+func asRequestErrors(err error) (graphql.RequestErrors, bool) {
+	switch e := err.(type) {
+	case graphql.RequestErrors:
+		return e, len(e) > 0
+	case operationreport.Report:
+		requestErrors := graphql.RequestErrorsFromOperationReport(e)
+		return requestErrors, len(requestErrors) > 0
+	case *operationreport.Report:
+		if e == nil {
+			return nil, false
+		}
+		requestErrors := graphql.RequestErrorsFromOperationReport(*e)
+		return requestErrors, len(requestErrors) > 0
+	case *graphql.RateLimitExceededError:
+		return e.RequestErrors(), true
+	}
+	return nil, false
+}
+
+// negotiateMediaType selects the response media type from the Accept headers.
+// Without Accept header, application/json is used as recommended by the GraphQL-over-HTTP specification.
+// On equal quality, application/graphql-response+json is preferred, wildcards select application/json.
+func negotiateMediaType(accept []string) (mediaType string, ok bool) {
+	if len(accept) == 0 {
+		return ContentTypeJSON, true
+	}
+
+	bestQuality := 0.0
+	for _, header := range accept {
+		for _, value := range strings.Split(header, ",") {
+			accepted, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+			if err != nil {
+				continue
+			}
+			quality := 1.0
+			if q, ok := params["q"]; ok {
+				if quality, err = strconv.ParseFloat(q, 64); err != nil {
+					continue
+				}
+			}
+			if quality <= 0 {
+				continue
+			}
+
+			var candidate string
+			switch accepted {
+			case ContentTypeGraphQLResponseJSON:
+				candidate = ContentTypeGraphQLResponseJSON
+			case ContentTypeJSON, "application/*", "*/*":
+				candidate = ContentTypeJSON
+			default:
+				continue
+			}
+
+			if quality > bestQuality || (quality == bestQuality && candidate == ContentTypeGraphQLResponseJSON) {
+				bestQuality = quality
+				mediaType = candidate
+			}
+		}
+	}
+	return mediaType, mediaType != ""
+}
+
+func isJSONObjectOrNull(data json.RawMessage) bool {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return false
+	}
+	switch value.(type) {
+	case map[string]interface{}, nil:
+		return true
+	}
+	return false
+}
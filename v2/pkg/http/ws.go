@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/jensneuse/abstractlogger"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/subscription"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/subscription/websocket"
+)
+
+const (
+	httpHeaderUpgrade string = "Upgrade"
+)
+
+func (h *Handler) isWebsocketUpgrade(r *http.Request) bool {
+	for _, header := range r.Header[httpHeaderUpgrade] {
+		if strings.EqualFold(header, "websocket") {
+			return true
+		}
+	}
+	return false
+}
+
+// upgrade upgrades the connection and blocks until the websocket handler is set up.
+// The connection is served in a new goroutine, the headers of the upgrade request are forwarded to the upstreams.
+func (h *Handler) upgrade(w http.ResponseWriter, r *http.Request) {
+	conn, _, _, err := h.options.WebsocketUpgrader.Upgrade(r, w)
+	if err != nil {
+		// the upgrader has already written an error response
+		h.options.Logger.Error("http.Handler.upgrade",
+			abstractlogger.String("message", "could not upgrade connection"),
+			abstractlogger.Error(err),
+		)
+		return
+	}
+
+	done := make(chan bool)
+	errChan := make(chan error)
+
+	options := append([]websocket.HandleOptionFunc{
+		websocket.WithLogger(h.options.Logger),
+		websocket.WithProtocolFromRequestHeaders(r),
+	}, h.options.WebsocketHandleOptions...)
+
+	executorPool := subscription.NewExecutorV2Pool(h.engine, subscription.NewInitialHttpRequestContext(r))
+	go websocket.Handle(done, errChan, conn, executorPool, options...)
+	select {
+	case err := <-errChan:
+		h.options.Logger.Error("http.Handler.upgrade",
+			abstractlogger.String("message", "could not handle websocket connection"),
+			abstractlogger.Error(err),
+		)
+	case <-done:
+	}
+}
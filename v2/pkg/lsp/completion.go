@@ -0,0 +1,163 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astparser"
+)
+
+// complete returns the completion items at offset, items are filtered by the word typed at the position
+func complete(s *schema, doc *document, offset int) []CompletionItem {
+	ctx := analyzeContext(s, doc.text, offset)
+	prefix := doc.text[ctx.wordStart:offset]
+
+	var items []CompletionItem
+	switch ctx.kind {
+	case contextSelection:
+		items = completeFields(s, ctx.typeName)
+	case contextFieldArgument:
+		if ref, ok := s.fieldDefinition(ctx.typeName, ctx.fieldName); ok {
+			items = completeArguments(s, s.definition.FieldDefinitionArgumentsDefinitions(ref))
+		}
+	case contextDirectiveArgument:
+		if ref, ok := s.definition.DirectiveDefinitionByName(ctx.directiveName); ok {
+			items = completeArguments(s, s.definition.DirectiveDefinitions[ref].ArgumentsDefinition.Refs)
+		}
+	case contextDirective:
+		items = completeDirectives(s)
+	case contextTypeCondition:
+		items = completeTypes(s, func(kind ast.NodeKind) bool {
+			return kind == ast.NodeKindObjectTypeDefinition || kind == ast.NodeKindInterfaceTypeDefinition || kind == ast.NodeKindUnionTypeDefinition
+		})
+	case contextVariableType:
+		items = completeTypes(s, func(kind ast.NodeKind) bool {
+			return kind == ast.NodeKindInputObjectTypeDefinition || kind == ast.NodeKindScalarTypeDefinition || kind == ast.NodeKindEnumTypeDefinition
+		})
+	case contextFragmentSpread:
+		items = completeFragments(doc)
+	}
+
+	filtered := items[:0]
+	for _, item := range items {
+		if strings.HasPrefix(item.Label, prefix) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+func completeFields(s *schema, typeName string) []CompletionItem {
+	node, ok := s.node(typeName)
+	if !ok {
+		return nil
+	}
+
+	items := make([]CompletionItem, 0, len(s.definition.NodeFieldDefinitions(node))+1)
+	for _, ref := range s.definition.NodeFieldDefinitions(node) {
+		name := s.definition.FieldDefinitionNameString(ref)
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		_, deprecated := s.deprecationReason(s.definition.FieldDefinitionDirectives(ref))
+		items = append(items, CompletionItem{
+			Label:         name,
+			Kind:          CompletionItemKindField,
+			Detail:        s.printType(s.definition.FieldDefinitionType(ref)),
+			Documentation: s.definition.FieldDefinitionDescriptionString(ref),
+			Deprecated:    deprecated,
+		})
+	}
+	items = append(items, CompletionItem{
+		Label:  "__typename",
+		Kind:   CompletionItemKindField,
+		Detail: "String!",
+	})
+
+	if node.Kind == ast.NodeKindUnionTypeDefinition || node.Kind == ast.NodeKindInterfaceTypeDefinition {
+		items = append(items, CompletionItem{
+			Label:      "... on",
+			Kind:       CompletionItemKindKeyword,
+			InsertText: "... on ",
+		})
+	}
+	return items
+}
+
+func completeArguments(s *schema, refs []int) []CompletionItem {
+	items := make([]CompletionItem, 0, len(refs))
+	for _, ref := range refs {
+		name := s.definition.InputValueDefinitionNameString(ref)
+		items = append(items, CompletionItem{
+			Label:         name,
+			Kind:          CompletionItemKindVariable,
+			Detail:        s.printType(s.definition.InputValueDefinitionType(ref)),
+			Documentation: s.definition.InputValueDefinitionDescriptionString(ref),
+			InsertText:    name + ": ",
+		})
+	}
+	return items
+}
+
+func completeDirectives(s *schema) []CompletionItem {
+	items := make([]CompletionItem, 0, len(s.definition.DirectiveDefinitions))
+	for ref := range s.definition.DirectiveDefinitions {
+		items = append(items, CompletionItem{
+			Label:         s.definition.DirectiveDefinitionNameString(ref),
+			Kind:          CompletionItemKindKeyword,
+			Documentation: s.definition.DirectiveDefinitionDescriptionString(ref),
+		})
+	}
+	return items
+}
+
+func completeTypes(s *schema, include func(kind ast.NodeKind) bool) []CompletionItem {
+	var items []CompletionItem
+	for _, node := range s.definition.RootNodes {
+		if !include(node.Kind) {
+			continue
+		}
+		name := s.definition.NodeNameString(node)
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		items = append(items, CompletionItem{
+			Label:         name,
+			Kind:          completionItemKind(node.Kind),
+			Documentation: s.nodeDescription(node),
+		})
+	}
+	return items
+}
+
+func completeFragments(doc *document) []CompletionItem {
+	operation, _, _ := astparser.ParseGraphqlDocumentStringTolerant(doc.text)
+
+	items := make([]CompletionItem, 0, len(operation.FragmentDefinitions)+1)
+	items = append(items, CompletionItem{
+		Label:      "on",
+		Kind:       CompletionItemKindKeyword,
+		InsertText: "on ",
+	})
+	for ref := range operation.FragmentDefinitions {
+		items = append(items, CompletionItem{
+			Label:  operation.FragmentDefinitionNameString(ref),
+			Kind:   CompletionItemKindStruct,
+			Detail: "on " + operation.FragmentDefinitionTypeNameString(ref),
+		})
+	}
+	return items
+}
+
+func completionItemKind(kind ast.NodeKind) CompletionItemKind {
+	switch kind {
+	case ast.NodeKindInterfaceTypeDefinition:
+		return CompletionItemKindInterface
+	case ast.NodeKindEnumTypeDefinition:
+		return CompletionItemKindEnum
+	case ast.NodeKindInputObjectTypeDefinition:
+		return CompletionItemKindStruct
+	default:
+		return CompletionItemKindClass
+	}
+}
@@ -0,0 +1,216 @@
+package lsp
+
+import (
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer/keyword"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer/token"
+)
+
+type contextKind int
+
+const (
+	contextNone contextKind = iota
+	// contextSelection is a field in the selection set of typeName
+	contextSelection
+	// contextFieldArgument is an argument name of the field fieldName on typeName
+	contextFieldArgument
+	// contextDirectiveArgument is an argument name of the directive directiveName
+	contextDirectiveArgument
+	// contextDirective is a directive name
+	contextDirective
+	// contextTypeCondition is the type of an inline fragment or fragment definition
+	contextTypeCondition
+	// contextVariableType is the type of a variable definition
+	contextVariableType
+	// contextFragmentSpread is the name of a spread fragment
+	contextFragmentSpread
+)
+
+// cursorContext describes the syntactical context of a position in an executable document
+type cursorContext struct {
+	kind          contextKind
+	typeName      string
+	fieldName     string
+	directiveName string
+	// word is the name at the position, wordStart and wordEnd are its byte offsets
+	word      string
+	wordStart int
+	wordEnd   int
+	// isAlias is true if the word is the alias of a field
+	isAlias bool
+}
+
+// argumentsOwner is the field, directive or operation an argument list belongs to
+type argumentsOwner struct {
+	typeName      string
+	fieldName     string
+	directiveName string
+	variables     bool
+}
+
+// analyzeContext determines the context at offset by scanning the tokens in front of it.
+// Other than the parser it works on incomplete documents, e.g. while a selection set is typed.
+func analyzeContext(s *schema, text string, offset int) cursorContext {
+	tokens := lex(text)
+
+	result := cursorContext{wordStart: offset, wordEnd: offset}
+	stop := len(tokens)
+	for i, tok := range tokens {
+		if tok.Keyword == keyword.IDENT && int(tok.Literal.Start) <= offset && int(tok.Literal.End) >= offset {
+			stop = i
+			result.word = text[tok.Literal.Start:tok.Literal.End]
+			result.wordStart = int(tok.Literal.Start)
+			result.wordEnd = int(tok.Literal.End)
+			result.isAlias = i+1 < len(tokens) && tokens[i+1].Keyword == keyword.COLON
+			break
+		}
+		if int(tok.Literal.Start) >= offset {
+			stop = i
+			break
+		}
+	}
+
+	var (
+		stack         []string
+		pendingType   string
+		lastFieldType string
+		lastField     string
+		expectType    bool
+		parens        int
+		owner         argumentsOwner
+	)
+
+	identAt := func(i int) string {
+		return text[tokens[i].Literal.Start:tokens[i].Literal.End]
+	}
+	previous := func(i int) keyword.Keyword {
+		if i == 0 {
+			return keyword.UNDEFINED
+		}
+		return tokens[i-1].Keyword
+	}
+
+	for i := 0; i < stop; i++ {
+		tok := tokens[i]
+
+		if parens > 0 {
+			switch tok.Keyword {
+			case keyword.LPAREN, keyword.LBRACK, keyword.LBRACE:
+				parens++
+			case keyword.RPAREN, keyword.RBRACK, keyword.RBRACE:
+				parens--
+			}
+			continue
+		}
+
+		switch tok.Keyword {
+		case keyword.LPAREN:
+			parens = 1
+			switch {
+			case i >= 2 && tokens[i-1].Keyword == keyword.IDENT && tokens[i-2].Keyword == keyword.AT:
+				owner = argumentsOwner{directiveName: identAt(i - 1)}
+			case len(stack) == 0:
+				owner = argumentsOwner{variables: true}
+			default:
+				owner = argumentsOwner{typeName: stack[len(stack)-1], fieldName: lastField}
+			}
+		case keyword.LBRACE:
+			typeName := pendingType
+			if typeName == "" {
+				if len(stack) == 0 {
+					typeName = s.rootTypeName("query")
+				} else {
+					typeName = lastFieldType
+				}
+			}
+			stack = append(stack, typeName)
+			pendingType, lastField, lastFieldType = "", "", ""
+		case keyword.RBRACE:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			pendingType, lastField, lastFieldType = "", "", ""
+		case keyword.IDENT:
+			name := identAt(i)
+			switch {
+			case previous(i) == keyword.AT:
+				// directive name
+			case expectType:
+				pendingType = name
+				expectType = false
+			case name == "on" && (previous(i) == keyword.SPREAD || len(stack) == 0):
+				expectType = true
+			case previous(i) == keyword.SPREAD:
+				// fragment spread
+			case len(stack) == 0:
+				if rootTypeName := s.rootTypeName(name); rootTypeName != "" && previous(i) != keyword.COLON {
+					pendingType = rootTypeName
+				}
+			case i+1 < len(tokens) && tokens[i+1].Keyword == keyword.COLON:
+				// alias
+			default:
+				lastField = name
+				lastFieldType = s.fieldTypeName(stack[len(stack)-1], name)
+			}
+		}
+	}
+
+	previousKeyword := keyword.UNDEFINED
+	if stop > 0 {
+		previousKeyword = tokens[stop-1].Keyword
+	}
+
+	switch {
+	case previousKeyword == keyword.AT:
+		result.kind = contextDirective
+	case expectType:
+		result.kind = contextTypeCondition
+	case parens > 0:
+		if owner.variables {
+			if previousKeyword == keyword.COLON || previousKeyword == keyword.LBRACK {
+				result.kind = contextVariableType
+			}
+			break
+		}
+		if parens != 1 || previousKeyword == keyword.COLON || previousKeyword == keyword.DOLLAR {
+			break
+		}
+		result.typeName, result.fieldName, result.directiveName = owner.typeName, owner.fieldName, owner.directiveName
+		if owner.directiveName != "" {
+			result.kind = contextDirectiveArgument
+		} else {
+			result.kind = contextFieldArgument
+		}
+	case previousKeyword == keyword.SPREAD:
+		result.kind = contextFragmentSpread
+		if len(stack) > 0 {
+			result.typeName = stack[len(stack)-1]
+		}
+	case len(stack) > 0:
+		result.kind = contextSelection
+		result.typeName = stack[len(stack)-1]
+	}
+
+	return result
+}
+
+// lex returns all tokens of text except comments
+func lex(text string) []token.Token {
+	input := &ast.Input{}
+	input.ResetInputString(text)
+	l := &lexer.Lexer{}
+	l.SetInput(input)
+
+	var tokens []token.Token
+	for {
+		tok := l.Read()
+		switch tok.Keyword {
+		case keyword.EOF:
+			return tokens
+		case keyword.COMMENT:
+			continue
+		}
+		tokens = append(tokens, tok)
+	}
+}
@@ -0,0 +1,87 @@
+package lsp
+
+import (
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astparser"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astvalidation"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/operationreport"
+)
+
+const diagnosticSource = "graphql"
+
+// diagnoseOperations reports syntax errors and validation errors of an executable document
+func diagnoseOperations(s *schema, validator *astvalidation.OperationValidator, doc *document) []Diagnostic {
+	operation, syntaxErrors, _ := astparser.ParseGraphqlDocumentStringTolerant(doc.text)
+
+	diagnostics := syntaxDiagnostics(doc, syntaxErrors)
+	if s == nil || len(operation.RootNodes) == 0 {
+		return diagnostics
+	}
+
+	report := operationreport.Report{}
+	validator.Validate(&operation, s.definition, &report)
+	return append(diagnostics, reportDiagnostics(doc, report)...)
+}
+
+// diagnoseSchema reports syntax errors and validation errors of the schema
+func diagnoseSchema(doc *document) []Diagnostic {
+	_, syntaxErrors, _ := astparser.ParseGraphqlDocumentStringTolerant(doc.text)
+	if len(syntaxErrors) != 0 {
+		return syntaxDiagnostics(doc, syntaxErrors)
+	}
+
+	s, err := parseSchema(doc.uri, doc.text)
+	if err != nil {
+		return errorDiagnostics(doc, err)
+	}
+
+	report := operationreport.Report{}
+	astvalidation.DefaultDefinitionValidator().Validate(s.definition, &report)
+	return reportDiagnostics(doc, report)
+}
+
+func syntaxDiagnostics(doc *document, syntaxErrors []astparser.Diagnostic) []Diagnostic {
+	diagnostics := make([]Diagnostic, 0, len(syntaxErrors))
+	for _, syntaxError := range syntaxErrors {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    doc.textRange(syntaxError.Position),
+			Severity: SeverityError,
+			Source:   diagnosticSource,
+			Message:  syntaxError.Message,
+		})
+	}
+	return diagnostics
+}
+
+func reportDiagnostics(doc *document, report operationreport.Report) []Diagnostic {
+	diagnostics := make([]Diagnostic, 0, len(report.ExternalErrors)+len(report.InternalErrors))
+	for _, externalError := range report.ExternalErrors {
+		diagnostic := Diagnostic{
+			Severity: SeverityError,
+			Source:   diagnosticSource,
+			Message:  externalError.Message,
+		}
+		if len(externalError.Locations) != 0 {
+			diagnostic.Range = doc.wordRange(externalError.Locations[0].Line, externalError.Locations[0].Column)
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+	for _, internalError := range report.InternalErrors {
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity: SeverityError,
+			Source:   diagnosticSource,
+			Message:  internalError.Error(),
+		})
+	}
+	return diagnostics
+}
+
+func errorDiagnostics(doc *document, err error) []Diagnostic {
+	if report, ok := err.(operationreport.Report); ok {
+		return reportDiagnostics(doc, report)
+	}
+	return []Diagnostic{{
+		Severity: SeverityError,
+		Source:   diagnosticSource,
+		Message:  err.Error(),
+	}}
+}
@@ -0,0 +1,118 @@
+package lsp
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lexer/position"
+)
+
+// document is a text document opened in the editor
+type document struct {
+	uri  string
+	text string
+	// lines contains the byte offset of the beginning of each line
+	lines []int
+}
+
+func newDocument(uri, text string) *document {
+	d := &document{
+		uri:  uri,
+		text: text,
+	}
+	d.lines = append(d.lines, 0)
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			d.lines = append(d.lines, i+1)
+		}
+	}
+	return d
+}
+
+// offset converts a LSP position into a byte offset, positions beyond a line or the text are clamped
+func (d *document) offset(pos Position) int {
+	if pos.Line < 0 {
+		return 0
+	}
+	if pos.Line >= len(d.lines) {
+		return len(d.text)
+	}
+	offset := d.lines[pos.Line]
+	end := d.lineEnd(pos.Line)
+	for character := 0; character < pos.Character && offset < end; {
+		r, size := utf8.DecodeRuneInString(d.text[offset:])
+		offset += size
+		character += len(utf16.Encode([]rune{r}))
+	}
+	return offset
+}
+
+// position converts a byte offset into a LSP position
+func (d *document) position(offset int) Position {
+	if offset > len(d.text) {
+		offset = len(d.text)
+	}
+	line := 0
+	for line+1 < len(d.lines) && d.lines[line+1] <= offset {
+		line++
+	}
+	character := 0
+	for _, r := range d.text[d.lines[line]:offset] {
+		character += len(utf16.Encode([]rune{r}))
+	}
+	return Position{Line: line, Character: character}
+}
+
+func (d *document) lineEnd(line int) int {
+	if line+1 < len(d.lines) {
+		return d.lines[line+1] - 1
+	}
+	return len(d.text)
+}
+
+// lineColumnOffset converts a one based line and byte column as used by the lexer into a byte offset
+func (d *document) lineColumnOffset(line, column uint32) int {
+	if line == 0 || int(line) > len(d.lines) {
+		return len(d.text)
+	}
+	offset := d.lines[line-1] + int(column) - 1
+	if end := d.lineEnd(int(line) - 1); offset > end {
+		return end
+	}
+	if offset < 0 {
+		return 0
+	}
+	return offset
+}
+
+// textRange converts a lexer position into a LSP range
+func (d *document) textRange(pos position.Position) Range {
+	return Range{
+		Start: d.position(d.lineColumnOffset(pos.LineStart, pos.CharStart)),
+		End:   d.position(d.lineColumnOffset(pos.LineEnd, pos.CharEnd)),
+	}
+}
+
+// wordRange returns the range of the name starting at the one based line and column,
+// it is used for errors which only carry a location
+func (d *document) wordRange(line, column uint32) Range {
+	start := d.lineColumnOffset(line, column)
+	end := start
+	for end < len(d.text) && isNameByte(d.text[end]) {
+		end++
+	}
+	return Range{Start: d.position(start), End: d.position(end)}
+}
+
+func (d *document) bytesRange(start, end int) Range {
+	return Range{Start: d.position(start), End: d.position(end)}
+}
+
+// fullRange covers the whole document
+func (d *document) fullRange() Range {
+	return d.bytesRange(0, len(d.text))
+}
+
+func isNameByte(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
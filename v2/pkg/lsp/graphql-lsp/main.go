@@ -0,0 +1,44 @@
+// Command graphql-lsp runs the GraphQL language server over stdio.
+//
+//	graphql-lsp -schema schema.graphql
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/lsp"
+)
+
+func main() {
+	schemaFile := flag.String("schema", "schema.graphql", "path to the schema operations are validated against")
+	flag.Parse()
+
+	if err := run(*schemaFile); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(schemaFile string) error {
+	schema, err := os.ReadFile(schemaFile)
+	if err != nil {
+		return err
+	}
+	absolutePath, err := filepath.Abs(schemaFile)
+	if err != nil {
+		return err
+	}
+
+	server, err := lsp.New(lsp.Config{
+		Schema:    schema,
+		SchemaURI: "file://" + filepath.ToSlash(absolutePath),
+	})
+	if err != nil {
+		return err
+	}
+	return server.Serve(context.Background(), os.Stdin, os.Stdout)
+}
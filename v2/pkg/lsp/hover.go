@@ -0,0 +1,127 @@
+package lsp
+
+import (
+	"fmt"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astparser"
+)
+
+// hover describes the field, argument, directive or type at offset
+func hover(s *schema, doc *document, offset int) *Hover {
+	ctx := analyzeContext(s, doc.text, offset)
+	if ctx.word == "" {
+		return nil
+	}
+
+	var contents string
+	switch ctx.kind {
+	case contextSelection:
+		if ctx.isAlias {
+			return nil
+		}
+		if ctx.word == "__typename" {
+			contents = markdown(fmt.Sprintf("%s.__typename: String!", ctx.typeName), "The name of the object type.", "", false)
+			break
+		}
+		ref, ok := s.fieldDefinition(ctx.typeName, ctx.word)
+		if !ok {
+			return nil
+		}
+		reason, deprecated := s.deprecationReason(s.definition.FieldDefinitionDirectives(ref))
+		signature := fmt.Sprintf("%s.%s: %s", ctx.typeName, ctx.word, s.printType(s.definition.FieldDefinitionType(ref)))
+		contents = markdown(signature, s.definition.FieldDefinitionDescriptionString(ref), reason, deprecated)
+	case contextFieldArgument, contextDirectiveArgument:
+		var (
+			ref int
+			ok  bool
+		)
+		if ctx.kind == contextFieldArgument {
+			ref, ok = s.fieldArgument(ctx.typeName, ctx.fieldName, ctx.word)
+		} else {
+			ref, ok = s.directiveArgument(ctx.directiveName, ctx.word)
+		}
+		if !ok {
+			return nil
+		}
+		reason, deprecated := s.deprecationReason(s.definition.InputValueDefinitions[ref].Directives.Refs)
+		signature := fmt.Sprintf("%s: %s", ctx.word, s.printType(s.definition.InputValueDefinitionType(ref)))
+		contents = markdown(signature, s.definition.InputValueDefinitionDescriptionString(ref), reason, deprecated)
+	case contextDirective:
+		ref, ok := s.definition.DirectiveDefinitionByName(ctx.word)
+		if !ok {
+			return nil
+		}
+		contents = markdown("@"+ctx.word, s.definition.DirectiveDefinitionDescriptionString(ref), "", false)
+	case contextTypeCondition, contextVariableType:
+		node, ok := s.node(ctx.word)
+		if !ok {
+			return nil
+		}
+		contents = markdown(fmt.Sprintf("%s %s", typeKeyword(node.Kind), ctx.word), s.nodeDescription(node), "", false)
+	default:
+		return nil
+	}
+
+	wordRange := doc.bytesRange(ctx.wordStart, ctx.wordEnd)
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: contents},
+		Range:    &wordRange,
+	}
+}
+
+// definition returns the location of the fragment, type or field definition at offset
+func definition(s *schema, doc *document, offset int) (Location, bool) {
+	ctx := analyzeContext(s, doc.text, offset)
+	if ctx.word == "" {
+		return Location{}, false
+	}
+
+	switch ctx.kind {
+	case contextFragmentSpread:
+		operation, _, _ := astparser.ParseGraphqlDocumentStringTolerant(doc.text)
+		ref, ok := operation.FragmentDefinitionRef([]byte(ctx.word))
+		if !ok {
+			return Location{}, false
+		}
+		name := operation.FragmentDefinitions[ref].Name
+		return Location{URI: doc.uri, Range: doc.bytesRange(int(name.Start), int(name.End))}, true
+	case contextSelection:
+		if ctx.isAlias {
+			return Location{}, false
+		}
+		ref, ok := s.fieldDefinition(ctx.typeName, ctx.word)
+		if !ok {
+			return Location{}, false
+		}
+		return s.location(s.definition.FieldDefinitions[ref].Name)
+	case contextTypeCondition, contextVariableType:
+		node, ok := s.node(ctx.word)
+		if !ok {
+			return Location{}, false
+		}
+		name, ok := s.nodeNameRef(node)
+		if !ok {
+			return Location{}, false
+		}
+		return s.location(name)
+	}
+	return Location{}, false
+}
+
+func typeKeyword(kind ast.NodeKind) string {
+	switch kind {
+	case ast.NodeKindInterfaceTypeDefinition:
+		return "interface"
+	case ast.NodeKindUnionTypeDefinition:
+		return "union"
+	case ast.NodeKindEnumTypeDefinition:
+		return "enum"
+	case ast.NodeKindInputObjectTypeDefinition:
+		return "input"
+	case ast.NodeKindScalarTypeDefinition:
+		return "scalar"
+	default:
+		return "type"
+	}
+}
@@ -0,0 +1,188 @@
+package lsp
+
+import (
+	"strings"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astnormalization"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astparser"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/asttransform"
+)
+
+// schema is the definition documents are edited against
+type schema struct {
+	definition *ast.Document
+	// text is the schema as opened in the editor, definitions are located in it
+	text *document
+}
+
+// parseSchema parses and normalizes a schema, the base schema with the builtin scalars and directives is added to it
+func parseSchema(uri, sdl string) (*schema, error) {
+	definition, report := astparser.ParseGraphqlDocumentString(sdl)
+	if report.HasErrors() {
+		return nil, report
+	}
+	if err := asttransform.MergeDefinitionWithBaseSchema(&definition); err != nil {
+		return nil, err
+	}
+	astnormalization.NormalizeDefinition(&definition, &report)
+	if report.HasErrors() {
+		return nil, report
+	}
+
+	return &schema{
+		definition: &definition,
+		text:       newDocument(uri, sdl),
+	}, nil
+}
+
+func (s *schema) node(typeName string) (ast.Node, bool) {
+	return s.definition.Index.FirstNonExtensionNodeByNameBytes([]byte(typeName))
+}
+
+// rootTypeName returns the name of the root type for "query", "mutation" or "subscription"
+func (s *schema) rootTypeName(operationType string) string {
+	switch operationType {
+	case "query":
+		return string(s.definition.Index.QueryTypeName)
+	case "mutation":
+		return string(s.definition.Index.MutationTypeName)
+	case "subscription":
+		return string(s.definition.Index.SubscriptionTypeName)
+	}
+	return ""
+}
+
+func (s *schema) fieldDefinition(typeName, fieldName string) (int, bool) {
+	node, ok := s.node(typeName)
+	if !ok {
+		return ast.InvalidRef, false
+	}
+	return s.definition.NodeFieldDefinitionByName(node, []byte(fieldName))
+}
+
+// fieldTypeName returns the named type of a field or an empty string if the field is unknown
+func (s *schema) fieldTypeName(typeName, fieldName string) string {
+	ref, ok := s.fieldDefinition(typeName, fieldName)
+	if !ok {
+		return ""
+	}
+	return s.definition.FieldDefinitionTypeNameString(ref)
+}
+
+func (s *schema) fieldArgument(typeName, fieldName, argumentName string) (int, bool) {
+	ref, ok := s.fieldDefinition(typeName, fieldName)
+	if !ok {
+		return ast.InvalidRef, false
+	}
+	return s.inputValueByName(s.definition.FieldDefinitionArgumentsDefinitions(ref), argumentName)
+}
+
+func (s *schema) directiveArgument(directiveName, argumentName string) (int, bool) {
+	ref, ok := s.definition.DirectiveDefinitionByName(directiveName)
+	if !ok {
+		return ast.InvalidRef, false
+	}
+	return s.inputValueByName(s.definition.DirectiveDefinitions[ref].ArgumentsDefinition.Refs, argumentName)
+}
+
+func (s *schema) inputValueByName(refs []int, name string) (int, bool) {
+	for _, ref := range refs {
+		if s.definition.InputValueDefinitionNameString(ref) == name {
+			return ref, true
+		}
+	}
+	return ast.InvalidRef, false
+}
+
+func (s *schema) printType(ref int) string {
+	out, err := s.definition.PrintTypeBytes(ref, nil)
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+func (s *schema) nodeDescription(node ast.Node) string {
+	switch node.Kind {
+	case ast.NodeKindObjectTypeDefinition:
+		return s.definition.ObjectTypeDescriptionNameString(node.Ref)
+	case ast.NodeKindInterfaceTypeDefinition:
+		return s.definition.InterfaceTypeDefinitionDescriptionString(node.Ref)
+	case ast.NodeKindUnionTypeDefinition:
+		return s.definition.UnionTypeDefinitionDescriptionString(node.Ref)
+	case ast.NodeKindEnumTypeDefinition:
+		return s.definition.EnumTypeDefinitionDescriptionString(node.Ref)
+	case ast.NodeKindInputObjectTypeDefinition:
+		return s.definition.InputObjectTypeDefinitionDescriptionString(node.Ref)
+	case ast.NodeKindScalarTypeDefinition:
+		return s.definition.ScalarTypeDefinitionDescriptionString(node.Ref)
+	}
+	return ""
+}
+
+// nodeNameRef returns the reference to the name of a type definition
+func (s *schema) nodeNameRef(node ast.Node) (ast.ByteSliceReference, bool) {
+	switch node.Kind {
+	case ast.NodeKindObjectTypeDefinition:
+		return s.definition.ObjectTypeDefinitions[node.Ref].Name, true
+	case ast.NodeKindInterfaceTypeDefinition:
+		return s.definition.InterfaceTypeDefinitions[node.Ref].Name, true
+	case ast.NodeKindUnionTypeDefinition:
+		return s.definition.UnionTypeDefinitions[node.Ref].Name, true
+	case ast.NodeKindEnumTypeDefinition:
+		return s.definition.EnumTypeDefinitions[node.Ref].Name, true
+	case ast.NodeKindInputObjectTypeDefinition:
+		return s.definition.InputObjectTypeDefinitions[node.Ref].Name, true
+	case ast.NodeKindScalarTypeDefinition:
+		return s.definition.ScalarTypeDefinitions[node.Ref].Name, true
+	}
+	return ast.ByteSliceReference{}, false
+}
+
+// location returns the location of a name in the schema text,
+// names of the base schema and imported nodes are outside the text and have no location
+func (s *schema) location(ref ast.ByteSliceReference) (Location, bool) {
+	if s.text.uri == "" || ref.Length() == 0 || int(ref.End) > len(s.text.text) {
+		return Location{}, false
+	}
+	if s.text.text[ref.Start:ref.End] != string(s.definition.Input.ByteSlice(ref)) {
+		return Location{}, false
+	}
+	return Location{
+		URI:   s.text.uri,
+		Range: s.text.bytesRange(int(ref.Start), int(ref.End)),
+	}, true
+}
+
+// deprecationReason returns the reason of a @deprecated directive in the given directives
+func (s *schema) deprecationReason(directiveRefs []int) (string, bool) {
+	for _, ref := range directiveRefs {
+		if s.definition.DirectiveNameString(ref) != "deprecated" {
+			continue
+		}
+		reason := "No longer supported"
+		if value, ok := s.definition.DirectiveArgumentValueByName(ref, []byte("reason")); ok && value.Kind == ast.ValueKindString {
+			reason = s.definition.StringValueContentString(value.Ref)
+		}
+		return reason, true
+	}
+	return "", false
+}
+
+// markdown renders a definition as GraphQL code block followed by its description and deprecation reason
+func markdown(signature, description, deprecationReason string, deprecated bool) string {
+	out := strings.Builder{}
+	out.WriteString("```graphql\n")
+	out.WriteString(signature)
+	out.WriteString("\n```")
+	if description != "" {
+		out.WriteString("\n\n")
+		out.WriteString(description)
+	}
+	if deprecated {
+		out.WriteString("\n\n**Deprecated**: ")
+		out.WriteString(deprecationReason)
+	}
+	return out.String()
+}
@@ -0,0 +1,306 @@
+// Package lsp implements a GraphQL language server for executable documents edited against a schema.
+//
+// The server supports diagnostics from the parser and the astvalidation rules, completion of fields,
+// arguments, directives, types and fragments, hover with descriptions and deprecation reasons,
+// go-to-definition for fields, types and fragments and formatting via astprinter.Format.
+// It speaks JSON-RPC over any reader and writer, usually stdin and stdout:
+//
+//	server, err := lsp.New(lsp.Config{Schema: sdl, SchemaURI: "file:///schema.graphql"})
+//	if err != nil {
+//		return err
+//	}
+//	return server.Serve(ctx, os.Stdin, os.Stdout)
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astparser"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astprinter"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/astvalidation"
+)
+
+// Config configures the language server
+type Config struct {
+	// Schema is the SDL of the schema documents are validated against
+	Schema []byte
+	// SchemaURI is the document URI of the schema.
+	// It's the target of go-to-definition for fields and types,
+	// when the schema is opened in the editor it gets validated and changes are applied to the other documents.
+	SchemaURI string
+	// ValidationRules overrides the rules of astvalidation.DefaultOperationValidator
+	ValidationRules []astvalidation.Rule
+	// FormattingIndent is the indent used by astprinter.Format to format documents, defaults to two spaces
+	FormattingIndent string
+}
+
+// Server is a GraphQL language server, use New to create it.
+// A Server handles the messages of a single client sequentially.
+type Server struct {
+	config    Config
+	schema    *schema
+	validator *astvalidation.OperationValidator
+	documents map[string]*document
+	conn      *conn
+	shutdown  bool
+}
+
+// New creates a language server for the schema of the config
+func New(config Config) (*Server, error) {
+	s, err := parseSchema(config.SchemaURI, string(config.Schema))
+	if err != nil {
+		return nil, fmt.Errorf("lsp: invalid schema: %w", err)
+	}
+
+	validator := astvalidation.DefaultOperationValidator()
+	if config.ValidationRules != nil {
+		validator = astvalidation.NewOperationValidator(config.ValidationRules)
+	}
+	if config.FormattingIndent == "" {
+		config.FormattingIndent = "  "
+	}
+
+	return &Server{
+		config:    config,
+		schema:    s,
+		validator: validator,
+		documents: map[string]*document{},
+	}, nil
+}
+
+// Serve handles messages from in and writes responses and notifications to out.
+// It returns when the client sends the exit notification, in is closed or the context is done.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.conn = newConn(in, out)
+
+	messages := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			message, err := s.conn.read()
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case messages <- message:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		case message := <-messages:
+			exit, err := s.handleMessage(message)
+			if err != nil || exit {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) handleMessage(message []byte) (exit bool, err error) {
+	var req request
+	if err := json.Unmarshal(message, &req); err != nil {
+		return false, s.conn.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()})
+	}
+	if req.Method == "exit" {
+		return true, nil
+	}
+
+	result, err := s.handle(&req)
+	if req.isNotification() {
+		return false, nil
+	}
+	return false, s.conn.reply(req.ID, result, err)
+}
+
+func (s *Server) handle(req *request) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, &responseError{Code: codeInternalError, Message: fmt.Sprintf("%s: %v", req.Method, r)}
+		}
+	}()
+
+	if s.shutdown {
+		return nil, &responseError{Code: codeInvalidRequest, Message: "server is shut down"}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:           TextDocumentSyncKindFull,
+				CompletionProvider:         CompletionOptions{TriggerCharacters: []string{"{", "(", "@", "."}},
+				HoverProvider:              true,
+				DefinitionProvider:         true,
+				DocumentFormattingProvider: true,
+			},
+			ServerInfo: ServerInfo{Name: "graphql-go-tools"},
+		}, nil
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.update(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		return nil, s.update(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return nil, s.publishDiagnostics(params.TextDocument.URI, []Diagnostic{})
+	case "textDocument/completion":
+		doc, offset, err := s.documentPosition(req)
+		if err != nil || doc == nil {
+			return nil, err
+		}
+		items := complete(s.schema, doc, offset)
+		if items == nil {
+			items = []CompletionItem{}
+		}
+		return CompletionList{Items: items}, nil
+	case "textDocument/hover":
+		doc, offset, err := s.documentPosition(req)
+		if err != nil || doc == nil {
+			return nil, err
+		}
+		if result := hover(s.schema, doc, offset); result != nil {
+			return result, nil
+		}
+		return nil, nil
+	case "textDocument/definition":
+		doc, offset, err := s.documentPosition(req)
+		if err != nil || doc == nil {
+			return nil, err
+		}
+		if location, ok := definition(s.schema, doc, offset); ok {
+			return location, nil
+		}
+		return nil, nil
+	case "textDocument/formatting":
+		var params DocumentFormattingParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		doc, ok := s.documents[params.TextDocument.URI]
+		if !ok {
+			return nil, nil
+		}
+		return s.format(doc), nil
+	}
+
+	if req.isNotification() {
+		return nil, nil
+	}
+	return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+}
+
+// update stores the text of a document and publishes its diagnostics.
+// If the document is the schema, the schema is replaced and all documents are diagnosed again.
+func (s *Server) update(uri, text string) error {
+	doc := newDocument(uri, text)
+	s.documents[uri] = doc
+
+	if uri != s.config.SchemaURI {
+		return s.publishDiagnostics(uri, diagnoseOperations(s.schema, s.validator, doc))
+	}
+
+	diagnostics := diagnoseSchema(doc)
+	if err := s.publishDiagnostics(uri, diagnostics); err != nil {
+		return err
+	}
+	if len(diagnostics) != 0 {
+		return nil
+	}
+	updated, err := parseSchema(uri, text)
+	if err != nil {
+		return nil
+	}
+	s.schema = updated
+
+	for documentURI, document := range s.documents {
+		if documentURI == uri {
+			continue
+		}
+		if err := s.publishDiagnostics(documentURI, diagnoseOperations(s.schema, s.validator, document)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) publishDiagnostics(uri string, diagnostics []Diagnostic) error {
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+	return s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: diagnostics,
+	})
+}
+
+// format returns an edit replacing the whole document with its formatted source, documents with syntax errors aren't formatted
+func (s *Server) format(doc *document) []TextEdit {
+	parsed, report := astparser.ParseGraphqlDocumentStringWithComments(doc.text)
+	if report.HasErrors() {
+		return []TextEdit{}
+	}
+
+	out, err := astprinter.FormatString(&parsed, astprinter.FormatOptions{Indent: s.config.FormattingIndent})
+	if err != nil || out == doc.text {
+		return []TextEdit{}
+	}
+
+	return []TextEdit{{
+		Range:   doc.fullRange(),
+		NewText: out,
+	}}
+}
+
+func (s *Server) documentPosition(req *request) (*document, int, error) {
+	var params TextDocumentPositionParams
+	if err := unmarshalParams(req, &params); err != nil {
+		return nil, 0, err
+	}
+	doc, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return nil, 0, nil
+	}
+	return doc, doc.offset(params.Position), nil
+}
+
+func unmarshalParams(req *request, params interface{}) error {
+	if err := json.Unmarshal(req.Params, params); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,494 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSchemaURI = "file:///schema.graphql"
+	testSchema    = `schema { query: Query }
+
+"""The root query"""
+type Query {
+  """Find a hero"""
+  hero(episode: Episode): Character
+  droid(id: ID!): Droid
+  search(text: String): [SearchResult]
+  oldHero: Character @deprecated(reason: "Use hero")
+}
+
+enum Episode { NEWHOPE EMPIRE JEDI }
+
+interface Character {
+  name: String!
+  friends: [Character]
+}
+
+type Human implements Character {
+  name: String!
+  friends: [Character]
+  height: Float
+}
+
+"A mechanical character"
+type Droid implements Character {
+  name: String!
+  friends: [Character]
+  primaryFunction: String
+}
+
+union SearchResult = Human | Droid
+`
+)
+
+type testClient struct {
+	t             *testing.T
+	in            *io.PipeWriter
+	conn          *conn
+	id            int
+	notifications []notification
+	done          chan error
+}
+
+type testMessage struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+func newTestClient(t *testing.T) *testClient {
+	t.Helper()
+
+	server, err := New(Config{
+		Schema:    []byte(testSchema),
+		SchemaURI: testSchemaURI,
+	})
+	require.NoError(t, err)
+
+	clientToServerReader, clientToServerWriter := io.Pipe()
+	serverToClientReader, serverToClientWriter := io.Pipe()
+
+	client := &testClient{
+		t:    t,
+		in:   clientToServerWriter,
+		conn: newConn(serverToClientReader, clientToServerWriter),
+		done: make(chan error, 1),
+	}
+	go func() {
+		client.done <- server.Serve(context.Background(), clientToServerReader, serverToClientWriter)
+		_ = serverToClientWriter.Close()
+	}()
+	t.Cleanup(func() {
+		_ = clientToServerWriter.Close()
+	})
+
+	var result InitializeResult
+	client.request("initialize", map[string]interface{}{}, &result)
+	client.notify("initialized", map[string]interface{}{})
+	return client
+}
+
+func (c *testClient) notify(method string, params interface{}) {
+	c.t.Helper()
+	require.NoError(c.t, c.conn.notify(method, params))
+}
+
+// request sends a request and reads messages until its response, notifications are collected
+func (c *testClient) request(method string, params interface{}, result interface{}) *responseError {
+	c.t.Helper()
+
+	c.id++
+	id := json.RawMessage(strings.TrimSpace(string(mustMarshal(c.t, c.id))))
+	require.NoError(c.t, c.conn.write(struct {
+		JSONRPC string           `json:"jsonrpc"`
+		ID      *json.RawMessage `json:"id"`
+		Method  string           `json:"method"`
+		Params  interface{}      `json:"params"`
+	}{"2.0", &id, method, params}))
+
+	for {
+		message := c.read()
+		if message.ID == nil {
+			c.notifications = append(c.notifications, notification{Method: message.Method, Params: message.Params})
+			continue
+		}
+		require.Equal(c.t, c.id, *message.ID)
+		if message.Error != nil {
+			return message.Error
+		}
+		if result != nil {
+			require.NoError(c.t, json.Unmarshal(message.Result, result))
+		}
+		return nil
+	}
+}
+
+// diagnostics returns the next diagnostics published for uri
+func (c *testClient) diagnostics(uri string) []Diagnostic {
+	c.t.Helper()
+
+	for {
+		var params json.RawMessage
+		if len(c.notifications) > 0 {
+			next := c.notifications[0]
+			c.notifications = c.notifications[1:]
+			if next.Method != "textDocument/publishDiagnostics" {
+				continue
+			}
+			params = next.Params.(json.RawMessage)
+		} else {
+			message := c.read()
+			if message.Method != "textDocument/publishDiagnostics" {
+				continue
+			}
+			params = message.Params
+		}
+
+		var published PublishDiagnosticsParams
+		require.NoError(c.t, json.Unmarshal(params, &published))
+		if published.URI == uri {
+			return published.Diagnostics
+		}
+	}
+}
+
+func (c *testClient) read() testMessage {
+	c.t.Helper()
+
+	type result struct {
+		body []byte
+		err  error
+	}
+	read := make(chan result, 1)
+	go func() {
+		body, err := c.conn.read()
+		read <- result{body, err}
+	}()
+
+	select {
+	case r := <-read:
+		require.NoError(c.t, r.err)
+		var message testMessage
+		require.NoError(c.t, json.Unmarshal(r.body, &message))
+		return message
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("timeout waiting for message")
+		return testMessage{}
+	}
+}
+
+func (c *testClient) open(uri, text string) []Diagnostic {
+	c.t.Helper()
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, LanguageID: "graphql", Version: 1, Text: text},
+	})
+	return c.diagnostics(uri)
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	out, err := json.Marshal(v)
+	require.NoError(t, err)
+	return out
+}
+
+// cursor removes the "|" marker from text and returns its position
+func cursor(text string) (string, Position) {
+	index := strings.Index(text, "|")
+	doc := newDocument("", text[:index]+text[index+1:])
+	return doc.text, doc.position(index)
+}
+
+func completionLabels(items []CompletionItem) []string {
+	labels := make([]string, 0, len(items))
+	for _, item := range items {
+		labels = append(labels, item.Label)
+	}
+	return labels
+}
+
+func TestServer(t *testing.T) {
+	t.Run("initialize", func(t *testing.T) {
+		client := newTestClient(t)
+		var result InitializeResult
+		assert.Nil(t, client.request("initialize", map[string]interface{}{}, &result))
+		assert.True(t, result.Capabilities.HoverProvider)
+		assert.True(t, result.Capabilities.DefinitionProvider)
+		assert.True(t, result.Capabilities.DocumentFormattingProvider)
+		assert.Equal(t, TextDocumentSyncKindFull, result.Capabilities.TextDocumentSync)
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		client := newTestClient(t)
+		err := client.request("workspace/symbol", map[string]interface{}{}, nil)
+		require.NotNil(t, err)
+		assert.Equal(t, codeMethodNotFound, err.Code)
+	})
+
+	t.Run("shutdown and exit", func(t *testing.T) {
+		client := newTestClient(t)
+		assert.Nil(t, client.request("shutdown", nil, nil))
+		client.notify("exit", nil)
+		select {
+		case err := <-client.done:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("server did not exit")
+		}
+	})
+}
+
+func TestServer_Diagnostics(t *testing.T) {
+	client := newTestClient(t)
+
+	diagnostics := client.open("file:///query.graphql", `query Hero {
+  hero {
+    name
+  }
+  droid(id: 1, name: "R2") { name }
+}
+
+query Broken {
+  droid(id: ) { name }
+}
+`)
+	assert.Equal(t, []Diagnostic{
+		{
+			Range:    Range{Start: Position{Line: 8, Character: 12}, End: Position{Line: 8, Character: 13}},
+			Severity: SeverityError,
+			Source:   diagnosticSource,
+			Message:  "unexpected token - got: RPAREN want one of: []",
+		},
+		{
+			Range:    Range{Start: Position{Line: 4, Character: 15}, End: Position{Line: 4, Character: 19}},
+			Severity: SeverityError,
+			Source:   diagnosticSource,
+			Message:  `Unknown argument "name" on field "Query.droid".`,
+		},
+	}, diagnostics)
+
+	client.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: "file:///query.graphql"},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: `query Hero { hero { name } }`}},
+	})
+	assert.Empty(t, client.diagnostics("file:///query.graphql"))
+
+	t.Run("schema changes are applied to open documents", func(t *testing.T) {
+		client.notify("textDocument/didChange", DidChangeTextDocumentParams{
+			TextDocument:   TextDocumentIdentifier{URI: "file:///query.graphql"},
+			ContentChanges: []TextDocumentContentChangeEvent{{Text: `query Droid { droid(id: 1) { age } }`}},
+		})
+		require.Len(t, client.diagnostics("file:///query.graphql"), 1)
+
+		schemaDiagnostics := client.open(testSchemaURI, strings.Replace(testSchema, "primaryFunction: String", "primaryFunction: String\n  age: Int", 1))
+		assert.Empty(t, schemaDiagnostics)
+		assert.Empty(t, client.diagnostics("file:///query.graphql"))
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		schemaDiagnostics := client.open(testSchemaURI, "type Query { hero: Unknown }")
+		require.Len(t, schemaDiagnostics, 1)
+		assert.Contains(t, schemaDiagnostics[0].Message, "Unknown")
+	})
+}
+
+func TestServer_Completion(t *testing.T) {
+	client := newTestClient(t)
+
+	run := func(t *testing.T, text string) []CompletionItem {
+		t.Helper()
+		text, position := cursor(text)
+		client.open("file:///completion.graphql", text)
+
+		var list CompletionList
+		require.Nil(t, client.request("textDocument/completion", TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: "file:///completion.graphql"},
+			Position:     position,
+		}, &list))
+		return list.Items
+	}
+
+	t.Run("root fields", func(t *testing.T) {
+		items := run(t, "{ | }")
+		assert.Equal(t, []string{"hero", "droid", "search", "oldHero", "__typename"}, completionLabels(items))
+		assert.Equal(t, CompletionItem{Label: "hero", Kind: CompletionItemKindField, Detail: "Character", Documentation: "Find a hero"}, items[0])
+		assert.True(t, items[3].Deprecated)
+	})
+
+	t.Run("fields filtered by prefix", func(t *testing.T) {
+		items := run(t, "query Droid { droid(id: 1) { friends { na| } } }")
+		assert.Equal(t, []string{"name"}, completionLabels(items))
+	})
+
+	t.Run("fields of incomplete selection set", func(t *testing.T) {
+		items := run(t, "query Droid {\n  droid(id: 1) {\n    |")
+		assert.Equal(t, []string{"name", "friends", "primaryFunction", "__typename"}, completionLabels(items))
+	})
+
+	t.Run("fields after alias", func(t *testing.T) {
+		items := run(t, "{ r2: droid(id: 1) { primary: p| } }")
+		assert.Equal(t, []string{"primaryFunction"}, completionLabels(items))
+	})
+
+	t.Run("abstract type", func(t *testing.T) {
+		items := run(t, "{ search { | } }")
+		assert.Equal(t, []string{"__typename", "... on"}, completionLabels(items))
+	})
+
+	t.Run("type condition", func(t *testing.T) {
+		items := run(t, "{ search { ... on | } }")
+		assert.Equal(t, []string{"Query", "Character", "Human", "Droid", "SearchResult"}, completionLabels(items))
+	})
+
+	t.Run("inline fragment fields", func(t *testing.T) {
+		items := run(t, "{ search { ... on Human { h| } } }")
+		assert.Equal(t, []string{"height"}, completionLabels(items))
+	})
+
+	t.Run("fragment definition fields", func(t *testing.T) {
+		items := run(t, "fragment DroidFields on Droid { p| }")
+		assert.Equal(t, []string{"primaryFunction"}, completionLabels(items))
+	})
+
+	t.Run("fragment spread", func(t *testing.T) {
+		items := run(t, "{ hero { ...| } }\nfragment CharacterFields on Character { name }")
+		assert.Equal(t, []string{"on", "CharacterFields"}, completionLabels(items))
+		assert.Equal(t, "on Character", items[1].Detail)
+	})
+
+	t.Run("field arguments", func(t *testing.T) {
+		items := run(t, "{ hero(|) { name } }")
+		assert.Equal(t, []CompletionItem{{Label: "episode", Kind: CompletionItemKindVariable, Detail: "Episode", InsertText: "episode: "}}, items)
+	})
+
+	t.Run("no arguments in value position", func(t *testing.T) {
+		items := run(t, "{ hero(episode: |) { name } }")
+		assert.Empty(t, items)
+	})
+
+	t.Run("directives", func(t *testing.T) {
+		items := run(t, "{ hero @| { name } }")
+		assert.Subset(t, completionLabels(items), []string{"include", "skip", "deprecated"})
+	})
+
+	t.Run("directive arguments", func(t *testing.T) {
+		items := run(t, "{ hero @include(|) { name } }")
+		assert.Equal(t, []string{"if"}, completionLabels(items))
+	})
+
+	t.Run("variable types", func(t *testing.T) {
+		items := run(t, "query Hero($episode: E|) { hero { name } }")
+		assert.Equal(t, []string{"Episode"}, completionLabels(items))
+	})
+}
+
+func TestServer_Hover(t *testing.T) {
+	client := newTestClient(t)
+
+	run := func(t *testing.T, text string) *Hover {
+		t.Helper()
+		text, position := cursor(text)
+		client.open("file:///hover.graphql", text)
+
+		var result *Hover
+		require.Nil(t, client.request("textDocument/hover", TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: "file:///hover.graphql"},
+			Position:     position,
+		}, &result))
+		return result
+	}
+
+	t.Run("field", func(t *testing.T) {
+		result := run(t, "{ he|ro { name } }")
+		require.NotNil(t, result)
+		assert.Equal(t, "```graphql\nQuery.hero: Character\n```\n\nFind a hero", result.Contents.Value)
+		assert.Equal(t, &Range{Start: Position{Line: 0, Character: 2}, End: Position{Line: 0, Character: 6}}, result.Range)
+	})
+
+	t.Run("deprecated field", func(t *testing.T) {
+		result := run(t, "{ oldHero| { name } }")
+		require.NotNil(t, result)
+		assert.Equal(t, "```graphql\nQuery.oldHero: Character\n```\n\n**Deprecated**: Use hero", result.Contents.Value)
+	})
+
+	t.Run("argument", func(t *testing.T) {
+		result := run(t, "{ droid(|id: 1) { name } }")
+		require.NotNil(t, result)
+		assert.Equal(t, "```graphql\nid: ID!\n```", result.Contents.Value)
+	})
+
+	t.Run("type condition", func(t *testing.T) {
+		result := run(t, "{ search { ... on Dro|id { name } } }")
+		require.NotNil(t, result)
+		assert.Equal(t, "```graphql\ntype Droid\n```\n\nA mechanical character", result.Contents.Value)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		assert.Nil(t, run(t, "{ unknown| }"))
+	})
+}
+
+func TestServer_Definition(t *testing.T) {
+	client := newTestClient(t)
+
+	run := func(t *testing.T, text string) *Location {
+		t.Helper()
+		text, position := cursor(text)
+		client.open("file:///definition.graphql", text)
+
+		var result *Location
+		require.Nil(t, client.request("textDocument/definition", TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: "file:///definition.graphql"},
+			Position:     position,
+		}, &result))
+		return result
+	}
+
+	t.Run("field", func(t *testing.T) {
+		assert.Equal(t, &Location{
+			URI:   testSchemaURI,
+			Range: Range{Start: Position{Line: 6, Character: 2}, End: Position{Line: 6, Character: 7}},
+		}, run(t, "{ dro|id(id: 1) { name } }"))
+	})
+
+	t.Run("type", func(t *testing.T) {
+		assert.Equal(t, &Location{
+			URI:   testSchemaURI,
+			Range: Range{Start: Position{Line: 18, Character: 5}, End: Position{Line: 18, Character: 10}},
+		}, run(t, "{ search { ... on Hu|man { name } } }"))
+	})
+
+	t.Run("fragment", func(t *testing.T) {
+		assert.Equal(t, &Location{
+			URI:   "file:///definition.graphql",
+			Range: Range{Start: Position{Line: 1, Character: 9}, End: Position{Line: 1, Character: 24}},
+		}, run(t, "{ hero { ...Character|Fields } }\nfragment CharacterFields on Character { name }"))
+	})
+
+	t.Run("builtin type", func(t *testing.T) {
+		assert.Nil(t, run(t, "query Q($id: I|D!) { droid(id: $id) { name } }"))
+	})
+}
+
+func TestServer_Formatting(t *testing.T) {
+	client := newTestClient(t)
+	client.open("file:///format.graphql", "query Hero{hero{name # keep me\n...on Droid{primaryFunction}}}")
+
+	var edits []TextEdit
+	require.Nil(t, client.request("textDocument/formatting", DocumentFormattingParams{
+		TextDocument: TextDocumentIdentifier{URI: "file:///format.graphql"},
+	}, &edits))
+	assert.Equal(t, []TextEdit{{
+		Range:   Range{Start: Position{Line: 0, Character: 0}, End: Position{Line: 1, Character: 30}},
+		NewText: "query Hero {\n  hero {\n    name # keep me\n    ... on Droid {\n      primaryFunction\n    }\n  }\n}\n",
+	}}, edits)
+}
@@ -0,0 +1,63 @@
+// Package metrics defines the measurements reported by the engine, the resolver, the subscription handlers and data sources.
+package metrics
+
+import (
+	"time"
+)
+
+// Metrics receives the measurements of the engine and its components.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// OperationCompleted is called after the engine executed an operation
+	OperationCompleted(operationType, operationName string, duration time.Duration, failed bool)
+	// PlanCacheLookup is called for every lookup of an execution plan in the plan cache of the engine
+	PlanCacheLookup(hit bool)
+	// FetchCompleted is called after a fetch of a data source completed
+	FetchCompleted(dataSourceID string, duration time.Duration, failed bool)
+	// ActiveSubscriptions reports the number of subscriptions and triggers of the resolver whenever they change.
+	// Subscriptions sharing the same upstream subscription share a trigger.
+	ActiveSubscriptions(subscriptions, triggers int)
+	// SubscriptionUpdatesDropped is called when updates of a subscription were dropped because its client could not keep up,
+	// policy is the overflow policy of the resolver which dropped the updates
+	SubscriptionUpdatesDropped(policy string, count int)
+	// WebSocketConnectionOpened is called when a client opened a websocket connection
+	WebSocketConnectionOpened()
+	// WebSocketConnectionClosed is called when a websocket connection of a client was closed
+	WebSocketConnectionClosed()
+	// KafkaConsumerLag reports the number of messages of a partition which were not consumed by the consumer group yet
+	KafkaConsumerLag(groupID, topic string, partition int32, lag int64)
+}
+
+// Noop is a Metrics implementation discarding all measurements, it's used if no metrics are configured.
+type Noop struct{}
+
+func (Noop) OperationCompleted(operationType, operationName string, duration time.Duration, failed bool) {
+}
+
+func (Noop) PlanCacheLookup(hit bool) {}
+
+func (Noop) FetchCompleted(dataSourceID string, duration time.Duration, failed bool) {}
+
+func (Noop) ActiveSubscriptions(subscriptions, triggers int) {}
+
+func (Noop) SubscriptionUpdatesDropped(policy string, count int) {}
+
+func (Noop) WebSocketConnectionOpened() {}
+
+func (Noop) WebSocketConnectionClosed() {}
+
+func (Noop) KafkaConsumerLag(groupID, topic string, partition int32, lag int64) {}
+
+// OrNoop returns m or Noop if m is nil
+func OrNoop(m Metrics) Metrics {
+	if m == nil {
+		return Noop{}
+	}
+	return m
+}
+
+// Interface Guards
+var (
+	_ Metrics = Noop{}
+	_ Metrics = (*Prometheus)(nil)
+)
@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultPrometheusNamespace is the namespace of all metrics if PrometheusOptions.Namespace is empty
+	DefaultPrometheusNamespace = "graphql"
+
+	labelOperationType = "operation_type"
+	labelOperationName = "operation_name"
+	labelStatus        = "status"
+	labelResult        = "result"
+	labelDataSource    = "datasource"
+	labelGroupID       = "group_id"
+	labelTopic         = "topic"
+	labelPartition     = "partition"
+	labelPolicy        = "policy"
+
+	statusSuccess = "success"
+	statusError   = "error"
+	resultHit     = "hit"
+	resultMiss    = "miss"
+)
+
+// PrometheusOptions configures the Prometheus metrics.
+type PrometheusOptions struct {
+	// Namespace prefixes all metric names, defaults to DefaultPrometheusNamespace
+	Namespace string
+	// DurationBuckets are the buckets in seconds of the operation and fetch duration histograms, defaults to prometheus.DefBuckets
+	DurationBuckets []float64
+}
+
+// Prometheus is a Metrics implementation recording all measurements as Prometheus metrics.
+type Prometheus struct {
+	operations          *prometheus.CounterVec
+	operationDuration   *prometheus.HistogramVec
+	planCacheLookups    *prometheus.CounterVec
+	fetchDuration       *prometheus.HistogramVec
+	fetchErrors         *prometheus.CounterVec
+	subscriptions       prometheus.Gauge
+	triggers            prometheus.Gauge
+	droppedUpdates      *prometheus.CounterVec
+	websocketConnection prometheus.Gauge
+	kafkaConsumerLag    *prometheus.GaugeVec
+}
+
+// NewPrometheus creates the metrics and registers them with the registerer.
+func NewPrometheus(registerer prometheus.Registerer, options PrometheusOptions) (*Prometheus, error) {
+	if options.Namespace == "" {
+		options.Namespace = DefaultPrometheusNamespace
+	}
+	if options.DurationBuckets == nil {
+		options.DurationBuckets = prometheus.DefBuckets
+	}
+
+	p := &Prometheus{
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: options.Namespace,
+			Name:      "operations_total",
+			Help:      "Number of executed operations.",
+		}, []string{labelOperationType, labelOperationName, labelStatus}),
+		operationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: options.Namespace,
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of the execution of operations.",
+			Buckets:   options.DurationBuckets,
+		}, []string{labelOperationType, labelOperationName}),
+		planCacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: options.Namespace,
+			Name:      "plan_cache_lookups_total",
+			Help:      "Number of lookups in the execution plan cache by result.",
+		}, []string{labelResult}),
+		fetchDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: options.Namespace,
+			Name:      "fetch_duration_seconds",
+			Help:      "Duration of data source fetches.",
+			Buckets:   options.DurationBuckets,
+		}, []string{labelDataSource}),
+		fetchErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: options.Namespace,
+			Name:      "fetch_errors_total",
+			Help:      "Number of failed data source fetches.",
+		}, []string{labelDataSource}),
+		subscriptions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: options.Namespace,
+			Name:      "active_subscriptions",
+			Help:      "Number of active subscriptions.",
+		}),
+		triggers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: options.Namespace,
+			Name:      "active_subscription_triggers",
+			Help:      "Number of active upstream subscriptions shared by subscriptions.",
+		}),
+		droppedUpdates: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: options.Namespace,
+			Name:      "subscription_updates_dropped_total",
+			Help:      "Number of subscription updates dropped because the client could not keep up, by overflow policy.",
+		}, []string{labelPolicy}),
+		websocketConnection: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: options.Namespace,
+			Name:      "websocket_connections",
+			Help:      "Number of open websocket connections of clients.",
+		}),
+		kafkaConsumerLag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: options.Namespace,
+			Name:      "kafka_consumer_lag",
+			Help:      "Number of messages of a partition not consumed by the consumer group yet.",
+		}, []string{labelGroupID, labelTopic, labelPartition}),
+	}
+
+	collectors := []prometheus.Collector{
+		p.operations,
+		p.operationDuration,
+		p.planCacheLookups,
+		p.fetchDuration,
+		p.fetchErrors,
+		p.subscriptions,
+		p.triggers,
+		p.droppedUpdates,
+		p.websocketConnection,
+		p.kafkaConsumerLag,
+	}
+	for _, collector := range collectors {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *Prometheus) OperationCompleted(operationType, operationName string, duration time.Duration, failed bool) {
+	status := statusSuccess
+	if failed {
+		status = statusError
+	}
+	p.operations.WithLabelValues(operationType, operationName, status).Inc()
+	p.operationDuration.WithLabelValues(operationType, operationName).Observe(duration.Seconds())
+}
+
+func (p *Prometheus) PlanCacheLookup(hit bool) {
+	result := resultMiss
+	if hit {
+		result = resultHit
+	}
+	p.planCacheLookups.WithLabelValues(result).Inc()
+}
+
+func (p *Prometheus) FetchCompleted(dataSourceID string, duration time.Duration, failed bool) {
+	p.fetchDuration.WithLabelValues(dataSourceID).Observe(duration.Seconds())
+	if failed {
+		p.fetchErrors.WithLabelValues(dataSourceID).Inc()
+	}
+}
+
+func (p *Prometheus) ActiveSubscriptions(subscriptions, triggers int) {
+	p.subscriptions.Set(float64(subscriptions))
+	p.triggers.Set(float64(triggers))
+}
+
+func (p *Prometheus) SubscriptionUpdatesDropped(policy string, count int) {
+	p.droppedUpdates.WithLabelValues(policy).Add(float64(count))
+}
+
+func (p *Prometheus) WebSocketConnectionOpened() {
+	p.websocketConnection.Inc()
+}
+
+func (p *Prometheus) WebSocketConnectionClosed() {
+	p.websocketConnection.Dec()
+}
+
+func (p *Prometheus) KafkaConsumerLag(groupID, topic string, partition int32, lag int64) {
+	p.kafkaConsumerLag.WithLabelValues(groupID, topic, strconv.FormatInt(int64(partition), 10)).Set(float64(lag))
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheus(t *testing.T) {
+	newPrometheus := func(t *testing.T) (*Prometheus, *prometheus.Registry) {
+		registry := prometheus.NewRegistry()
+		p, err := NewPrometheus(registry, PrometheusOptions{
+			DurationBuckets: []float64{0.1, 1},
+		})
+		require.NoError(t, err)
+		return p, registry
+	}
+
+	t.Run("operations", func(t *testing.T) {
+		p, registry := newPrometheus(t)
+		p.OperationCompleted("query", "Products", 50*time.Millisecond, false)
+		p.OperationCompleted("query", "Products", 500*time.Millisecond, true)
+
+		expected := `
+# HELP graphql_operations_total Number of executed operations.
+# TYPE graphql_operations_total counter
+graphql_operations_total{operation_name="Products",operation_type="query",status="error"} 1
+graphql_operations_total{operation_name="Products",operation_type="query",status="success"} 1
+# HELP graphql_operation_duration_seconds Duration of the execution of operations.
+# TYPE graphql_operation_duration_seconds histogram
+graphql_operation_duration_seconds_bucket{operation_name="Products",operation_type="query",le="0.1"} 1
+graphql_operation_duration_seconds_bucket{operation_name="Products",operation_type="query",le="1"} 2
+graphql_operation_duration_seconds_bucket{operation_name="Products",operation_type="query",le="+Inf"} 2
+graphql_operation_duration_seconds_sum{operation_name="Products",operation_type="query"} 0.55
+graphql_operation_duration_seconds_count{operation_name="Products",operation_type="query"} 2
+`
+		assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "graphql_operations_total", "graphql_operation_duration_seconds"))
+	})
+
+	t.Run("plan cache and fetches", func(t *testing.T) {
+		p, registry := newPrometheus(t)
+		p.PlanCacheLookup(false)
+		p.PlanCacheLookup(true)
+		p.PlanCacheLookup(true)
+		p.FetchCompleted("products", 20*time.Millisecond, false)
+		p.FetchCompleted("products", 20*time.Millisecond, true)
+
+		expected := `
+# HELP graphql_plan_cache_lookups_total Number of lookups in the execution plan cache by result.
+# TYPE graphql_plan_cache_lookups_total counter
+graphql_plan_cache_lookups_total{result="hit"} 2
+graphql_plan_cache_lookups_total{result="miss"} 1
+# HELP graphql_fetch_errors_total Number of failed data source fetches.
+# TYPE graphql_fetch_errors_total counter
+graphql_fetch_errors_total{datasource="products"} 1
+`
+		assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "graphql_plan_cache_lookups_total", "graphql_fetch_errors_total"))
+		assert.Equal(t, 1, testutil.CollectAndCount(p.fetchDuration))
+	})
+
+	t.Run("subscriptions, websocket connections and kafka consumer lag", func(t *testing.T) {
+		p, registry := newPrometheus(t)
+		p.ActiveSubscriptions(3, 2)
+		p.SubscriptionUpdatesDropped("drop_oldest", 2)
+		p.SubscriptionUpdatesDropped("drop_oldest", 1)
+		p.WebSocketConnectionOpened()
+		p.WebSocketConnectionOpened()
+		p.WebSocketConnectionClosed()
+		p.KafkaConsumerLag("group", "products", 1, 42)
+
+		expected := `
+# HELP graphql_active_subscriptions Number of active subscriptions.
+# TYPE graphql_active_subscriptions gauge
+graphql_active_subscriptions 3
+# HELP graphql_active_subscription_triggers Number of active upstream subscriptions shared by subscriptions.
+# TYPE graphql_active_subscription_triggers gauge
+graphql_active_subscription_triggers 2
+# HELP graphql_subscription_updates_dropped_total Number of subscription updates dropped because the client could not keep up, by overflow policy.
+# TYPE graphql_subscription_updates_dropped_total counter
+graphql_subscription_updates_dropped_total{policy="drop_oldest"} 3
+# HELP graphql_websocket_connections Number of open websocket connections of clients.
+# TYPE graphql_websocket_connections gauge
+graphql_websocket_connections 1
+# HELP graphql_kafka_consumer_lag Number of messages of a partition not consumed by the consumer group yet.
+# TYPE graphql_kafka_consumer_lag gauge
+graphql_kafka_consumer_lag{group_id="group",partition="1",topic="products"} 42
+`
+		assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+			"graphql_active_subscriptions", "graphql_active_subscription_triggers", "graphql_subscription_updates_dropped_total", "graphql_websocket_connections", "graphql_kafka_consumer_lag"))
+	})
+
+	t.Run("namespace", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		p, err := NewPrometheus(registry, PrometheusOptions{Namespace: "gateway"})
+		require.NoError(t, err)
+		p.PlanCacheLookup(true)
+
+		families, err := registry.Gather()
+		require.NoError(t, err)
+		require.NotEmpty(t, families)
+		for _, family := range families {
+			assert.True(t, strings.HasPrefix(family.GetName(), "gateway_"), family.GetName())
+		}
+	})
+
+	t.Run("registering twice fails", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		_, err := NewPrometheus(registry, PrometheusOptions{})
+		require.NoError(t, err)
+		_, err = NewPrometheus(registry, PrometheusOptions{})
+		assert.Error(t, err)
+	})
+}
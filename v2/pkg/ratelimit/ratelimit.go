@@ -0,0 +1,137 @@
+// Package ratelimit implements token bucket rate limiting with pluggable stores for the buckets.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is the configuration of a token bucket.
+type Limit struct {
+	// Rate is the number of tokens added to the bucket per second
+	Rate float64
+	// Burst is the capacity of the bucket, it is the maximum cost allowed at once
+	Burst int
+}
+
+// Result is the result of taking tokens from a bucket.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left in the bucket
+	Remaining int
+	// RetryAfter is the time until enough tokens are available if the request was not allowed.
+	// It is zero if the cost exceeds the burst, because such a request is never allowed.
+	RetryAfter time.Duration
+}
+
+// Store holds the token buckets of all keys.
+// Implementations for distributed counters, e.g. backed by Redis, must take the tokens atomically.
+type Store interface {
+	// Take takes cost tokens from the bucket of key, nothing is taken if the request is not allowed.
+	Take(ctx context.Context, key string, cost int, limit Limit) (Result, error)
+}
+
+// Bucket is a token bucket, it is full when created.
+type Bucket struct {
+	mu      sync.Mutex
+	limit   Limit
+	tokens  float64
+	updated time.Time
+}
+
+// NewBucket creates a full bucket.
+func NewBucket(limit Limit) *Bucket {
+	return &Bucket{
+		limit:   limit,
+		tokens:  float64(limit.Burst),
+		updated: time.Now(),
+	}
+}
+
+// Take takes cost tokens from the bucket.
+func (b *Bucket) Take(cost int) Result {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.take(time.Now(), cost)
+}
+
+func (b *Bucket) take(now time.Time, cost int) Result {
+	b.refill(now)
+	if float64(cost) <= b.tokens {
+		b.tokens -= float64(cost)
+		return Result{Allowed: true, Remaining: int(b.tokens)}
+	}
+	result := Result{Remaining: int(b.tokens)}
+	if cost <= b.limit.Burst && b.limit.Rate > 0 {
+		seconds := (float64(cost) - b.tokens) / b.limit.Rate
+		result.RetryAfter = time.Duration(math.Ceil(seconds * float64(time.Second)))
+	}
+	return result
+}
+
+func (b *Bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed.Seconds()*b.limit.Rate)
+	}
+	b.updated = now
+}
+
+// full returns true if the bucket would be full at now, full buckets are equal to new ones
+func (b *Bucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= float64(b.limit.Burst)
+}
+
+// inMemorySweepInterval is the interval in which full buckets are removed from the InMemoryStore
+const inMemorySweepInterval = time.Minute
+
+// InMemoryStore is a Store keeping the buckets in memory, it limits the requests of a single instance only.
+type InMemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*Bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewInMemoryStore creates an empty InMemoryStore.
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{
+		buckets: make(map[string]*Bucket),
+		now:     time.Now,
+	}
+}
+
+func (s *InMemoryStore) Take(_ context.Context, key string, cost int, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	bucket, ok := s.buckets[key]
+	if !ok || bucket.limit != limit {
+		bucket = &Bucket{limit: limit, tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = bucket
+	}
+	return bucket.take(now, cost), nil
+}
+
+// sweep removes full buckets, so that the store doesn't grow with every key ever seen
+func (s *InMemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < inMemorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, bucket := range s.buckets {
+		if bucket.full(now) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// Interface Guards
+var (
+	_ Store = (*InMemoryStore)(nil)
+)
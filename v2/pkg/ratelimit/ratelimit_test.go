@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucket(t *testing.T) {
+	start := time.Now()
+	newBucket := func() *Bucket {
+		return &Bucket{limit: Limit{Rate: 2, Burst: 4}, tokens: 4, updated: start}
+	}
+
+	t.Run("takes tokens until the bucket is empty", func(t *testing.T) {
+		bucket := newBucket()
+		assert.Equal(t, Result{Allowed: true, Remaining: 1}, bucket.take(start, 3))
+		assert.Equal(t, Result{Allowed: true, Remaining: 0}, bucket.take(start, 1))
+		assert.Equal(t, Result{Allowed: false, Remaining: 0, RetryAfter: 500 * time.Millisecond}, bucket.take(start, 1))
+	})
+
+	t.Run("refills tokens up to the burst", func(t *testing.T) {
+		bucket := newBucket()
+		require.True(t, bucket.take(start, 4).Allowed)
+		assert.Equal(t, Result{Allowed: true, Remaining: 0}, bucket.take(start.Add(time.Second), 2))
+		assert.Equal(t, Result{Allowed: true, Remaining: 0}, bucket.take(start.Add(time.Hour), 4))
+	})
+
+	t.Run("rejected requests take no tokens", func(t *testing.T) {
+		bucket := newBucket()
+		require.True(t, bucket.take(start, 3).Allowed)
+		assert.Equal(t, Result{Allowed: false, Remaining: 1, RetryAfter: time.Second}, bucket.take(start, 3))
+		assert.Equal(t, Result{Allowed: true, Remaining: 0}, bucket.take(start, 1))
+	})
+
+	t.Run("cost exceeding the burst is never allowed", func(t *testing.T) {
+		bucket := newBucket()
+		assert.Equal(t, Result{Allowed: false, Remaining: 4}, bucket.take(start, 5))
+	})
+}
+
+func TestInMemoryStore(t *testing.T) {
+	limit := Limit{Rate: 1, Burst: 2}
+
+	newStore := func() (*InMemoryStore, *time.Time) {
+		now := time.Now()
+		store := NewInMemoryStore()
+		store.now = func() time.Time {
+			return now
+		}
+		return store, &now
+	}
+
+	take := func(t *testing.T, store *InMemoryStore, key string, cost int, limit Limit) Result {
+		t.Helper()
+		result, err := store.Take(context.Background(), key, cost, limit)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("buckets are separated by key", func(t *testing.T) {
+		store, _ := newStore()
+		assert.True(t, take(t, store, "a", 2, limit).Allowed)
+		assert.False(t, take(t, store, "a", 1, limit).Allowed)
+		assert.True(t, take(t, store, "b", 2, limit).Allowed)
+	})
+
+	t.Run("changed limit resets the bucket", func(t *testing.T) {
+		store, _ := newStore()
+		assert.True(t, take(t, store, "a", 2, limit).Allowed)
+		assert.True(t, take(t, store, "a", 2, Limit{Rate: 1, Burst: 3}).Allowed)
+	})
+
+	t.Run("full buckets are swept", func(t *testing.T) {
+		store, now := newStore()
+		take(t, store, "a", 1, limit)
+		take(t, store, "b", 1, Limit{Rate: 0.001, Burst: 2})
+		require.Len(t, store.buckets, 2)
+
+		*now = now.Add(inMemorySweepInterval)
+		take(t, store, "c", 1, limit)
+		assert.Len(t, store.buckets, 2)
+		assert.NotContains(t, store.buckets, "a")
+		assert.Contains(t, store.buckets, "b")
+	})
+}
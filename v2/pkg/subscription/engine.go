@@ -14,6 +14,7 @@ import (
 
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ratelimit"
 )
 
 type errOnBeforeStartHookFailure struct {
@@ -62,6 +63,8 @@ type ExecutorEngine struct {
 	maxExecutionTries int
 	// initialRetryWaitTime is the time that will initially be set for waiting for the next retry attempt.
 	initialRetryWaitTime time.Duration
+	// subscriptionStartLimit is the token bucket limiting the subscriptions started on the connection, nil if unlimited.
+	subscriptionStartLimit *ratelimit.Bucket
 }
 
 // StartOperation will start any operation.
@@ -71,6 +74,11 @@ func (e *ExecutorEngine) StartOperation(ctx context.Context, id string, payload
 		return err
 	}
 
+	if err = e.checkSubscriptionStartLimit(executor); err != nil {
+		eventHandler.Emit(EventTypeOnError, id, nil, err)
+		return err
+	}
+
 	if err = e.handleOnBeforeStart(executor); err != nil {
 		eventHandler.Emit(EventTypeOnError, id, nil, err)
 		return &errOnBeforeStartHookFailure{wrappedErr: err}
@@ -80,7 +88,7 @@ func (e *ExecutorEngine) StartOperation(ctx context.Context, id string, payload
 		return err
 	}
 
-	if executor.OperationType() == ast.OperationTypeSubscription {
+	if executor.OperationType() == ast.OperationTypeSubscription || isLiveQuery(executor) {
 		go e.startSubscription(ctx, id, executor, eventHandler)
 		return nil
 	}
@@ -110,6 +118,17 @@ func (e *ExecutorEngine) TerminateAllSubscriptions(eventHandler EventHandler) er
 	return nil
 }
 
+// checkSubscriptionStartLimit returns a graphql.RateLimitExceededError if the operation is a subscription exceeding the subscription start limit
+func (e *ExecutorEngine) checkSubscriptionStartLimit(executor Executor) error {
+	if e.subscriptionStartLimit == nil || (executor.OperationType() != ast.OperationTypeSubscription && !isLiveQuery(executor)) {
+		return nil
+	}
+	if result := e.subscriptionStartLimit.Take(1); !result.Allowed {
+		return &graphql.RateLimitExceededError{RetryAfter: result.RetryAfter}
+	}
+	return nil
+}
+
 func (e *ExecutorEngine) handleOnBeforeStart(executor Executor) error {
 	switch e := executor.(type) {
 	case *ExecutorV2:
@@ -121,6 +140,17 @@ func (e *ExecutorEngine) handleOnBeforeStart(executor Executor) error {
 	return nil
 }
 
+// isLiveQuery returns true if the executor executes a query marked with @live, its results are streamed like the ones of a subscription
+func isLiveQuery(executor Executor) bool {
+	switch e := executor.(type) {
+	case *ExecutorV2:
+		isLive, err := e.operation.IsLiveQuery()
+		return err == nil && isLive
+	}
+
+	return false
+}
+
 func (e *ExecutorEngine) checkForDuplicateSubscriberID(ctx context.Context, id string, eventHandler EventHandler) (context.Context, error) {
 	ctx, subsErr := e.subCancellations.AddWithParent(id, ctx)
 	if errors.Is(subsErr, ErrSubscriberIDAlreadyExists) {
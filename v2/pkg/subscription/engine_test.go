@@ -14,6 +14,7 @@ import (
 
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ast"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ratelimit"
 )
 
 func TestExecutorEngine_StartExecutionBackoff(t *testing.T) {
@@ -484,6 +485,72 @@ func TestExecutorEngine_StartOperation(t *testing.T) {
 			return true
 		}, 1*time.Second, 10*time.Millisecond)
 	})
+
+	t.Run("subscription start limit", func(t *testing.T) {
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx, cancelFunc := context.WithTimeout(context.Background(), 25*time.Millisecond)
+		defer cancelFunc()
+
+		payload := []byte(`{"query":"subscription { receiveData }"}`)
+
+		executorMock := NewMockExecutor(ctrl)
+		executorMock.EXPECT().OperationType().
+			Return(ast.OperationTypeSubscription).
+			Times(3)
+		executorMock.EXPECT().SetContext(assignableToContextWithCancel(ctx)).
+			Times(1)
+		executorMock.EXPECT().Execute(gomock.AssignableToTypeOf(&graphql.EngineResultWriter{})).
+			Do(func(resultWriter *graphql.EngineResultWriter) {
+				_, _ = resultWriter.Write([]byte(`{ "data": { "receiveData": "newData" } }`))
+			}).
+			Times(1)
+
+		executorPoolMock := NewMockExecutorPool(ctrl)
+		executorPoolMock.EXPECT().Get(gomock.Eq(payload)).
+			Return(executorMock, nil).
+			Times(2)
+		executorPoolMock.EXPECT().Put(gomock.Eq(executorMock)).
+			Do(func(_ Executor) {
+				wg.Done()
+			}).
+			Times(1)
+
+		eventHandlerMock := NewMockEventHandler(ctrl)
+		eventHandlerMock.EXPECT().Emit(gomock.Eq(EventTypeOnSubscriptionData), gomock.Eq("1"), gomock.AssignableToTypeOf([]byte{}), gomock.Nil()).
+			Times(1)
+		eventHandlerMock.EXPECT().Emit(gomock.Eq(EventTypeOnError), gomock.Eq("2"), gomock.Nil(), gomock.AssignableToTypeOf(&graphql.RateLimitExceededError{})).
+			Times(1)
+
+		engine := ExecutorEngine{
+			logger:           abstractlogger.Noop{},
+			subCancellations: subscriptionCancellations{},
+			executorPool:     executorPoolMock,
+			bufferPool: &sync.Pool{
+				New: func() interface{} {
+					writer := graphql.NewEngineResultWriterFromBuffer(bytes.NewBuffer(make([]byte, 0, 1024)))
+					return &writer
+				},
+			},
+			subscriptionUpdateInterval: 100 * time.Millisecond,
+			subscriptionStartLimit:     ratelimit.NewBucket(ratelimit.Limit{Rate: 0.001, Burst: 1}),
+		}
+
+		err := engine.StartOperation(ctx, "1", payload, eventHandlerMock)
+		assert.NoError(t, err)
+
+		err = engine.StartOperation(ctx, "2", payload, eventHandlerMock)
+		var rateLimitErr *graphql.RateLimitExceededError
+		assert.ErrorAs(t, err, &rateLimitErr)
+		assert.Greater(t, rateLimitErr.RetryAfter, time.Duration(0))
+
+		<-ctx.Done()
+		wg.Wait()
+	})
 }
 
 func TestExecutorEngine_StopSubscription(t *testing.T) {
@@ -12,6 +12,7 @@ import (
 	"github.com/jensneuse/abstractlogger"
 
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/graphql"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ratelimit"
 )
 
 var ErrCouldNotReadMessageFromClient = errors.New("could not read message from client")
@@ -49,6 +50,8 @@ type UniversalProtocolHandlerOptions struct {
 	CustomReadErrorTimeOut           time.Duration
 	CustomSubscriptionExecutionTries int
 	CustomEngine                     Engine
+	// SubscriptionStartLimit limits the subscriptions started per connection, it is ignored if CustomEngine is set
+	SubscriptionStartLimit *ratelimit.Limit
 }
 
 // UniversalProtocolHandler can handle any protocol by using the Protocol interface.
@@ -124,6 +127,10 @@ func NewUniversalProtocolHandlerWithOptions(client TransportClient, protocol Pro
 		} else {
 			engine.maxExecutionTries = DefaultSubscriptionExecutionTries
 		}
+
+		if options.SubscriptionStartLimit != nil {
+			engine.subscriptionStartLimit = ratelimit.NewBucket(*options.SubscriptionStartLimit)
+		}
 		handler.engine = &engine
 	}
 
@@ -192,9 +199,10 @@ func (u *UniversalProtocolHandler) Handle(ctx context.Context) {
 				err := u.protocol.Handle(ctxWithCancel, u.engine, message)
 				if err != nil {
 					var onBeforeStartHookError *errOnBeforeStartHookFailure
-					if errors.As(err, &onBeforeStartHookError) {
-						// if we do have an errOnBeforeStartHookFailure than the error is expected and should be
-						// logged as 'Debug'.
+					var rateLimitExceededError *graphql.RateLimitExceededError
+					if errors.As(err, &onBeforeStartHookError) || errors.As(err, &rateLimitExceededError) {
+						// if we do have an errOnBeforeStartHookFailure or a rejected subscription start than the error is expected
+						// and should be logged as 'Debug'.
 						u.logger.Debug("subscription.UniversalProtocolHandler.Handle: on protocol handling message",
 							abstractlogger.Error(err),
 						)
@@ -8,6 +8,8 @@ import (
 
 	"github.com/jensneuse/abstractlogger"
 
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/metrics"
+	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/ratelimit"
 	"github.com/TykTechnologies/graphql-go-tools/v2/pkg/subscription"
 )
 
@@ -40,6 +42,8 @@ type HandleOptions struct {
 	CustomReadErrorTimeOut           time.Duration
 	CustomSubscriptionEngine         subscription.Engine
 	CustomSubscriptionExecutionRetry int
+	Metrics                          metrics.Metrics
+	SubscriptionStartLimit           *ratelimit.Limit
 }
 
 // HandleOptionFunc can be used to define option functions.
@@ -109,6 +113,21 @@ func WithCustomSubscriptionEngine(subscriptionEngine subscription.Engine) Handle
 	}
 }
 
+// WithMetrics is a function that sets the metrics counting the open websocket connections.
+func WithMetrics(m metrics.Metrics) HandleOptionFunc {
+	return func(opts *HandleOptions) {
+		opts.Metrics = m
+	}
+}
+
+// WithSubscriptionStartLimit is a function that limits the subscriptions a client can start on a connection,
+// subscriptions exceeding the limit are rejected with a rate limit error.
+func WithSubscriptionStartLimit(limit ratelimit.Limit) HandleOptionFunc {
+	return func(opts *HandleOptions) {
+		opts.SubscriptionStartLimit = &limit
+	}
+}
+
 // WithProtocol is a function that sets the protocol.
 func WithProtocol(protocol Protocol) HandleOptionFunc {
 	return func(opts *HandleOptions) {
@@ -193,6 +212,7 @@ func HandleWithOptions(done chan bool, errChan chan error, conn net.Conn, execut
 		CustomSubscriptionUpdateInterval: options.CustomSubscriptionUpdateInterval,
 		CustomReadErrorTimeOut:           options.CustomReadErrorTimeOut,
 		CustomEngine:                     options.CustomSubscriptionEngine,
+		SubscriptionStartLimit:           options.SubscriptionStartLimit,
 	})
 	if err != nil {
 		options.Logger.Error("websocket.HandleWithOptions: on subscription handler creation",
@@ -205,6 +225,10 @@ func HandleWithOptions(done chan bool, errChan chan error, conn net.Conn, execut
 		return
 	}
 
+	m := metrics.OrNoop(options.Metrics)
+	m.WebSocketConnectionOpened()
+	defer m.WebSocketConnectionClosed()
+
 	close(done)
 	subscriptionHandler.Handle(context.Background()) // Blocking
 }